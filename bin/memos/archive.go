@@ -0,0 +1,211 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/usememos/memos/plugin/archive"
+	apiv1 "github.com/usememos/memos/server/router/api/v1"
+	"github.com/usememos/memos/store"
+	"github.com/usememos/memos/store/db"
+)
+
+var (
+	exportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export the memos and resources of a user as a zip archive",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			s, user, err := openStoreForUser(ctx, cmd)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			userArchive, err := apiv1.ExportUserArchive(ctx, s, user)
+			if err != nil {
+				return err
+			}
+			output, _ := cmd.Flags().GetString("output")
+			file, err := os.Create(output)
+			if err != nil {
+				return errors.Wrap(err, "failed to create output file")
+			}
+			defer file.Close()
+			if err := archive.Write(file, userArchive); err != nil {
+				return err
+			}
+			fmt.Printf("Exported %d memos and %d resources to %s\n", len(userArchive.Memos), len(userArchive.Resources), output)
+			return nil
+		},
+	}
+	importCmd = &cobra.Command{
+		Use:   "import",
+		Short: "Import a zip archive produced by export for a user",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			s, user, err := openStoreForUser(ctx, cmd)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			input, _ := cmd.Flags().GetString("input")
+			file, err := os.Open(input)
+			if err != nil {
+				return errors.Wrap(err, "failed to open input file")
+			}
+			defer file.Close()
+			stat, err := file.Stat()
+			if err != nil {
+				return errors.Wrap(err, "failed to stat input file")
+			}
+			userArchive, err := archive.Read(file, stat.Size(), archive.Limits{})
+			if err != nil {
+				return err
+			}
+			result, err := apiv1.ImportUserArchive(ctx, s, user, userArchive)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Imported %d memos and %d resources, skipped %d memos and %d resources imported before\n",
+				result.ImportedMemos, result.ImportedResources, result.SkippedMemos, result.SkippedResources)
+			return nil
+		},
+	}
+	importNotesCmd = &cobra.Command{
+		Use:   "import-notes",
+		Short: "Import the notes of another app for a user",
+		Long: `Import the notes of another app for a user.
+
+The source is one of obsidian (a vault directory or zip), evernote (an .enex file)
+and google-keep (a Takeout directory or zip). Use --dry-run to report what would be
+imported without writing anything.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			source, _ := cmd.Flags().GetString("source")
+			input, _ := cmd.Flags().GetString("input")
+			notes, err := readNotes(source, input)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Found %d memos, %d resources and %d relations\n", len(notes.Memos), len(notes.Resources), apiv1.CountArchiveRelations(notes))
+			for _, warning := range notes.Warnings {
+				fmt.Printf("warning: %s\n", warning)
+			}
+			if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+				return nil
+			}
+
+			s, user, err := openStoreForUser(ctx, cmd)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+			result, err := apiv1.ImportUserArchive(ctx, s, user, notes)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Imported %d memos and %d resources, skipped %d memos and %d resources imported before\n",
+				result.ImportedMemos, result.ImportedResources, result.SkippedMemos, result.SkippedResources)
+			return nil
+		},
+	}
+)
+
+func init() {
+	exportCmd.Flags().String("user", "", "username of the user to export")
+	exportCmd.Flags().String("output", "memos-export.zip", "path of the archive to write")
+	importCmd.Flags().String("user", "", "username of the user to import for")
+	importCmd.Flags().String("input", "", "path of the archive to import")
+	importNotesCmd.Flags().String("user", "", "username of the user to import for")
+	importNotesCmd.Flags().String("source", "", "app the notes are exported from: obsidian, evernote or google-keep")
+	importNotesCmd.Flags().String("input", "", "path of the exported notes")
+	importNotesCmd.Flags().Bool("dry-run", false, "report what would be imported without importing")
+	for _, cmd := range []*cobra.Command{exportCmd, importCmd, importNotesCmd} {
+		if err := cmd.MarkFlagRequired("user"); err != nil {
+			panic(err)
+		}
+	}
+	for _, cmd := range []*cobra.Command{importCmd, importNotesCmd} {
+		if err := cmd.MarkFlagRequired("input"); err != nil {
+			panic(err)
+		}
+	}
+	if err := importNotesCmd.MarkFlagRequired("source"); err != nil {
+		panic(err)
+	}
+
+	rootCmd.AddCommand(exportCmd, importCmd, importNotesCmd)
+}
+
+// readNotes converts the notes exported from source at input to an archive.
+func readNotes(source, input string) (*archive.Archive, error) {
+	if source == "evernote" {
+		file, err := os.Open(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open input file")
+		}
+		defer file.Close()
+		return archive.ReadEvernoteENEX(file)
+	}
+
+	var read func(fs.FS) (*archive.Archive, error)
+	switch source {
+	case "obsidian":
+		read = archive.ReadObsidianVault
+	case "google-keep":
+		read = archive.ReadGoogleKeepTakeout
+	default:
+		return nil, errors.Errorf("unsupported source %q", source)
+	}
+	stat, err := os.Stat(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to stat input")
+	}
+	if stat.IsDir() {
+		return read(os.DirFS(input))
+	}
+	zipReader, err := zip.OpenReader(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open zip")
+	}
+	defer zipReader.Close()
+	return read(zipReader)
+}
+
+// openStoreForUser opens the migrated store of the instance and returns the user of the --user flag.
+func openStoreForUser(ctx context.Context, cmd *cobra.Command) (*store.Store, *store.User, error) {
+	instanceProfile := newInstanceProfile()
+	if err := instanceProfile.Validate(); err != nil {
+		return nil, nil, err
+	}
+	dbDriver, err := db.NewDBDriver(instanceProfile)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create db driver")
+	}
+	s := store.New(dbDriver, instanceProfile)
+	if err := s.Migrate(ctx); err != nil {
+		s.Close()
+		return nil, nil, errors.Wrap(err, "failed to migrate")
+	}
+
+	username, _ := cmd.Flags().GetString("user")
+	user, err := s.GetUser(ctx, &store.FindUser{Username: &username})
+	if err != nil {
+		s.Close()
+		return nil, nil, errors.Wrap(err, "failed to get user")
+	}
+	if user == nil {
+		s.Close()
+		return nil, nil, errors.Errorf("user %q not found", username)
+	}
+	return s, user, nil
+}
@@ -35,16 +35,7 @@ var (
 		Use:   "memos",
 		Short: `An open source, lightweight note-taking service. Easily capture and share your great thoughts.`,
 		Run: func(_ *cobra.Command, _ []string) {
-			instanceProfile := &profile.Profile{
-				Mode:        viper.GetString("mode"),
-				Addr:        viper.GetString("addr"),
-				Port:        viper.GetInt("port"),
-				Data:        viper.GetString("data"),
-				Driver:      viper.GetString("driver"),
-				DSN:         viper.GetString("dsn"),
-				InstanceURL: viper.GetString("instance-url"),
-				Version:     version.GetCurrentVersion(viper.GetString("mode")),
-			}
+			instanceProfile := newInstanceProfile()
 			if err := instanceProfile.Validate(); err != nil {
 				panic(err)
 			}
@@ -110,6 +101,7 @@ func init() {
 	rootCmd.PersistentFlags().String("driver", "sqlite", "database driver")
 	rootCmd.PersistentFlags().String("dsn", "", "database source name(aka. DSN)")
 	rootCmd.PersistentFlags().String("instance-url", "", "the url of your memos instance")
+	rootCmd.PersistentFlags().StringSlice("trusted-proxies", nil, "IPs and CIDRs of the reverse proxies whose X-Forwarded-For headers are trusted")
 
 	if err := viper.BindPFlag("mode", rootCmd.PersistentFlags().Lookup("mode")); err != nil {
 		panic(err)
@@ -132,12 +124,32 @@ func init() {
 	if err := viper.BindPFlag("instance-url", rootCmd.PersistentFlags().Lookup("instance-url")); err != nil {
 		panic(err)
 	}
+	if err := viper.BindPFlag("trusted-proxies", rootCmd.PersistentFlags().Lookup("trusted-proxies")); err != nil {
+		panic(err)
+	}
 
 	viper.SetEnvPrefix("memos")
 	viper.AutomaticEnv()
 	if err := viper.BindEnv("instance-url", "MEMOS_INSTANCE_URL"); err != nil {
 		panic(err)
 	}
+	if err := viper.BindEnv("trusted-proxies", "MEMOS_TRUSTED_PROXIES"); err != nil {
+		panic(err)
+	}
+}
+
+func newInstanceProfile() *profile.Profile {
+	return &profile.Profile{
+		Mode:           viper.GetString("mode"),
+		Addr:           viper.GetString("addr"),
+		Port:           viper.GetInt("port"),
+		Data:           viper.GetString("data"),
+		Driver:         viper.GetString("driver"),
+		DSN:            viper.GetString("dsn"),
+		InstanceURL:    viper.GetString("instance-url"),
+		TrustedProxies: viper.GetStringSlice("trusted-proxies"),
+		Version:        version.GetCurrentVersion(viper.GetString("mode")),
+	}
 }
 
 func printGreetings(profile *profile.Profile) {
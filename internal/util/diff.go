@@ -0,0 +1,56 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// DiffOperation is the operation of a line in a diff.
+type DiffOperation int
+
+const (
+	// DiffEqual is a line in both texts.
+	DiffEqual DiffOperation = iota
+	// DiffInsert is a line only in the new text.
+	DiffInsert
+	// DiffDelete is a line only in the old text.
+	DiffDelete
+)
+
+type DiffLine struct {
+	Operation DiffOperation
+	Content   string
+}
+
+// DiffLines returns the line-level diff that turns the old text into the new one.
+// Deleted lines come before the lines inserted in their place.
+func DiffLines(oldText, newText string) []DiffLine {
+	a, b := splitLines(oldText), splitLines(newText)
+	lines := []DiffLine{}
+	for _, opCode := range difflib.NewMatcher(a, b).GetOpCodes() {
+		switch opCode.Tag {
+		case 'e':
+			for _, line := range a[opCode.I1:opCode.I2] {
+				lines = append(lines, DiffLine{Operation: DiffEqual, Content: line})
+			}
+		case 'd', 'r':
+			for _, line := range a[opCode.I1:opCode.I2] {
+				lines = append(lines, DiffLine{Operation: DiffDelete, Content: line})
+			}
+		}
+		if opCode.Tag == 'i' || opCode.Tag == 'r' {
+			for _, line := range b[opCode.J1:opCode.J2] {
+				lines = append(lines, DiffLine{Operation: DiffInsert, Content: line})
+			}
+		}
+	}
+	return lines
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		oldText string
+		newText string
+		want    []DiffLine
+	}{
+		{
+			oldText: "",
+			newText: "",
+			want:    []DiffLine{},
+		},
+		{
+			oldText: "",
+			newText: "a\nb",
+			want: []DiffLine{
+				{Operation: DiffInsert, Content: "a"},
+				{Operation: DiffInsert, Content: "b"},
+			},
+		},
+		{
+			oldText: "a\nb\nc",
+			newText: "a\nc",
+			want: []DiffLine{
+				{Operation: DiffEqual, Content: "a"},
+				{Operation: DiffDelete, Content: "b"},
+				{Operation: DiffEqual, Content: "c"},
+			},
+		},
+		{
+			oldText: "a\nb\nc",
+			newText: "a\nB\nc\nd",
+			want: []DiffLine{
+				{Operation: DiffEqual, Content: "a"},
+				{Operation: DiffDelete, Content: "b"},
+				{Operation: DiffInsert, Content: "B"},
+				{Operation: DiffEqual, Content: "c"},
+				{Operation: DiffInsert, Content: "d"},
+			},
+		},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.want, DiffLines(test.oldText, test.newText))
+	}
+}
@@ -0,0 +1,294 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Version is the version of the archive format.
+	Version = 1
+	// ManifestPath is the path of the manifest in the archive.
+	ManifestPath = "manifest.json"
+
+	frontMatterDelimiter = "---"
+)
+
+// Archive is a portable copy of a user's memos and resources.
+//
+// It is stored as a zip with a manifest, a Markdown file with YAML front matter per memo
+// under memos/, and the resource blobs under resources/.
+type Archive struct {
+	ExportTime time.Time
+	Username   string
+	Memos      []*Memo
+	Resources  []*Resource
+
+	// Warnings are the problems met converting notes of another app, they are not written.
+	Warnings []string
+}
+
+// Memo is a memo in the archive. Its fields other than Content form the front matter.
+type Memo struct {
+	UID        string      `yaml:"uid"`
+	Visibility string      `yaml:"visibility"`
+	State      string      `yaml:"state,omitempty"`
+	Pinned     bool        `yaml:"pinned,omitempty"`
+	Tags       []string    `yaml:"tags,omitempty"`
+	CreateTime time.Time   `yaml:"created"`
+	UpdateTime time.Time   `yaml:"updated"`
+	Location   *Location   `yaml:"location,omitempty"`
+	Relations  []*Relation `yaml:"relations,omitempty"`
+	Resources  []string    `yaml:"resources,omitempty"`
+	// ScheduleTime is the time a scheduled memo is published, nil if it is published.
+	ScheduleTime *time.Time `yaml:"schedule_time,omitempty"`
+
+	Content string `yaml:"-"`
+}
+
+type Location struct {
+	Placeholder string  `yaml:"placeholder,omitempty"`
+	Latitude    float64 `yaml:"latitude"`
+	Longitude   float64 `yaml:"longitude"`
+}
+
+// Relation is a relation from the memo to the memo with UID Memo.
+type Relation struct {
+	Memo string `yaml:"memo"`
+	Type string `yaml:"type"`
+}
+
+// Resource is a resource in the archive, its metadata is kept in the manifest.
+type Resource struct {
+	UID        string    `json:"uid"`
+	Filename   string    `json:"filename"`
+	Type       string    `json:"type"`
+	Size       int64     `json:"size"`
+	CreateTime time.Time `json:"created"`
+	// Memo is the UID of the memo the resource is attached to, empty if it is not attached.
+	Memo string `json:"memo,omitempty"`
+	// ExternalLink is set for resources that link to an external file, they have no blob.
+	ExternalLink string `json:"external_link,omitempty"`
+	// Path is the path of the blob in the archive.
+	Path string `json:"path,omitempty"`
+
+	Blob []byte `json:"-"`
+	// Open opens the blob when Blob is nil, so that writing an archive holds one blob in memory at most.
+	Open func() (io.ReadCloser, error) `json:"-"`
+}
+
+type manifest struct {
+	Version    int             `json:"version"`
+	ExportTime time.Time       `json:"export_time"`
+	Username   string          `json:"username"`
+	Memos      []*manifestMemo `json:"memos"`
+	Resources  []*Resource     `json:"resources"`
+}
+
+type manifestMemo struct {
+	UID  string `json:"uid"`
+	Path string `json:"path"`
+}
+
+// Write writes the archive as a zip.
+func Write(w io.Writer, archive *Archive) error {
+	zipWriter := zip.NewWriter(w)
+	m := &manifest{
+		Version:    Version,
+		ExportTime: archive.ExportTime,
+		Username:   archive.Username,
+		Memos:      []*manifestMemo{},
+		Resources:  []*Resource{},
+	}
+
+	for _, memo := range archive.Memos {
+		content, err := MarshalMemo(memo)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal memo %s", memo.UID)
+		}
+		memoPath := fmt.Sprintf("memos/%s.md", memo.UID)
+		if err := writeFile(zipWriter, memoPath, content); err != nil {
+			return err
+		}
+		m.Memos = append(m.Memos, &manifestMemo{UID: memo.UID, Path: memoPath})
+	}
+	for _, resource := range archive.Resources {
+		if resource.ExternalLink == "" {
+			resource.Path = fmt.Sprintf("resources/%s/%s", resource.UID, sanitizeFilename(resource.Filename))
+			if resource.Blob == nil && resource.Open != nil {
+				if err := copyFile(zipWriter, resource.Path, resource.Open); err != nil {
+					return err
+				}
+			} else if err := writeFile(zipWriter, resource.Path, resource.Blob); err != nil {
+				return err
+			}
+		}
+		m.Resources = append(m.Resources, resource)
+	}
+
+	manifestBytes, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal manifest")
+	}
+	if err := writeFile(zipWriter, ManifestPath, manifestBytes); err != nil {
+		return err
+	}
+	return zipWriter.Close()
+}
+
+// Read reads an archive written by Write, failing if its files exceed the limits.
+func Read(r io.ReaderAt, size int64, limits Limits) (*Archive, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open zip")
+	}
+	fsys := LimitFS(zipReader, limits)
+
+	manifestBytes, err := readFile(fsys, ManifestPath)
+	if err != nil {
+		return nil, err
+	}
+	m := &manifest{}
+	if err := json.Unmarshal(manifestBytes, m); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal manifest")
+	}
+	if m.Version > Version {
+		return nil, errors.Errorf("unsupported archive version %d", m.Version)
+	}
+
+	archive := &Archive{
+		ExportTime: m.ExportTime,
+		Username:   m.Username,
+		Memos:      []*Memo{},
+		Resources:  []*Resource{},
+	}
+	for _, entry := range m.Memos {
+		content, err := readFile(fsys, entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		memo, err := UnmarshalMemo(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal memo %s", entry.Path)
+		}
+		if memo.UID == "" {
+			memo.UID = entry.UID
+		}
+		archive.Memos = append(archive.Memos, memo)
+	}
+	for _, resource := range m.Resources {
+		if resource.Path != "" {
+			blob, err := readFile(fsys, resource.Path)
+			if err != nil {
+				return nil, err
+			}
+			resource.Blob = blob
+		}
+		archive.Resources = append(archive.Resources, resource)
+	}
+	return archive, nil
+}
+
+// MarshalMemo returns the memo as Markdown with YAML front matter.
+func MarshalMemo(memo *Memo) ([]byte, error) {
+	frontMatter, err := yaml.Marshal(memo)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelimiter + "\n")
+	buf.Write(frontMatter)
+	buf.WriteString(frontMatterDelimiter + "\n")
+	buf.WriteString(memo.Content)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalMemo parses Markdown with optional YAML front matter into a memo.
+func UnmarshalMemo(data []byte) (*Memo, error) {
+	memo := &Memo{}
+	frontMatter, content, ok := SplitFrontMatter(string(data))
+	if ok {
+		if err := yaml.Unmarshal([]byte(frontMatter), memo); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal front matter")
+		}
+	}
+	memo.Content = content
+	return memo, nil
+}
+
+// SplitFrontMatter splits Markdown into its YAML front matter and content.
+// It returns false if the Markdown has no front matter.
+func SplitFrontMatter(markdown string) (string, string, bool) {
+	normalized := strings.ReplaceAll(markdown, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, frontMatterDelimiter+"\n") {
+		return "", markdown, false
+	}
+	rest := normalized[len(frontMatterDelimiter)+1:]
+	if strings.HasPrefix(rest, frontMatterDelimiter+"\n") {
+		return "", rest[len(frontMatterDelimiter)+1:], true
+	}
+	end := strings.Index(rest, "\n"+frontMatterDelimiter+"\n")
+	if end < 0 {
+		if strings.HasSuffix(rest, "\n"+frontMatterDelimiter) {
+			return rest[:len(rest)-len(frontMatterDelimiter)], "", true
+		}
+		return "", markdown, false
+	}
+	return rest[:end+1], rest[end+len(frontMatterDelimiter)+2:], true
+}
+
+func writeFile(zipWriter *zip.Writer, name string, content []byte) error {
+	w, err := zipWriter.Create(name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", name)
+	}
+	if _, err := w.Write(content); err != nil {
+		return errors.Wrapf(err, "failed to write %s", name)
+	}
+	return nil
+}
+
+func copyFile(zipWriter *zip.Writer, name string, open func() (io.ReadCloser, error)) error {
+	r, err := open()
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", name)
+	}
+	defer r.Close()
+	w, err := zipWriter.Create(name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", name)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return errors.Wrapf(err, "failed to write %s", name)
+	}
+	return nil
+}
+
+func readFile(fsys fs.FS, name string) ([]byte, error) {
+	content, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.Errorf("%s not found in archive", name)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", name)
+	}
+	return content, nil
+}
+
+func sanitizeFilename(filename string) string {
+	filename = path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	if filename == "." || filename == "/" || filename == ".." {
+		return "file"
+	}
+	return filename
+}
@@ -0,0 +1,129 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRead(t *testing.T) {
+	createTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	archive := &Archive{
+		ExportTime: createTime,
+		Username:   "test",
+		Memos: []*Memo{
+			{
+				UID:        "memo-1",
+				Visibility: "PUBLIC",
+				State:      "NORMAL",
+				Pinned:     true,
+				Tags:       []string{"tag"},
+				CreateTime: createTime,
+				UpdateTime: createTime,
+				Location:   &Location{Placeholder: "home", Latitude: 1.5, Longitude: 2.5},
+				Resources:  []string{"resource-1"},
+				Content:    "# Title\n\n---\n\ncontent #tag",
+			},
+			{
+				UID:        "memo-2",
+				Visibility: "PRIVATE",
+				CreateTime: createTime,
+				UpdateTime: createTime,
+				Relations:  []*Relation{{Memo: "memo-1", Type: "COMMENT"}},
+				Content:    "comment",
+			},
+		},
+		Resources: []*Resource{
+			{
+				UID:        "resource-1",
+				Filename:   "../image.png",
+				Type:       "image/png",
+				Size:       4,
+				CreateTime: createTime,
+				Memo:       "memo-1",
+				Blob:       []byte("blob"),
+			},
+			{
+				UID:          "resource-2",
+				Filename:     "link",
+				CreateTime:   createTime,
+				ExternalLink: "https://example.com/file",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, archive))
+	read, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()), DefaultLimits)
+	require.NoError(t, err)
+	require.Equal(t, archive, read)
+	require.Equal(t, "resources/resource-1/image.png", read.Resources[0].Path)
+	require.Equal(t, "", read.Resources[1].Path)
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		markdown    string
+		frontMatter string
+		content     string
+		ok          bool
+	}{
+		{
+			markdown: "no front matter",
+			content:  "no front matter",
+		},
+		{
+			markdown:    "---\ntitle: test\n---\ncontent\n---\nmore",
+			frontMatter: "title: test\n",
+			content:     "content\n---\nmore",
+			ok:          true,
+		},
+		{
+			markdown: "---\n---\ncontent",
+			content:  "content",
+			ok:       true,
+		},
+		{
+			markdown:    "---\r\ntitle: test\r\n---",
+			frontMatter: "title: test\n",
+			ok:          true,
+		},
+		{
+			markdown: "---\nunclosed",
+			content:  "---\nunclosed",
+		},
+	}
+	for _, test := range tests {
+		frontMatter, content, ok := SplitFrontMatter(test.markdown)
+		require.Equal(t, test.frontMatter, frontMatter)
+		require.Equal(t, test.content, content)
+		require.Equal(t, test.ok, ok)
+	}
+}
+
+func TestReadLimits(t *testing.T) {
+	// The manifest references the same blob several times.
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	require.NoError(t, writeFile(zipWriter, "resources/blob", bytes.Repeat([]byte("a"), 1000)))
+	require.NoError(t, writeFile(zipWriter, ManifestPath, []byte(`{"version":1,"resources":[
+		{"uid":"resource-1","path":"resources/blob"},
+		{"uid":"resource-2","path":"resources/blob"},
+		{"uid":"resource-3","path":"resources/blob"}
+	]}`)))
+	require.NoError(t, zipWriter.Close())
+	read := func(limits Limits) error {
+		_, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()), limits)
+		return err
+	}
+
+	require.NoError(t, read(Limits{}))
+	require.NoError(t, read(Limits{MaxSize: 4000, MaxFiles: 4}))
+	require.ErrorContains(t, read(Limits{MaxFiles: 3}), "more than 3 files")
+	require.ErrorContains(t, read(Limits{MaxSize: 2500}), "more than 2500 bytes")
+	require.NoError(t, read(Limits{MaxFileSize: 1000}))
+	require.ErrorContains(t, read(Limits{MaxFileSize: 999}), "resources/blob is larger than 999 bytes")
+}
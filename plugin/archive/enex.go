@@ -0,0 +1,225 @@
+package archive
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	evernoteSource     = "evernote"
+	evernoteTimeLayout = "20060102T150405Z"
+)
+
+type enexExport struct {
+	Notes []*enexNote `xml:"note"`
+}
+
+type enexNote struct {
+	Title     string          `xml:"title"`
+	Content   string          `xml:"content"`
+	Created   string          `xml:"created"`
+	Updated   string          `xml:"updated"`
+	Tags      []string        `xml:"tag"`
+	Resources []*enexResource `xml:"resource"`
+}
+
+type enexResource struct {
+	Data struct {
+		Encoding string `xml:"encoding,attr"`
+		Value    string `xml:",chardata"`
+	} `xml:"data"`
+	Mime       string `xml:"mime"`
+	Attributes struct {
+		FileName string `xml:"file-name"`
+	} `xml:"resource-attributes"`
+}
+
+// ReadEvernoteENEX converts the notes of an Evernote export (.enex) to an archive.
+// The note content is converted from ENML to Markdown, and the attached files become resources.
+func ReadEvernoteENEX(r io.Reader) (*Archive, error) {
+	export := &enexExport{}
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	if err := decoder.Decode(export); err != nil {
+		return nil, errors.Wrap(err, "failed to decode enex")
+	}
+
+	archive := &Archive{
+		ExportTime: time.Now().UTC(),
+		Memos:      []*Memo{},
+		Resources:  []*Resource{},
+	}
+	for i, note := range export.Notes {
+		key := fmt.Sprintf("%s/%s/%d", note.Created, note.Title, i)
+		memo := &Memo{
+			UID:        sourceUID(evernoteSource, key),
+			Visibility: "PRIVATE",
+			CreateTime: parseEvernoteTime(note.Created),
+		}
+		memo.UpdateTime = memo.CreateTime
+		if updated := parseEvernoteTime(note.Updated); !updated.IsZero() {
+			memo.UpdateTime = updated
+		}
+
+		body, err := convertENMLToMarkdown(note.Content)
+		if err != nil {
+			warnf(archive, "%q: failed to convert content: %v", note.Title, err)
+		}
+		memo.Content = noteContent(note.Title, body, note.Tags)
+
+		for j, enexResource := range note.Resources {
+			if enexResource.Data.Encoding != "" && enexResource.Data.Encoding != "base64" {
+				warnf(archive, "%q: unsupported attachment encoding %q", note.Title, enexResource.Data.Encoding)
+				continue
+			}
+			blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(enexResource.Data.Value), ""))
+			if err != nil {
+				warnf(archive, "%q: failed to decode attachment: %v", note.Title, err)
+				continue
+			}
+			filename := enexResource.Attributes.FileName
+			if filename == "" {
+				filename = fmt.Sprintf("attachment-%d", j+1)
+			}
+			resourceType := enexResource.Mime
+			if resourceType == "" {
+				resourceType = detectType(filename, blob)
+			}
+			resource := &Resource{
+				UID:        sourceUID(evernoteSource, fmt.Sprintf("%s/%d", key, j)),
+				Filename:   filename,
+				Type:       resourceType,
+				Size:       int64(len(blob)),
+				CreateTime: memo.CreateTime,
+				Memo:       memo.UID,
+				Blob:       blob,
+			}
+			archive.Resources = append(archive.Resources, resource)
+			memo.Resources = append(memo.Resources, resource.UID)
+		}
+		archive.Memos = append(archive.Memos, memo)
+	}
+	return archive, nil
+}
+
+func parseEvernoteTime(value string) time.Time {
+	t, err := time.Parse(evernoteTimeLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// convertENMLToMarkdown converts the XHTML of a note to Markdown,
+// keeping the block structure, lists, checkboxes and links.
+func convertENMLToMarkdown(enml string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(enml))
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	newline := func() {
+		if s := sb.String(); s != "" && !strings.HasSuffix(s, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	var walk func(node *html.Node, listPrefix string)
+	walk = func(node *html.Node, listPrefix string) {
+		switch node.Type {
+		case html.TextNode:
+			sb.WriteString(node.Data)
+			return
+		case html.ElementNode:
+			switch {
+			case node.DataAtom == atom.Br:
+				sb.WriteString("\n")
+				return
+			case node.DataAtom == atom.Hr:
+				newline()
+				sb.WriteString("---\n")
+				return
+			case node.Data == "en-todo":
+				// The HTML parser does not know en-todo is void, so the text after it may be its child.
+				if getAttribute(node, "checked") == "true" {
+					sb.WriteString("- [x] ")
+				} else {
+					sb.WriteString("- [ ] ")
+				}
+			case node.Data == "en-media":
+				return
+			case node.DataAtom == atom.A:
+				var text strings.Builder
+				for child := node.FirstChild; child != nil; child = child.NextSibling {
+					text.WriteString(nodeText(child))
+				}
+				if href := getAttribute(node, "href"); href != "" {
+					sb.WriteString(fmt.Sprintf("[%s](%s)", text.String(), href))
+				} else {
+					sb.WriteString(text.String())
+				}
+				return
+			case node.DataAtom == atom.Ul:
+				listPrefix = "- "
+			case node.DataAtom == atom.Ol:
+				listPrefix = "1. "
+			case node.DataAtom == atom.Li:
+				newline()
+				sb.WriteString(listPrefix)
+			case isHeading(node.DataAtom):
+				newline()
+				sb.WriteString(strings.Repeat("#", int(node.Data[1]-'0')) + " ")
+			case isBlock(node.DataAtom):
+				newline()
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child, listPrefix)
+		}
+		if node.Type == html.ElementNode && (isBlock(node.DataAtom) || isHeading(node.DataAtom) || node.DataAtom == atom.Li) {
+			newline()
+		}
+	}
+	walk(doc, "- ")
+	return strings.TrimSpace(blankLinesRegexp.ReplaceAllString(sb.String(), "\n\n")), nil
+}
+
+func nodeText(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var sb strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(nodeText(child))
+	}
+	return sb.String()
+}
+
+func getAttribute(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func isHeading(a atom.Atom) bool {
+	return a == atom.H1 || a == atom.H2 || a == atom.H3 || a == atom.H4 || a == atom.H5 || a == atom.H6
+}
+
+func isBlock(a atom.Atom) bool {
+	switch a {
+	case atom.Div, atom.P, atom.Blockquote, atom.Pre, atom.Table, atom.Tr, atom.Ul, atom.Ol:
+		return true
+	}
+	return false
+}
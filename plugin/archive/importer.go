@@ -0,0 +1,65 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var blankLinesRegexp = regexp.MustCompile(`\n{3,}`)
+
+// sourceUID returns a stable UID for a note or attachment of another app,
+// so converting the same notes again yields the same UIDs.
+func sourceUID(source string, key string) string {
+	hash := sha256.Sum256([]byte(source + "/" + key))
+	return hex.EncodeToString(hash[:])[:32]
+}
+
+// noteContent assembles the content of a memo from the title, body and tags of a note.
+func noteContent(title string, body string, tags []string) string {
+	parts := []string{}
+	if title = strings.TrimSpace(title); title != "" {
+		parts = append(parts, "# "+title)
+	}
+	if body = strings.TrimSpace(body); body != "" {
+		parts = append(parts, body)
+	}
+	tagTokens := []string{}
+	for _, tag := range tags {
+		if tag = formatTag(tag); tag != "" && !strings.Contains(body, tag) {
+			tagTokens = append(tagTokens, tag)
+		}
+	}
+	if len(tagTokens) > 0 {
+		parts = append(parts, strings.Join(tagTokens, " "))
+	}
+	return blankLinesRegexp.ReplaceAllString(strings.Join(parts, "\n\n"), "\n\n")
+}
+
+// formatTag formats a tag of another app as a memo tag, e.g. "Work Notes" -> "#Work-Notes".
+func formatTag(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	tag = strings.Join(strings.FieldsFunc(tag, unicode.IsSpace), "-")
+	if tag == "" {
+		return ""
+	}
+	return "#" + tag
+}
+
+// detectType returns the MIME type of an attachment from its filename, or else its content.
+func detectType(filename string, blob []byte) string {
+	if t := mime.TypeByExtension(path.Ext(filename)); t != "" {
+		return t
+	}
+	return http.DetectContentType(blob)
+}
+
+func warnf(archive *Archive, format string, args ...any) {
+	archive.Warnings = append(archive.Warnings, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,115 @@
+package archive
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadObsidianVault(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"Note.md": {
+			Data:    []byte("---\ntags: [work, Project X]\ncreated: 2023-05-06\n---\nSee [[Other|the other note]] and [[Missing]].\n\n![[image.png]]"),
+			ModTime: modTime,
+		},
+		"folder/Other.md": {
+			Data:    []byte("Back to [[Note#Heading]]. ![](../attachments/doc.pdf)"),
+			ModTime: modTime,
+		},
+		"attachments/image.png": {Data: []byte("png")},
+		"attachments/doc.pdf":   {Data: []byte("pdf")},
+		".obsidian/app.json":    {Data: []byte("{}")},
+	}
+	archive, err := ReadObsidianVault(fsys)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(archive.Memos))
+	require.Equal(t, 2, len(archive.Resources))
+
+	note, other := archive.Memos[0], archive.Memos[1]
+	require.Equal(t, "# Note\n\nSee the other note and Missing.\n\n#work #Project-X", note.Content)
+	require.Equal(t, time.Date(2023, 5, 6, 0, 0, 0, 0, time.UTC), note.CreateTime)
+	require.Equal(t, modTime, note.UpdateTime)
+	require.Equal(t, []*Relation{{Memo: other.UID, Type: "REFERENCE"}}, note.Relations)
+	require.Equal(t, 1, len(note.Resources))
+	require.Equal(t, "# Other\n\nBack to Note.", other.Content)
+	require.Equal(t, []*Relation{{Memo: note.UID, Type: "REFERENCE"}}, other.Relations)
+	require.Equal(t, 1, len(other.Resources))
+	for _, resource := range archive.Resources {
+		if resource.Memo == note.UID {
+			require.Equal(t, "image.png", resource.Filename)
+			require.Equal(t, "image/png", resource.Type)
+		} else {
+			require.Equal(t, "doc.pdf", resource.Filename)
+			require.Equal(t, []byte("pdf"), resource.Blob)
+		}
+	}
+	require.Equal(t, []string{`Note.md: linked note "Missing" not found`}, archive.Warnings)
+
+	// Converting the vault again yields the same UIDs.
+	again, err := ReadObsidianVault(fsys)
+	require.NoError(t, err)
+	require.Equal(t, note.UID, again.Memos[0].UID)
+}
+
+func TestReadEvernoteENEX(t *testing.T) {
+	enex := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE en-export SYSTEM "http://xml.evernote.com/pub/evernote-export3.dtd">
+<en-export export-date="20240101T000000Z" application="Evernote">
+  <note>
+    <title>Shopping</title>
+    <content><![CDATA[<?xml version="1.0" encoding="UTF-8"?><!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd"><en-note><div>Buy:</div><div><en-todo checked="true"/>milk</div><div><en-todo/>eggs</div><ul><li>one</li><li>two</li></ul><div>See <a href="https://example.com">shop</a><br/>now</div><en-media type="image/png" hash="abc"/></en-note>]]></content>
+    <created>20230102T030405Z</created>
+    <updated>20230103T030405Z</updated>
+    <tag>home</tag>
+    <resource>
+      <data encoding="base64">cG5n</data>
+      <mime>image/png</mime>
+      <resource-attributes><file-name>photo.png</file-name></resource-attributes>
+    </resource>
+  </note>
+</en-export>`
+	archive, err := ReadEvernoteENEX(strings.NewReader(enex))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(archive.Memos))
+	memo := archive.Memos[0]
+	require.Equal(t, "# Shopping\n\nBuy:\n- [x] milk\n- [ ] eggs\n- one\n- two\nSee [shop](https://example.com)\nnow\n\n#home", memo.Content)
+	require.Equal(t, time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC), memo.CreateTime)
+	require.Equal(t, time.Date(2023, 1, 3, 3, 4, 5, 0, time.UTC), memo.UpdateTime)
+	require.Equal(t, 1, len(archive.Resources))
+	require.Equal(t, "photo.png", archive.Resources[0].Filename)
+	require.Equal(t, []byte("png"), archive.Resources[0].Blob)
+	require.Equal(t, memo.UID, archive.Resources[0].Memo)
+}
+
+func TestReadGoogleKeepTakeout(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Takeout/Keep/list.json": {Data: []byte(`{
+			"title": "Todo",
+			"listContent": [{"text": "done", "isChecked": true}, {"text": "open", "isChecked": false}],
+			"isPinned": true,
+			"isArchived": true,
+			"createdTimestampUsec": 1700000000000000,
+			"userEditedTimestampUsec": 1700000100000000,
+			"labels": [{"name": "Chores"}],
+			"attachments": [{"filePath": "photo.jpg", "mimetype": "image/jpeg"}]
+		}`)},
+		"Takeout/Keep/photo.jpg":    {Data: []byte("jpg")},
+		"Takeout/Keep/trashed.json": {Data: []byte(`{"textContent": "gone", "isTrashed": true}`)},
+	}
+	archive, err := ReadGoogleKeepTakeout(fsys)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(archive.Memos))
+	memo := archive.Memos[0]
+	require.Equal(t, "# Todo\n\n- [x] done\n- [ ] open\n\n#Chores", memo.Content)
+	require.True(t, memo.Pinned)
+	require.Equal(t, "ARCHIVED", memo.State)
+	require.Equal(t, time.Unix(1700000000, 0).UTC(), memo.CreateTime)
+	require.Equal(t, time.Unix(1700000100, 0).UTC(), memo.UpdateTime)
+	require.Equal(t, 1, len(archive.Resources))
+	require.Equal(t, "image/jpeg", archive.Resources[0].Type)
+	require.Equal(t, []string{"Takeout/Keep/trashed.json: skipped trashed note"}, archive.Warnings)
+}
@@ -0,0 +1,133 @@
+package archive
+
+import (
+	"encoding/json"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const googleKeepSource = "google_keep"
+
+type keepNote struct {
+	Title       string `json:"title"`
+	TextContent string `json:"textContent"`
+	ListContent []struct {
+		Text      string `json:"text"`
+		IsChecked bool   `json:"isChecked"`
+	} `json:"listContent"`
+	IsArchived              bool  `json:"isArchived"`
+	IsPinned                bool  `json:"isPinned"`
+	IsTrashed               bool  `json:"isTrashed"`
+	CreatedTimestampUsec    int64 `json:"createdTimestampUsec"`
+	UserEditedTimestampUsec int64 `json:"userEditedTimestampUsec"`
+	Labels                  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Attachments []struct {
+		FilePath string `json:"filePath"`
+		Mimetype string `json:"mimetype"`
+	} `json:"attachments"`
+}
+
+// ReadGoogleKeepTakeout converts the notes of a Google Keep Takeout to an archive.
+// Every JSON file in fsys is read as a note, trashed notes are left out.
+func ReadGoogleKeepTakeout(fsys fs.FS) (*Archive, error) {
+	archive := &Archive{
+		ExportTime: time.Now().UTC(),
+		Memos:      []*Memo{},
+		Resources:  []*Resource{},
+	}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(path.Ext(p), ".json") {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		note := &keepNote{}
+		if err := json.Unmarshal(data, note); err != nil {
+			warnf(archive, "%s: not a note: %v", p, err)
+			return nil
+		}
+		if note.IsTrashed {
+			warnf(archive, "%s: skipped trashed note", p)
+			return nil
+		}
+
+		memo := &Memo{
+			UID:        sourceUID(googleKeepSource, p),
+			Visibility: "PRIVATE",
+			State:      "NORMAL",
+			Pinned:     note.IsPinned,
+			CreateTime: time.UnixMicro(note.CreatedTimestampUsec).UTC(),
+			UpdateTime: time.UnixMicro(note.UserEditedTimestampUsec).UTC(),
+		}
+		if note.IsArchived {
+			memo.State = "ARCHIVED"
+		}
+		if note.CreatedTimestampUsec == 0 {
+			memo.CreateTime = memo.UpdateTime
+		}
+
+		body := note.TextContent
+		if len(note.ListContent) > 0 {
+			items := []string{}
+			for _, item := range note.ListContent {
+				if item.IsChecked {
+					items = append(items, "- [x] "+item.Text)
+				} else {
+					items = append(items, "- [ ] "+item.Text)
+				}
+			}
+			body = strings.Join(append([]string{body}, items...), "\n")
+		}
+		labels := []string{}
+		for _, label := range note.Labels {
+			labels = append(labels, label.Name)
+		}
+		memo.Content = noteContent(note.Title, body, labels)
+		if memo.Content == "" && len(note.Attachments) == 0 {
+			warnf(archive, "%s: skipped empty note", p)
+			return nil
+		}
+
+		for _, attachment := range note.Attachments {
+			attachmentPath := path.Join(path.Dir(p), attachment.FilePath)
+			blob, err := fs.ReadFile(fsys, attachmentPath)
+			if err != nil {
+				warnf(archive, "%s: attachment %s not found", p, attachment.FilePath)
+				continue
+			}
+			resourceType := attachment.Mimetype
+			if resourceType == "" {
+				resourceType = detectType(attachmentPath, blob)
+			}
+			resource := &Resource{
+				UID:        sourceUID(googleKeepSource, attachmentPath),
+				Filename:   path.Base(attachmentPath),
+				Type:       resourceType,
+				Size:       int64(len(blob)),
+				CreateTime: memo.CreateTime,
+				Memo:       memo.UID,
+				Blob:       blob,
+			}
+			archive.Resources = append(archive.Resources, resource)
+			memo.Resources = append(memo.Resources, resource.UID)
+		}
+		archive.Memos = append(archive.Memos, memo)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read takeout")
+	}
+	return archive, nil
+}
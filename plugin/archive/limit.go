@@ -0,0 +1,123 @@
+package archive
+
+import (
+	"io/fs"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultLimits are the largest limits of reading the archives and notes that users upload.
+var DefaultLimits = Limits{
+	MaxSize:  1 << 30,
+	MaxFiles: 100000,
+}
+
+// Limits bound the work of reading an archive or the notes of another app, since a small zip can
+// decompress to a huge size, or reference the same large file many times. A zero field means no limit.
+type Limits struct {
+	// MaxSize is the maximum total size of the decompressed files read.
+	MaxSize int64
+	// MaxFileSize is the maximum decompressed size of a single file read.
+	MaxFileSize int64
+	// MaxFiles is the maximum number of files read, counting every read of the same file.
+	MaxFiles int
+}
+
+// LimitFS returns a file system that fails reading files once they exceed the limits in total.
+func LimitFS(fsys fs.FS, limits Limits) fs.FS {
+	return &limitFS{
+		fsys:   fsys,
+		limits: limits,
+	}
+}
+
+type limitFS struct {
+	fsys   fs.FS
+	limits Limits
+
+	mu    sync.Mutex
+	size  int64
+	files int
+}
+
+func (l *limitFS) Open(name string) (fs.File, error) {
+	file, err := l.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	// Directories are walked, not read.
+	if stat.IsDir() {
+		return file, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.files++
+	if l.limits.MaxFiles > 0 && l.files > l.limits.MaxFiles {
+		file.Close()
+		return nil, errors.Errorf("more than %d files", l.limits.MaxFiles)
+	}
+	// Readers allocate the size that the file declares, so it must not exceed the size left either.
+	if l.limits.MaxSize > 0 && stat.Size() > l.limits.MaxSize-l.size {
+		file.Close()
+		return nil, errors.Errorf("more than %d bytes of files", l.limits.MaxSize)
+	}
+	if l.limits.MaxFileSize > 0 && stat.Size() > l.limits.MaxFileSize {
+		file.Close()
+		return nil, errors.Errorf("%s is larger than %d bytes", name, l.limits.MaxFileSize)
+	}
+	return &limitFile{File: file, fsys: l, name: name}, nil
+}
+
+// left returns the size left to read, or -1 without a size limit.
+func (l *limitFS) left() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limits.MaxSize <= 0 {
+		return -1
+	}
+	return max(l.limits.MaxSize-l.size, 0)
+}
+
+// take takes n bytes from the size left to read.
+func (l *limitFS) take(n int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.size += int64(n)
+	if l.limits.MaxSize > 0 && l.size > l.limits.MaxSize {
+		return errors.Errorf("more than %d bytes of files", l.limits.MaxSize)
+	}
+	return nil
+}
+
+type limitFile struct {
+	fs.File
+	fsys *limitFS
+	name string
+	size int64
+}
+
+func (f *limitFile) Read(p []byte) (int, error) {
+	// Read at most one byte past the limits, which is enough to tell that they are exceeded.
+	if left := f.fsys.left(); left >= 0 && int64(len(p)) > left+1 {
+		p = p[:left+1]
+	}
+	if maxFileSize := f.fsys.limits.MaxFileSize; maxFileSize > 0 && int64(len(p)) > maxFileSize-f.size+1 {
+		p = p[:max(maxFileSize-f.size+1, 0)]
+	}
+	n, err := f.File.Read(p)
+	f.size += int64(n)
+	if limitErr := f.fsys.take(n); limitErr != nil {
+		return n, limitErr
+	}
+	if maxFileSize := f.fsys.limits.MaxFileSize; maxFileSize > 0 && f.size > maxFileSize {
+		return n, errors.Errorf("%s is larger than %d bytes", f.name, maxFileSize)
+	}
+	return n, err
+}
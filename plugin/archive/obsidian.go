@@ -0,0 +1,218 @@
+package archive
+
+import (
+	"io/fs"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const obsidianSource = "obsidian"
+
+var (
+	// wikilinkRegexp matches [[Note]], [[Note#Heading|Alias]] and embeds like ![[image.png]].
+	wikilinkRegexp = regexp.MustCompile(`(!?)\[\[([^\]|#]*)(#[^\]|]*)?(?:\|([^\]]*))?\]\]`)
+	// markdownEmbedRegexp matches embeds like ![alt](attachments/image.png).
+	markdownEmbedRegexp = regexp.MustCompile(`!\[[^\]]*\]\(([^)\s]+)\)`)
+)
+
+type obsidianNote struct {
+	path    string
+	name    string
+	uid     string
+	content string
+	modTime time.Time
+}
+
+// ReadObsidianVault converts the notes of an Obsidian vault to an archive.
+// Wikilinks between notes become memo relations, and embedded attachments become resources.
+func ReadObsidianVault(fsys fs.FS) (*Archive, error) {
+	notes := []*obsidianNote{}
+	notesByName := map[string]*obsidianNote{}
+	attachments := map[string]string{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Skip the configuration and trash of the vault.
+		if p != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !strings.EqualFold(path.Ext(p), ".md") {
+			attachments[strings.ToLower(p)] = p
+			// Attachments are usually embedded by their filename only.
+			if _, ok := attachments[strings.ToLower(path.Base(p))]; !ok {
+				attachments[strings.ToLower(path.Base(p))] = p
+			}
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(path.Base(p), path.Ext(p))
+		note := &obsidianNote{
+			path:    p,
+			name:    name,
+			uid:     sourceUID(obsidianSource, p),
+			content: string(content),
+			modTime: info.ModTime(),
+		}
+		notes = append(notes, note)
+		notesByName[strings.ToLower(strings.TrimSuffix(p, path.Ext(p)))] = note
+		if _, ok := notesByName[strings.ToLower(name)]; !ok {
+			notesByName[strings.ToLower(name)] = note
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read vault")
+	}
+
+	archive := &Archive{
+		ExportTime: time.Now().UTC(),
+		Memos:      []*Memo{},
+		Resources:  []*Resource{},
+	}
+	for _, note := range notes {
+		frontMatter, body, _ := SplitFrontMatter(note.content)
+		properties := map[string]any{}
+		if err := yaml.Unmarshal([]byte(frontMatter), &properties); err != nil {
+			warnf(archive, "%s: invalid front matter: %v", note.path, err)
+		}
+
+		memo := &Memo{
+			UID:        note.uid,
+			Visibility: "PRIVATE",
+			CreateTime: note.modTime.UTC(),
+			UpdateTime: note.modTime.UTC(),
+		}
+		if created, ok := parseObsidianTime(properties["created"]); ok {
+			memo.CreateTime = created
+		} else if created, ok := parseObsidianTime(properties["date"]); ok {
+			memo.CreateTime = created
+		}
+
+		embed := func(target string) bool {
+			attachmentPath, ok := attachments[strings.ToLower(target)]
+			if !ok {
+				attachmentPath, ok = attachments[strings.ToLower(path.Join(path.Dir(note.path), target))]
+			}
+			if !ok {
+				return false
+			}
+			blob, err := fs.ReadFile(fsys, attachmentPath)
+			if err != nil {
+				warnf(archive, "%s: failed to read attachment %s: %v", note.path, attachmentPath, err)
+				return true
+			}
+			resource := &Resource{
+				UID:        sourceUID(obsidianSource, note.path+"/"+attachmentPath),
+				Filename:   path.Base(attachmentPath),
+				Type:       detectType(attachmentPath, blob),
+				Size:       int64(len(blob)),
+				CreateTime: memo.CreateTime,
+				Memo:       memo.UID,
+				Blob:       blob,
+			}
+			archive.Resources = append(archive.Resources, resource)
+			memo.Resources = append(memo.Resources, resource.UID)
+			return true
+		}
+		body = wikilinkRegexp.ReplaceAllStringFunc(body, func(match string) string {
+			groups := wikilinkRegexp.FindStringSubmatch(match)
+			isEmbed, target, alias := groups[1] == "!", strings.TrimSpace(groups[2]), groups[4]
+			if isEmbed && !strings.EqualFold(path.Ext(target), ".md") && path.Ext(target) != "" {
+				if !embed(target) {
+					warnf(archive, "%s: attachment %q not found", note.path, target)
+				}
+				return ""
+			}
+			if linked, ok := notesByName[strings.ToLower(strings.TrimSuffix(target, ".md"))]; ok {
+				if linked != note && !hasRelation(memo, linked.uid) {
+					memo.Relations = append(memo.Relations, &Relation{Memo: linked.uid, Type: "REFERENCE"})
+				}
+			} else if target != "" {
+				warnf(archive, "%s: linked note %q not found", note.path, target)
+			}
+			if alias != "" {
+				return alias
+			}
+			if target == "" {
+				return strings.TrimPrefix(groups[3], "#")
+			}
+			return target
+		})
+		body = markdownEmbedRegexp.ReplaceAllStringFunc(body, func(match string) string {
+			target := markdownEmbedRegexp.FindStringSubmatch(match)[1]
+			if unescaped, err := url.PathUnescape(target); err == nil {
+				target = unescaped
+			}
+			if strings.Contains(target, "://") || !embed(target) {
+				return match
+			}
+			return ""
+		})
+
+		memo.Content = noteContent(note.name, body, obsidianTags(properties["tags"]))
+		archive.Memos = append(archive.Memos, memo)
+	}
+	return archive, nil
+}
+
+func hasRelation(memo *Memo, uid string) bool {
+	for _, relation := range memo.Relations {
+		if relation.Memo == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// obsidianTags returns the tags of the tags property, a list or a comma separated string.
+func obsidianTags(value any) []string {
+	tags := []string{}
+	switch v := value.(type) {
+	case string:
+		for _, tag := range strings.Split(v, ",") {
+			tags = append(tags, strings.TrimSpace(tag))
+		}
+	case []any:
+		for _, tag := range v {
+			if s, ok := tag.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+	}
+	return tags
+}
+
+func parseObsidianTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC(), true
+	case string:
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.UTC(), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
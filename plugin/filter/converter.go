@@ -1,7 +1,15 @@
 package filter
 
 import (
+	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	exprv1 "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 )
 
 type ConvertContext struct {
@@ -18,3 +26,430 @@ func NewConvertContext() *ConvertContext {
 		Args:   []any{},
 	}
 }
+
+var comparisonOperators = map[string]string{
+	"_==_": "=",
+	"_!=_": "!=",
+	"_<_":  "<",
+	"_>_":  ">",
+	"_<=_": "<=",
+	"_>=_": ">=",
+}
+
+// ConvertExprToSQL converts a memo filter parsed with MemoFilterCELAttributes to a SQL condition of the dialect.
+// The condition is written to the buffer of ctx and its arguments are appended to the args of ctx.
+// Unsupported fields, operators and functions are reported as errors.
+func ConvertExprToSQL(ctx *ConvertContext, dialect Dialect, expr *exprv1.Expr) error {
+	c := &converter{ctx: ctx, dialect: dialect}
+	condition, err := c.condition(expr)
+	if err != nil {
+		return err
+	}
+	ctx.Buffer.WriteString(condition)
+	return nil
+}
+
+// ConvertMemoFilter converts a memo filter to a SQL condition of the dialect and its arguments.
+// The placeholders of the arguments are numbered after the argsOffset arguments of the statement before the condition.
+func ConvertMemoFilter(filter string, dialect Dialect, argsOffset int) (string, []any, error) {
+	parsedExpr, err := Parse(filter, MemoFilterCELAttributes...)
+	if err != nil {
+		return "", nil, err
+	}
+	ctx := NewConvertContext()
+	ctx.ArgsOffset = argsOffset
+	if err := ConvertExprToSQL(ctx, dialect, parsedExpr.GetExpr()); err != nil {
+		return "", nil, err
+	}
+	return ctx.Buffer.String(), ctx.Args, nil
+}
+
+// ValidateMemoFilter returns an error if the memo filter can not be converted to SQL.
+func ValidateMemoFilter(filter string) error {
+	_, _, err := ConvertMemoFilter(filter, validationDialect{}, 0)
+	return err
+}
+
+// ReactionCount returns the number of reactions to a memo of the memo table.
+func ReactionCount(d Dialect) string {
+	return fmt.Sprintf("(SELECT COUNT(*) FROM %s WHERE %s.%s = %s)",
+		d.Quote("reaction"), d.Quote("reaction"), d.Quote("content_id"), d.Concat("'memos/'", d.Quote("memo")+"."+d.Quote("uid")))
+}
+
+// CommentCount returns the number of comments on a memo of the memo table.
+func CommentCount(d Dialect) string {
+	return fmt.Sprintf("(SELECT COUNT(*) FROM %s AS %s WHERE %s.%s = %s.%s AND %s.%s = 'COMMENT')",
+		d.Quote("memo_relation"), d.Quote("comment"), d.Quote("comment"), d.Quote("related_memo_id"), d.Quote("memo"), d.Quote("id"), d.Quote("comment"), d.Quote("type"))
+}
+
+type converter struct {
+	ctx     *ConvertContext
+	dialect Dialect
+}
+
+func (c *converter) condition(expr *exprv1.Expr) (string, error) {
+	switch kind := expr.ExprKind.(type) {
+	case *exprv1.Expr_ConstExpr:
+		value, ok := kind.ConstExpr.ConstantKind.(*exprv1.Constant_BoolValue)
+		if !ok {
+			return "", errors.New("filter must be a condition")
+		}
+		return c.dialect.Bool(value.BoolValue), nil
+	case *exprv1.Expr_IdentExpr, *exprv1.Expr_SelectExpr:
+		field, err := getFieldName(expr)
+		if err != nil {
+			return "", err
+		}
+		return c.boolField(field, true)
+	case *exprv1.Expr_CallExpr:
+		return c.call(kind.CallExpr)
+	default:
+		return "", errors.New("unsupported expression, only fields, functions and operators can be used")
+	}
+}
+
+func (c *converter) call(call *exprv1.Expr_Call) (string, error) {
+	switch call.Function {
+	case "_&&_", "_||_":
+		conditions := []string{}
+		for _, arg := range call.Args {
+			condition, err := c.condition(arg)
+			if err != nil {
+				return "", err
+			}
+			conditions = append(conditions, condition)
+		}
+		operator := " AND "
+		if call.Function == "_||_" {
+			operator = " OR "
+		}
+		return "(" + strings.Join(conditions, operator) + ")", nil
+	case "!_":
+		condition, err := c.condition(call.Args[0])
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + condition + ")", nil
+	case "_==_", "_!=_", "_<_", "_>_", "_<=_", "_>=_":
+		return c.comparison(call.Function, call.Args[0], call.Args[1])
+	case "@in":
+		return c.in(call.Args[0], call.Args[1])
+	case "contains", "startsWith", "within":
+		return c.stringFunction(call)
+	default:
+		return "", errors.Errorf("unsupported function %s", functionName(call.Function))
+	}
+}
+
+func (c *converter) comparison(function string, left, right *exprv1.Expr) (string, error) {
+	operator := comparisonOperators[function]
+	field, err := getFieldName(left)
+	if err != nil {
+		return "", errors.Errorf("the left side of %s must be a field", operator)
+	}
+	value, err := GetConstValue(right)
+	if err != nil {
+		return "", errors.Errorf("%s must be compared with a constant", field)
+	}
+
+	switch field {
+	case "content", "visibility", "row_status", "creator", "location.placeholder":
+		if operator != "=" && operator != "!=" {
+			return "", errors.Errorf("operator %s is not supported for %s", operator, field)
+		}
+		s, ok := value.(string)
+		if !ok {
+			return "", errors.Errorf("%s must be compared with a string", field)
+		}
+		column, arg, err := c.stringField(field, s)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s %s", column, operator, c.arg(arg)), nil
+	case "create_time", "update_time":
+		timestamp, err := getTimestamp(field, value)
+		if err != nil {
+			return "", err
+		}
+		column := c.column("created_ts")
+		if field == "update_time" {
+			column = c.column("updated_ts")
+		}
+		return fmt.Sprintf("%s %s %s", c.dialect.Timestamp(column), operator, c.arg(timestamp)), nil
+	case "reaction_count", "comment_count":
+		count, ok := value.(int64)
+		if !ok {
+			return "", errors.Errorf("%s must be compared with an integer", field)
+		}
+		return fmt.Sprintf("%s %s %s", c.count(field), operator, c.arg(count)), nil
+	case "location.latitude", "location.longitude":
+		var number float64
+		switch v := value.(type) {
+		case int64:
+			number = float64(v)
+		case float64:
+			number = v
+		default:
+			return "", errors.Errorf("%s must be compared with a number", field)
+		}
+		column := c.dialect.JSONNumber(c.column("payload"), "location", strings.TrimPrefix(field, "location."))
+		return fmt.Sprintf("%s %s %s", column, operator, c.arg(number)), nil
+	case "pinned", "has_link", "has_task_list", "has_code", "has_incomplete_tasks":
+		if operator != "=" && operator != "!=" {
+			return "", errors.Errorf("operator %s is not supported for %s", operator, field)
+		}
+		b, ok := value.(bool)
+		if !ok {
+			return "", errors.Errorf("%s must be compared with a boolean", field)
+		}
+		return c.boolField(field, b == (operator == "="))
+	case "tag":
+		if operator != "=" {
+			return "", errors.Errorf("operator %s is not supported for tag, use !(tag == ...) instead", operator)
+		}
+		s, ok := value.(string)
+		if !ok {
+			return "", errors.New("tag must be compared with a string")
+		}
+		return c.tagIn([]string{s}), nil
+	default:
+		return "", errors.Errorf("unsupported field %s", field)
+	}
+}
+
+func (c *converter) in(left, right *exprv1.Expr) (string, error) {
+	field, err := getFieldName(left)
+	if err != nil {
+		return "", errors.New("the left side of in must be a field")
+	}
+	list := right.GetListExpr()
+	if list == nil {
+		return "", errors.Errorf("%s in must be followed by a list", field)
+	}
+	values := []string{}
+	for _, element := range list.Elements {
+		value, err := GetConstValue(element)
+		if err != nil {
+			return "", errors.Errorf("%s must be compared with a list of constants", field)
+		}
+		s, ok := value.(string)
+		if !ok {
+			return "", errors.Errorf("%s must be compared with a list of strings", field)
+		}
+		values = append(values, s)
+	}
+	if len(values) == 0 {
+		return c.dialect.Bool(false), nil
+	}
+
+	switch field {
+	case "tag":
+		return c.tagIn(values), nil
+	case "content", "visibility", "row_status", "creator", "location.placeholder":
+		var column string
+		placeholders := []string{}
+		for _, value := range values {
+			fieldColumn, arg, err := c.stringField(field, value)
+			if err != nil {
+				return "", err
+			}
+			column = fieldColumn
+			placeholders = append(placeholders, c.arg(arg))
+		}
+		return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ",")), nil
+	default:
+		return "", errors.Errorf("operator in is not supported for %s", field)
+	}
+}
+
+func (c *converter) stringFunction(call *exprv1.Expr_Call) (string, error) {
+	if call.Target == nil {
+		return "", errors.Errorf("%s must be called on a field, e.g. content.%s(\"...\")", call.Function, call.Function)
+	}
+	field, err := getFieldName(call.Target)
+	if err != nil {
+		return "", errors.Errorf("%s must be called on a field", call.Function)
+	}
+	if len(call.Args) != 1 {
+		return "", errors.Errorf("%s takes one argument", call.Function)
+	}
+	value, err := GetConstValue(call.Args[0])
+	if err != nil {
+		return "", errors.Errorf("the argument of %s must be a constant", call.Function)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", errors.Errorf("the argument of %s must be a string", call.Function)
+	}
+
+	if call.Function == "within" && field != "tag" {
+		return "", errors.Errorf("function within is not supported for %s", field)
+	}
+
+	pattern := s + "%"
+	if call.Function == "contains" {
+		pattern = "%" + s + "%"
+	}
+	// LIKE is case sensitive in some databases only, so both sides are lower cased.
+	switch field {
+	case "content":
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", c.column("content"), c.arg(pattern)), nil
+	case "location.placeholder":
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", c.dialect.JSONText(c.column("payload"), "location", "placeholder"), c.arg(pattern)), nil
+	case "tag":
+		tag := c.dialect.Quote("memo_tag") + "." + c.dialect.Quote("tag")
+		switch call.Function {
+		case "startsWith":
+			// Hierarchical tags like "work/project" start with their parent tag.
+			return c.tagExists(fmt.Sprintf("SUBSTR(%s, 1, %d) = %s", tag, utf8.RuneCountInString(s), c.arg(s))), nil
+		case "within":
+			// The tag itself or any of its descendants, "work" matches "work/project" but not "workshop".
+			parent := strings.TrimSuffix(s, "/")
+			if parent == "" {
+				return "", errors.New("the argument of within must be a tag")
+			}
+			return c.tagExists(fmt.Sprintf("(%s = %s OR SUBSTR(%s, 1, %d) = %s)",
+				tag, c.arg(parent), tag, utf8.RuneCountInString(parent)+1, c.arg(parent+"/"))), nil
+		default:
+			return "", errors.New("function contains is not supported for tag, use tag.startsWith(...), tag.within(...) or tag in [...]")
+		}
+	default:
+		return "", errors.Errorf("function %s is not supported for %s", call.Function, field)
+	}
+}
+
+// stringField returns the column of a field compared with strings and the argument for the value.
+func (c *converter) stringField(field, value string) (string, any, error) {
+	switch field {
+	case "content":
+		return c.column("content"), value, nil
+	case "visibility":
+		if !slices.Contains([]string{"PUBLIC", "PROTECTED", "PRIVATE"}, value) {
+			return "", nil, errors.Errorf("invalid visibility %q", value)
+		}
+		return c.column("visibility"), value, nil
+	case "row_status":
+		if !slices.Contains([]string{"NORMAL", "ARCHIVED"}, value) {
+			return "", nil, errors.Errorf("invalid row_status %q", value)
+		}
+		return c.column("row_status"), value, nil
+	case "creator":
+		id, err := strconv.ParseInt(strings.TrimPrefix(value, "users/"), 10, 32)
+		if err != nil || !strings.HasPrefix(value, "users/") {
+			return "", nil, errors.Errorf("invalid creator %q, it must be like users/1", value)
+		}
+		return c.column("creator_id"), int32(id), nil
+	case "location.placeholder":
+		return c.dialect.JSONText(c.column("payload"), "location", "placeholder"), value, nil
+	default:
+		return "", nil, errors.Errorf("unsupported field %s", field)
+	}
+}
+
+func (c *converter) boolField(field string, value bool) (string, error) {
+	var condition string
+	switch field {
+	case "pinned":
+		return fmt.Sprintf("%s = %s", c.column("pinned"), c.dialect.Bool(value)), nil
+	case "has_link":
+		condition = c.dialect.JSONIsTrue(c.column("payload"), "property", "hasLink")
+	case "has_task_list":
+		condition = c.dialect.JSONIsTrue(c.column("payload"), "property", "hasTaskList")
+	case "has_code":
+		condition = c.dialect.JSONIsTrue(c.column("payload"), "property", "hasCode")
+	case "has_incomplete_tasks":
+		condition = c.dialect.JSONIsTrue(c.column("payload"), "property", "hasIncompleteTasks")
+	default:
+		return "", errors.Errorf("%s is not a condition, compare it with a value", field)
+	}
+	if !value {
+		return "NOT (" + condition + ")", nil
+	}
+	return condition, nil
+}
+
+func (c *converter) tagIn(tags []string) string {
+	placeholders := []string{}
+	for _, tag := range tags {
+		placeholders = append(placeholders, c.arg(tag))
+	}
+	return c.tagExists(fmt.Sprintf("%s.%s IN (%s)", c.dialect.Quote("memo_tag"), c.dialect.Quote("tag"), strings.Join(placeholders, ",")))
+}
+
+// tagExists returns the condition that the memo has a tag in the memo_tag table matching the condition.
+func (c *converter) tagExists(condition string) string {
+	memoTag := c.dialect.Quote("memo_tag")
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s.%s = %s AND %s)", memoTag, memoTag, c.dialect.Quote("memo_id"), c.column("id"), condition)
+}
+
+func (c *converter) count(field string) string {
+	if field == "reaction_count" {
+		return ReactionCount(c.dialect)
+	}
+	return CommentCount(c.dialect)
+}
+
+func (c *converter) column(name string) string {
+	return c.dialect.Quote("memo") + "." + c.dialect.Quote(name)
+}
+
+// arg appends an argument and returns its placeholder.
+func (c *converter) arg(value any) string {
+	c.ctx.Args = append(c.ctx.Args, value)
+	return c.dialect.Placeholder(c.ctx.ArgsOffset + len(c.ctx.Args))
+}
+
+// getFieldName returns the name of a field, like content or location.latitude.
+func getFieldName(expr *exprv1.Expr) (string, error) {
+	if sel := expr.GetSelectExpr(); sel != nil {
+		operand, err := getFieldName(sel.Operand)
+		if err != nil {
+			return "", err
+		}
+		return operand + "." + sel.Field, nil
+	}
+	return GetIdentExprName(expr)
+}
+
+// getTimestamp returns the seconds since the epoch of a RFC 3339 string or an integer.
+func getTimestamp(field string, value any) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case string:
+		timestamp, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return 0, errors.Errorf("invalid %s %q, it must be a RFC 3339 time like 2006-01-02T15:04:05Z", field, v)
+		}
+		return timestamp.Unix(), nil
+	default:
+		return 0, errors.Errorf("%s must be compared with a time string or seconds since the epoch", field)
+	}
+}
+
+func functionName(function string) string {
+	if operator, ok := comparisonOperators[function]; ok {
+		return operator
+	}
+	return strings.Trim(function, "_@")
+}
+
+// validationDialect is the dialect filters are converted to for validation only.
+type validationDialect struct{}
+
+func (validationDialect) Quote(identifier string) string { return identifier }
+func (validationDialect) Placeholder(int) string         { return "?" }
+func (validationDialect) Bool(value bool) string         { return strconv.FormatBool(value) }
+func (validationDialect) Concat(values ...string) string {
+	return strings.Join(values, " || ")
+}
+func (validationDialect) Timestamp(column string) string { return column }
+func (validationDialect) JSONText(column string, keys ...string) string {
+	return column + "." + strings.Join(keys, ".")
+}
+func (validationDialect) JSONNumber(column string, keys ...string) string {
+	return column + "." + strings.Join(keys, ".")
+}
+func (validationDialect) JSONIsTrue(column string, keys ...string) string {
+	return column + "." + strings.Join(keys, ".")
+}
@@ -0,0 +1,80 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMemoFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		err    string
+	}{
+		{
+			filter: `content.contains("hello") && tag.startsWith("work/") && !pinned`,
+		},
+		{
+			filter: `creator in ["users/1", "users/2"] && location.latitude >= 10.5 && comment_count > 0`,
+		},
+		{
+			filter: `create_time > 1700000000 || update_time < "2024-01-01T00:00:00Z"`,
+		},
+		{
+			filter: `tag.within("project/alpha") && !tag.within("project/alpha/archive")`,
+		},
+		{
+			filter: `1`,
+			err:    "filter must be a condition",
+		},
+		{
+			filter: `content`,
+			err:    "content is not a condition, compare it with a value",
+		},
+		{
+			filter: `content.endsWith("hello")`,
+			err:    "unsupported function endsWith",
+		},
+		{
+			filter: `content > "hello"`,
+			err:    "operator > is not supported for content",
+		},
+		{
+			filter: `visibility == "SECRET"`,
+			err:    `invalid visibility "SECRET"`,
+		},
+		{
+			filter: `creator == "alice"`,
+			err:    `invalid creator "alice", it must be like users/1`,
+		},
+		{
+			filter: `create_time > "yesterday"`,
+			err:    `invalid create_time "yesterday", it must be a RFC 3339 time like 2006-01-02T15:04:05Z`,
+		},
+		{
+			filter: `location.altitude > 100`,
+			err:    "unsupported field location.altitude",
+		},
+		{
+			filter: `tag.contains("work")`,
+			err:    "function contains is not supported for tag, use tag.startsWith(...), tag.within(...) or tag in [...]",
+		},
+		{
+			filter: `content.within("work")`,
+			err:    "function within is not supported for content",
+		},
+		{
+			filter: `["a"].exists(t, t == tag)`,
+			err:    "unsupported expression, only fields, functions and operators can be used",
+		},
+	}
+
+	for _, tt := range tests {
+		err := ValidateMemoFilter(tt.filter)
+		if tt.err == "" {
+			require.NoError(t, err, tt.filter)
+		} else {
+			require.EqualError(t, err, tt.err, tt.filter)
+		}
+	}
+}
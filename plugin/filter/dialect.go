@@ -0,0 +1,21 @@
+package filter
+
+// Dialect is the SQL dialect of a database that filters are converted to.
+type Dialect interface {
+	// Quote quotes an identifier, e.g. the name of a table or column.
+	Quote(identifier string) string
+	// Placeholder returns the placeholder of the n-th argument of the statement, counted from 1.
+	Placeholder(n int) string
+	// Bool returns the literal of a boolean.
+	Bool(value bool) string
+	// Concat returns the concatenation of the string expressions.
+	Concat(values ...string) string
+	// Timestamp returns the timestamp column as seconds since the epoch.
+	Timestamp(column string) string
+	// JSONText returns the text at the keys of the JSON column.
+	JSONText(column string, keys ...string) string
+	// JSONNumber returns the number at the keys of the JSON column.
+	JSONNumber(column string, keys ...string) string
+	// JSONIsTrue returns the condition that the value at the keys of the JSON column is true.
+	JSONIsTrue(column string, keys ...string) string
+}
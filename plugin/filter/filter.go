@@ -1,6 +1,10 @@
 package filter
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/google/cel-go/cel"
 	"github.com/pkg/errors"
 	exprv1 "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
@@ -9,12 +13,36 @@ import (
 // MemoFilterCELAttributes are the CEL attributes for memo.
 var MemoFilterCELAttributes = []cel.EnvOption{
 	cel.Variable("content", cel.StringType),
-	// As the built-in timestamp type is deprecated, we use string type for now.
-	// e.g., "2021-01-01T00:00:00Z"
-	cel.Variable("create_time", cel.StringType),
-	cel.Variable("tag", cel.StringType),
-	cel.Variable("update_time", cel.StringType),
+	// The creator is the name of a user, e.g. "users/1".
+	cel.Variable("creator", cel.StringType),
+	// As the built-in timestamp type is deprecated, times are RFC 3339 strings like "2021-01-01T00:00:00Z",
+	// or seconds since the epoch.
+	cel.Variable("create_time", cel.DynType),
+	cel.Variable("update_time", cel.DynType),
 	cel.Variable("visibility", cel.StringType),
+	cel.Variable("row_status", cel.StringType),
+	cel.Variable("pinned", cel.BoolType),
+	// The tag is any tag of the memo, e.g. `tag in ["work"]` or `tag.startsWith("work/")`.
+	// `tag.within("work")` matches the tag "work" and its descendants like "work/project".
+	cel.Variable("tag", cel.StringType),
+	cel.Function("within", cel.MemberOverload("string_within_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType)),
+	cel.Variable("has_link", cel.BoolType),
+	cel.Variable("has_task_list", cel.BoolType),
+	cel.Variable("has_code", cel.BoolType),
+	cel.Variable("has_incomplete_tasks", cel.BoolType),
+	// The location has the fields placeholder, latitude and longitude.
+	cel.Variable("location", cel.MapType(cel.StringType, cel.DynType)),
+	cel.Variable("reaction_count", cel.IntType),
+	cel.Variable("comment_count", cel.IntType),
+}
+
+// TagSearchFilter returns the memo filter matching memos with all of the tags or their descendants.
+func TagSearchFilter(tags []string) string {
+	conditions := []string{}
+	for _, tag := range tags {
+		conditions = append(conditions, fmt.Sprintf("tag.within(%s)", strconv.Quote(tag)))
+	}
+	return strings.Join(conditions, " && ")
 }
 
 // Parse parses the filter string and returns the parsed expression.
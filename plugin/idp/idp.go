@@ -4,4 +4,8 @@ type IdentityProviderUserInfo struct {
 	Identifier  string
 	DisplayName string
 	Email       string
+	// EmailVerified is whether the identity provider has verified that the email belongs to the user.
+	EmailVerified bool
+	// Groups is the groups of the user, if the identity provider reports them.
+	Groups []string
 }
@@ -0,0 +1,212 @@
+// Package ldap is the plugin for LDAP Identity Provider, e.g. OpenLDAP or Active Directory.
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+
+	"github.com/usememos/memos/plugin/idp"
+	storepb "github.com/usememos/memos/proto/gen/store"
+)
+
+const (
+	// DefaultIdentifierAttribute is the attribute mapped to the username when the field mapping does not set one.
+	DefaultIdentifierAttribute = "uid"
+	// DefaultDisplayNameAttribute is the attribute mapped to the nickname when the field mapping does not set one.
+	DefaultDisplayNameAttribute = "cn"
+	// DefaultEmailAttribute is the attribute mapped to the email when the field mapping does not set one.
+	DefaultEmailAttribute = "mail"
+	// DefaultGroupAttribute is the attribute that lists the groups of a user when the config does not set one.
+	DefaultGroupAttribute = "memberOf"
+
+	// usernamePlaceholder is replaced with the escaped username in the user filter.
+	usernamePlaceholder = "{username}"
+	// timeout bounds connecting to and every request against the directory server.
+	timeout = 10 * time.Second
+)
+
+// ErrInvalidCredentials is returned when no user matches the username or the password is wrong.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// IdentityProvider represents an LDAP Identity Provider.
+type IdentityProvider struct {
+	config *storepb.LDAPConfig
+}
+
+// NewIdentityProvider initializes a new LDAP Identity Provider with the given configuration.
+func NewIdentityProvider(config *storepb.LDAPConfig) (*IdentityProvider, error) {
+	for v, field := range map[string]string{
+		config.Url:        "url",
+		config.BaseDn:     "baseDn",
+		config.UserFilter: "userFilter",
+	} {
+		if v == "" {
+			return nil, errors.Errorf(`the field "%s" is empty but required`, field)
+		}
+	}
+	u, err := url.Parse(config.Url)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+	if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return nil, errors.Errorf("unsupported url scheme %q", u.Scheme)
+	}
+	if config.StartTls && u.Scheme == "ldaps" {
+		return nil, errors.New("StartTLS is only supported with ldap:// urls")
+	}
+	if !strings.Contains(config.UserFilter, usernamePlaceholder) {
+		return nil, errors.Errorf("the user filter must contain %s", usernamePlaceholder)
+	}
+	if _, err := goldap.CompileFilter(strings.ReplaceAll(config.UserFilter, usernamePlaceholder, "x")); err != nil {
+		return nil, errors.Wrap(err, "invalid user filter")
+	}
+
+	return &IdentityProvider{
+		config: config,
+	}, nil
+}
+
+// Authenticate binds as the user that the username finds with the password, and returns the user
+// information that the attributes of the user describe.
+func (p *IdentityProvider) Authenticate(ctx context.Context, username, password string) (*idp.IdentityProviderUserInfo, error) {
+	// An empty password would make an unauthenticated bind, which servers accept without checking anything.
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if p.config.BindDn != "" {
+		if err := conn.Bind(p.config.BindDn, p.config.BindPassword); err != nil {
+			return nil, errors.Wrap(err, "failed to bind with the bind DN")
+		}
+	}
+	entry, err := p.searchUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, errors.Wrap(err, "failed to bind as the user")
+	}
+	return p.mapAttributes(entry)
+}
+
+func (p *IdentityProvider) dial(ctx context.Context) (*goldap.Conn, error) {
+	u, err := url.Parse(p.config.Url)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+	tlsConfig := &tls.Config{
+		ServerName: u.Hostname(),
+		// #nosec G402 -- Admins opt into it for directory servers with self-signed certificates.
+		InsecureSkipVerify: p.config.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	if deadline, ok := ctx.Deadline(); ok {
+		dialer.Deadline = deadline
+	}
+	conn, err := goldap.DialURL(p.config.Url, goldap.DialWithDialer(dialer), goldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the directory server")
+	}
+	conn.SetTimeout(timeout)
+	if p.config.StartTls {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "failed to start TLS")
+		}
+	}
+	return conn, nil
+}
+
+// searchUser returns the only entry that the user filter finds for the username.
+func (p *IdentityProvider) searchUser(conn *goldap.Conn, username string) (*goldap.Entry, error) {
+	filter := strings.ReplaceAll(p.config.UserFilter, usernamePlaceholder, goldap.EscapeFilter(username))
+	result, err := conn.Search(goldap.NewSearchRequest(
+		p.config.BaseDn,
+		goldap.ScopeWholeSubtree,
+		goldap.NeverDerefAliases,
+		// Ask for one more entry than needed to tell ambiguous filters apart.
+		2,
+		int(timeout.Seconds()),
+		false,
+		filter,
+		p.attributes(),
+		nil,
+	))
+	if err != nil && !goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+		return nil, errors.Wrap(err, "failed to search user")
+	}
+	if result == nil || len(result.Entries) == 0 {
+		return nil, ErrInvalidCredentials
+	}
+	if len(result.Entries) > 1 {
+		return nil, errors.Errorf("the user filter finds more than one user for %q", username)
+	}
+	return result.Entries[0], nil
+}
+
+func (p *IdentityProvider) attributes() []string {
+	fieldMapping := p.fieldMapping()
+	return []string{fieldMapping.Identifier, fieldMapping.DisplayName, fieldMapping.Email, p.groupAttribute()}
+}
+
+func (p *IdentityProvider) mapAttributes(entry *goldap.Entry) (*idp.IdentityProviderUserInfo, error) {
+	fieldMapping := p.fieldMapping()
+	userInfo := &idp.IdentityProviderUserInfo{
+		Identifier:  entry.GetAttributeValue(fieldMapping.Identifier),
+		DisplayName: entry.GetAttributeValue(fieldMapping.DisplayName),
+		Email:       entry.GetAttributeValue(fieldMapping.Email),
+		Groups:      entry.GetAttributeValues(p.groupAttribute()),
+	}
+	if userInfo.Identifier == "" {
+		return nil, errors.Errorf("the attribute %q is not found or has empty value", fieldMapping.Identifier)
+	}
+	if userInfo.DisplayName == "" {
+		userInfo.DisplayName = userInfo.Identifier
+	}
+	return userInfo, nil
+}
+
+// fieldMapping returns the field mapping of the config with the defaults for the unset attributes.
+func (p *IdentityProvider) fieldMapping() *storepb.FieldMapping {
+	fieldMapping := &storepb.FieldMapping{
+		Identifier:  DefaultIdentifierAttribute,
+		DisplayName: DefaultDisplayNameAttribute,
+		Email:       DefaultEmailAttribute,
+	}
+	if p.config.FieldMapping != nil {
+		if p.config.FieldMapping.Identifier != "" {
+			fieldMapping.Identifier = p.config.FieldMapping.Identifier
+		}
+		if p.config.FieldMapping.DisplayName != "" {
+			fieldMapping.DisplayName = p.config.FieldMapping.DisplayName
+		}
+		if p.config.FieldMapping.Email != "" {
+			fieldMapping.Email = p.config.FieldMapping.Email
+		}
+	}
+	return fieldMapping
+}
+
+func (p *IdentityProvider) groupAttribute() string {
+	if p.config.GroupAttribute == "" {
+		return DefaultGroupAttribute
+	}
+	return p.config.GroupAttribute
+}
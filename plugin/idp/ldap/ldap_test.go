@@ -0,0 +1,372 @@
+package ldap
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/usememos/memos/plugin/idp"
+	storepb "github.com/usememos/memos/proto/gen/store"
+)
+
+const (
+	testBaseDN       = "ou=people,dc=example,dc=com"
+	testBindDN       = "cn=memos,dc=example,dc=com"
+	testBindPassword = "bind-password"
+	testUserFilter   = "(&(objectClass=person)(uid={username}))"
+)
+
+type mockEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// mockDirectory is a stand-in directory server that supports simple binds, searches with AND and
+// equality filters, and StartTLS. Only the bind DN can search.
+type mockDirectory struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	entries   []*mockEntry
+}
+
+// newMockDirectory starts a directory server that serves ldaps:// if useTLS is set, and ldap:// with
+// StartTLS otherwise.
+func newMockDirectory(t *testing.T, useTLS bool) *mockDirectory {
+	d := &mockDirectory{
+		tlsConfig: newTestTLSConfig(t),
+		entries: []*mockEntry{
+			{
+				dn:       "uid=alice,ou=people,dc=example,dc=com",
+				password: "alice-password",
+				attributes: map[string][]string{
+					"objectClass": {"person"},
+					"uid":         {"alice"},
+					"cn":          {"Alice"},
+					"mail":        {"alice@example.com"},
+					"memberOf":    {"cn=admins,ou=groups,dc=example,dc=com", "cn=staff,ou=groups,dc=example,dc=com"},
+				},
+			},
+			{
+				dn:       "uid=bob,ou=people,dc=example,dc=com",
+				password: "bob-password",
+				attributes: map[string][]string{
+					"objectClass":    {"person"},
+					"uid":            {"bob"},
+					"sAMAccountName": {"bob.smith"},
+				},
+			},
+		},
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	if useTLS {
+		listener = tls.NewListener(listener, d.tlsConfig)
+	}
+	d.listener = listener
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go d.serve(conn)
+		}
+	}()
+	return d
+}
+
+func (d *mockDirectory) url(scheme string) string {
+	return scheme + "://" + d.listener.Addr().String()
+}
+
+func (d *mockDirectory) serve(conn net.Conn) {
+	defer conn.Close()
+	boundDN := ""
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		messageID, _ := packet.Children[0].Value.(int64)
+		request := packet.Children[1]
+		switch request.Tag {
+		case goldap.ApplicationBindRequest:
+			dn, password := request.Children[1].Data.String(), request.Children[2].Data.String()
+			resultCode := uint16(goldap.LDAPResultInvalidCredentials)
+			if (dn == testBindDN && password == testBindPassword) || d.checkPassword(dn, password) {
+				resultCode, boundDN = goldap.LDAPResultSuccess, dn
+			}
+			d.write(conn, messageID, newResult(goldap.ApplicationBindResponse, resultCode))
+		case goldap.ApplicationSearchRequest:
+			if boundDN != testBindDN {
+				d.write(conn, messageID, newResult(goldap.ApplicationSearchResultDone, goldap.LDAPResultInsufficientAccessRights))
+				continue
+			}
+			baseDN, filter := request.Children[0].Data.String(), request.Children[6]
+			for _, entry := range d.entries {
+				if strings.HasSuffix(entry.dn, ","+baseDN) && entry.matches(filter) {
+					d.write(conn, messageID, entry.packet())
+				}
+			}
+			d.write(conn, messageID, newResult(goldap.ApplicationSearchResultDone, goldap.LDAPResultSuccess))
+		case goldap.ApplicationExtendedRequest:
+			d.write(conn, messageID, newResult(goldap.ApplicationExtendedResponse, goldap.LDAPResultSuccess))
+			tlsConn := tls.Server(conn, d.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+		default:
+			return
+		}
+	}
+}
+
+func (d *mockDirectory) checkPassword(dn, password string) bool {
+	for _, entry := range d.entries {
+		if entry.dn == dn && entry.password == password {
+			return true
+		}
+	}
+	return false
+}
+
+func (*mockDirectory) write(conn net.Conn, messageID int64, response *ber.Packet) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	packet.AppendChild(response)
+	// The client notices a failed write, and the test goroutine is the only one that may fail the test.
+	_, _ = conn.Write(packet.Bytes())
+}
+
+func newResult(tag ber.Tag, resultCode uint16) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(resultCode), "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return result
+}
+
+// matches returns whether the entry matches the filter, which may only use AND and equality.
+func (e *mockEntry) matches(filter *ber.Packet) bool {
+	switch filter.Tag {
+	case goldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !e.matches(child) {
+				return false
+			}
+		}
+		return true
+	case goldap.FilterEqualityMatch:
+		attribute, value := filter.Children[0].Data.String(), filter.Children[1].Data.String()
+		for _, v := range e.attributes[attribute] {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func (e *mockEntry) packet() *ber.Packet {
+	entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "DN"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range e.attributes {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	entry.AppendChild(attributes)
+	return entry
+}
+
+// newTestTLSConfig returns a TLS config with a self-signed certificate.
+func newTestTLSConfig(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ldap.example.com"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{certificate}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+func TestNewIdentityProvider(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *storepb.LDAPConfig
+		containsErr string
+	}{
+		{
+			name:        "no url",
+			config:      &storepb.LDAPConfig{BaseDn: testBaseDN, UserFilter: testUserFilter},
+			containsErr: `the field "url" is empty but required`,
+		},
+		{
+			name:        "unsupported scheme",
+			config:      &storepb.LDAPConfig{Url: "http://ldap.example.com", BaseDn: testBaseDN, UserFilter: testUserFilter},
+			containsErr: `unsupported url scheme "http"`,
+		},
+		{
+			name:        "StartTLS with ldaps",
+			config:      &storepb.LDAPConfig{Url: "ldaps://ldap.example.com", StartTls: true, BaseDn: testBaseDN, UserFilter: testUserFilter},
+			containsErr: "StartTLS is only supported with ldap:// urls",
+		},
+		{
+			name:        "no username placeholder",
+			config:      &storepb.LDAPConfig{Url: "ldap://ldap.example.com", BaseDn: testBaseDN, UserFilter: "(uid=alice)"},
+			containsErr: "the user filter must contain {username}",
+		},
+		{
+			name:        "invalid user filter",
+			config:      &storepb.LDAPConfig{Url: "ldap://ldap.example.com", BaseDn: testBaseDN, UserFilter: "(uid={username}"},
+			containsErr: "invalid user filter",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewIdentityProvider(test.config)
+			require.ErrorContains(t, err, test.containsErr)
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	ldaps := newMockDirectory(t, true)
+	startTLS := newMockDirectory(t, false)
+
+	configs := map[string]*storepb.LDAPConfig{
+		"ldaps": {
+			Url:                ldaps.url("ldaps"),
+			InsecureSkipVerify: true,
+			BindDn:             testBindDN,
+			BindPassword:       testBindPassword,
+			BaseDn:             testBaseDN,
+			UserFilter:         testUserFilter,
+		},
+		"StartTLS": {
+			Url:                startTLS.url("ldap"),
+			StartTls:           true,
+			InsecureSkipVerify: true,
+			BindDn:             testBindDN,
+			BindPassword:       testBindPassword,
+			BaseDn:             testBaseDN,
+			UserFilter:         testUserFilter,
+		},
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewIdentityProvider(config)
+			require.NoError(t, err)
+
+			userInfo, err := p.Authenticate(ctx, "alice", "alice-password")
+			require.NoError(t, err)
+			require.Equal(t, &idp.IdentityProviderUserInfo{
+				Identifier:  "alice",
+				DisplayName: "Alice",
+				Email:       "alice@example.com",
+				Groups:      []string{"cn=admins,ou=groups,dc=example,dc=com", "cn=staff,ou=groups,dc=example,dc=com"},
+			}, userInfo)
+
+			for _, credentials := range [][2]string{
+				{"alice", "wrong-password"},
+				{"alice", ""},
+				{"carol", "alice-password"},
+				// The username is escaped, so it cannot change the filter.
+				{"*", "alice-password"},
+				{"alice)(uid=*", "alice-password"},
+			} {
+				_, err := p.Authenticate(ctx, credentials[0], credentials[1])
+				require.ErrorIs(t, err, ErrInvalidCredentials, credentials)
+			}
+		})
+	}
+}
+
+func TestAuthenticateFieldMapping(t *testing.T) {
+	ctx := context.Background()
+	d := newMockDirectory(t, true)
+	p, err := NewIdentityProvider(&storepb.LDAPConfig{
+		Url:                d.url("ldaps"),
+		InsecureSkipVerify: true,
+		BindDn:             testBindDN,
+		BindPassword:       testBindPassword,
+		BaseDn:             testBaseDN,
+		UserFilter:         "(sAMAccountName={username})",
+		FieldMapping:       &storepb.FieldMapping{Identifier: "sAMAccountName"},
+	})
+	require.NoError(t, err)
+
+	userInfo, err := p.Authenticate(ctx, "bob.smith", "bob-password")
+	require.NoError(t, err)
+	require.Equal(t, &idp.IdentityProviderUserInfo{
+		Identifier:  "bob.smith",
+		DisplayName: "bob.smith",
+		Groups:      []string{},
+	}, userInfo)
+}
+
+func TestAuthenticateErrors(t *testing.T) {
+	ctx := context.Background()
+	d := newMockDirectory(t, false)
+
+	// The certificate of the server is verified unless the config skips it.
+	p, err := NewIdentityProvider(&storepb.LDAPConfig{
+		Url:          d.url("ldap"),
+		StartTls:     true,
+		BindDn:       testBindDN,
+		BindPassword: testBindPassword,
+		BaseDn:       testBaseDN,
+		UserFilter:   testUserFilter,
+	})
+	require.NoError(t, err)
+	_, err = p.Authenticate(ctx, "alice", "alice-password")
+	require.ErrorContains(t, err, "failed to start TLS")
+
+	// A wrong bind password is a configuration error rather than wrong user credentials.
+	p, err = NewIdentityProvider(&storepb.LDAPConfig{
+		Url:          d.url("ldap"),
+		BindDn:       testBindDN,
+		BindPassword: "wrong-password",
+		BaseDn:       testBaseDN,
+		UserFilter:   testUserFilter,
+	})
+	require.NoError(t, err)
+	_, err = p.Authenticate(ctx, "alice", "alice-password")
+	require.ErrorContains(t, err, "failed to bind with the bind DN")
+	require.NotErrorIs(t, err, ErrInvalidCredentials)
+}
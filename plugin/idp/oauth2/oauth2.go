@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
@@ -112,6 +113,25 @@ func (p *IdentityProvider) UserInfo(token string) (*idp.IdentityProviderUserInfo
 		if v, ok := claims[p.config.FieldMapping.Email].(string); ok {
 			userInfo.Email = v
 		}
+		// Providers that verify emails report it in the email_verified claim, like OpenID Connect.
+		switch v := claims["email_verified"].(type) {
+		case bool:
+			userInfo.EmailVerified = v
+		case string:
+			userInfo.EmailVerified = strings.EqualFold(v, "true")
+		}
+	}
+	if p.config.FieldMapping.Groups != "" {
+		switch v := claims[p.config.FieldMapping.Groups].(type) {
+		case string:
+			userInfo.Groups = []string{v}
+		case []any:
+			for _, group := range v {
+				if s, ok := group.(string); ok {
+					userInfo.Groups = append(userInfo.Groups, s)
+				}
+			}
+		}
 	}
 	return userInfo, nil
 }
@@ -122,9 +122,11 @@ func TestIdentityProvider(t *testing.T) {
 	)
 	userInfo, err := json.Marshal(
 		map[string]any{
-			"sub":   testSubject,
-			"name":  testName,
-			"email": testEmail,
+			"sub":            testSubject,
+			"name":           testName,
+			"email":          testEmail,
+			"email_verified": true,
+			"groups":         []string{"staff", "admins"},
 		},
 	)
 	require.NoError(t, err)
@@ -141,6 +143,7 @@ func TestIdentityProvider(t *testing.T) {
 				Identifier:  "sub",
 				DisplayName: "name",
 				Email:       "email",
+				Groups:      "groups",
 			},
 		},
 	)
@@ -155,9 +158,11 @@ func TestIdentityProvider(t *testing.T) {
 	require.NoError(t, err)
 
 	wantUserInfo := &idp.IdentityProviderUserInfo{
-		Identifier:  testSubject,
-		DisplayName: testName,
-		Email:       testEmail,
+		Identifier:    testSubject,
+		DisplayName:   testName,
+		Email:         testEmail,
+		EmailVerified: true,
+		Groups:        []string{"staff", "admins"},
 	}
 	assert.Equal(t, wantUserInfo, userInfoResult)
 }
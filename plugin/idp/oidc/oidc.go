@@ -0,0 +1,407 @@
+// Package oidc is the plugin for OpenID Connect Identity Provider.
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/usememos/memos/plugin/idp"
+	storepb "github.com/usememos/memos/proto/gen/store"
+)
+
+const (
+	// DefaultIdentifierClaim is the claim mapped to the username when the field mapping does not set one.
+	DefaultIdentifierClaim = "preferred_username"
+	// DefaultDisplayNameClaim is the claim mapped to the nickname when the field mapping does not set one.
+	DefaultDisplayNameClaim = "name"
+	// DefaultEmailClaim is the claim mapped to the email when the field mapping does not set one.
+	DefaultEmailClaim = "email"
+	// DefaultGroupsClaim is the claim that lists the groups of the user when the config does not set one.
+	DefaultGroupsClaim = "groups"
+
+	// discoveryPath is the path of the provider metadata, relative to the issuer URL.
+	discoveryPath = "/.well-known/openid-configuration"
+	// clockSkew is the tolerated difference between the clocks of memos and the identity provider.
+	clockSkew = time.Minute
+)
+
+// defaultScopes are the scopes requested besides "openid" when the config does not set any.
+var defaultScopes = []string{"profile", "email"}
+
+// signingMethods are the algorithms accepted for ID token signatures.
+var signingMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// Discovery is the provider metadata that the issuer publishes.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// IdentityProvider represents an OpenID Connect Identity Provider.
+type IdentityProvider struct {
+	config    *storepb.OIDCConfig
+	discovery *Discovery
+	client    *http.Client
+}
+
+// NewIdentityProvider initializes a new OpenID Connect Identity Provider with the given configuration,
+// fetching the provider metadata from the issuer.
+func NewIdentityProvider(ctx context.Context, config *storepb.OIDCConfig) (*IdentityProvider, error) {
+	for v, field := range map[string]string{
+		config.IssuerUrl: "issuerUrl",
+		config.ClientId:  "clientId",
+	} {
+		if v == "" {
+			return nil, errors.Errorf(`the field "%s" is empty but required`, field)
+		}
+	}
+
+	p := &IdentityProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+	discovery := &Discovery{}
+	if err := p.getJSON(ctx, strings.TrimSuffix(config.IssuerUrl, "/")+discoveryPath, "", discovery); err != nil {
+		return nil, errors.Wrap(err, "failed to get provider metadata")
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(config.IssuerUrl, "/") {
+		return nil, errors.Errorf("the issuer %q of the provider metadata does not match %q", discovery.Issuer, config.IssuerUrl)
+	}
+	for v, field := range map[string]string{
+		discovery.AuthorizationEndpoint: "authorization_endpoint",
+		discovery.TokenEndpoint:         "token_endpoint",
+		discovery.JWKSURI:               "jwks_uri",
+	} {
+		if v == "" {
+			return nil, errors.Errorf(`the provider metadata is missing "%s"`, field)
+		}
+	}
+	p.discovery = discovery
+	return p, nil
+}
+
+// AuthorizationURL returns the URL to redirect the user to for signing in. The PKCE code challenge
+// is derived from the code verifier, which must be passed to ExchangeToken later, and the nonce must
+// be passed to UserInfo.
+func (p *IdentityProvider) AuthorizationURL(redirectURL, state, nonce, codeVerifier string) string {
+	return p.oauth2Config(redirectURL).AuthCodeURL(state,
+		oauth2.S256ChallengeOption(codeVerifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	)
+}
+
+// ExchangeToken returns the token issued for the given authorization code and PKCE code verifier.
+func (p *IdentityProvider) ExchangeToken(ctx context.Context, redirectURL, code, codeVerifier string) (*oauth2.Token, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	token, err := p.oauth2Config(redirectURL).Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to exchange token")
+	}
+	return token, nil
+}
+
+// UserInfo validates the ID token of the token against the nonce and returns the user information
+// that its claims describe, completed by the claims of the user info endpoint.
+func (p *IdentityProvider) UserInfo(ctx context.Context, token *oauth2.Token, nonce string) (*idp.IdentityProviderUserInfo, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New(`missing "id_token" from token response`)
+	}
+	claims, err := p.VerifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.discovery.UserinfoEndpoint != "" && token.AccessToken != "" {
+		userinfoClaims := map[string]any{}
+		if err := p.getJSON(ctx, p.discovery.UserinfoEndpoint, token.AccessToken, &userinfoClaims); err != nil {
+			return nil, errors.Wrap(err, "failed to get user information")
+		}
+		// The user info response must be about the user of the ID token, per OpenID Connect Core 5.3.2.
+		if userinfoClaims["sub"] != claims["sub"] {
+			return nil, errors.New("the subject of the user information does not match the ID token")
+		}
+		for key, value := range userinfoClaims {
+			if _, ok := claims[key]; !ok {
+				claims[key] = value
+			}
+		}
+	}
+	return p.mapClaims(claims)
+}
+
+// VerifyIDToken returns the claims of the ID token after validating its signature, issuer, audience,
+// expiration and nonce.
+func (p *IdentityProvider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (jwt.MapClaims, error) {
+	keys, err := p.getSigningKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		for _, key := range keys {
+			if key.kid != kid && kid != "" {
+				continue
+			}
+			if key.matches(t.Method) {
+				return key.publicKey, nil
+			}
+		}
+		return nil, errors.Errorf("no signing key found for kid %q", kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(p.discovery.Issuer),
+		jwt.WithAudience(p.config.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid ID token")
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); nonce == "" || tokenNonce != nonce {
+		return nil, errors.New("invalid ID token: nonce does not match")
+	}
+	// An ID token for several audiences must be authorized for this client, per OpenID Connect Core 3.1.3.7.
+	if audience, err := claims.GetAudience(); err == nil && len(audience) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.config.ClientId {
+			return nil, errors.New("invalid ID token: authorized party does not match")
+		}
+	}
+	return claims, nil
+}
+
+func (p *IdentityProvider) oauth2Config(redirectURL string) *oauth2.Config {
+	scopes := p.config.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+	if !slices.Contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+	return &oauth2.Config{
+		ClientID:     p.config.ClientId,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   p.discovery.AuthorizationEndpoint,
+			TokenURL:  p.discovery.TokenEndpoint,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+func (p *IdentityProvider) mapClaims(claims jwt.MapClaims) (*idp.IdentityProviderUserInfo, error) {
+	fieldMapping := p.config.FieldMapping
+	if fieldMapping == nil {
+		fieldMapping = &storepb.FieldMapping{}
+	}
+	identifierClaim := valueOrDefault(fieldMapping.Identifier, DefaultIdentifierClaim)
+	emailClaim := valueOrDefault(fieldMapping.Email, DefaultEmailClaim)
+
+	userInfo := &idp.IdentityProviderUserInfo{
+		Identifier:  claimString(claims, identifierClaim),
+		DisplayName: claimString(claims, valueOrDefault(fieldMapping.DisplayName, DefaultDisplayNameClaim)),
+		Email:       claimString(claims, emailClaim),
+		// The email_verified claim is only about the standard email claim.
+		EmailVerified: emailClaim == DefaultEmailClaim && claimBool(claims, "email_verified"),
+		Groups:        claimStrings(claims, valueOrDefault(p.config.GroupsClaim, DefaultGroupsClaim)),
+	}
+	if userInfo.Identifier == "" {
+		return nil, errors.Errorf("the field %q is not found in claims or has empty value", identifierClaim)
+	}
+	if userInfo.DisplayName == "" {
+		userInfo.DisplayName = userInfo.Identifier
+	}
+	return userInfo, nil
+}
+
+// getJSON decodes the JSON response of a GET request, authorized by the access token if it is set.
+func (p *IdentityProvider) getJSON(ctx context.Context, url, accessToken string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to new http request")
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to request %s", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return errors.Wrap(err, "failed to unmarshal response body")
+	}
+	return nil
+}
+
+type signingKey struct {
+	kid       string
+	publicKey any
+}
+
+// matches returns whether the key can verify signatures of the signing method.
+func (k *signingKey) matches(method jwt.SigningMethod) bool {
+	switch k.publicKey.(type) {
+	case *rsa.PublicKey:
+		_, ok := method.(*jwt.SigningMethodRSA)
+		return ok
+	case *ecdsa.PublicKey:
+		_, ok := method.(*jwt.SigningMethodECDSA)
+		return ok
+	default:
+		return false
+	}
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// getSigningKeys returns the RSA and EC signing keys of the JSON Web Key Set of the issuer.
+func (p *IdentityProvider) getSigningKeys(ctx context.Context) ([]*signingKey, error) {
+	jwks := struct {
+		Keys []*jsonWebKey `json:"keys"`
+	}{}
+	if err := p.getJSON(ctx, p.discovery.JWKSURI, "", &jwks); err != nil {
+		return nil, errors.Wrap(err, "failed to get JSON Web Key Set")
+	}
+	keys := []*signingKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		publicKey, err := jwk.publicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %q", jwk.Kid)
+		}
+		if publicKey != nil {
+			keys = append(keys, &signingKey{kid: jwk.Kid, publicKey: publicKey})
+		}
+	}
+	return keys, nil
+}
+
+// publicKey returns the public key of the JSON Web Key, or nil for unsupported key types.
+func (k *jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{
+			"P-256": elliptic.P256(),
+			"P-384": elliptic.P384(),
+			"P-521": elliptic.P521(),
+		}
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, errors.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode key parameter")
+	}
+	if len(b) == 0 {
+		return nil, errors.New("missing key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func claimString(claims jwt.MapClaims, name string) string {
+	v, _ := claims[name].(string)
+	return v
+}
+
+// claimBool returns whether the claim is true, accepting the string "true" that some providers send.
+func claimBool(claims jwt.MapClaims, name string) bool {
+	switch v := claims[name].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	default:
+		return false
+	}
+}
+
+// claimStrings returns the claim as a list, accepting a single string as well.
+func claimStrings(claims jwt.MapClaims, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		list := []string{}
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
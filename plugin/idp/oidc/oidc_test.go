@@ -0,0 +1,367 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/usememos/memos/plugin/idp"
+	storepb "github.com/usememos/memos/proto/gen/store"
+)
+
+const (
+	testClientID     = "test-client-id"
+	testClientSecret = "test-client-secret"
+	testRedirectURL  = "https://memos.example.com/auth/callback"
+	testCode         = "test-code"
+	testAccessToken  = "test-access-token"
+	testKeyID        = "test-key"
+)
+
+// mockProvider is a stand-in OpenID Connect provider that issues ID tokens signed by its key.
+type mockProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	// codeChallenge is the PKCE code challenge that the authorization request carried.
+	codeChallenge string
+	// idTokenClaims is the claims of the issued ID token.
+	idTokenClaims jwt.MapClaims
+	userinfo      map[string]any
+	// issuer overrides the issuer of the provider metadata.
+	issuer string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	m := &mockProvider{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		issuer := m.server.URL
+		if m.issuer != "" {
+			issuer = m.issuer
+		}
+		writeJSON(t, w, map[string]any{
+			"issuer":                 issuer,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"userinfo_endpoint":      m.server.URL + "/userinfo",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, map[string]any{
+			"keys": []map[string]any{
+				{
+					"kty": "RSA",
+					"kid": testKeyID,
+					"use": "sig",
+					"alg": "RS256",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != testCode ||
+			r.PostForm.Get("client_id") != testClientID ||
+			r.PostForm.Get("client_secret") != testClientSecret ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != m.codeChallenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, m.idTokenClaims)
+		idToken.Header["kid"] = testKeyID
+		rawIDToken, err := idToken.SignedString(m.key)
+		require.NoError(t, err)
+		writeJSON(t, w, map[string]any{
+			"access_token": testAccessToken,
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     rawIDToken,
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testAccessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(t, w, m.userinfo)
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// authorize records the PKCE code challenge of the authorization URL, like the provider does when
+// the user is redirected to it.
+func (m *mockProvider) authorize(authorizationURL string) url.Values {
+	u, err := url.Parse(authorizationURL)
+	require.NoError(m.t, err)
+	query := u.Query()
+	require.Equal(m.t, "S256", query.Get("code_challenge_method"))
+	m.codeChallenge = query.Get("code_challenge")
+	return query
+}
+
+func (m *mockProvider) config() *storepb.OIDCConfig {
+	return &storepb.OIDCConfig{
+		IssuerUrl:    m.server.URL,
+		ClientId:     testClientID,
+		ClientSecret: testClientSecret,
+	}
+}
+
+func (m *mockProvider) validClaims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":                m.server.URL,
+		"sub":                "user-1",
+		"aud":                testClientID,
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              nonce,
+		"preferred_username": "alice",
+		"name":               "Alice",
+		"groups":             []string{"staff", "admins"},
+	}
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(t, json.NewEncoder(w).Encode(v))
+}
+
+func TestNewIdentityProvider(t *testing.T) {
+	ctx := context.Background()
+	m := newMockProvider(t)
+
+	_, err := NewIdentityProvider(ctx, &storepb.OIDCConfig{ClientId: testClientID})
+	require.ErrorContains(t, err, `the field "issuerUrl" is empty but required`)
+
+	// The issuer of the metadata must be the configured issuer.
+	m.issuer = "https://attacker.example.com"
+	_, err = NewIdentityProvider(ctx, m.config())
+	require.ErrorContains(t, err, "does not match")
+	m.issuer = ""
+
+	_, err = NewIdentityProvider(ctx, m.config())
+	require.NoError(t, err)
+}
+
+func TestIdentityProvider(t *testing.T) {
+	ctx := context.Background()
+	m := newMockProvider(t)
+	m.userinfo = map[string]any{"sub": "user-1", "email": "alice@example.com"}
+	p, err := NewIdentityProvider(ctx, m.config())
+	require.NoError(t, err)
+
+	nonce, codeVerifier := "test-nonce", "test-code-verifier-that-is-long-enough-for-pkce"
+	query := m.authorize(p.AuthorizationURL(testRedirectURL, "test-state", nonce, codeVerifier))
+	require.Equal(t, "test-state", query.Get("state"))
+	require.Equal(t, nonce, query.Get("nonce"))
+	require.Equal(t, "openid profile email", query.Get("scope"))
+	m.idTokenClaims = m.validClaims(nonce)
+
+	// The code is bound to the code verifier.
+	_, err = p.ExchangeToken(ctx, testRedirectURL, testCode, "another-code-verifier")
+	require.Error(t, err)
+
+	token, err := p.ExchangeToken(ctx, testRedirectURL, testCode, codeVerifier)
+	require.NoError(t, err)
+	userInfo, err := p.UserInfo(ctx, token, nonce)
+	require.NoError(t, err)
+	require.Equal(t, &idp.IdentityProviderUserInfo{
+		Identifier:  "alice",
+		DisplayName: "Alice",
+		Email:       "alice@example.com",
+		Groups:      []string{"staff", "admins"},
+	}, userInfo)
+
+	// The user information must be about the subject of the ID token.
+	m.userinfo = map[string]any{"sub": "user-2", "email": "bob@example.com"}
+	token, err = p.ExchangeToken(ctx, testRedirectURL, testCode, codeVerifier)
+	require.NoError(t, err)
+	_, err = p.UserInfo(ctx, token, nonce)
+	require.ErrorContains(t, err, "does not match the ID token")
+}
+
+func TestIdentityProviderFieldMapping(t *testing.T) {
+	ctx := context.Background()
+	m := newMockProvider(t)
+	config := m.config()
+	config.Scopes = []string{"openid", "roles"}
+	config.FieldMapping = &storepb.FieldMapping{Identifier: "email"}
+	config.GroupsClaim = "roles"
+	p, err := NewIdentityProvider(ctx, config)
+	require.NoError(t, err)
+
+	nonce, codeVerifier := "test-nonce", "test-code-verifier-that-is-long-enough-for-pkce"
+	query := m.authorize(p.AuthorizationURL(testRedirectURL, "test-state", nonce, codeVerifier))
+	require.Equal(t, "openid roles", query.Get("scope"))
+	m.idTokenClaims = jwt.MapClaims{
+		"iss":   m.server.URL,
+		"sub":   "user-1",
+		"aud":   []string{testClientID},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": nonce,
+		"email": "alice@example.com",
+		"roles": "editor",
+	}
+	m.userinfo = map[string]any{"sub": "user-1"}
+
+	token, err := p.ExchangeToken(ctx, testRedirectURL, testCode, codeVerifier)
+	require.NoError(t, err)
+	userInfo, err := p.UserInfo(ctx, token, nonce)
+	require.NoError(t, err)
+	require.Equal(t, &idp.IdentityProviderUserInfo{
+		Identifier:  "alice@example.com",
+		DisplayName: "alice@example.com",
+		Email:       "alice@example.com",
+		Groups:      []string{"editor"},
+	}, userInfo)
+}
+
+func TestMapClaimsEmailVerified(t *testing.T) {
+	tests := []struct {
+		name          string
+		fieldMapping  *storepb.FieldMapping
+		emailVerified any
+		expected      bool
+	}{
+		{name: "verified", emailVerified: true, expected: true},
+		{name: "verified as string", emailVerified: "true", expected: true},
+		{name: "unverified", emailVerified: false, expected: false},
+		{name: "unverified as string", emailVerified: "false", expected: false},
+		{name: "missing", expected: false},
+		{
+			// The claim does not verify emails mapped from other claims.
+			name:          "other email claim",
+			fieldMapping:  &storepb.FieldMapping{Email: "upn"},
+			emailVerified: true,
+			expected:      false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &IdentityProvider{config: &storepb.OIDCConfig{FieldMapping: test.fieldMapping}}
+			claims := jwt.MapClaims{"preferred_username": "alice", "email": "alice@example.com", "upn": "alice@example.com"}
+			if test.emailVerified != nil {
+				claims["email_verified"] = test.emailVerified
+			}
+			userInfo, err := p.mapClaims(claims)
+			require.NoError(t, err)
+			require.Equal(t, "alice@example.com", userInfo.Email)
+			require.Equal(t, test.expected, userInfo.EmailVerified)
+		})
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	ctx := context.Background()
+	m := newMockProvider(t)
+	p, err := NewIdentityProvider(ctx, m.config())
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	nonce := "test-nonce"
+	tests := []struct {
+		name        string
+		modify      func(claims jwt.MapClaims)
+		signingKey  *rsa.PrivateKey
+		containsErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(jwt.MapClaims) {},
+		},
+		{
+			name:        "wrong nonce",
+			modify:      func(claims jwt.MapClaims) { claims["nonce"] = "another-nonce" },
+			containsErr: "nonce does not match",
+		},
+		{
+			name:        "missing nonce",
+			modify:      func(claims jwt.MapClaims) { delete(claims, "nonce") },
+			containsErr: "nonce does not match",
+		},
+		{
+			name:        "wrong audience",
+			modify:      func(claims jwt.MapClaims) { claims["aud"] = "another-client-id" },
+			containsErr: "invalid ID token",
+		},
+		{
+			name:        "several audiences without authorized party",
+			modify:      func(claims jwt.MapClaims) { claims["aud"] = []string{testClientID, "another-client-id"} },
+			containsErr: "authorized party does not match",
+		},
+		{
+			name:        "wrong issuer",
+			modify:      func(claims jwt.MapClaims) { claims["iss"] = "https://attacker.example.com" },
+			containsErr: "invalid ID token",
+		},
+		{
+			name:        "expired",
+			modify:      func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+			containsErr: "invalid ID token",
+		},
+		{
+			name:        "missing expiration",
+			modify:      func(claims jwt.MapClaims) { delete(claims, "exp") },
+			containsErr: "invalid ID token",
+		},
+		{
+			name:        "signed by another key",
+			modify:      func(jwt.MapClaims) {},
+			signingKey:  otherKey,
+			containsErr: "invalid ID token",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims := m.validClaims(nonce)
+			test.modify(claims)
+			signingKey := m.key
+			if test.signingKey != nil {
+				signingKey = test.signingKey
+			}
+			idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+			idToken.Header["kid"] = testKeyID
+			rawIDToken, err := idToken.SignedString(signingKey)
+			require.NoError(t, err)
+
+			_, err = p.VerifyIDToken(ctx, rawIDToken, nonce)
+			if test.containsErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, test.containsErr)
+			}
+		})
+	}
+
+	// Tokens signed with a symmetric algorithm are rejected even if the key would verify them.
+	idToken := jwt.NewWithClaims(jwt.SigningMethodHS256, m.validClaims(nonce))
+	rawIDToken, err := idToken.SignedString([]byte(testClientSecret))
+	require.NoError(t, err)
+	_, err = p.VerifyIDToken(ctx, rawIDToken, nonce)
+	require.ErrorContains(t, err, "invalid ID token")
+}
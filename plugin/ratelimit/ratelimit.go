@@ -0,0 +1,239 @@
+// Package ratelimit is the plugin for limiting the rate of requests with token buckets, and for locking
+// keys out after repeated failures, e.g. failed sign-ins to an account.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// failureWindow is how long failures are remembered after the last one.
+const failureWindow = 24 * time.Hour
+
+// State is the state of a key, either the token bucket of a rate limit or the failures of a lockout.
+type State struct {
+	// Tokens is the number of requests left in the token bucket at the updated time.
+	Tokens float64
+	// Failures is the number of failures in a row.
+	Failures int32
+	// LockedUntil is the time the lockout of the key ends.
+	LockedUntil time.Time
+	// UpdatedTime is the time the state last changed.
+	UpdatedTime time.Time
+}
+
+// Store keeps the states of the keys. Its updates are atomic, so that limiters sharing a store, e.g.
+// the processes sharing a database, count every request and failure.
+type Store interface {
+	// Get returns the state of the key, or nil if the key has no state.
+	Get(ctx context.Context, key string) (*State, error)
+	// Take refills the bucket of the key by the limit up to the time, and takes a token from it if it has
+	// one. A key without state has a full bucket. It returns whether it took a token.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (bool, error)
+	// Fail records a failure of the key at the time, and returns the failures in a row. The failures
+	// start over if the last change of the key is older than the window.
+	Fail(ctx context.Context, key string, now time.Time, window time.Duration) (int32, error)
+	// Lock locks the key out until the time, unless it is locked out longer already.
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Delete deletes the state of the key.
+	Delete(ctx context.Context, key string) error
+	// Prune deletes the states that last changed before the time.
+	Prune(ctx context.Context, before time.Time) error
+}
+
+// Limit is the rate limit of a token bucket.
+type Limit struct {
+	// RequestsPerMinute is the rate at which the bucket refills.
+	RequestsPerMinute int32
+	// Burst is the size of the bucket.
+	Burst int32
+}
+
+// Lockout locks a key out once it fails Threshold times in a row. The first lockout lasts Duration,
+// and every further failure doubles it up to MaxDuration.
+type Lockout struct {
+	Threshold   int32
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// Limiter limits the requests and locks out the failures of keys, keeping their states in a store.
+type Limiter struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewLimiter returns a new limiter that keeps the states in the store.
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of the key. It returns how long to wait until a token is
+// available if the bucket is empty, or 0 if the request is allowed.
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (time.Duration, error) {
+	if limit.RequestsPerMinute <= 0 || limit.Burst <= 0 {
+		return 0, nil
+	}
+	now := l.now()
+	ok, err := l.store.Take(ctx, key, limit, now)
+	if err != nil || ok {
+		return 0, err
+	}
+	state, err := l.store.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	// The state was pruned in the meantime, so the bucket is full again.
+	if state == nil {
+		return 0, nil
+	}
+	tokens := refill(state, limit, now)
+	if tokens >= 1 {
+		return 0, nil
+	}
+	return time.Duration(math.Ceil((1-tokens)/limit.rate()*1000)) * time.Millisecond, nil
+}
+
+// LockedOut returns how long the lockout of the key lasts, or 0 if the key is not locked out.
+func (l *Limiter) LockedOut(ctx context.Context, key string) (time.Duration, error) {
+	state, err := l.store.Get(ctx, key)
+	if err != nil || state == nil {
+		return 0, err
+	}
+	return max(state.LockedUntil.Sub(l.now()), 0), nil
+}
+
+// Fail records a failure of the key, and returns how long the key is locked out for it, or 0 if the
+// failures have not reached the threshold yet.
+func (l *Limiter) Fail(ctx context.Context, key string, lockout Lockout) (time.Duration, error) {
+	now := l.now()
+	failures, err := l.store.Fail(ctx, key, now, failureWindow)
+	if err != nil {
+		return 0, err
+	}
+	if lockout.Threshold <= 0 || failures < lockout.Threshold {
+		return 0, nil
+	}
+	duration := lockout.Duration
+	for i := lockout.Threshold; i < failures && duration < lockout.MaxDuration; i++ {
+		duration *= 2
+	}
+	duration = min(duration, lockout.MaxDuration)
+	if err := l.store.Lock(ctx, key, now.Add(duration)); err != nil {
+		return 0, err
+	}
+	return duration, nil
+}
+
+// Reset forgets the failures of the key.
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	return l.store.Delete(ctx, key)
+}
+
+// Prune deletes the states that have not changed for a day. By then, the buckets are full again,
+// the lockouts have ended and the failures are forgotten.
+func (l *Limiter) Prune(ctx context.Context) error {
+	return l.store.Prune(ctx, l.now().Add(-failureWindow))
+}
+
+// rate returns the tokens that the bucket refills per second.
+func (limit Limit) rate() float64 {
+	return float64(limit.RequestsPerMinute) / time.Minute.Seconds()
+}
+
+// refill returns the tokens in the bucket of the state at the time.
+func refill(state *State, limit Limit, now time.Time) float64 {
+	return min(float64(limit.Burst), state.Tokens+max(now.Sub(state.UpdatedTime).Seconds(), 0)*limit.rate())
+}
+
+// MemoryStore keeps the states in memory.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]*State
+}
+
+// NewMemoryStore returns a new empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		states: map[string]*State{},
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (*State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[key]
+	if !ok {
+		return nil, nil
+	}
+	clone := *state
+	return &clone, nil
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens := float64(limit.Burst)
+	if state, ok := s.states[key]; ok {
+		tokens = refill(state, limit, now)
+	}
+	if tokens < 1 {
+		return false, nil
+	}
+	s.states[key] = &State{
+		Tokens:      tokens - 1,
+		UpdatedTime: now,
+	}
+	return true, nil
+}
+
+func (s *MemoryStore) Fail(_ context.Context, key string, now time.Time, window time.Duration) (int32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[key]
+	if !ok || now.Sub(state.UpdatedTime) > window {
+		state = &State{}
+		s.states[key] = state
+	}
+	state.Failures++
+	state.UpdatedTime = now
+	return state.Failures, nil
+}
+
+func (s *MemoryStore) Lock(_ context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state, ok := s.states[key]; ok && until.After(state.LockedUntil) {
+		state.LockedUntil = until
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, key)
+	return nil
+}
+
+func (s *MemoryStore) Prune(_ context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, state := range s.states {
+		if state.UpdatedTime.Before(before) {
+			delete(s.states, key)
+		}
+	}
+	return nil
+}
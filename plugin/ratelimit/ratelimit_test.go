@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter() (*Limiter, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	limiter := NewLimiter(NewMemoryStore())
+	limiter.now = func() time.Time {
+		return now
+	}
+	return limiter, &now
+}
+
+func TestAllow(t *testing.T) {
+	ctx := context.Background()
+	limiter, now := newTestLimiter()
+	limit := Limit{RequestsPerMinute: 6, Burst: 3}
+
+	// The bucket starts full.
+	for i := 0; i < 3; i++ {
+		retryAfter, err := limiter.Allow(ctx, "ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+	}
+	retryAfter, err := limiter.Allow(ctx, "ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Second, retryAfter)
+
+	// Other keys have their own bucket.
+	retryAfter, err = limiter.Allow(ctx, "ip:10.0.0.2", limit)
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+
+	// A token is added every 10 seconds.
+	*now = now.Add(4 * time.Second)
+	retryAfter, err = limiter.Allow(ctx, "ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	require.Equal(t, 6*time.Second, retryAfter)
+	*now = now.Add(6 * time.Second)
+	retryAfter, err = limiter.Allow(ctx, "ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+
+	// The bucket never holds more than the burst.
+	*now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		retryAfter, err = limiter.Allow(ctx, "ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+	}
+	retryAfter, err = limiter.Allow(ctx, "ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	require.NotZero(t, retryAfter)
+
+	// A limit without rate or burst allows everything.
+	retryAfter, err = limiter.Allow(ctx, "ip:10.0.0.1", Limit{})
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+}
+
+func TestLockout(t *testing.T) {
+	ctx := context.Background()
+	limiter, now := newTestLimiter()
+	lockout := Lockout{Threshold: 3, Duration: 30 * time.Second, MaxDuration: 2 * time.Minute}
+
+	for i := 0; i < 2; i++ {
+		duration, err := limiter.Fail(ctx, "signin:alice", lockout)
+		require.NoError(t, err)
+		require.Zero(t, duration)
+	}
+	lockedOut, err := limiter.LockedOut(ctx, "signin:alice")
+	require.NoError(t, err)
+	require.Zero(t, lockedOut)
+
+	// Every failure from the threshold on doubles the lockout, up to the maximum.
+	for _, expected := range []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 2 * time.Minute} {
+		duration, err := limiter.Fail(ctx, "signin:alice", lockout)
+		require.NoError(t, err)
+		require.Equal(t, expected, duration)
+	}
+	lockedOut, err = limiter.LockedOut(ctx, "signin:alice")
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Minute, lockedOut)
+	*now = now.Add(2 * time.Minute)
+	lockedOut, err = limiter.LockedOut(ctx, "signin:alice")
+	require.NoError(t, err)
+	require.Zero(t, lockedOut)
+
+	// Failures are forgotten after a reset, or a day without failures.
+	require.NoError(t, limiter.Reset(ctx, "signin:alice"))
+	for i := 0; i < 2; i++ {
+		duration, err := limiter.Fail(ctx, "signin:alice", lockout)
+		require.NoError(t, err)
+		require.Zero(t, duration)
+	}
+	*now = now.Add(25 * time.Hour)
+	duration, err := limiter.Fail(ctx, "signin:alice", lockout)
+	require.NoError(t, err)
+	require.Zero(t, duration)
+}
+
+func TestPrune(t *testing.T) {
+	ctx := context.Background()
+	limiter, now := newTestLimiter()
+	store := limiter.store.(*MemoryStore)
+
+	_, err := limiter.Allow(ctx, "ip:10.0.0.1", Limit{RequestsPerMinute: 1, Burst: 1})
+	require.NoError(t, err)
+	*now = now.Add(23 * time.Hour)
+	_, err = limiter.Fail(ctx, "signin:alice", Lockout{Threshold: 1, Duration: time.Minute, MaxDuration: time.Hour})
+	require.NoError(t, err)
+
+	*now = now.Add(2 * time.Hour)
+	require.NoError(t, limiter.Prune(ctx))
+	state, err := store.Get(ctx, "ip:10.0.0.1")
+	require.NoError(t, err)
+	require.Nil(t, state)
+	state, err = store.Get(ctx, "signin:alice")
+	require.NoError(t, err)
+	require.Equal(t, int32(1), state.Failures)
+}
+
+func TestAllowConcurrently(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	limit := Limit{RequestsPerMinute: 1, Burst: 10}
+
+	// Limiters sharing a store, like processes sharing a database, take every token once.
+	allowed := make(chan bool, 100)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			retryAfter, err := NewLimiter(store).Allow(ctx, "ip:10.0.0.1", limit)
+			assert.NoError(t, err)
+			allowed <- retryAfter == 0
+		}()
+	}
+	wg.Wait()
+	close(allowed)
+	count := 0
+	for ok := range allowed {
+		if ok {
+			count++
+		}
+	}
+	require.Equal(t, 10, count)
+}
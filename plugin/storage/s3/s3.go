@@ -77,6 +77,35 @@ func (c *Client) PresignGetObject(ctx context.Context, key string) (string, erro
 	return presignResult.URL, nil
 }
 
+// GetObject downloads an object from S3.
+func (c *Client) GetObject(ctx context.Context, key string) ([]byte, error) {
+	output, err := c.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: c.Bucket,
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get object")
+	}
+	defer output.Body.Close()
+	content, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read object")
+	}
+	return content, nil
+}
+
+// OpenObject opens an object in S3 for reading, the caller must close it.
+func (c *Client) OpenObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	output, err := c.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: c.Bucket,
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get object")
+	}
+	return output.Body, nil
+}
+
 // DeleteObject deletes an object in S3.
 func (c *Client) DeleteObject(ctx context.Context, key string) error {
 	_, err := c.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
@@ -0,0 +1,52 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCode(t *testing.T) {
+	// The SHA1 test vectors of RFC 6238, truncated to 6 digits.
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+	for _, test := range tests {
+		code, err := GenerateCode(secret, time.Unix(test.unix, 0))
+		require.NoError(t, err)
+		require.Equal(t, test.code, code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	code, err := GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	step, ok := Validate(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, now.Unix()/Period, step)
+	_, ok = Validate(secret, code, now.Add(Period*time.Second))
+	require.True(t, ok)
+	_, ok = Validate(secret, code, now.Add(3*Period*time.Second))
+	require.False(t, ok)
+	_, ok = Validate(secret, "12345", now)
+	require.False(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Memos", "steven", "JBSWY3DPEHPK3PXP")
+	require.Equal(t, "otpauth://totp/Memos:steven?algorithm=SHA1&digits=6&issuer=Memos&period=30&secret=JBSWY3DPEHPK3PXP", uri)
+}
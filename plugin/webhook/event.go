@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"slices"
+)
+
+// Event types of webhook requests.
+const (
+	EventMemoCreated        = "memos.memo.created"
+	EventMemoUpdated        = "memos.memo.updated"
+	EventMemoDeleted        = "memos.memo.deleted"
+	EventMemoPinned         = "memos.memo.pinned"
+	EventMemoArchived       = "memos.memo.archived"
+	EventMemoCommentCreated = "memos.memo.comment.created"
+	EventReactionCreated    = "memos.reaction.created"
+	EventReactionDeleted    = "memos.reaction.deleted"
+	EventResourceCreated    = "memos.resource.created"
+	// EventUserSignedUp is only sent to webhooks of admins.
+	EventUserSignedUp = "memos.user.signed_up"
+)
+
+// EventTypes are all the event types a webhook can subscribe to.
+var EventTypes = []string{
+	EventMemoCreated,
+	EventMemoUpdated,
+	EventMemoDeleted,
+	EventMemoPinned,
+	EventMemoArchived,
+	EventMemoCommentCreated,
+	EventReactionCreated,
+	EventReactionDeleted,
+	EventResourceCreated,
+	EventUserSignedUp,
+}
+
+// DefaultEventTypes are the event types received by webhooks without any subscription.
+var DefaultEventTypes = []string{
+	EventMemoCreated,
+	EventMemoUpdated,
+	EventMemoDeleted,
+}
+
+// IsSubscribed returns whether a webhook subscribing to the event types receives the event.
+func IsSubscribed(eventTypes []string, eventType string) bool {
+	if len(eventTypes) == 0 {
+		eventTypes = DefaultEventTypes
+	}
+	return slices.Contains(eventTypes, eventType)
+}
@@ -2,9 +2,13 @@ package webhook
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
@@ -18,6 +22,33 @@ var (
 	timeout = 30 * time.Second
 )
 
+// maxResponseBodySize is the maximum size of the response body read for its error code and the delivery
+// log, so that an endpoint cannot make the server read an endless response.
+const maxResponseBodySize = 64 << 10
+
+const (
+	// TimestampHeader is the header carrying the unix time at which the request was signed.
+	TimestampHeader = "X-Memos-Timestamp"
+	// SignatureHeader is the header carrying the signature of the request, see Sign.
+	SignatureHeader = "X-Memos-Signature"
+)
+
+// Request is a request to a webhook endpoint.
+type Request struct {
+	URL  string
+	Body []byte
+	// Secret is the key used to sign the request, the request is not signed if empty.
+	Secret string
+	// Headers are extra static headers sent with the request.
+	Headers map[string]string
+}
+
+// Response is the response received from a webhook endpoint.
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
+
 // Post posts the message to webhook endpoint.
 func Post(requestPayload *v1pb.WebhookRequestPayload) error {
 	body, err := protojson.Marshal(requestPayload)
@@ -25,41 +56,72 @@ func Post(requestPayload *v1pb.WebhookRequestPayload) error {
 		return errors.Wrapf(err, "failed to marshal webhook request to %s", requestPayload.Url)
 	}
 
-	req, err := http.NewRequest("POST", requestPayload.Url, bytes.NewBuffer(body))
+	_, err = Send(&Request{
+		URL:  requestPayload.Url,
+		Body: body,
+	})
+	return err
+}
+
+// Send sends the JSON body of the request to webhook endpoint.
+// The response is returned whenever the endpoint has been reached, even if it reports a failure.
+func Send(request *Request) (*Response, error) {
+	url, body := request.URL, request.Body
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
 	if err != nil {
-		return errors.Wrapf(err, "failed to construct webhook request to %s", requestPayload.Url)
+		return nil, errors.Wrapf(err, "failed to construct webhook request to %s", url)
 	}
 
+	for key, value := range request.Headers {
+		req.Header.Set(key, value)
+	}
 	req.Header.Set("Content-Type", "application/json")
+	if request.Secret != "" {
+		timestamp := time.Now().Unix()
+		req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(SignatureHeader, Sign(request.Secret, timestamp, body))
+	}
 	client := &http.Client{
 		Timeout: timeout,
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return errors.Wrapf(err, "failed to post webhook to %s", requestPayload.Url)
+		return nil, errors.Wrapf(err, "failed to post webhook to %s", url)
 	}
+	defer resp.Body.Close()
 
-	b, err := io.ReadAll(resp.Body)
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
 	if err != nil {
-		return errors.Wrapf(err, "failed to read webhook response from %s", requestPayload.Url)
+		return nil, errors.Wrapf(err, "failed to read webhook response from %s", url)
 	}
-	defer resp.Body.Close()
 
+	response := &Response{
+		StatusCode: resp.StatusCode,
+		Body:       b,
+	}
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return errors.Errorf("failed to post webhook %s, status code: %d, response body: %s", requestPayload.Url, resp.StatusCode, b)
+		return response, errors.Errorf("failed to post webhook %s, status code: %d, response body: %s", url, resp.StatusCode, b)
 	}
 
-	response := &struct {
+	// Endpoints replying with a JSON object may report a failure with a non-zero code.
+	result := &struct {
 		Code    int    `json:"code"`
 		Message string `json:"message"`
 	}{}
-	if err := json.Unmarshal(b, response); err != nil {
-		return errors.Wrapf(err, "failed to unmarshal webhook response from %s", requestPayload.Url)
+	if err := json.Unmarshal(b, result); err == nil && result.Code != 0 {
+		return response, errors.Errorf("receive error code sent by webhook server, code %d, msg: %s", result.Code, result.Message)
 	}
 
-	if response.Code != 0 {
-		return errors.Errorf("receive error code sent by webhook server, code %d, msg: %s", response.Code, response.Message)
-	}
+	return response, nil
+}
 
-	return nil
+// Sign returns the signature of a request body sent at the given unix timestamp,
+// formatted as "sha256=" followed by the hex encoded HMAC-SHA256 of "{timestamp}.{body}" keyed with the secret.
+// Receivers should recompute it, compare it in constant time and reject stale timestamps.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
 }
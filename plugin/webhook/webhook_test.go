@@ -1 +1,118 @@
 package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		statusCode   int
+		responseBody string
+		wantErr      bool
+	}{
+		{
+			statusCode:   http.StatusOK,
+			responseBody: "",
+		},
+		{
+			statusCode:   http.StatusOK,
+			responseBody: "ok",
+		},
+		{
+			statusCode:   http.StatusOK,
+			responseBody: `{"code":0}`,
+		},
+		{
+			statusCode:   http.StatusOK,
+			responseBody: `{"code":1,"message":"error"}`,
+			wantErr:      true,
+		},
+		{
+			statusCode:   http.StatusBadGateway,
+			responseBody: "bad gateway",
+			wantErr:      true,
+		},
+	}
+
+	for _, test := range tests {
+		var requestBody []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(test.statusCode)
+			_, _ = w.Write([]byte(test.responseBody))
+		}))
+		response, err := Send(&Request{
+			URL:  server.URL,
+			Body: []byte(`{"url":"test"}`),
+		})
+		server.Close()
+		if test.wantErr {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+		require.Equal(t, `{"url":"test"}`, string(requestBody))
+		require.Equal(t, test.statusCode, response.StatusCode)
+		require.Equal(t, test.responseBody, string(response.Body))
+	}
+}
+
+func TestSendLargeResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(strings.Repeat("a", maxResponseBodySize+1)))
+	}))
+	defer server.Close()
+
+	// Only the start of the response body is read.
+	response, err := Send(&Request{
+		URL:  server.URL,
+		Body: []byte(`{}`),
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Body, maxResponseBodySize)
+}
+
+func TestSendSigned(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	}))
+	defer server.Close()
+
+	body := []byte(`{"url":"test"}`)
+	_, err := Send(&Request{
+		URL:    server.URL,
+		Body:   body,
+		Secret: "secret",
+		Headers: map[string]string{
+			"X-Api-Key":    "key",
+			"Content-Type": "text/plain",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "key", header.Get("X-Api-Key"))
+	require.Equal(t, "application/json", header.Get("Content-Type"))
+	timestamp, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	require.NoError(t, err)
+	require.Equal(t, Sign("secret", timestamp, body), header.Get(SignatureHeader))
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	require.Equal(t, "sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163", Sign("secret", 1700000000, []byte("{}")))
+}
+
+func TestIsSubscribed(t *testing.T) {
+	require.True(t, IsSubscribed(nil, EventMemoCreated))
+	require.False(t, IsSubscribed(nil, EventReactionCreated))
+	require.True(t, IsSubscribed([]string{EventReactionCreated}, EventReactionCreated))
+	require.False(t, IsSubscribed([]string{EventReactionCreated}, EventMemoCreated))
+}
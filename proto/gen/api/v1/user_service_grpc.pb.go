@@ -21,24 +21,33 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	UserService_ListUsers_FullMethodName             = "/memos.api.v1.UserService/ListUsers"
-	UserService_GetUser_FullMethodName               = "/memos.api.v1.UserService/GetUser"
-	UserService_GetUserByUsername_FullMethodName     = "/memos.api.v1.UserService/GetUserByUsername"
-	UserService_GetUserAvatarBinary_FullMethodName   = "/memos.api.v1.UserService/GetUserAvatarBinary"
-	UserService_CreateUser_FullMethodName            = "/memos.api.v1.UserService/CreateUser"
-	UserService_UpdateUser_FullMethodName            = "/memos.api.v1.UserService/UpdateUser"
-	UserService_DeleteUser_FullMethodName            = "/memos.api.v1.UserService/DeleteUser"
-	UserService_ListAllUserStats_FullMethodName      = "/memos.api.v1.UserService/ListAllUserStats"
-	UserService_GetUserStats_FullMethodName          = "/memos.api.v1.UserService/GetUserStats"
-	UserService_GetUserSetting_FullMethodName        = "/memos.api.v1.UserService/GetUserSetting"
-	UserService_UpdateUserSetting_FullMethodName     = "/memos.api.v1.UserService/UpdateUserSetting"
-	UserService_ListUserAccessTokens_FullMethodName  = "/memos.api.v1.UserService/ListUserAccessTokens"
-	UserService_CreateUserAccessToken_FullMethodName = "/memos.api.v1.UserService/CreateUserAccessToken"
-	UserService_DeleteUserAccessToken_FullMethodName = "/memos.api.v1.UserService/DeleteUserAccessToken"
-	UserService_ListShortcuts_FullMethodName         = "/memos.api.v1.UserService/ListShortcuts"
-	UserService_CreateShortcut_FullMethodName        = "/memos.api.v1.UserService/CreateShortcut"
-	UserService_UpdateShortcut_FullMethodName        = "/memos.api.v1.UserService/UpdateShortcut"
-	UserService_DeleteShortcut_FullMethodName        = "/memos.api.v1.UserService/DeleteShortcut"
+	UserService_ListUsers_FullMethodName               = "/memos.api.v1.UserService/ListUsers"
+	UserService_GetUser_FullMethodName                 = "/memos.api.v1.UserService/GetUser"
+	UserService_GetUserByUsername_FullMethodName       = "/memos.api.v1.UserService/GetUserByUsername"
+	UserService_GetUserAvatarBinary_FullMethodName     = "/memos.api.v1.UserService/GetUserAvatarBinary"
+	UserService_CreateUser_FullMethodName              = "/memos.api.v1.UserService/CreateUser"
+	UserService_UpdateUser_FullMethodName              = "/memos.api.v1.UserService/UpdateUser"
+	UserService_DeleteUser_FullMethodName              = "/memos.api.v1.UserService/DeleteUser"
+	UserService_ListAllUserStats_FullMethodName        = "/memos.api.v1.UserService/ListAllUserStats"
+	UserService_GetUserStats_FullMethodName            = "/memos.api.v1.UserService/GetUserStats"
+	UserService_GetUserSetting_FullMethodName          = "/memos.api.v1.UserService/GetUserSetting"
+	UserService_UpdateUserSetting_FullMethodName       = "/memos.api.v1.UserService/UpdateUserSetting"
+	UserService_ListUserAccessTokens_FullMethodName    = "/memos.api.v1.UserService/ListUserAccessTokens"
+	UserService_CreateUserAccessToken_FullMethodName   = "/memos.api.v1.UserService/CreateUserAccessToken"
+	UserService_DeleteUserAccessToken_FullMethodName   = "/memos.api.v1.UserService/DeleteUserAccessToken"
+	UserService_ListUserSessions_FullMethodName        = "/memos.api.v1.UserService/ListUserSessions"
+	UserService_RevokeUserSession_FullMethodName       = "/memos.api.v1.UserService/RevokeUserSession"
+	UserService_RevokeAllUserSessions_FullMethodName   = "/memos.api.v1.UserService/RevokeAllUserSessions"
+	UserService_ListShortcuts_FullMethodName           = "/memos.api.v1.UserService/ListShortcuts"
+	UserService_CreateShortcut_FullMethodName          = "/memos.api.v1.UserService/CreateShortcut"
+	UserService_UpdateShortcut_FullMethodName          = "/memos.api.v1.UserService/UpdateShortcut"
+	UserService_DeleteShortcut_FullMethodName          = "/memos.api.v1.UserService/DeleteShortcut"
+	UserService_EnrollTOTP_FullMethodName              = "/memos.api.v1.UserService/EnrollTOTP"
+	UserService_VerifyTOTP_FullMethodName              = "/memos.api.v1.UserService/VerifyTOTP"
+	UserService_DisableTOTP_FullMethodName             = "/memos.api.v1.UserService/DisableTOTP"
+	UserService_RegenerateRecoveryCodes_FullMethodName = "/memos.api.v1.UserService/RegenerateRecoveryCodes"
+	UserService_ImportUserData_FullMethodName          = "/memos.api.v1.UserService/ImportUserData"
+	UserService_ImportUserNotes_FullMethodName         = "/memos.api.v1.UserService/ImportUserNotes"
 )
 
 // UserServiceClient is the client API for UserService service.
@@ -73,6 +82,13 @@ type UserServiceClient interface {
 	CreateUserAccessToken(ctx context.Context, in *CreateUserAccessTokenRequest, opts ...grpc.CallOption) (*UserAccessToken, error)
 	// DeleteUserAccessToken deletes an access token for a user.
 	DeleteUserAccessToken(ctx context.Context, in *DeleteUserAccessTokenRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ListUserSessions returns the active sign-in sessions of a user.
+	ListUserSessions(ctx context.Context, in *ListUserSessionsRequest, opts ...grpc.CallOption) (*ListUserSessionsResponse, error)
+	// RevokeUserSession revokes a sign-in session of a user, which signs the client of the session out.
+	RevokeUserSession(ctx context.Context, in *RevokeUserSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// RevokeAllUserSessions signs a user out everywhere by revoking all the sign-in sessions of the user,
+	// and optionally the access tokens that the user created.
+	RevokeAllUserSessions(ctx context.Context, in *RevokeAllUserSessionsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// ListShortcuts returns a list of shortcuts for a user.
 	ListShortcuts(ctx context.Context, in *ListShortcutsRequest, opts ...grpc.CallOption) (*ListShortcutsResponse, error)
 	// CreateShortcut creates a new shortcut for a user.
@@ -81,6 +97,22 @@ type UserServiceClient interface {
 	UpdateShortcut(ctx context.Context, in *UpdateShortcutRequest, opts ...grpc.CallOption) (*Shortcut, error)
 	// DeleteShortcut deletes a shortcut for a user.
 	DeleteShortcut(ctx context.Context, in *DeleteShortcutRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// EnrollTOTP generates a new TOTP secret for a user. Two-factor authentication is enabled
+	// once VerifyTOTP verifies a code of the secret.
+	EnrollTOTP(ctx context.Context, in *EnrollTOTPRequest, opts ...grpc.CallOption) (*EnrollTOTPResponse, error)
+	// VerifyTOTP verifies a code of the enrolled TOTP secret, enables two-factor authentication
+	// and returns new recovery codes.
+	VerifyTOTP(ctx context.Context, in *VerifyTOTPRequest, opts ...grpc.CallOption) (*RecoveryCodesResponse, error)
+	// DisableTOTP disables two-factor authentication for a user.
+	// Users who can manage users can disable it for users of a lower role, and the host for any user.
+	DisableTOTP(ctx context.Context, in *DisableTOTPRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// RegenerateRecoveryCodes replaces the recovery codes of a user.
+	RegenerateRecoveryCodes(ctx context.Context, in *RegenerateRecoveryCodesRequest, opts ...grpc.CallOption) (*RecoveryCodesResponse, error)
+	// ImportUserData imports an archive exported from GET /api/v1/{name=users/*}:export for a user.
+	// Memos and resources that were imported before are skipped.
+	ImportUserData(ctx context.Context, in *ImportUserDataRequest, opts ...grpc.CallOption) (*ImportUserDataResponse, error)
+	// ImportUserNotes imports the notes of another note app for a user.
+	ImportUserNotes(ctx context.Context, in *ImportUserNotesRequest, opts ...grpc.CallOption) (*ImportUserNotesResponse, error)
 }
 
 type userServiceClient struct {
@@ -231,6 +263,36 @@ func (c *userServiceClient) DeleteUserAccessToken(ctx context.Context, in *Delet
 	return out, nil
 }
 
+func (c *userServiceClient) ListUserSessions(ctx context.Context, in *ListUserSessionsRequest, opts ...grpc.CallOption) (*ListUserSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListUserSessionsResponse)
+	err := c.cc.Invoke(ctx, UserService_ListUserSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) RevokeUserSession(ctx context.Context, in *RevokeUserSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, UserService_RevokeUserSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) RevokeAllUserSessions(ctx context.Context, in *RevokeAllUserSessionsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, UserService_RevokeAllUserSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *userServiceClient) ListShortcuts(ctx context.Context, in *ListShortcutsRequest, opts ...grpc.CallOption) (*ListShortcutsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListShortcutsResponse)
@@ -271,6 +333,66 @@ func (c *userServiceClient) DeleteShortcut(ctx context.Context, in *DeleteShortc
 	return out, nil
 }
 
+func (c *userServiceClient) EnrollTOTP(ctx context.Context, in *EnrollTOTPRequest, opts ...grpc.CallOption) (*EnrollTOTPResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnrollTOTPResponse)
+	err := c.cc.Invoke(ctx, UserService_EnrollTOTP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) VerifyTOTP(ctx context.Context, in *VerifyTOTPRequest, opts ...grpc.CallOption) (*RecoveryCodesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecoveryCodesResponse)
+	err := c.cc.Invoke(ctx, UserService_VerifyTOTP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) DisableTOTP(ctx context.Context, in *DisableTOTPRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, UserService_DisableTOTP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) RegenerateRecoveryCodes(ctx context.Context, in *RegenerateRecoveryCodesRequest, opts ...grpc.CallOption) (*RecoveryCodesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecoveryCodesResponse)
+	err := c.cc.Invoke(ctx, UserService_RegenerateRecoveryCodes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ImportUserData(ctx context.Context, in *ImportUserDataRequest, opts ...grpc.CallOption) (*ImportUserDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportUserDataResponse)
+	err := c.cc.Invoke(ctx, UserService_ImportUserData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ImportUserNotes(ctx context.Context, in *ImportUserNotesRequest, opts ...grpc.CallOption) (*ImportUserNotesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportUserNotesResponse)
+	err := c.cc.Invoke(ctx, UserService_ImportUserNotes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserServiceServer is the server API for UserService service.
 // All implementations must embed UnimplementedUserServiceServer
 // for forward compatibility.
@@ -303,6 +425,13 @@ type UserServiceServer interface {
 	CreateUserAccessToken(context.Context, *CreateUserAccessTokenRequest) (*UserAccessToken, error)
 	// DeleteUserAccessToken deletes an access token for a user.
 	DeleteUserAccessToken(context.Context, *DeleteUserAccessTokenRequest) (*emptypb.Empty, error)
+	// ListUserSessions returns the active sign-in sessions of a user.
+	ListUserSessions(context.Context, *ListUserSessionsRequest) (*ListUserSessionsResponse, error)
+	// RevokeUserSession revokes a sign-in session of a user, which signs the client of the session out.
+	RevokeUserSession(context.Context, *RevokeUserSessionRequest) (*emptypb.Empty, error)
+	// RevokeAllUserSessions signs a user out everywhere by revoking all the sign-in sessions of the user,
+	// and optionally the access tokens that the user created.
+	RevokeAllUserSessions(context.Context, *RevokeAllUserSessionsRequest) (*emptypb.Empty, error)
 	// ListShortcuts returns a list of shortcuts for a user.
 	ListShortcuts(context.Context, *ListShortcutsRequest) (*ListShortcutsResponse, error)
 	// CreateShortcut creates a new shortcut for a user.
@@ -311,6 +440,22 @@ type UserServiceServer interface {
 	UpdateShortcut(context.Context, *UpdateShortcutRequest) (*Shortcut, error)
 	// DeleteShortcut deletes a shortcut for a user.
 	DeleteShortcut(context.Context, *DeleteShortcutRequest) (*emptypb.Empty, error)
+	// EnrollTOTP generates a new TOTP secret for a user. Two-factor authentication is enabled
+	// once VerifyTOTP verifies a code of the secret.
+	EnrollTOTP(context.Context, *EnrollTOTPRequest) (*EnrollTOTPResponse, error)
+	// VerifyTOTP verifies a code of the enrolled TOTP secret, enables two-factor authentication
+	// and returns new recovery codes.
+	VerifyTOTP(context.Context, *VerifyTOTPRequest) (*RecoveryCodesResponse, error)
+	// DisableTOTP disables two-factor authentication for a user.
+	// Users who can manage users can disable it for users of a lower role, and the host for any user.
+	DisableTOTP(context.Context, *DisableTOTPRequest) (*emptypb.Empty, error)
+	// RegenerateRecoveryCodes replaces the recovery codes of a user.
+	RegenerateRecoveryCodes(context.Context, *RegenerateRecoveryCodesRequest) (*RecoveryCodesResponse, error)
+	// ImportUserData imports an archive exported from GET /api/v1/{name=users/*}:export for a user.
+	// Memos and resources that were imported before are skipped.
+	ImportUserData(context.Context, *ImportUserDataRequest) (*ImportUserDataResponse, error)
+	// ImportUserNotes imports the notes of another note app for a user.
+	ImportUserNotes(context.Context, *ImportUserNotesRequest) (*ImportUserNotesResponse, error)
 	mustEmbedUnimplementedUserServiceServer()
 }
 
@@ -363,6 +508,15 @@ func (UnimplementedUserServiceServer) CreateUserAccessToken(context.Context, *Cr
 func (UnimplementedUserServiceServer) DeleteUserAccessToken(context.Context, *DeleteUserAccessTokenRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserAccessToken not implemented")
 }
+func (UnimplementedUserServiceServer) ListUserSessions(context.Context, *ListUserSessionsRequest) (*ListUserSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserSessions not implemented")
+}
+func (UnimplementedUserServiceServer) RevokeUserSession(context.Context, *RevokeUserSessionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeUserSession not implemented")
+}
+func (UnimplementedUserServiceServer) RevokeAllUserSessions(context.Context, *RevokeAllUserSessionsRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAllUserSessions not implemented")
+}
 func (UnimplementedUserServiceServer) ListShortcuts(context.Context, *ListShortcutsRequest) (*ListShortcutsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListShortcuts not implemented")
 }
@@ -375,6 +529,24 @@ func (UnimplementedUserServiceServer) UpdateShortcut(context.Context, *UpdateSho
 func (UnimplementedUserServiceServer) DeleteShortcut(context.Context, *DeleteShortcutRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteShortcut not implemented")
 }
+func (UnimplementedUserServiceServer) EnrollTOTP(context.Context, *EnrollTOTPRequest) (*EnrollTOTPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollTOTP not implemented")
+}
+func (UnimplementedUserServiceServer) VerifyTOTP(context.Context, *VerifyTOTPRequest) (*RecoveryCodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyTOTP not implemented")
+}
+func (UnimplementedUserServiceServer) DisableTOTP(context.Context, *DisableTOTPRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableTOTP not implemented")
+}
+func (UnimplementedUserServiceServer) RegenerateRecoveryCodes(context.Context, *RegenerateRecoveryCodesRequest) (*RecoveryCodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegenerateRecoveryCodes not implemented")
+}
+func (UnimplementedUserServiceServer) ImportUserData(context.Context, *ImportUserDataRequest) (*ImportUserDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportUserData not implemented")
+}
+func (UnimplementedUserServiceServer) ImportUserNotes(context.Context, *ImportUserNotesRequest) (*ImportUserNotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportUserNotes not implemented")
+}
 func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
 func (UnimplementedUserServiceServer) testEmbeddedByValue()                     {}
 
@@ -648,6 +820,60 @@ func _UserService_DeleteUserAccessToken_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_ListUserSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ListUserSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_ListUserSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ListUserSessions(ctx, req.(*ListUserSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_RevokeUserSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeUserSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).RevokeUserSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_RevokeUserSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).RevokeUserSession(ctx, req.(*RevokeUserSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_RevokeAllUserSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeAllUserSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).RevokeAllUserSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_RevokeAllUserSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).RevokeAllUserSessions(ctx, req.(*RevokeAllUserSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _UserService_ListShortcuts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListShortcutsRequest)
 	if err := dec(in); err != nil {
@@ -720,6 +946,114 @@ func _UserService_DeleteShortcut_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_EnrollTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollTOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).EnrollTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_EnrollTOTP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).EnrollTOTP(ctx, req.(*EnrollTOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_VerifyTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyTOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).VerifyTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_VerifyTOTP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).VerifyTOTP(ctx, req.(*VerifyTOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_DisableTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableTOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).DisableTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_DisableTOTP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).DisableTOTP(ctx, req.(*DisableTOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_RegenerateRecoveryCodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegenerateRecoveryCodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).RegenerateRecoveryCodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_RegenerateRecoveryCodes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).RegenerateRecoveryCodes(ctx, req.(*RegenerateRecoveryCodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ImportUserData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportUserDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ImportUserData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_ImportUserData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ImportUserData(ctx, req.(*ImportUserDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ImportUserNotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportUserNotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ImportUserNotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_ImportUserNotes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ImportUserNotes(ctx, req.(*ImportUserNotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UserService_ServiceDesc is the grpc.ServiceDesc for UserService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -783,6 +1117,18 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteUserAccessToken",
 			Handler:    _UserService_DeleteUserAccessToken_Handler,
 		},
+		{
+			MethodName: "ListUserSessions",
+			Handler:    _UserService_ListUserSessions_Handler,
+		},
+		{
+			MethodName: "RevokeUserSession",
+			Handler:    _UserService_RevokeUserSession_Handler,
+		},
+		{
+			MethodName: "RevokeAllUserSessions",
+			Handler:    _UserService_RevokeAllUserSessions_Handler,
+		},
 		{
 			MethodName: "ListShortcuts",
 			Handler:    _UserService_ListShortcuts_Handler,
@@ -799,6 +1145,30 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteShortcut",
 			Handler:    _UserService_DeleteShortcut_Handler,
 		},
+		{
+			MethodName: "EnrollTOTP",
+			Handler:    _UserService_EnrollTOTP_Handler,
+		},
+		{
+			MethodName: "VerifyTOTP",
+			Handler:    _UserService_VerifyTOTP_Handler,
+		},
+		{
+			MethodName: "DisableTOTP",
+			Handler:    _UserService_DisableTOTP_Handler,
+		},
+		{
+			MethodName: "RegenerateRecoveryCodes",
+			Handler:    _UserService_RegenerateRecoveryCodes_Handler,
+		},
+		{
+			MethodName: "ImportUserData",
+			Handler:    _UserService_ImportUserData_Handler,
+		},
+		{
+			MethodName: "ImportUserNotes",
+			Handler:    _UserService_ImportUserNotes_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/v1/user_service.proto",
@@ -3,6 +3,7 @@ package profile
 import (
 	"fmt"
 	"log/slog"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -30,12 +31,50 @@ type Profile struct {
 	Version string
 	// InstanceURL is the url of your memos instance.
 	InstanceURL string
+	// TrustedProxies is the IPs and CIDRs of the reverse proxies in front of the server, whose
+	// X-Forwarded-For headers are trusted to tell the IPs of clients.
+	TrustedProxies []string
 }
 
 func (p *Profile) IsDev() bool {
 	return p.Mode != "prod"
 }
 
+// IsTrustedProxy returns whether the IP is a trusted proxy. The server itself is always trusted, since
+// the gateway calls the gRPC API through it.
+func (p *Profile) IsTrustedProxy(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() {
+		return true
+	}
+	if addr, err := netip.ParseAddr(p.Addr); err == nil && addr.Unmap() == ip {
+		return true
+	}
+	for _, proxy := range p.TrustedProxies {
+		if prefix, err := parseTrustedProxy(proxy); err == nil && prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxy parses a trusted proxy as a CIDR, or as a single IP.
+func parseTrustedProxy(proxy string) (netip.Prefix, error) {
+	if strings.Contains(proxy, "/") {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
 func checkDataDir(dataDir string) (string, error) {
 	// Convert to absolute path if relative path is supplied.
 	if !filepath.IsAbs(dataDir) {
@@ -80,6 +119,21 @@ func (p *Profile) Validate() error {
 		return err
 	}
 
+	// Trusted proxies from the environment come as a single comma-separated value.
+	trustedProxies := []string{}
+	for _, value := range p.TrustedProxies {
+		for _, proxy := range strings.Split(value, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy == "" {
+				continue
+			}
+			if _, err := parseTrustedProxy(proxy); err != nil {
+				return errors.Wrapf(err, "invalid trusted proxy %q", proxy)
+			}
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	p.TrustedProxies = trustedProxies
+
 	p.Data = dataDir
 	if p.Driver == "sqlite" && p.DSN == "" {
 		dbFile := fmt.Sprintf("memos_%s.db", p.Mode)
@@ -2,18 +2,24 @@ package v1
 
 import (
 	"context"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
 	"github.com/usememos/memos/internal/util"
 	storepb "github.com/usememos/memos/proto/gen/store"
+	"github.com/usememos/memos/server/profile"
 	"github.com/usememos/memos/store"
 )
 
@@ -27,17 +33,22 @@ const (
 	accessTokenContextKey
 )
 
+// accessTokenUsageInterval is the minimum interval between two records of the usage of an access token from the same IP.
+const accessTokenUsageInterval = time.Minute
+
 // GRPCAuthInterceptor is the auth interceptor for gRPC server.
 type GRPCAuthInterceptor struct {
-	Store  *store.Store
-	secret string
+	Store      *store.Store
+	secret     string
+	authorizer *Authorizer
 }
 
 // NewGRPCAuthInterceptor returns a new API auth interceptor.
 func NewGRPCAuthInterceptor(store *store.Store, secret string) *GRPCAuthInterceptor {
 	return &GRPCAuthInterceptor{
-		Store:  store,
-		secret: secret,
+		Store:      store,
+		secret:     secret,
+		authorizer: NewAuthorizer(store),
 	}
 }
 
@@ -52,13 +63,44 @@ func (in *GRPCAuthInterceptor) AuthenticationInterceptor(ctx context.Context, re
 		return nil, status.Errorf(codes.Unauthenticated, "failed to get access token: %v", err)
 	}
 
-	username, err := in.authenticate(ctx, accessToken)
+	username, userAccessToken, err := in.authenticate(ctx, accessToken)
 	if err != nil {
 		if isUnauthorizeAllowedMethod(serverInfo.FullMethod) {
 			return handler(ctx, request)
 		}
 		return nil, err
 	}
+	ctx, err = in.authorize(ctx, username, accessToken, userAccessToken, serverInfo.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, request)
+}
+
+// AuthenticateHTTPRequest authenticates a request that the gateway serves without calling the gRPC server,
+// with the checks of AuthenticationInterceptor for the method. It returns the context of the current user.
+func (in *GRPCAuthInterceptor) AuthenticateHTTPRequest(r *http.Request, fullMethod string) (context.Context, error) {
+	md := metadata.MD{}
+	for key, values := range r.Header {
+		md.Append(key, values...)
+	}
+	ctx := metadata.NewIncomingContext(r.Context(), md)
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+	}
+	accessToken, err := getTokenFromMetadata(md)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to get access token: %v", err)
+	}
+	username, userAccessToken, err := in.authenticate(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return in.authorize(ctx, username, accessToken, userAccessToken, fullMethod)
+}
+
+// authorize checks that the authenticated user can call the method, and returns the context of the user.
+func (in *GRPCAuthInterceptor) authorize(ctx context.Context, username, accessToken string, userAccessToken *storepb.AccessTokensUserSetting_AccessToken, fullMethod string) (context.Context, error) {
 	user, err := in.Store.GetUser(ctx, &store.FindUser{
 		Username: &username,
 	})
@@ -71,18 +113,30 @@ func (in *GRPCAuthInterceptor) AuthenticationInterceptor(ctx context.Context, re
 	if user.RowStatus == store.Archived {
 		return nil, errors.Errorf("user %q is archived", username)
 	}
-	if isOnlyForAdminAllowedMethod(serverInfo.FullMethod) && user.Role != store.RoleHost && user.Role != store.RoleAdmin {
-		return nil, errors.Errorf("user %q is not admin", username)
+	if !isMethodAllowedForScopes(fullMethod, userAccessToken.Scopes) {
+		return nil, status.Errorf(codes.PermissionDenied, "access token does not have the scope to call %s", fullMethod)
 	}
 
 	ctx = context.WithValue(ctx, usernameContextKey, username)
 	ctx = context.WithValue(ctx, accessTokenContextKey, accessToken)
-	return handler(ctx, request)
+	if !isTwoFactorEnrollmentAllowedMethod(fullMethod) {
+		if err := in.checkTwoFactorRequirement(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	if permission, ok := getMethodPermission(fullMethod); ok {
+		if err := in.authorizer.Authorize(ctx, permission, ""); err != nil {
+			return nil, err
+		}
+	}
+	in.recordAccessTokenUsage(ctx, user.ID, userAccessToken)
+	return ctx, nil
 }
 
-func (in *GRPCAuthInterceptor) authenticate(ctx context.Context, accessToken string) (string, error) {
+// authenticate returns the username of the access token and the access token in the user's settings.
+func (in *GRPCAuthInterceptor) authenticate(ctx context.Context, accessToken string) (string, *storepb.AccessTokensUserSetting_AccessToken, error) {
 	if accessToken == "" {
-		return "", status.Errorf(codes.Unauthenticated, "access token not found")
+		return "", nil, status.Errorf(codes.Unauthenticated, "access token not found")
 	}
 	claims := &ClaimsMessage{}
 	_, err := jwt.ParseWithClaims(accessToken, claims, func(t *jwt.Token) (any, error) {
@@ -97,36 +151,37 @@ func (in *GRPCAuthInterceptor) authenticate(ctx context.Context, accessToken str
 		return nil, status.Errorf(codes.Unauthenticated, "unexpected access token kid=%v", t.Header["kid"])
 	})
 	if err != nil {
-		return "", status.Errorf(codes.Unauthenticated, "Invalid or expired access token")
+		return "", nil, status.Errorf(codes.Unauthenticated, "Invalid or expired access token")
 	}
 
 	// We either have a valid access token or we will attempt to generate new access token.
 	userID, err := util.ConvertStringToInt32(claims.Subject)
 	if err != nil {
-		return "", errors.Wrap(err, "malformed ID in the token")
+		return "", nil, errors.Wrap(err, "malformed ID in the token")
 	}
 	user, err := in.Store.GetUser(ctx, &store.FindUser{
 		ID: &userID,
 	})
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get user")
+		return "", nil, errors.Wrap(err, "failed to get user")
 	}
 	if user == nil {
-		return "", errors.Errorf("user %q not exists", userID)
+		return "", nil, errors.Errorf("user %q not exists", userID)
 	}
 	if user.RowStatus == store.Archived {
-		return "", errors.Errorf("user %q is archived", userID)
+		return "", nil, errors.Errorf("user %q is archived", userID)
 	}
 
 	accessTokens, err := in.Store.GetUserAccessTokens(ctx, user.ID)
 	if err != nil {
-		return "", errors.Wrapf(err, "failed to get user access tokens")
+		return "", nil, errors.Wrapf(err, "failed to get user access tokens")
 	}
-	if !validateAccessToken(accessToken, accessTokens) {
-		return "", status.Errorf(codes.Unauthenticated, "invalid access token")
+	userAccessToken := findAccessToken(accessToken, accessTokens)
+	if userAccessToken == nil {
+		return "", nil, status.Errorf(codes.Unauthenticated, "invalid access token")
 	}
 
-	return user.Username, nil
+	return user.Username, userAccessToken, nil
 }
 
 func getTokenFromMetadata(md metadata.MD) (string, error) {
@@ -152,11 +207,93 @@ func getTokenFromMetadata(md metadata.MD) (string, error) {
 	return accessToken, nil
 }
 
-func validateAccessToken(accessTokenString string, userAccessTokens []*storepb.AccessTokensUserSetting_AccessToken) bool {
+// checkTwoFactorRequirement returns an error if the workspace requires admins to enable two-factor
+// authentication and the user is an admin who has not enabled it yet.
+func (in *GRPCAuthInterceptor) checkTwoFactorRequirement(ctx context.Context, user *store.User) error {
+	if user.Role != store.RoleHost && user.Role != store.RoleAdmin {
+		return nil
+	}
+	workspaceGeneralSetting, err := in.Store.GetWorkspaceGeneralSetting(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get workspace general setting: %v", err)
+	}
+	if !workspaceGeneralSetting.RequireTwoFactorForAdmins {
+		return nil
+	}
+	twoFactorAuth, err := in.Store.GetUserTwoFactorAuth(ctx, user.ID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get two-factor authentication: %v", err)
+	}
+	if twoFactorAuth == nil || !twoFactorAuth.Enabled {
+		return status.Errorf(codes.PermissionDenied, "two-factor authentication is required for admins")
+	}
+	return nil
+}
+
+func findAccessToken(accessTokenString string, userAccessTokens []*storepb.AccessTokensUserSetting_AccessToken) *storepb.AccessTokensUserSetting_AccessToken {
 	for _, userAccessToken := range userAccessTokens {
 		if accessTokenString == userAccessToken.AccessToken {
-			return true
+			return userAccessToken
+		}
+	}
+	return nil
+}
+
+// recordAccessTokenUsage records the last use of the access token. To avoid writing the user settings on
+// every request, the usage is only recorded once per accessTokenUsageInterval unless the client IP changes.
+func (in *GRPCAuthInterceptor) recordAccessTokenUsage(ctx context.Context, userID int32, userAccessToken *storepb.AccessTokensUserSetting_AccessToken) {
+	now := time.Now()
+	ip := getClientIP(ctx, in.Store.Profile)
+	if userAccessToken.LastUsedTime != nil && now.Sub(userAccessToken.LastUsedTime.AsTime()) < accessTokenUsageInterval && userAccessToken.LastUsedIp == ip {
+		return
+	}
+	if err := in.Store.UpdateUserAccessTokenUsage(ctx, userID, userAccessToken.AccessToken, now, ip); err != nil {
+		slog.Warn("failed to record access token usage", slog.Any("err", err))
+	}
+}
+
+// getClientIP returns the IP of the client. Starting from the peer, it follows the X-Forwarded-For
+// header from right to left as long as the hops are trusted proxies, since the entries on the left are
+// whatever the client sent. The gateway appends the address it received the request from.
+func getClientIP(ctx context.Context, profile *profile.Profile) string {
+	ip := ""
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	hops := []string{}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, forwardedFor := range md.Get("x-forwarded-for") {
+			for _, hop := range strings.Split(forwardedFor, ",") {
+				if hop = strings.TrimSpace(hop); hop != "" {
+					hops = append(hops, hop)
+				}
+			}
+		}
+	}
+	for len(hops) > 0 {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil || !profile.IsTrustedProxy(addr) {
+			break
+		}
+		ip, hops = hops[len(hops)-1], hops[:len(hops)-1]
+	}
+	return ip
+}
+
+// getUserAgent returns the user agent of the client, preferring the header of the browser that the
+// gateway forwards over the user agent of the gRPC client.
+func getUserAgent(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, key := range []string{"grpcgateway-user-agent", "user-agent"} {
+		if userAgent := md.Get(key); len(userAgent) > 0 && userAgent[0] != "" {
+			return userAgent[0]
 		}
 	}
-	return false
+	return ""
 }
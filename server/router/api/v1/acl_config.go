@@ -1,5 +1,12 @@
 package v1
 
+import (
+	"slices"
+	"strings"
+
+	"github.com/usememos/memos/store"
+)
+
 var authenticationAllowlistMethods = map[string]bool{
 	"/memos.api.v1.WorkspaceService/GetWorkspaceProfile":          true,
 	"/memos.api.v1.WorkspaceSettingService/GetWorkspaceSetting":   true,
@@ -9,6 +16,7 @@ var authenticationAllowlistMethods = map[string]bool{
 	"/memos.api.v1.AuthService/GetAuthStatus":                     true,
 	"/memos.api.v1.AuthService/SignIn":                            true,
 	"/memos.api.v1.AuthService/SignInWithSSO":                     true,
+	"/memos.api.v1.AuthService/CreateSSOAuthorization":            true,
 	"/memos.api.v1.AuthService/SignOut":                           true,
 	"/memos.api.v1.AuthService/SignUp":                            true,
 	"/memos.api.v1.UserService/GetUser":                           true,
@@ -18,6 +26,9 @@ var authenticationAllowlistMethods = map[string]bool{
 	"/memos.api.v1.UserService/SearchUsers":                       true,
 	"/memos.api.v1.MemoService/GetMemo":                           true,
 	"/memos.api.v1.MemoService/ListMemos":                         true,
+	"/memos.api.v1.MemoService/SearchMemos":                       true,
+	"/memos.api.v1.MemoService/ListTags":                          true,
+	"/memos.api.v1.MemoService/GetSharedMemo":                     true,
 	"/memos.api.v1.MarkdownService/GetLinkMetadata":               true,
 	"/memos.api.v1.ResourceService/GetResourceBinary":             true,
 }
@@ -27,12 +38,155 @@ func isUnauthorizeAllowedMethod(fullMethodName string) bool {
 	return authenticationAllowlistMethods[fullMethodName]
 }
 
-var allowedMethodsOnlyForAdmin = map[string]bool{
-	"/memos.api.v1.UserService/CreateUser":                      true,
-	"/memos.api.v1.WorkspaceSettingService/SetWorkspaceSetting": true,
+// twoFactorEnrollmentAllowedMethods are the methods that admins can call before they enable two-factor
+// authentication when the workspace requires it.
+var twoFactorEnrollmentAllowedMethods = map[string]bool{
+	"/memos.api.v1.WorkspaceService/GetWorkspaceProfile":        true,
+	"/memos.api.v1.WorkspaceSettingService/GetWorkspaceSetting": true,
+	"/memos.api.v1.AuthService/GetAuthStatus":                   true,
+	"/memos.api.v1.AuthService/SignOut":                         true,
+	"/memos.api.v1.UserService/GetUser":                         true,
+	"/memos.api.v1.UserService/GetUserSetting":                  true,
+	"/memos.api.v1.UserService/EnrollTOTP":                      true,
+	"/memos.api.v1.UserService/VerifyTOTP":                      true,
+}
+
+// isTwoFactorEnrollmentAllowedMethod returns whether admins who must enable two-factor authentication can call the method.
+func isTwoFactorEnrollmentAllowedMethod(fullMethodName string) bool {
+	return twoFactorEnrollmentAllowedMethods[fullMethodName]
+}
+
+// methodPermissions is the permission that the current user needs to call a method.
+var methodPermissions = map[string]store.Permission{
+	"/memos.api.v1.UserService/ListUsers":                          store.PermissionUsersManage,
+	"/memos.api.v1.UserService/CreateUser":                         store.PermissionUsersManage,
+	"/memos.api.v1.WorkspaceSettingService/SetWorkspaceSetting":    store.PermissionWorkspaceManage,
+	"/memos.api.v1.IdentityProviderService/CreateIdentityProvider": store.PermissionWorkspaceManage,
+	"/memos.api.v1.IdentityProviderService/UpdateIdentityProvider": store.PermissionWorkspaceManage,
+	"/memos.api.v1.IdentityProviderService/DeleteIdentityProvider": store.PermissionWorkspaceManage,
+	"/memos.api.v1.RoleService/ListRoles":                          store.PermissionRolesManage,
+	"/memos.api.v1.RoleService/GetRole":                            store.PermissionRolesManage,
+	"/memos.api.v1.RoleService/CreateRole":                         store.PermissionRolesManage,
+	"/memos.api.v1.RoleService/UpdateRole":                         store.PermissionRolesManage,
+	"/memos.api.v1.RoleService/DeleteRole":                         store.PermissionRolesManage,
+}
+
+// getMethodPermission returns the permission needed to call the method, and false if every user can call it.
+func getMethodPermission(fullMethodName string) (store.Permission, bool) {
+	permission, ok := methodPermissions[fullMethodName]
+	return permission, ok
 }
 
-// isOnlyForAdminAllowedMethod returns true if the method is allowed to be called only by admin.
-func isOnlyForAdminAllowedMethod(methodName string) bool {
-	return allowedMethodsOnlyForAdmin[methodName]
+// Access token scopes. An access token without scopes has full access to the account.
+const (
+	ScopeMemosRead      = "memos:read"
+	ScopeMemosWrite     = "memos:write"
+	ScopeResourcesRead  = "resources:read"
+	ScopeResourcesWrite = "resources:write"
+	// ScopeAdmin allows every method, like an access token without scopes.
+	ScopeAdmin = "admin"
+)
+
+var accessTokenScopes = []string{ScopeMemosRead, ScopeMemosWrite, ScopeResourcesRead, ScopeResourcesWrite, ScopeAdmin}
+
+// methodScopes is the scope an access token needs to call a method. An empty scope allows every access token.
+// The methods not listed here need the admin scope.
+var methodScopes = map[string]string{
+	"/memos.api.v1.AuthService/GetAuthStatus":              "",
+	"/memos.api.v1.MarkdownService/ParseMarkdown":          "",
+	"/memos.api.v1.MarkdownService/RestoreMarkdownNodes":   "",
+	"/memos.api.v1.MarkdownService/StringifyMarkdownNodes": "",
+	"/memos.api.v1.MarkdownService/GetLinkMetadata":        "",
+	"/memos.api.v1.MemoService/ListMemos":                  ScopeMemosRead,
+	"/memos.api.v1.MemoService/SearchMemos":                ScopeMemosRead,
+	"/memos.api.v1.MemoService/GetMemo":                    ScopeMemosRead,
+	"/memos.api.v1.MemoService/GetSharedMemo":              ScopeMemosRead,
+	"/memos.api.v1.MemoService/ListTags":                   ScopeMemosRead,
+	"/memos.api.v1.MemoService/ListMemoResources":          ScopeMemosRead,
+	"/memos.api.v1.MemoService/ListMemoRelations":          ScopeMemosRead,
+	"/memos.api.v1.MemoService/ListMemoComments":           ScopeMemosRead,
+	"/memos.api.v1.MemoService/ListMemoReactions":          ScopeMemosRead,
+	"/memos.api.v1.MemoService/ListMemoRevisions":          ScopeMemosRead,
+	"/memos.api.v1.MemoService/GetMemoRevision":            ScopeMemosRead,
+	"/memos.api.v1.MemoService/DiffMemoRevisions":          ScopeMemosRead,
+	"/memos.api.v1.MemoService/ListMemoShares":             ScopeMemosRead,
+	"/memos.api.v1.MemoService/ListMemoGrants":             ScopeMemosRead,
+	"/memos.api.v1.MemoService/CreateMemo":                 ScopeMemosWrite,
+	"/memos.api.v1.MemoService/UpdateMemo":                 ScopeMemosWrite,
+	"/memos.api.v1.MemoService/DeleteMemo":                 ScopeMemosWrite,
+	"/memos.api.v1.MemoService/SetMemoResources":           ScopeMemosWrite,
+	"/memos.api.v1.MemoService/SetMemoRelations":           ScopeMemosWrite,
+	"/memos.api.v1.MemoService/CreateMemoComment":          ScopeMemosWrite,
+	"/memos.api.v1.MemoService/UpsertMemoReaction":         ScopeMemosWrite,
+	"/memos.api.v1.MemoService/DeleteMemoReaction":         ScopeMemosWrite,
+	"/memos.api.v1.MemoService/RenameMemoTag":              ScopeMemosWrite,
+	"/memos.api.v1.MemoService/DeleteMemoTag":              ScopeMemosWrite,
+	"/memos.api.v1.MemoService/RestoreMemoRevision":        ScopeMemosWrite,
+	"/memos.api.v1.MemoService/CreateMemoShare":            ScopeMemosWrite,
+	"/memos.api.v1.MemoService/RevokeMemoShare":            ScopeMemosWrite,
+	"/memos.api.v1.MemoService/SetMemoGrants":              ScopeMemosWrite,
+	"/memos.api.v1.ResourceService/ListResources":          ScopeResourcesRead,
+	"/memos.api.v1.ResourceService/GetResource":            ScopeResourcesRead,
+	"/memos.api.v1.ResourceService/GetResourceBinary":      ScopeResourcesRead,
+	"/memos.api.v1.ResourceService/CreateResource":         ScopeResourcesWrite,
+	"/memos.api.v1.ResourceService/UpdateResource":         ScopeResourcesWrite,
+	"/memos.api.v1.ResourceService/DeleteResource":         ScopeResourcesWrite,
+}
+
+// isMethodAllowedForScopes returns whether an access token with the scopes can call the method.
+// A write scope also allows the methods that need the read scope of the same kind.
+func isMethodAllowedForScopes(fullMethodName string, scopes []string) bool {
+	if len(scopes) == 0 || slices.Contains(scopes, ScopeAdmin) {
+		return true
+	}
+	scope, ok := methodScopes[fullMethodName]
+	if !ok {
+		return false
+	}
+	if scope == "" || slices.Contains(scopes, scope) {
+		return true
+	}
+	kind, ok := strings.CutSuffix(scope, ":read")
+	return ok && slices.Contains(scopes, kind+":write")
+}
+
+// rateLimitClass is the rate limit of the workspace rate limit setting that a method counts against.
+type rateLimitClass int
+
+const (
+	// rateLimitClassAuth is limited per client IP by the auth limit.
+	rateLimitClassAuth rateLimitClass = iota + 1
+	// rateLimitClassWrite is limited per user, or per client IP for anonymous requests, by the write limit.
+	rateLimitClassWrite
+)
+
+// authRateLimitedMethods are the methods that anonymous clients call to authenticate, or to make the
+// server fetch a URL.
+var authRateLimitedMethods = map[string]bool{
+	"/memos.api.v1.AuthService/SignIn":                 true,
+	"/memos.api.v1.AuthService/SignInWithSSO":          true,
+	"/memos.api.v1.AuthService/CreateSSOAuthorization": true,
+	"/memos.api.v1.AuthService/SignUp":                 true,
+	"/memos.api.v1.MarkdownService/GetLinkMetadata":    true,
+}
+
+// readMethodPrefixes are the prefixes of the names of the methods that only read, which are not rate limited.
+var readMethodPrefixes = []string{"Get", "List", "Search", "Diff", "Parse", "Stringify", "Export"}
+
+// getMethodRateLimitClass returns the rate limit that the method counts against, and false if the method is not rate limited.
+func getMethodRateLimitClass(fullMethodName string) (rateLimitClass, bool) {
+	if authRateLimitedMethods[fullMethodName] {
+		return rateLimitClassAuth, true
+	}
+	name, ok := strings.CutPrefix(fullMethodName, "/memos.api.v1.")
+	if !ok {
+		return 0, false
+	}
+	_, name, _ = strings.Cut(name, "/")
+	for _, prefix := range readMethodPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return 0, false
+		}
+	}
+	return rateLimitClassWrite, true
 }
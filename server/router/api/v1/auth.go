@@ -22,6 +22,11 @@ const (
 	CookieExpDuration = AccessTokenDuration - 1*time.Minute
 	// AccessTokenCookieName is the cookie name of access token.
 	AccessTokenCookieName = "memos.access-token"
+
+	// SSOFlowTokenAudienceName is the audience name of the token that carries an SSO authorization.
+	SSOFlowTokenAudienceName = "user.sso-flow"
+	// SSOFlowTokenDuration is how long users have to sign in with the identity provider.
+	SSOFlowTokenDuration = 10 * time.Minute
 )
 
 type ClaimsMessage struct {
@@ -29,6 +34,16 @@ type ClaimsMessage struct {
 	jwt.RegisteredClaims
 }
 
+// SSOFlowClaims is the state of an SSO authorization, which the client passes back when signing in
+// so that the server needs not keep it.
+type SSOFlowClaims struct {
+	IdpID        int32  `json:"idp_id"`
+	RedirectURI  string `json:"redirect_uri"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	jwt.RegisteredClaims
+}
+
 // GenerateAccessToken generates an access token.
 func GenerateAccessToken(username string, userID int32, expirationTime time.Time, secret []byte) (string, error) {
 	return generateToken(username, userID, AccessTokenAudienceName, expirationTime, secret)
@@ -61,3 +76,36 @@ func generateToken(username string, userID int32, audience string, expirationTim
 
 	return tokenString, nil
 }
+
+// generateSSOFlowToken generates a jwt token that carries the SSO authorization.
+func generateSSOFlowToken(claims *SSOFlowClaims, expirationTime time.Time, secret []byte) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    Issuer,
+		Audience:  jwt.ClaimStrings{SSOFlowTokenAudienceName},
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(expirationTime),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = KeyID
+	return token.SignedString(secret)
+}
+
+// parseSSOFlowToken returns the SSO authorization of a token generated by generateSSOFlowToken.
+func parseSSOFlowToken(tokenString string, secret []byte) (*SSOFlowClaims, error) {
+	claims := &SSOFlowClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (any, error) {
+		if kid, ok := t.Header["kid"].(string); ok && kid == KeyID {
+			return secret, nil
+		}
+		return nil, fmt.Errorf("unexpected kid=%v", t.Header["kid"])
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}),
+		jwt.WithIssuer(Issuer),
+		jwt.WithAudience(SSOFlowTokenAudienceName),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
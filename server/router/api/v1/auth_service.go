@@ -15,10 +15,13 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/usememos/memos/internal/util"
 	"github.com/usememos/memos/plugin/idp"
 	"github.com/usememos/memos/plugin/idp/oauth2"
+	"github.com/usememos/memos/plugin/idp/oidc"
+	"github.com/usememos/memos/plugin/webhook"
 	v1pb "github.com/usememos/memos/proto/gen/api/v1"
 	storepb "github.com/usememos/memos/proto/gen/store"
 	"github.com/usememos/memos/store"
@@ -26,6 +29,7 @@ import (
 
 const (
 	unmatchedUsernameAndPasswordError = "unmatched username and password"
+	twoFactorCodeRequiredError        = "two-factor authentication code is required"
 )
 
 func (s *APIV1Service) GetAuthStatus(ctx context.Context, _ *v1pb.GetAuthStatusRequest) (*v1pb.User, error) {
@@ -44,31 +48,57 @@ func (s *APIV1Service) GetAuthStatus(ctx context.Context, _ *v1pb.GetAuthStatusR
 }
 
 func (s *APIV1Service) SignIn(ctx context.Context, request *v1pb.SignInRequest) (*v1pb.User, error) {
+	if err := s.rateLimiter.checkSignInLockout(ctx, request.Username); err != nil {
+		return nil, err
+	}
 	user, err := s.Store.GetUser(ctx, &store.FindUser{
 		Username: &request.Username,
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get user, error: %v", err)
 	}
-	if user == nil {
-		return nil, status.Errorf(codes.InvalidArgument, unmatchedUsernameAndPasswordError)
-	}
 	// Compare the stored hashed password, with the hashed version of the password that was received.
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password)); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, unmatchedUsernameAndPasswordError)
-	}
-
-	workspaceGeneralSetting, err := s.Store.GetWorkspaceGeneralSetting(ctx)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get workspace general setting, error: %v", err)
-	}
-	// Check if the password auth in is allowed.
-	if workspaceGeneralSetting.DisallowPasswordAuth && user.Role == store.RoleUser {
-		return nil, status.Errorf(codes.PermissionDenied, "password signin is not allowed")
+	if user != nil && bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password)) == nil {
+		workspaceGeneralSetting, err := s.Store.GetWorkspaceGeneralSetting(ctx)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get workspace general setting, error: %v", err)
+		}
+		// Check if the password auth in is allowed.
+		if workspaceGeneralSetting.DisallowPasswordAuth && user.Role == store.RoleUser {
+			return nil, status.Errorf(codes.PermissionDenied, "password signin is not allowed")
+		}
+	} else {
+		// The password may be the one of a directory that an LDAP identity provider delegates to.
+		user, err = s.signInWithLDAP(ctx, request.Username, request.Password)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			s.rateLimiter.recordSignInFailure(ctx, request.Username)
+			return nil, status.Errorf(codes.InvalidArgument, unmatchedUsernameAndPasswordError)
+		}
 	}
 	if user.RowStatus == store.Archived {
 		return nil, status.Errorf(codes.PermissionDenied, "user has been archived with username %s", request.Username)
 	}
+	twoFactorEnabled, err := s.isTwoFactorAuthEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get two-factor authentication, error: %v", err)
+	}
+	if twoFactorEnabled {
+		if request.TotpCode == "" {
+			return nil, status.Errorf(codes.FailedPrecondition, twoFactorCodeRequiredError)
+		}
+		ok, err := s.verifyTwoFactorCode(ctx, user.ID, request.TotpCode)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to verify two-factor authentication code, error: %v", err)
+		}
+		if !ok {
+			s.rateLimiter.recordSignInFailure(ctx, request.Username)
+			return nil, status.Errorf(codes.InvalidArgument, "invalid two-factor authentication code")
+		}
+	}
+	s.rateLimiter.resetSignInFailures(ctx, request.Username)
 
 	expireTime := time.Now().Add(AccessTokenDuration)
 	if request.NeverExpire {
@@ -106,47 +136,35 @@ func (s *APIV1Service) SignInWithSSO(ctx context.Context, request *v1pb.SignInWi
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to get user info, error: %v", err)
 		}
-	}
-
-	identifierFilter := identityProvider.IdentifierFilter
-	if identifierFilter != "" {
-		identifierFilterRegex, err := regexp.Compile(identifierFilter)
+	} else if identityProvider.Type == storepb.IdentityProvider_OIDC {
+		flow, err := parseSSOFlowToken(request.FlowToken, []byte(s.Secret))
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to compile identifier filter regex, error: %v", err)
+			return nil, status.Errorf(codes.InvalidArgument, "invalid flow token, error: %v", err)
 		}
-		if !identifierFilterRegex.MatchString(userInfo.Identifier) {
-			return nil, status.Errorf(codes.PermissionDenied, "identifier %s is not allowed", userInfo.Identifier)
+		if flow.IdpID != identityProvider.Id || flow.RedirectURI != request.RedirectUri {
+			return nil, status.Errorf(codes.InvalidArgument, "flow token does not match the request")
 		}
-	}
-
-	user, err := s.Store.GetUser(ctx, &store.FindUser{
-		Username: &userInfo.Identifier,
-	})
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get user, error: %v", err)
-	}
-	if user == nil {
-		userCreate := &store.User{
-			Username: userInfo.Identifier,
-			// The new signup user should be normal user by default.
-			Role:     store.RoleUser,
-			Nickname: userInfo.DisplayName,
-			Email:    userInfo.Email,
-		}
-		password, err := util.RandomString(20)
+		oidcIdentityProvider, err := oidc.NewIdentityProvider(ctx, identityProvider.Config.GetOidcConfig())
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to generate random password, error: %v", err)
+			return nil, status.Errorf(codes.Internal, "failed to create oidc identity provider, error: %v", err)
 		}
-		passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		token, err := oidcIdentityProvider.ExchangeToken(ctx, request.RedirectUri, request.Code, flow.CodeVerifier)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to generate password hash, error: %v", err)
+			return nil, status.Errorf(codes.Internal, "failed to exchange token, error: %v", err)
 		}
-		userCreate.PasswordHash = string(passwordHash)
-		user, err = s.Store.CreateUser(ctx, userCreate)
+		userInfo, err = oidcIdentityProvider.UserInfo(ctx, token, flow.Nonce)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to create user, error: %v", err)
+			return nil, status.Errorf(codes.PermissionDenied, "failed to get user info, error: %v", err)
 		}
 	}
+	if userInfo == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported identity provider type %s", identityProvider.Type)
+	}
+
+	user, err := s.provisionIdentityProviderUser(ctx, identityProvider, userInfo)
+	if err != nil {
+		return nil, err
+	}
 	if user.RowStatus == store.Archived {
 		return nil, status.Errorf(codes.PermissionDenied, "user has been archived with username %s", userInfo.Identifier)
 	}
@@ -157,12 +175,107 @@ func (s *APIV1Service) SignInWithSSO(ctx context.Context, request *v1pb.SignInWi
 	return convertUserFromStore(user), nil
 }
 
+// checkIdentifierFilter returns an error unless the identifier matches the identifier filter of the identity provider.
+func checkIdentifierFilter(identityProvider *storepb.IdentityProvider, identifier string) error {
+	if identityProvider.IdentifierFilter == "" {
+		return nil
+	}
+	identifierFilterRegex, err := regexp.Compile(identityProvider.IdentifierFilter)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to compile identifier filter regex, error: %v", err)
+	}
+	if !identifierFilterRegex.MatchString(identifier) {
+		return status.Errorf(codes.PermissionDenied, "identifier %s is not allowed", identifier)
+	}
+	return nil
+}
+
+// createIdentityProviderUser creates the user of an identity provider with a random password, since the
+// identity provider authenticates them.
+func (s *APIV1Service) createIdentityProviderUser(ctx context.Context, userInfo *idp.IdentityProviderUserInfo, role store.Role) (*store.User, error) {
+	userCreate := &store.User{
+		Username: userInfo.Identifier,
+		Role:     role,
+		Nickname: userInfo.DisplayName,
+		Email:    userInfo.Email,
+	}
+	password, err := util.RandomString(20)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate random password, error: %v", err)
+	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate password hash, error: %v", err)
+	}
+	userCreate.PasswordHash = string(passwordHash)
+	user, err := s.Store.CreateUser(ctx, userCreate)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create user, error: %v", err)
+	}
+	s.dispatchUserSignedUpWebhook(ctx, user)
+	return user, nil
+}
+
+func (s *APIV1Service) CreateSSOAuthorization(ctx context.Context, request *v1pb.CreateSSOAuthorizationRequest) (*v1pb.SSOAuthorization, error) {
+	identityProvider, err := s.Store.GetIdentityProvider(ctx, &store.FindIdentityProvider{
+		ID: &request.IdpId,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get identity provider, error: %v", err)
+	}
+	if identityProvider == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "identity provider not found")
+	}
+	if identityProvider.Type != storepb.IdentityProvider_OIDC {
+		return nil, status.Errorf(codes.InvalidArgument, "identity provider %s does not support authorization", identityProvider.Type)
+	}
+	if request.RedirectUri == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "redirect uri is required")
+	}
+
+	oidcIdentityProvider, err := oidc.NewIdentityProvider(ctx, identityProvider.Config.GetOidcConfig())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create oidc identity provider, error: %v", err)
+	}
+	nonce, err := util.RandomString(32)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate nonce, error: %v", err)
+	}
+	// The code verifier of PKCE is 43 to 128 characters, per RFC 7636.
+	codeVerifier, err := util.RandomString(64)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate code verifier, error: %v", err)
+	}
+	flowToken, err := generateSSOFlowToken(&SSOFlowClaims{
+		IdpID:        identityProvider.Id,
+		RedirectURI:  request.RedirectUri,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+	}, time.Now().Add(SSOFlowTokenDuration), []byte(s.Secret))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate flow token, error: %v", err)
+	}
+	return &v1pb.SSOAuthorization{
+		AuthorizationUrl: oidcIdentityProvider.AuthorizationURL(request.RedirectUri, request.State, nonce, codeVerifier),
+		FlowToken:        flowToken,
+	}, nil
+}
+
 func (s *APIV1Service) doSignIn(ctx context.Context, user *store.User, expireTime time.Time) error {
 	accessToken, err := GenerateAccessToken(user.Email, user.ID, expireTime, []byte(s.Secret))
 	if err != nil {
 		return status.Errorf(codes.Internal, "failed to generate access token, error: %v", err)
 	}
-	if err := s.UpsertAccessTokenToStore(ctx, user, accessToken, "user login"); err != nil {
+	if err := s.UpsertAccessTokenToStore(ctx, user, &storepb.AccessTokensUserSetting_AccessToken{
+		AccessToken: accessToken,
+		Description: "user login",
+		Session: &storepb.AccessTokensUserSetting_Session{
+			Id:         util.GenUUID(),
+			UserAgent:  getUserAgent(ctx),
+			Ip:         getClientIP(ctx, s.Profile),
+			CreateTime: timestamppb.Now(),
+		},
+	}); err != nil {
 		return status.Errorf(codes.Internal, "failed to upsert access token to store, error: %v", err)
 	}
 
@@ -220,6 +333,7 @@ func (s *APIV1Service) SignUp(ctx context.Context, request *v1pb.SignUpRequest)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create user, error: %v", err)
 	}
+	s.dispatchUserSignedUpWebhook(ctx, user)
 
 	if err := s.doSignIn(ctx, user, time.Now().Add(AccessTokenDuration)); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to sign in, error: %v", err)
@@ -227,6 +341,19 @@ func (s *APIV1Service) SignUp(ctx context.Context, request *v1pb.SignUpRequest)
 	return convertUserFromStore(user), nil
 }
 
+// dispatchUserSignedUpWebhook tries to dispatch webhook to admins when a user signs up.
+func (s *APIV1Service) dispatchUserSignedUpWebhook(ctx context.Context, user *store.User) {
+	userMessage := convertUserFromStore(user)
+	if err := s.dispatchAdminWebhookEvent(ctx, &v1pb.WebhookRequestPayload{
+		ActivityType: webhook.EventUserSignedUp,
+		Creator:      userMessage.Name,
+		CreateTime:   timestamppb.New(time.Now()),
+		User:         userMessage,
+	}); err != nil {
+		slog.Warn("Failed to dispatch user signed up webhook", slog.Any("err", err))
+	}
+}
+
 func (s *APIV1Service) SignOut(ctx context.Context, _ *v1pb.SignOutRequest) (*emptypb.Empty, error) {
 	accessToken, ok := ctx.Value(accessTokenContextKey).(string)
 	// Try to delete the access token from the store.
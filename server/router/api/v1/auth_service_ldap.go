@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/usememos/memos/plugin/idp/ldap"
+	storepb "github.com/usememos/memos/proto/gen/store"
+	"github.com/usememos/memos/store"
+)
+
+// signInWithLDAP returns the user that an LDAP identity provider authenticates with the username and
+// password, provisioned by the rules of the identity provider, or nil if no LDAP identity provider does.
+func (s *APIV1Service) signInWithLDAP(ctx context.Context, username, password string) (*store.User, error) {
+	identityProviders, err := s.Store.ListIdentityProviders(ctx, &store.FindIdentityProvider{})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list identity providers, error: %v", err)
+	}
+	for _, identityProvider := range identityProviders {
+		if identityProvider.Type != storepb.IdentityProvider_LDAP {
+			continue
+		}
+		ldapIdentityProvider, err := ldap.NewIdentityProvider(identityProvider.Config.GetLdapConfig())
+		if err != nil {
+			slog.Warn("invalid ldap identity provider", slog.String("name", identityProvider.Name), slog.Any("err", err))
+			continue
+		}
+		userInfo, err := ldapIdentityProvider.Authenticate(ctx, username, password)
+		if err != nil {
+			if !errors.Is(err, ldap.ErrInvalidCredentials) {
+				slog.Warn("failed to authenticate with ldap identity provider", slog.String("name", identityProvider.Name), slog.Any("err", err))
+			}
+			continue
+		}
+		return s.provisionIdentityProviderUser(ctx, identityProvider, userInfo)
+	}
+	return nil, nil
+}
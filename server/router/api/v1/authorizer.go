@@ -0,0 +1,127 @@
+package v1
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/usememos/memos/store"
+)
+
+// Authorizer decides whether the current user may act on a resource. The auth interceptor and
+// the API handlers share it, so every check follows the built-in and custom roles of the user.
+type Authorizer struct {
+	Store *store.Store
+}
+
+// NewAuthorizer returns a new authorizer.
+func NewAuthorizer(store *store.Store) *Authorizer {
+	return &Authorizer{
+		Store: store,
+	}
+}
+
+// Authorize returns an error unless the current user has the permission on the resource.
+//
+// The resource is the name of a resource, e.g. "users/1" or "memos/abc", or empty for the workspace.
+// Users can act on the resources they own without the permission, so it is only needed to act on
+// the resources of other users or on the workspace.
+func (a *Authorizer) Authorize(ctx context.Context, permission store.Permission, resource string) error {
+	authorized, err := a.IsAuthorized(ctx, permission, resource)
+	if err != nil {
+		return err
+	}
+	if !authorized {
+		return status.Errorf(codes.PermissionDenied, "permission denied: %s is required", permission)
+	}
+	return nil
+}
+
+// IsAuthorized returns whether the current user has the permission on the resource.
+func (a *Authorizer) IsAuthorized(ctx context.Context, permission store.Permission, resource string) (bool, error) {
+	username, ok := ctx.Value(usernameContextKey).(string)
+	if !ok {
+		return false, nil
+	}
+	user, err := a.Store.GetUser(ctx, &store.FindUser{Username: &username})
+	if err != nil {
+		return false, status.Errorf(codes.Internal, "failed to get current user: %v", err)
+	}
+	if user == nil {
+		return false, nil
+	}
+	if resource != "" {
+		ownerID, err := a.getResourceOwnerID(ctx, resource)
+		if err != nil {
+			return false, err
+		}
+		if ownerID == user.ID {
+			return true, nil
+		}
+	}
+	hasPermission, err := a.Store.HasPermission(ctx, user, permission)
+	if err != nil {
+		return false, status.Errorf(codes.Internal, "failed to get user permissions: %v", err)
+	}
+	return hasPermission, nil
+}
+
+// getResourceOwnerID returns the ID of the user that owns the resource.
+func (a *Authorizer) getResourceOwnerID(ctx context.Context, resource string) (int32, error) {
+	switch {
+	case strings.HasPrefix(resource, UserNamePrefix):
+		userID, err := ExtractUserIDFromName(resource)
+		if err != nil {
+			return 0, status.Errorf(codes.InvalidArgument, "invalid user name: %v", err)
+		}
+		return userID, nil
+	case strings.HasPrefix(resource, GroupNamePrefix):
+		groupID, err := ExtractGroupIDFromName(resource)
+		if err != nil {
+			return 0, status.Errorf(codes.InvalidArgument, "invalid group name: %v", err)
+		}
+		group, err := a.Store.GetUserGroup(ctx, &store.FindUserGroup{ID: &groupID})
+		if err != nil {
+			return 0, status.Errorf(codes.Internal, "failed to get group: %v", err)
+		}
+		if group == nil {
+			return 0, status.Errorf(codes.NotFound, "group not found")
+		}
+		return group.CreatorID, nil
+	case strings.HasPrefix(resource, MemoNamePrefix):
+		memoUID, err := ExtractMemoUIDFromName(resource)
+		if err != nil {
+			return 0, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
+		}
+		memo, err := a.Store.GetMemo(ctx, &store.FindMemo{UID: &memoUID})
+		if err != nil {
+			return 0, status.Errorf(codes.Internal, "failed to get memo: %v", err)
+		}
+		if memo == nil {
+			return 0, status.Errorf(codes.NotFound, "memo not found")
+		}
+		return memo.CreatorID, nil
+	case strings.HasPrefix(resource, ResourceNamePrefix):
+		resourceUID, err := ExtractResourceUIDFromName(resource)
+		if err != nil {
+			return 0, status.Errorf(codes.InvalidArgument, "invalid resource name: %v", err)
+		}
+		storeResource, err := a.Store.GetResource(ctx, &store.FindResource{UID: &resourceUID})
+		if err != nil {
+			return 0, status.Errorf(codes.Internal, "failed to get resource: %v", err)
+		}
+		if storeResource == nil {
+			return 0, status.Errorf(codes.NotFound, "resource not found")
+		}
+		return storeResource.CreatorID, nil
+	default:
+		return 0, status.Errorf(codes.InvalidArgument, "unsupported resource %q", resource)
+	}
+}
+
+// Authorize returns an error unless the current user has the permission on the resource.
+func (s *APIV1Service) Authorize(ctx context.Context, permission store.Permission, resource string) error {
+	return s.authorizer.Authorize(ctx, permission, resource)
+}
@@ -44,6 +44,26 @@ func getPageToken(limit int, offset int) (string, error) {
 	})
 }
 
+// getMemoPageToken returns the token of the page after the memo at the cursor.
+func getMemoPageToken(limit int, cursor *store.MemoCursor) (string, error) {
+	return marshalPageToken(&v1pb.PageToken{
+		Limit: int32(limit),
+		Cursor: &v1pb.PageToken_Cursor{
+			Pinned:    cursor.Pinned,
+			DisplayTs: cursor.DisplayTs,
+			Id:        cursor.ID,
+		},
+	})
+}
+
+func convertMemoCursorFromPageToken(cursor *v1pb.PageToken_Cursor) *store.MemoCursor {
+	return &store.MemoCursor{
+		Pinned:    cursor.Pinned,
+		DisplayTs: cursor.DisplayTs,
+		ID:        cursor.Id,
+	}
+}
+
 func marshalPageToken(pageToken *v1pb.PageToken) (string, error) {
 	b, err := proto.Marshal(pageToken)
 	if err != nil {
@@ -62,7 +82,3 @@ func unmarshalPageToken(s string, pageToken *v1pb.PageToken) error {
 	}
 	return nil
 }
-
-func isSuperUser(user *store.User) bool {
-	return user.Role == store.RoleAdmin || user.Role == store.RoleHost
-}
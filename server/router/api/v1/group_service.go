@@ -0,0 +1,232 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1pb "github.com/usememos/memos/proto/gen/api/v1"
+	"github.com/usememos/memos/store"
+)
+
+func (s *APIV1Service) ListGroups(ctx context.Context, _ *v1pb.ListGroupsRequest) (*v1pb.ListGroupsResponse, error) {
+	groups, err := s.Store.ListUserGroups(ctx, &store.FindUserGroup{})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list groups: %v", err)
+	}
+	response := &v1pb.ListGroupsResponse{
+		Groups: []*v1pb.Group{},
+	}
+	for _, group := range groups {
+		groupMessage, err := s.convertGroupFromStore(ctx, group)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to convert group: %v", err)
+		}
+		response.Groups = append(response.Groups, groupMessage)
+	}
+	return response, nil
+}
+
+func (s *APIV1Service) GetGroup(ctx context.Context, request *v1pb.GetGroupRequest) (*v1pb.Group, error) {
+	group, err := s.getGroupByName(ctx, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	groupMessage, err := s.convertGroupFromStore(ctx, group)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert group: %v", err)
+	}
+	return groupMessage, nil
+}
+
+func (s *APIV1Service) CreateGroup(ctx context.Context, request *v1pb.CreateGroupRequest) (*v1pb.Group, error) {
+	user, err := s.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get current user")
+	}
+	if user == nil {
+		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	}
+	if request.Group == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "group is required")
+	}
+	title, err := s.validateGroupTitle(ctx, request.Group.Title, 0)
+	if err != nil {
+		return nil, err
+	}
+	memberIDs, err := s.extractMemberIDs(ctx, request.Group.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := s.Store.CreateUserGroup(ctx, &store.UserGroup{
+		Name:      title,
+		CreatorID: user.ID,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create group: %v", err)
+	}
+	if err := s.setGroupMembers(ctx, group.ID, memberIDs); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to set group members: %v", err)
+	}
+	groupMessage, err := s.convertGroupFromStore(ctx, group)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert group: %v", err)
+	}
+	return groupMessage, nil
+}
+
+func (s *APIV1Service) UpdateGroup(ctx context.Context, request *v1pb.UpdateGroupRequest) (*v1pb.Group, error) {
+	if request.Group == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "group is required")
+	}
+	if request.UpdateMask == nil || len(request.UpdateMask.Paths) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "update mask is required")
+	}
+	group, err := s.getGroupByName(ctx, request.Group.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Authorize(ctx, store.PermissionGroupsManage, request.Group.Name); err != nil {
+		return nil, err
+	}
+
+	update := &store.UpdateUserGroup{
+		ID: group.ID,
+	}
+	var memberIDs []int32
+	for _, path := range request.UpdateMask.Paths {
+		switch path {
+		case "title":
+			title, err := s.validateGroupTitle(ctx, request.Group.Title, group.ID)
+			if err != nil {
+				return nil, err
+			}
+			update.Name = &title
+		case "members":
+			memberIDs, err = s.extractMemberIDs(ctx, request.Group.Members)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "invalid update path: %s", path)
+		}
+	}
+
+	group, err = s.Store.UpdateUserGroup(ctx, update)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update group: %v", err)
+	}
+	if memberIDs != nil {
+		if err := s.setGroupMembers(ctx, group.ID, memberIDs); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to set group members: %v", err)
+		}
+	}
+	groupMessage, err := s.convertGroupFromStore(ctx, group)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert group: %v", err)
+	}
+	return groupMessage, nil
+}
+
+func (s *APIV1Service) DeleteGroup(ctx context.Context, request *v1pb.DeleteGroupRequest) (*emptypb.Empty, error) {
+	group, err := s.getGroupByName(ctx, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Authorize(ctx, store.PermissionGroupsManage, request.Name); err != nil {
+		return nil, err
+	}
+	if err := s.Store.DeleteUserGroup(ctx, &store.DeleteUserGroup{ID: group.ID}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete group: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *APIV1Service) getGroupByName(ctx context.Context, name string) (*store.UserGroup, error) {
+	groupID, err := ExtractGroupIDFromName(name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid group name: %v", err)
+	}
+	group, err := s.Store.GetUserGroup(ctx, &store.FindUserGroup{ID: &groupID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get group: %v", err)
+	}
+	if group == nil {
+		return nil, status.Errorf(codes.NotFound, "group not found")
+	}
+	return group, nil
+}
+
+// validateGroupTitle returns the trimmed title, and an error if it is empty or taken by a group other than groupID.
+func (s *APIV1Service) validateGroupTitle(ctx context.Context, title string, groupID int32) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", status.Errorf(codes.InvalidArgument, "title is required")
+	}
+	group, err := s.Store.GetUserGroup(ctx, &store.FindUserGroup{Name: &title})
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "failed to get group: %v", err)
+	}
+	if group != nil && group.ID != groupID {
+		return "", status.Errorf(codes.AlreadyExists, "group %q already exists", title)
+	}
+	return title, nil
+}
+
+func (s *APIV1Service) extractMemberIDs(ctx context.Context, members []string) ([]int32, error) {
+	memberIDs := []int32{}
+	for _, member := range members {
+		userID, err := ExtractUserIDFromName(member)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid member name: %v", err)
+		}
+		user, err := s.Store.GetUser(ctx, &store.FindUser{ID: &userID})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+		}
+		if user == nil {
+			return nil, status.Errorf(codes.NotFound, "member %q not found", member)
+		}
+		memberIDs = append(memberIDs, user.ID)
+	}
+	return memberIDs, nil
+}
+
+// setGroupMembers replaces the members of the group.
+func (s *APIV1Service) setGroupMembers(ctx context.Context, groupID int32, memberIDs []int32) error {
+	if err := s.Store.DeleteUserGroupMembers(ctx, &store.DeleteUserGroupMember{GroupID: &groupID}); err != nil {
+		return err
+	}
+	for _, userID := range memberIDs {
+		if _, err := s.Store.UpsertUserGroupMember(ctx, &store.UserGroupMember{GroupID: groupID, UserID: userID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *APIV1Service) convertGroupFromStore(ctx context.Context, group *store.UserGroup) (*v1pb.Group, error) {
+	members, err := s.Store.ListUserGroupMembers(ctx, &store.FindUserGroupMember{GroupID: &group.ID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list group members")
+	}
+	groupMessage := &v1pb.Group{
+		Name:       fmt.Sprintf("%s%d", GroupNamePrefix, group.ID),
+		Title:      group.Name,
+		Creator:    fmt.Sprintf("%s%d", UserNamePrefix, group.CreatorID),
+		CreateTime: timestamppb.New(time.Unix(group.CreatedTs, 0)),
+		Members:    []string{},
+	}
+	for _, member := range members {
+		groupMessage.Members = append(groupMessage.Members, fmt.Sprintf("%s%d", UserNamePrefix, member.UserID))
+	}
+	return groupMessage, nil
+}
@@ -0,0 +1,204 @@
+package v1
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/usememos/memos/plugin/idp"
+	v1pb "github.com/usememos/memos/proto/gen/api/v1"
+	storepb "github.com/usememos/memos/proto/gen/store"
+	"github.com/usememos/memos/store"
+)
+
+// roleRanks orders the built-in roles, so that the highest role that groups grant wins.
+var roleRanks = map[store.Role]int{
+	store.RoleUser:  1,
+	store.RoleAdmin: 2,
+	store.RoleHost:  3,
+}
+
+// provisionIdentityProviderUser returns the user that a user of the identity provider signs in as,
+// following the identifier filter and the provisioning rules of the identity provider.
+func (s *APIV1Service) provisionIdentityProviderUser(ctx context.Context, identityProvider *storepb.IdentityProvider, userInfo *idp.IdentityProviderUserInfo) (*store.User, error) {
+	if err := checkIdentifierFilter(identityProvider, userInfo.Identifier); err != nil {
+		return nil, err
+	}
+	rules := identityProvider.GetProvisioning()
+	if len(rules.GetRequiredGroups()) > 0 && !slices.ContainsFunc(userInfo.Groups, func(group string) bool {
+		return containsGroup(rules.GetRequiredGroups(), group)
+	}) {
+		return nil, status.Errorf(codes.PermissionDenied, "identifier %s is not in any of the required groups", userInfo.Identifier)
+	}
+	role, hasRole := resolveGroupRole(userInfo.Groups, rules.GetGroupRoleMappings())
+
+	user, err := s.findIdentityProviderUser(ctx, rules, userInfo)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		if rules.GetDisableAutoCreation() {
+			return nil, status.Errorf(codes.PermissionDenied, "identifier %s has no account, and the identity provider does not create accounts", userInfo.Identifier)
+		}
+		// The new signup user should be normal user by default.
+		if !hasRole {
+			role = store.RoleUser
+		}
+		return s.createIdentityProviderUser(ctx, userInfo, role)
+	}
+	if !rules.GetSyncOnSignIn() {
+		return user, nil
+	}
+
+	update := &store.UpdateUser{
+		ID: user.ID,
+	}
+	changed := false
+	if userInfo.DisplayName != "" && userInfo.DisplayName != user.Nickname {
+		update.Nickname, changed = &userInfo.DisplayName, true
+	}
+	if userInfo.Email != "" && userInfo.Email != user.Email {
+		update.Email, changed = &userInfo.Email, true
+	}
+	// Hosts are never demoted, so that the workspace cannot lose its host to a change in the identity provider.
+	if hasRole && role != user.Role && user.Role != store.RoleHost {
+		update.Role, changed = &role, true
+	}
+	if !changed {
+		return user, nil
+	}
+	user, err = s.Store.UpdateUser(ctx, update)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update user, error: %v", err)
+	}
+	return user, nil
+}
+
+// findIdentityProviderUser returns the user whose username is the identifier, or whose email is the
+// email if the rules link users by email.
+func (s *APIV1Service) findIdentityProviderUser(ctx context.Context, rules *storepb.ProvisioningRules, userInfo *idp.IdentityProviderUserInfo) (*store.User, error) {
+	user, err := s.Store.GetUser(ctx, &store.FindUser{
+		Username: &userInfo.Identifier,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user, error: %v", err)
+	}
+	if user != nil || !rules.GetLinkByEmail() || userInfo.Email == "" {
+		return user, nil
+	}
+
+	users, err := s.Store.ListUsers(ctx, &store.FindUser{
+		Email: &userInfo.Email,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list users, error: %v", err)
+	}
+	if len(users) > 1 {
+		return nil, status.Errorf(codes.FailedPrecondition, "several accounts have the email %s", userInfo.Email)
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	// Otherwise anyone able to set the email of their identity provider account could take over the account.
+	if !userInfo.EmailVerified {
+		return nil, status.Errorf(codes.PermissionDenied, "the identity provider has not verified the email %s", userInfo.Email)
+	}
+	privileged, err := s.isPrivilegedUser(ctx, users[0])
+	if err != nil {
+		return nil, err
+	}
+	if privileged {
+		return nil, status.Errorf(codes.PermissionDenied, "the account with the email %s has permissions, and is not linked by email", userInfo.Email)
+	}
+	return users[0], nil
+}
+
+// isPrivilegedUser returns whether the user has any permission through their built-in role or custom roles.
+func (s *APIV1Service) isPrivilegedUser(ctx context.Context, user *store.User) (bool, error) {
+	if user.Role == store.RoleHost || user.Role == store.RoleAdmin {
+		return true, nil
+	}
+	permissions, err := s.Store.ListUserPermissions(ctx, user)
+	if err != nil {
+		return false, status.Errorf(codes.Internal, "failed to list user permissions, error: %v", err)
+	}
+	return len(permissions) > 0, nil
+}
+
+// resolveGroupRole returns the highest role that the groups grant through the mappings. Without any
+// mapping, it returns false; otherwise groups without a mapped role only grant USER.
+func resolveGroupRole(groups []string, mappings []*storepb.GroupRoleMapping) (store.Role, bool) {
+	if len(mappings) == 0 {
+		return "", false
+	}
+	role := store.RoleUser
+	for _, mapping := range mappings {
+		mappedRole := store.Role(mapping.Role)
+		if roleRanks[mappedRole] > roleRanks[role] && containsGroup(groups, mapping.Group) {
+			role = mappedRole
+		}
+	}
+	return role, true
+}
+
+// containsGroup returns whether the groups contain the group, ignoring case like directories do.
+func containsGroup(groups []string, group string) bool {
+	return slices.ContainsFunc(groups, func(g string) bool {
+		return strings.EqualFold(g, group)
+	})
+}
+
+// validateProvisioningRules returns an error if the rules map groups to unknown roles.
+func validateProvisioningRules(rules *v1pb.ProvisioningRules) error {
+	for _, mapping := range rules.GetGroupRoleMappings() {
+		if mapping.Group == "" {
+			return status.Errorf(codes.InvalidArgument, "group of group role mapping is required")
+		}
+		if _, ok := roleRanks[store.Role(mapping.Role)]; !ok {
+			return status.Errorf(codes.InvalidArgument, "invalid role %q of group %q", mapping.Role, mapping.Group)
+		}
+	}
+	for _, group := range rules.GetRequiredGroups() {
+		if group == "" {
+			return status.Errorf(codes.InvalidArgument, "required group cannot be empty")
+		}
+	}
+	return nil
+}
+
+func convertProvisioningRulesFromStore(rules *storepb.ProvisioningRules) *v1pb.ProvisioningRules {
+	groupRoleMappings := []*v1pb.GroupRoleMapping{}
+	for _, mapping := range rules.GetGroupRoleMappings() {
+		groupRoleMappings = append(groupRoleMappings, &v1pb.GroupRoleMapping{
+			Group: mapping.Group,
+			Role:  mapping.Role,
+		})
+	}
+	return &v1pb.ProvisioningRules{
+		GroupRoleMappings:   groupRoleMappings,
+		RequiredGroups:      rules.GetRequiredGroups(),
+		DisableAutoCreation: rules.GetDisableAutoCreation(),
+		SyncOnSignIn:        rules.GetSyncOnSignIn(),
+		LinkByEmail:         rules.GetLinkByEmail(),
+	}
+}
+
+func convertProvisioningRulesToStore(rules *v1pb.ProvisioningRules) *storepb.ProvisioningRules {
+	groupRoleMappings := []*storepb.GroupRoleMapping{}
+	for _, mapping := range rules.GetGroupRoleMappings() {
+		groupRoleMappings = append(groupRoleMappings, &storepb.GroupRoleMapping{
+			Group: mapping.Group,
+			Role:  mapping.Role,
+		})
+	}
+	return &storepb.ProvisioningRules{
+		GroupRoleMappings:   groupRoleMappings,
+		RequiredGroups:      rules.GetRequiredGroups(),
+		DisableAutoCreation: rules.GetDisableAutoCreation(),
+		SyncOnSignIn:        rules.GetSyncOnSignIn(),
+		LinkByEmail:         rules.GetLinkByEmail(),
+	}
+}
@@ -8,18 +8,22 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 
+	"github.com/usememos/memos/plugin/idp/ldap"
 	v1pb "github.com/usememos/memos/proto/gen/api/v1"
 	storepb "github.com/usememos/memos/proto/gen/store"
 	"github.com/usememos/memos/store"
 )
 
 func (s *APIV1Service) CreateIdentityProvider(ctx context.Context, request *v1pb.CreateIdentityProviderRequest) (*v1pb.IdentityProvider, error) {
-	currentUser, err := s.GetCurrentUser(ctx)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	if err := s.Authorize(ctx, store.PermissionWorkspaceManage, ""); err != nil {
+		return nil, err
+	}
+
+	if err := validateIdentityProviderConfig(request.IdentityProvider.Type, request.IdentityProvider.Config); err != nil {
+		return nil, err
 	}
-	if currentUser.Role != store.RoleHost {
-		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	if err := validateProvisioningRules(request.IdentityProvider.Provisioning); err != nil {
+		return nil, err
 	}
 
 	identityProvider, err := s.Store.CreateIdentityProvider(ctx, convertIdentityProviderToStore(request.IdentityProvider))
@@ -81,7 +85,26 @@ func (s *APIV1Service) UpdateIdentityProvider(ctx context.Context, request *v1pb
 		case "identifier_filter":
 			update.IdentifierFilter = &request.IdentityProvider.IdentifierFilter
 		case "config":
+			if err := validateIdentityProviderConfig(request.IdentityProvider.Type, request.IdentityProvider.Config); err != nil {
+				return nil, err
+			}
 			update.Config = convertIdentityProviderConfigToStore(request.IdentityProvider.Type, request.IdentityProvider.Config)
+			// Keep the bind password that responses leave out, unless the update sets another one.
+			if ldapConfig := update.Config.GetLdapConfig(); ldapConfig != nil && ldapConfig.BindPassword == "" {
+				identityProvider, err := s.Store.GetIdentityProvider(ctx, &store.FindIdentityProvider{ID: &id})
+				if err != nil {
+					return nil, status.Errorf(codes.Internal, "failed to get identity provider, error: %+v", err)
+				}
+				if identityProvider == nil {
+					return nil, status.Errorf(codes.NotFound, "identity provider not found")
+				}
+				ldapConfig.BindPassword = identityProvider.Config.GetLdapConfig().GetBindPassword()
+			}
+		case "provisioning":
+			if err := validateProvisioningRules(request.IdentityProvider.Provisioning); err != nil {
+				return nil, err
+			}
+			update.Provisioning = convertProvisioningRulesToStore(request.IdentityProvider.Provisioning)
 		}
 	}
 
@@ -109,6 +132,7 @@ func convertIdentityProviderFromStore(identityProvider *storepb.IdentityProvider
 		Title:            identityProvider.Name,
 		IdentifierFilter: identityProvider.IdentifierFilter,
 		Type:             v1pb.IdentityProvider_Type(v1pb.IdentityProvider_Type_value[identityProvider.Type.String()]),
+		Provisioning:     convertProvisioningRulesFromStore(identityProvider.Provisioning),
 	}
 	if identityProvider.Type == storepb.IdentityProvider_OAUTH2 {
 		oauth2Config := identityProvider.Config.GetOauth2Config()
@@ -125,7 +149,47 @@ func convertIdentityProviderFromStore(identityProvider *storepb.IdentityProvider
 						Identifier:  oauth2Config.FieldMapping.Identifier,
 						DisplayName: oauth2Config.FieldMapping.DisplayName,
 						Email:       oauth2Config.FieldMapping.Email,
+						Groups:      oauth2Config.FieldMapping.Groups,
+					},
+				},
+			},
+		}
+	} else if identityProvider.Type == storepb.IdentityProvider_OIDC {
+		oidcConfig := identityProvider.Config.GetOidcConfig()
+		temp.Config = &v1pb.IdentityProviderConfig{
+			Config: &v1pb.IdentityProviderConfig_OidcConfig{
+				OidcConfig: &v1pb.OIDCConfig{
+					IssuerUrl:    oidcConfig.IssuerUrl,
+					ClientId:     oidcConfig.ClientId,
+					ClientSecret: oidcConfig.ClientSecret,
+					Scopes:       oidcConfig.Scopes,
+					FieldMapping: &v1pb.FieldMapping{
+						Identifier:  oidcConfig.GetFieldMapping().GetIdentifier(),
+						DisplayName: oidcConfig.GetFieldMapping().GetDisplayName(),
+						Email:       oidcConfig.GetFieldMapping().GetEmail(),
+					},
+					GroupsClaim: oidcConfig.GroupsClaim,
+				},
+			},
+		}
+	} else if identityProvider.Type == storepb.IdentityProvider_LDAP {
+		ldapConfig := identityProvider.Config.GetLdapConfig()
+		temp.Config = &v1pb.IdentityProviderConfig{
+			Config: &v1pb.IdentityProviderConfig_LdapConfig{
+				LdapConfig: &v1pb.LDAPConfig{
+					Url:                ldapConfig.Url,
+					StartTls:           ldapConfig.StartTls,
+					InsecureSkipVerify: ldapConfig.InsecureSkipVerify,
+					BindDn:             ldapConfig.BindDn,
+					// The bind password is write-only, since it gives access to the directory.
+					BaseDn:     ldapConfig.BaseDn,
+					UserFilter: ldapConfig.UserFilter,
+					FieldMapping: &v1pb.FieldMapping{
+						Identifier:  ldapConfig.GetFieldMapping().GetIdentifier(),
+						DisplayName: ldapConfig.GetFieldMapping().GetDisplayName(),
+						Email:       ldapConfig.GetFieldMapping().GetEmail(),
 					},
+					GroupAttribute: ldapConfig.GroupAttribute,
 				},
 			},
 		}
@@ -142,6 +206,7 @@ func convertIdentityProviderToStore(identityProvider *v1pb.IdentityProvider) *st
 		IdentifierFilter: identityProvider.IdentifierFilter,
 		Type:             storepb.IdentityProvider_Type(storepb.IdentityProvider_Type_value[identityProvider.Type.String()]),
 		Config:           convertIdentityProviderConfigToStore(identityProvider.Type, identityProvider.Config),
+		Provisioning:     convertProvisioningRulesToStore(identityProvider.Provisioning),
 	}
 	return temp
 }
@@ -162,10 +227,62 @@ func convertIdentityProviderConfigToStore(identityProviderType v1pb.IdentityProv
 						Identifier:  oauth2Config.FieldMapping.Identifier,
 						DisplayName: oauth2Config.FieldMapping.DisplayName,
 						Email:       oauth2Config.FieldMapping.Email,
+						Groups:      oauth2Config.FieldMapping.Groups,
+					},
+				},
+			},
+		}
+	} else if identityProviderType == v1pb.IdentityProvider_OIDC {
+		oidcConfig := config.GetOidcConfig()
+		return &storepb.IdentityProviderConfig{
+			Config: &storepb.IdentityProviderConfig_OidcConfig{
+				OidcConfig: &storepb.OIDCConfig{
+					IssuerUrl:    oidcConfig.GetIssuerUrl(),
+					ClientId:     oidcConfig.GetClientId(),
+					ClientSecret: oidcConfig.GetClientSecret(),
+					Scopes:       oidcConfig.GetScopes(),
+					FieldMapping: &storepb.FieldMapping{
+						Identifier:  oidcConfig.GetFieldMapping().GetIdentifier(),
+						DisplayName: oidcConfig.GetFieldMapping().GetDisplayName(),
+						Email:       oidcConfig.GetFieldMapping().GetEmail(),
+					},
+					GroupsClaim: oidcConfig.GetGroupsClaim(),
+				},
+			},
+		}
+	} else if identityProviderType == v1pb.IdentityProvider_LDAP {
+		ldapConfig := config.GetLdapConfig()
+		return &storepb.IdentityProviderConfig{
+			Config: &storepb.IdentityProviderConfig_LdapConfig{
+				LdapConfig: &storepb.LDAPConfig{
+					Url:                ldapConfig.GetUrl(),
+					StartTls:           ldapConfig.GetStartTls(),
+					InsecureSkipVerify: ldapConfig.GetInsecureSkipVerify(),
+					BindDn:             ldapConfig.GetBindDn(),
+					BindPassword:       ldapConfig.GetBindPassword(),
+					BaseDn:             ldapConfig.GetBaseDn(),
+					UserFilter:         ldapConfig.GetUserFilter(),
+					FieldMapping: &storepb.FieldMapping{
+						Identifier:  ldapConfig.GetFieldMapping().GetIdentifier(),
+						DisplayName: ldapConfig.GetFieldMapping().GetDisplayName(),
+						Email:       ldapConfig.GetFieldMapping().GetEmail(),
 					},
+					GroupAttribute: ldapConfig.GetGroupAttribute(),
 				},
 			},
 		}
 	}
 	return nil
 }
+
+// validateIdentityProviderConfig returns an error if the config cannot be used to sign in.
+func validateIdentityProviderConfig(identityProviderType v1pb.IdentityProvider_Type, config *v1pb.IdentityProviderConfig) error {
+	if identityProviderType != v1pb.IdentityProvider_LDAP {
+		return nil
+	}
+	ldapConfig := convertIdentityProviderConfigToStore(identityProviderType, config).GetLdapConfig()
+	if _, err := ldap.NewIdentityProvider(ldapConfig); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid ldap config: %v", err)
+	}
+	return nil
+}
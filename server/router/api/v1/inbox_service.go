@@ -20,6 +20,9 @@ func (s *APIV1Service) ListInboxes(ctx context.Context, request *v1pb.ListInboxe
 		return nil, status.Errorf(codes.Internal, "failed to get user")
 	}
 
+	inboxFind := &store.FindInbox{
+		ReceiverID: &user.ID,
+	}
 	var limit, offset int
 	if request.PageToken != "" {
 		var pageToken v1pb.PageToken
@@ -28,6 +31,12 @@ func (s *APIV1Service) ListInboxes(ctx context.Context, request *v1pb.ListInboxe
 		}
 		limit = int(pageToken.Limit)
 		offset = int(pageToken.Offset)
+		if pageToken.Cursor != nil {
+			inboxFind.Cursor = &store.InboxCursor{
+				CreatedTs: pageToken.Cursor.DisplayTs,
+				ID:        pageToken.Cursor.Id,
+			}
+		}
 	} else {
 		limit = int(request.PageSize)
 	}
@@ -35,12 +44,13 @@ func (s *APIV1Service) ListInboxes(ctx context.Context, request *v1pb.ListInboxe
 		limit = DefaultPageSize
 	}
 	limitPlusOne := limit + 1
+	inboxFind.Limit = &limitPlusOne
+	if inboxFind.Cursor == nil {
+		// Page tokens issued before keyset pagination carry an offset.
+		inboxFind.Offset = &offset
+	}
 
-	inboxes, err := s.Store.ListInboxes(ctx, &store.FindInbox{
-		ReceiverID: &user.ID,
-		Limit:      &limitPlusOne,
-		Offset:     &offset,
-	})
+	inboxes, err := s.Store.ListInboxes(ctx, inboxFind)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to list inbox: %v", err)
 	}
@@ -49,7 +59,14 @@ func (s *APIV1Service) ListInboxes(ctx context.Context, request *v1pb.ListInboxe
 	nextPageToken := ""
 	if len(inboxes) == limitPlusOne {
 		inboxes = inboxes[:limit]
-		nextPageToken, err = getPageToken(limit, offset+limit)
+		last := inboxes[limit-1]
+		nextPageToken, err = marshalPageToken(&v1pb.PageToken{
+			Limit: int32(limit),
+			Cursor: &v1pb.PageToken_Cursor{
+				DisplayTs: last.CreatedTs,
+				Id:        last.ID,
+			},
+		})
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to get next page token, error: %v", err)
 		}
@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/usememos/memos/store"
+)
+
+// memoAccess is the access to a memo that a request needs.
+type memoAccess int
+
+const (
+	// memoAccessRead is needed to view the memo with its comments, resources, relations and reactions,
+	// and to comment on and react to it.
+	memoAccessRead memoAccess = iota
+	// memoAccessWrite is needed to edit the content, location, resources and relations of the memo.
+	memoAccessWrite
+	// memoAccessOwner is needed for everything else, e.g. to archive, pin or delete the memo, or to
+	// revoke its shares.
+	memoAccessOwner
+	// memoAccessReveal is needed to reveal the memo beyond its readers: to view its revisions, share it
+	// or change its visibility and grants.
+	memoAccessReveal
+)
+
+// checkMemoAccess returns an error unless the user, nil when not signed in, has the access to the memo.
+//
+// The creator has every access. Other users read memos the same way, including those with the memos.manage
+// permission: public memos can be read by anyone, protected memos by signed-in users, and private memos only
+// through a memo grant to the user or to one of the user's groups. Users with the memos.manage permission
+// have every access to the memos they can read, so that moderating a memo does not expose it, since the
+// updated memo is returned. A write grant also gives write access. Scheduled memos are hidden from everyone
+// but the creator until published.
+func (s *APIV1Service) checkMemoAccess(ctx context.Context, user *store.User, memo *store.Memo, access memoAccess) error {
+	if user != nil && memo.CreatorID == user.ID {
+		return nil
+	}
+	if user != nil && access != memoAccessRead {
+		canManageMemos, err := s.authorizer.IsAuthorized(ctx, store.PermissionMemosManage, "")
+		if err != nil {
+			return err
+		}
+		if canManageMemos {
+			return s.checkMemoAccess(ctx, user, memo, memoAccessRead)
+		}
+	}
+	if memo.ScheduledTs != 0 {
+		return status.Errorf(codes.NotFound, "memo not found")
+	}
+	if access == memoAccessRead {
+		if memo.Visibility == store.Public || (user != nil && memo.Visibility == store.Protected) {
+			return nil
+		}
+	}
+	if user == nil || access >= memoAccessOwner {
+		return status.Errorf(codes.PermissionDenied, "permission denied")
+	}
+	permission, err := s.Store.GetMemoPermission(ctx, memo.ID, user.ID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get memo permission: %v", err)
+	}
+	if permission == store.MemoPermissionWrite || (permission == store.MemoPermissionRead && access == memoAccessRead) {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "permission denied")
+}
+
+// getMemoWithAccess returns the memo if the current user has the access to it.
+func (s *APIV1Service) getMemoWithAccess(ctx context.Context, memoUID string, access memoAccess) (*store.Memo, error) {
+	memo, err := s.Store.GetMemo(ctx, &store.FindMemo{UID: &memoUID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get memo: %v", err)
+	}
+	if memo == nil {
+		return nil, status.Errorf(codes.NotFound, "memo not found")
+	}
+	user, err := s.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get current user")
+	}
+	if err := s.checkMemoAccess(ctx, user, memo, access); err != nil {
+		return nil, err
+	}
+	return memo, nil
+}
+
+// checkResourceAccess returns an error unless the current user has the access to the memo of the resource,
+// or is the creator of a resource that is not attached to a memo.
+func (s *APIV1Service) checkResourceAccess(ctx context.Context, resource *store.Resource, access memoAccess) error {
+	if resource.MemoID == nil {
+		return s.Authorize(ctx, store.PermissionMemosManage, fmt.Sprintf("%s%d", UserNamePrefix, resource.CreatorID))
+	}
+	user, err := s.GetCurrentUser(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get current user")
+	}
+	memo, err := s.Store.GetMemo(ctx, &store.FindMemo{ID: resource.MemoID})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get memo: %v", err)
+	}
+	if memo == nil {
+		return status.Errorf(codes.NotFound, "memo not found")
+	}
+	return s.checkMemoAccess(ctx, user, memo, access)
+}
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	v1pb "github.com/usememos/memos/proto/gen/api/v1"
+	"github.com/usememos/memos/store"
+)
+
+func (s *APIV1Service) SetMemoGrants(ctx context.Context, request *v1pb.SetMemoGrantsRequest) (*emptypb.Empty, error) {
+	memoUID, err := ExtractMemoUIDFromName(request.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
+	}
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessReveal)
+	if err != nil {
+		return nil, err
+	}
+
+	grants := []*store.MemoGrant{}
+	for _, grant := range request.Grants {
+		create, err := s.convertMemoGrantToStore(ctx, memo, grant)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, create)
+	}
+	if err := s.Store.DeleteMemoGrants(ctx, &store.DeleteMemoGrant{MemoID: &memo.ID}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete memo grants: %v", err)
+	}
+	for _, grant := range grants {
+		if _, err := s.Store.UpsertMemoGrant(ctx, grant); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to upsert memo grant: %v", err)
+		}
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *APIV1Service) ListMemoGrants(ctx context.Context, request *v1pb.ListMemoGrantsRequest) (*v1pb.ListMemoGrantsResponse, error) {
+	memoUID, err := ExtractMemoUIDFromName(request.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
+	}
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := s.Store.ListMemoGrants(ctx, &store.FindMemoGrant{MemoID: &memo.ID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list memo grants: %v", err)
+	}
+	response := &v1pb.ListMemoGrantsResponse{
+		Grants: []*v1pb.MemoGrant{},
+	}
+	for _, grant := range grants {
+		response.Grants = append(response.Grants, convertMemoGrantFromStore(grant))
+	}
+	return response, nil
+}
+
+func (s *APIV1Service) convertMemoGrantToStore(ctx context.Context, memo *store.Memo, grant *v1pb.MemoGrant) (*store.MemoGrant, error) {
+	create := &store.MemoGrant{
+		MemoID: memo.ID,
+	}
+	switch grant.Permission {
+	case v1pb.MemoGrant_READ:
+		create.Permission = store.MemoPermissionRead
+	case v1pb.MemoGrant_WRITE:
+		create.Permission = store.MemoPermissionWrite
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid permission of %q", grant.Principal)
+	}
+
+	if strings.HasPrefix(grant.Principal, GroupNamePrefix) {
+		groupID, err := ExtractGroupIDFromName(grant.Principal)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid group name: %v", err)
+		}
+		group, err := s.Store.GetUserGroup(ctx, &store.FindUserGroup{ID: &groupID})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get group: %v", err)
+		}
+		if group == nil {
+			return nil, status.Errorf(codes.NotFound, "group %q not found", grant.Principal)
+		}
+		create.PrincipalType, create.PrincipalID = store.MemoGrantPrincipalGroup, group.ID
+		return create, nil
+	}
+
+	userID, err := ExtractUserIDFromName(grant.Principal)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid principal: %v", err)
+	}
+	user, err := s.Store.GetUser(ctx, &store.FindUser{ID: &userID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+	if user == nil {
+		return nil, status.Errorf(codes.NotFound, "user %q not found", grant.Principal)
+	}
+	create.PrincipalType, create.PrincipalID = store.MemoGrantPrincipalUser, user.ID
+	return create, nil
+}
+
+func convertMemoGrantFromStore(grant *store.MemoGrant) *v1pb.MemoGrant {
+	message := &v1pb.MemoGrant{
+		Principal:  fmt.Sprintf("%s%d", UserNamePrefix, grant.PrincipalID),
+		Permission: v1pb.MemoGrant_READ,
+	}
+	if grant.PrincipalType == store.MemoGrantPrincipalGroup {
+		message.Principal = fmt.Sprintf("%s%d", GroupNamePrefix, grant.PrincipalID)
+	}
+	if grant.Permission == store.MemoPermissionWrite {
+		message.Permission = v1pb.MemoGrant_WRITE
+	}
+	return message
+}
@@ -18,9 +18,9 @@ func (s *APIV1Service) SetMemoRelations(ctx context.Context, request *v1pb.SetMe
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
 	}
-	memo, err := s.Store.GetMemo(ctx, &store.FindMemo{UID: &memoUID})
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessWrite)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get memo")
+		return nil, err
 	}
 	referenceType := store.MemoRelationReference
 	// Delete all reference relations first.
@@ -45,9 +45,10 @@ func (s *APIV1Service) SetMemoRelations(ctx context.Context, request *v1pb.SetMe
 		if err != nil {
 			return nil, status.Errorf(codes.InvalidArgument, "invalid related memo name: %v", err)
 		}
-		relatedMemo, err := s.Store.GetMemo(ctx, &store.FindMemo{UID: &relatedMemoUID})
+		// A relation shows a snippet of the related memo, so it must be readable.
+		relatedMemo, err := s.getMemoWithAccess(ctx, relatedMemoUID, memoAccessRead)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to get related memo")
+			return nil, err
 		}
 		if _, err := s.Store.UpsertMemoRelation(ctx, &store.MemoRelation{
 			MemoID:        memo.ID,
@@ -66,10 +67,21 @@ func (s *APIV1Service) ListMemoRelations(ctx context.Context, request *v1pb.List
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
 	}
-	memo, err := s.Store.GetMemo(ctx, &store.FindMemo{UID: &memoUID})
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessRead)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get memo")
+		return nil, err
 	}
+	relations, err := s.listMemoRelations(ctx, memo)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list memo relations: %v", err)
+	}
+	return &v1pb.ListMemoRelationsResponse{
+		Relations: relations,
+	}, nil
+}
+
+// listMemoRelations lists the relations from and to the memo without checking the access to it.
+func (s *APIV1Service) listMemoRelations(ctx context.Context, memo *store.Memo) ([]*v1pb.MemoRelation, error) {
 	relationList := []*v1pb.MemoRelation{}
 	tempList, err := s.Store.ListMemoRelations(ctx, &store.FindMemoRelation{
 		MemoID: &memo.ID,
@@ -80,7 +92,7 @@ func (s *APIV1Service) ListMemoRelations(ctx context.Context, request *v1pb.List
 	for _, raw := range tempList {
 		relation, err := s.convertMemoRelationFromStore(ctx, raw)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to convert memo relation")
+			return nil, errors.Wrap(err, "failed to convert memo relation")
 		}
 		relationList = append(relationList, relation)
 	}
@@ -93,15 +105,11 @@ func (s *APIV1Service) ListMemoRelations(ctx context.Context, request *v1pb.List
 	for _, raw := range tempList {
 		relation, err := s.convertMemoRelationFromStore(ctx, raw)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to convert memo relation")
+			return nil, errors.Wrap(err, "failed to convert memo relation")
 		}
 		relationList = append(relationList, relation)
 	}
-
-	response := &v1pb.ListMemoRelationsResponse{
-		Relations: relationList,
-	}
-	return response, nil
+	return relationList, nil
 }
 
 func (s *APIV1Service) convertMemoRelationFromStore(ctx context.Context, memoRelation *store.MemoRelation) (*v1pb.MemoRelation, error) {
@@ -18,9 +18,9 @@ func (s *APIV1Service) SetMemoResources(ctx context.Context, request *v1pb.SetMe
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
 	}
-	memo, err := s.Store.GetMemo(ctx, &store.FindMemo{UID: &memoUID})
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessWrite)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get memo")
+		return nil, err
 	}
 	resources, err := s.Store.ListResources(ctx, &store.FindResource{
 		MemoID: &memo.ID,
@@ -81,22 +81,31 @@ func (s *APIV1Service) ListMemoResources(ctx context.Context, request *v1pb.List
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
 	}
-	memo, err := s.Store.GetMemo(ctx, &store.FindMemo{UID: &memoUID})
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessRead)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get memo: %v", err)
+		return nil, err
 	}
+	resources, err := s.listMemoResources(ctx, memo)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list resources: %v", err)
+	}
+	return &v1pb.ListMemoResourcesResponse{
+		Resources: resources,
+	}, nil
+}
+
+// listMemoResources lists the resources of the memo without checking the access to it.
+func (s *APIV1Service) listMemoResources(ctx context.Context, memo *store.Memo) ([]*v1pb.Resource, error) {
 	resources, err := s.Store.ListResources(ctx, &store.FindResource{
 		MemoID: &memo.ID,
 	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to list resources: %v", err)
+		return nil, err
 	}
 
-	response := &v1pb.ListMemoResourcesResponse{
-		Resources: []*v1pb.Resource{},
-	}
+	list := []*v1pb.Resource{}
 	for _, resource := range resources {
-		response.Resources = append(response.Resources, s.convertResourceFromStore(ctx, resource))
+		list = append(list, s.convertResourceFromStore(ctx, resource))
 	}
-	return response, nil
+	return list, nil
 }
@@ -0,0 +1,188 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/usememos/memos/internal/util"
+	v1pb "github.com/usememos/memos/proto/gen/api/v1"
+	"github.com/usememos/memos/store"
+)
+
+func (s *APIV1Service) ListMemoRevisions(ctx context.Context, request *v1pb.ListMemoRevisionsRequest) (*v1pb.ListMemoRevisionsResponse, error) {
+	memoUID, err := ExtractMemoUIDFromName(request.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
+	}
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessReveal)
+	if err != nil {
+		return nil, err
+	}
+
+	var limit, offset int
+	if request.PageToken != "" {
+		var pageToken v1pb.PageToken
+		if err := unmarshalPageToken(request.PageToken, &pageToken); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token: %v", err)
+		}
+		limit = int(pageToken.Limit)
+		offset = int(pageToken.Offset)
+	} else {
+		limit = int(request.PageSize)
+	}
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limitPlusOne := limit + 1
+	revisions, err := s.Store.ListMemoRevisions(ctx, &store.FindMemoRevision{
+		MemoID: &memo.ID,
+		Limit:  &limitPlusOne,
+		Offset: &offset,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list memo revisions: %v", err)
+	}
+
+	nextPageToken := ""
+	if len(revisions) == limitPlusOne {
+		revisions = revisions[:limit]
+		nextPageToken, err = getPageToken(limit, offset+limit)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get next page token, error: %v", err)
+		}
+	}
+	response := &v1pb.ListMemoRevisionsResponse{
+		Revisions:     []*v1pb.MemoRevision{},
+		NextPageToken: nextPageToken,
+	}
+	for _, revision := range revisions {
+		response.Revisions = append(response.Revisions, convertMemoRevisionFromStore(memo, revision))
+	}
+	return response, nil
+}
+
+func (s *APIV1Service) GetMemoRevision(ctx context.Context, request *v1pb.GetMemoRevisionRequest) (*v1pb.MemoRevision, error) {
+	memo, revision, err := s.getMemoRevision(ctx, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	return convertMemoRevisionFromStore(memo, revision), nil
+}
+
+func (s *APIV1Service) RestoreMemoRevision(ctx context.Context, request *v1pb.RestoreMemoRevisionRequest) (*v1pb.Memo, error) {
+	memo, revision, err := s.getMemoRevision(ctx, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Restoring is an update of the memo, which records a new revision.
+	return s.UpdateMemo(ctx, &v1pb.UpdateMemoRequest{
+		Memo: &v1pb.Memo{
+			Name:       fmt.Sprintf("%s%s", MemoNamePrefix, memo.UID),
+			Content:    revision.Content,
+			Visibility: convertVisibilityFromStore(revision.Visibility),
+		},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"content", "visibility"}},
+	})
+}
+
+func (s *APIV1Service) DiffMemoRevisions(ctx context.Context, request *v1pb.DiffMemoRevisionsRequest) (*v1pb.DiffMemoRevisionsResponse, error) {
+	memo, revision, err := s.getMemoRevision(ctx, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	baseContent := ""
+	if request.Base != "" {
+		memoUID, baseID, err := ExtractMemoRevisionFromName(request.Base)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid base revision name: %v", err)
+		}
+		if memoUID != memo.UID {
+			return nil, status.Errorf(codes.InvalidArgument, "base revision is not of the same memo")
+		}
+		base, err := s.Store.GetMemoRevision(ctx, &store.FindMemoRevision{ID: &baseID, MemoID: &memo.ID})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get memo revision: %v", err)
+		}
+		if base == nil {
+			return nil, status.Errorf(codes.NotFound, "base revision not found")
+		}
+		baseContent = base.Content
+	} else {
+		revisions, err := s.Store.ListMemoRevisions(ctx, &store.FindMemoRevision{MemoID: &memo.ID})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to list memo revisions: %v", err)
+		}
+		// Revisions are listed newest first, so the preceding one is the first with a lower ID.
+		for _, r := range revisions {
+			if r.ID < revision.ID {
+				baseContent = r.Content
+				break
+			}
+		}
+	}
+
+	response := &v1pb.DiffMemoRevisionsResponse{
+		Lines: []*v1pb.DiffMemoRevisionsResponse_Line{},
+	}
+	for _, line := range util.DiffLines(baseContent, revision.Content) {
+		response.Lines = append(response.Lines, &v1pb.DiffMemoRevisionsResponse_Line{
+			Operation: convertDiffOperationFromUtil(line.Operation),
+			Content:   line.Content,
+		})
+	}
+	return response, nil
+}
+
+// getMemoRevision returns the revision with the given name, together with its memo.
+func (s *APIV1Service) getMemoRevision(ctx context.Context, name string) (*store.Memo, *store.MemoRevision, error) {
+	memoUID, revisionID, err := ExtractMemoRevisionFromName(name)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "invalid revision name: %v", err)
+	}
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessReveal)
+	if err != nil {
+		return nil, nil, err
+	}
+	revision, err := s.Store.GetMemoRevision(ctx, &store.FindMemoRevision{ID: &revisionID, MemoID: &memo.ID})
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "failed to get memo revision: %v", err)
+	}
+	if revision == nil {
+		return nil, nil, status.Errorf(codes.NotFound, "revision not found")
+	}
+	return memo, revision, nil
+}
+
+func convertMemoRevisionFromStore(memo *store.Memo, revision *store.MemoRevision) *v1pb.MemoRevision {
+	revisionMessage := &v1pb.MemoRevision{
+		Name:       fmt.Sprintf("%s%s/%s%d", MemoNamePrefix, memo.UID, RevisionNamePrefix, revision.ID),
+		CreateTime: timestamppb.New(time.Unix(revision.CreatedTs, 0)),
+		Content:    revision.Content,
+		Visibility: convertVisibilityFromStore(revision.Visibility),
+	}
+	if revision.EditorID != 0 {
+		revisionMessage.Editor = fmt.Sprintf("%s%d", UserNamePrefix, revision.EditorID)
+	}
+	return revisionMessage
+}
+
+func convertDiffOperationFromUtil(operation util.DiffOperation) v1pb.DiffMemoRevisionsResponse_Line_Operation {
+	switch operation {
+	case util.DiffEqual:
+		return v1pb.DiffMemoRevisionsResponse_Line_EQUAL
+	case util.DiffInsert:
+		return v1pb.DiffMemoRevisionsResponse_Line_INSERT
+	case util.DiffDelete:
+		return v1pb.DiffMemoRevisionsResponse_Line_DELETE
+	default:
+		return v1pb.DiffMemoRevisionsResponse_Line_OPERATION_UNSPECIFIED
+	}
+}
@@ -3,22 +3,25 @@ package v1
 import (
 	"context"
 	"fmt"
+	"html"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/lithammer/shortuuid/v4"
 	"github.com/pkg/errors"
-	"github.com/usememos/gomark/ast"
 	"github.com/usememos/gomark/parser"
 	"github.com/usememos/gomark/parser/tokenizer"
 	"github.com/usememos/gomark/renderer"
-	"github.com/usememos/gomark/restore"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/usememos/memos/plugin/filter"
 	"github.com/usememos/memos/plugin/webhook"
 	v1pb "github.com/usememos/memos/proto/gen/api/v1"
 	storepb "github.com/usememos/memos/proto/gen/store"
@@ -58,6 +61,13 @@ func (s *APIV1Service) CreateMemo(ctx context.Context, request *v1pb.CreateMemoR
 	if request.Memo.Location != nil {
 		create.Payload.Location = convertLocationToStore(request.Memo.Location)
 	}
+	if request.Memo.ScheduleTime != nil {
+		scheduledTs := request.Memo.ScheduleTime.AsTime().Unix()
+		if scheduledTs <= time.Now().Unix() {
+			return nil, status.Errorf(codes.InvalidArgument, "schedule time must be in the future")
+		}
+		create.ScheduledTs = scheduledTs
+	}
 
 	memo, err := s.Store.CreateMemo(ctx, create)
 	if err != nil {
@@ -87,8 +97,11 @@ func (s *APIV1Service) CreateMemo(ctx context.Context, request *v1pb.CreateMemoR
 		return nil, errors.Wrap(err, "failed to convert memo")
 	}
 	// Try to dispatch webhook when memo is created.
-	if err := s.DispatchMemoCreatedWebhook(ctx, memoMessage); err != nil {
-		slog.Warn("Failed to dispatch memo created webhook", slog.Any("err", err))
+	// Scheduled memos dispatch it once they are published.
+	if memo.ScheduledTs == 0 {
+		if err := s.DispatchMemoCreatedWebhook(ctx, memoMessage); err != nil {
+			slog.Warn("Failed to dispatch memo created webhook", slog.Any("err", err))
+		}
 	}
 
 	return memoMessage, nil
@@ -99,9 +112,6 @@ func (s *APIV1Service) ListMemos(ctx context.Context, request *v1pb.ListMemosReq
 		// Exclude comments by default.
 		ExcludeComments: true,
 	}
-	if err := s.buildMemoFindWithFilter(ctx, memoFind, request.OldFilter); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "failed to build find memos with filter: %v", err)
-	}
 	if request.Parent != "" && request.Parent != "users/-" {
 		userID, err := ExtractUserIDFromName(request.Parent)
 		if err != nil {
@@ -120,9 +130,6 @@ func (s *APIV1Service) ListMemos(ctx context.Context, request *v1pb.ListMemosReq
 	if request.Direction == v1pb.Direction_ASC {
 		memoFind.OrderByTimeAsc = true
 	}
-	if request.Filter != "" {
-		memoFind.Filter = &request.Filter
-	}
 
 	currentUser, err := s.GetCurrentUser(ctx)
 	if err != nil {
@@ -130,8 +137,11 @@ func (s *APIV1Service) ListMemos(ctx context.Context, request *v1pb.ListMemosReq
 	}
 	if currentUser == nil {
 		memoFind.VisibilityList = []store.Visibility{store.Public}
+		memoFind.ExcludeScheduled = true
 	} else if memoFind.CreatorID == nil || *memoFind.CreatorID != currentUser.ID {
 		memoFind.VisibilityList = []store.Visibility{store.Public, store.Protected}
+		memoFind.GrantedUserID = &currentUser.ID
+		memoFind.ExcludeScheduled = true
 	}
 
 	workspaceMemoRelatedSetting, err := s.Store.GetWorkspaceMemoRelatedSetting(ctx)
@@ -141,6 +151,34 @@ func (s *APIV1Service) ListMemos(ctx context.Context, request *v1pb.ListMemosReq
 	if workspaceMemoRelatedSetting.DisplayWithUpdateTime {
 		memoFind.OrderByUpdatedTs = true
 	}
+	// The old filter is converted to the filter, so both are run by the same engine.
+	filters := []string{}
+	if request.OldFilter != "" {
+		oldFilter, err := convertLegacyMemoFilter(request.OldFilter, workspaceMemoRelatedSetting.DisplayWithUpdateTime)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid old filter: %v", err)
+		}
+		if oldFilter != "" {
+			filters = append(filters, oldFilter)
+		}
+	}
+	if request.Filter != "" {
+		if err := filter.ValidateMemoFilter(request.Filter); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid filter: %v", err)
+		}
+		filters = append(filters, request.Filter)
+	}
+	if len(filters) > 0 {
+		memoFilter := "(" + strings.Join(filters, ") && (") + ")"
+		memoFind.Filter = &memoFilter
+	}
+	if request.Sort != "" {
+		orderBy, err := parseMemoSort(request.Sort, request.Direction, workspaceMemoRelatedSetting.DisplayWithUpdateTime)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid sort: %v", err)
+		}
+		memoFind.OrderBy = orderBy
+	}
 
 	var limit, offset int
 	if request.PageToken != "" {
@@ -150,6 +188,10 @@ func (s *APIV1Service) ListMemos(ctx context.Context, request *v1pb.ListMemosReq
 		}
 		limit = int(pageToken.Limit)
 		offset = int(pageToken.Offset)
+		// Custom orderings are paged by offset, the counts they may order by change too often for a cursor.
+		if pageToken.Cursor != nil && len(memoFind.OrderBy) == 0 {
+			memoFind.Cursor = convertMemoCursorFromPageToken(pageToken.Cursor)
+		}
 	} else {
 		limit = int(request.PageSize)
 	}
@@ -158,7 +200,10 @@ func (s *APIV1Service) ListMemos(ctx context.Context, request *v1pb.ListMemosReq
 	}
 	limitPlusOne := limit + 1
 	memoFind.Limit = &limitPlusOne
-	memoFind.Offset = &offset
+	if memoFind.Cursor == nil {
+		// Page tokens issued before keyset pagination carry an offset.
+		memoFind.Offset = &offset
+	}
 	memos, err := s.Store.ListMemos(ctx, memoFind)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to list memos: %v", err)
@@ -168,7 +213,11 @@ func (s *APIV1Service) ListMemos(ctx context.Context, request *v1pb.ListMemosReq
 	nextPageToken := ""
 	if len(memos) == limitPlusOne {
 		memos = memos[:limit]
-		nextPageToken, err = getPageToken(limit, offset+limit)
+		if len(memoFind.OrderBy) > 0 {
+			nextPageToken, err = getPageToken(limit, offset+limit)
+		} else {
+			nextPageToken, err = getMemoPageToken(limit, memoFind.CursorOf(memos[limit-1]))
+		}
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to get next page token, error: %v", err)
 		}
@@ -188,31 +237,105 @@ func (s *APIV1Service) ListMemos(ctx context.Context, request *v1pb.ListMemosReq
 	return response, nil
 }
 
-func (s *APIV1Service) GetMemo(ctx context.Context, request *v1pb.GetMemoRequest) (*v1pb.Memo, error) {
-	memoUID, err := ExtractMemoUIDFromName(request.Name)
-	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
+func (s *APIV1Service) SearchMemos(ctx context.Context, request *v1pb.SearchMemosRequest) (*v1pb.SearchMemosResponse, error) {
+	terms := store.SplitSearchTerms(request.Query)
+	if len(terms) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "query is required")
 	}
-	memo, err := s.Store.GetMemo(ctx, &store.FindMemo{
-		UID: &memoUID,
-	})
+	state := store.Normal
+	memoFind := &store.FindMemo{
+		RowStatus:       &state,
+		FullTextSearch:  &request.Query,
+		ExcludeComments: true,
+	}
+	if request.Parent != "" && request.Parent != "users/-" {
+		userID, err := ExtractUserIDFromName(request.Parent)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid parent: %v", err)
+		}
+		memoFind.CreatorID = &userID
+	}
+	if request.Filter != "" {
+		if err := filter.ValidateMemoFilter(request.Filter); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid filter: %v", err)
+		}
+		memoFind.Filter = &request.Filter
+	}
+
+	currentUser, err := s.GetCurrentUser(ctx)
 	if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.Internal, "failed to get user")
 	}
-	if memo == nil {
-		return nil, status.Errorf(codes.NotFound, "memo not found")
+	if currentUser == nil {
+		memoFind.VisibilityList = []store.Visibility{store.Public}
+		memoFind.ExcludeScheduled = true
+	} else if memoFind.CreatorID == nil || *memoFind.CreatorID != currentUser.ID {
+		memoFind.VisibilityList = []store.Visibility{store.Public, store.Protected}
+		memoFind.GrantedUserID = &currentUser.ID
+		memoFind.ExcludeScheduled = true
 	}
-	if memo.Visibility != store.Public {
-		user, err := s.GetCurrentUser(ctx)
+
+	var limit, offset int
+	if request.PageToken != "" {
+		var pageToken v1pb.PageToken
+		if err := unmarshalPageToken(request.PageToken, &pageToken); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token: %v", err)
+		}
+		limit = int(pageToken.Limit)
+		offset = int(pageToken.Offset)
+	} else {
+		limit = int(request.PageSize)
+	}
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limitPlusOne := limit + 1
+	memoFind.Limit = &limitPlusOne
+	memoFind.Offset = &offset
+	memos, err := s.Store.ListMemos(ctx, memoFind)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to search memos: %v", err)
+	}
+
+	nextPageToken := ""
+	if len(memos) == limitPlusOne {
+		memos = memos[:limit]
+		nextPageToken, err = getPageToken(limit, offset+limit)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to get user")
+			return nil, status.Errorf(codes.Internal, "failed to get next page token, error: %v", err)
 		}
-		if user == nil {
-			return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	}
+	results := []*v1pb.SearchMemosResponse_Result{}
+	for _, memo := range memos {
+		memoMessage, err := s.convertMemoFromStore(ctx, memo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to convert memo")
 		}
-		if memo.Visibility == store.Private && memo.CreatorID != user.ID {
-			return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+		snippet, err := getMemoHighlightedSnippet(memo.Content, terms)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get memo highlighted snippet")
 		}
+		results = append(results, &v1pb.SearchMemosResponse_Result{
+			Memo:               memoMessage,
+			HighlightedSnippet: snippet,
+			Score:              memo.SearchScore,
+		})
+	}
+
+	return &v1pb.SearchMemosResponse{
+		Results:       results,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+func (s *APIV1Service) GetMemo(ctx context.Context, request *v1pb.GetMemoRequest) (*v1pb.Memo, error) {
+	memoUID, err := ExtractMemoUIDFromName(request.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
+	}
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessRead)
+	if err != nil {
+		return nil, err
 	}
 
 	memoMessage, err := s.convertMemoFromStore(ctx, memo)
@@ -231,25 +354,37 @@ func (s *APIV1Service) UpdateMemo(ctx context.Context, request *v1pb.UpdateMemoR
 		return nil, status.Errorf(codes.InvalidArgument, "update mask is required")
 	}
 
-	memo, err := s.Store.GetMemo(ctx, &store.FindMemo{UID: &memoUID})
+	// A write grant allows to edit the content, the other fields are left to the creator. Changing the
+	// visibility reveals the memo to other users.
+	access := memoAccessWrite
+	for _, path := range request.UpdateMask.Paths {
+		if path == "visibility" {
+			access = max(access, memoAccessReveal)
+		} else if !slices.Contains([]string{"content", "location", "resources", "relations", "update_time"}, path) {
+			access = max(access, memoAccessOwner)
+		}
+	}
+	memo, err := s.getMemoWithAccess(ctx, memoUID, access)
 	if err != nil {
 		return nil, err
 	}
-	if memo == nil {
-		return nil, status.Errorf(codes.NotFound, "memo not found")
+	// Reject the update if the memo was changed since the client read it.
+	if request.Memo.Etag != "" && request.Memo.Etag != getMemoEtag(memo) {
+		return nil, status.Errorf(codes.FailedPrecondition, "memo has been modified since it was read")
 	}
-
 	user, err := s.GetCurrentUser(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get current user")
 	}
-	// Only the creator or admin can update the memo.
-	if memo.CreatorID != user.ID && !isSuperUser(user) {
-		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
-	}
 
 	update := &store.UpdateMemo{
-		ID: memo.ID,
+		ID:       memo.ID,
+		EditorID: &user.ID,
+	}
+	if request.Memo.Etag != "" {
+		// The check above is repeated by the update itself, so that concurrent updates cannot pass it both.
+		current := *memo
+		update.Current = &current
 	}
 	for _, path := range request.UpdateMask.Paths {
 		if path == "content" {
@@ -301,32 +436,52 @@ func (s *APIV1Service) UpdateMemo(ctx context.Context, request *v1pb.UpdateMemoR
 			} else {
 				update.CreatedTs = &displayTs
 			}
+		} else if path == "schedule_time" {
+			// A past or empty schedule time publishes the memo immediately.
+			var scheduledTs int64
+			if request.Memo.ScheduleTime != nil && request.Memo.ScheduleTime.AsTime().Unix() > time.Now().Unix() {
+				scheduledTs = request.Memo.ScheduleTime.AsTime().Unix()
+			}
+			update.ScheduledTs = &scheduledTs
+			if scheduledTs == 0 && memo.ScheduledTs != 0 {
+				now := time.Now().Unix()
+				update.CreatedTs = &now
+				update.UpdatedTs = &now
+			}
 		} else if path == "location" {
 			payload := memo.Payload
 			payload.Location = convertLocationToStore(request.Memo.Location)
 			update.Payload = payload
-		} else if path == "resources" {
-			_, err := s.SetMemoResources(ctx, &v1pb.SetMemoResourcesRequest{
-				Name:      request.Memo.Name,
-				Resources: request.Memo.Resources,
-			})
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to set memo resources")
-			}
-		} else if path == "relations" {
-			_, err := s.SetMemoRelations(ctx, &v1pb.SetMemoRelationsRequest{
-				Name:      request.Memo.Name,
-				Relations: request.Memo.Relations,
-			})
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to set memo relations")
-			}
 		}
 	}
 
 	if err = s.Store.UpdateMemo(ctx, update); err != nil {
+		if errors.Is(err, store.ErrMemoModified) {
+			return nil, status.Errorf(codes.FailedPrecondition, "memo has been modified since it was read")
+		}
 		return nil, status.Errorf(codes.Internal, "failed to update memo")
 	}
+	// The resources and relations are set once the update passed the etag check, so that a stale update
+	// does not replace them.
+	if slices.Contains(request.UpdateMask.Paths, "resources") {
+		if _, err := s.SetMemoResources(ctx, &v1pb.SetMemoResourcesRequest{
+			Name:      request.Memo.Name,
+			Resources: request.Memo.Resources,
+		}); err != nil {
+			return nil, errors.Wrap(err, "failed to set memo resources")
+		}
+	}
+	if slices.Contains(request.UpdateMask.Paths, "relations") {
+		if _, err := s.SetMemoRelations(ctx, &v1pb.SetMemoRelationsRequest{
+			Name:      request.Memo.Name,
+			Relations: request.Memo.Relations,
+		}); err != nil {
+			return nil, errors.Wrap(err, "failed to set memo relations")
+		}
+	}
+	pinned := update.Pinned != nil && *update.Pinned && !memo.Pinned
+	archived := update.RowStatus != nil && *update.RowStatus == store.Archived && memo.RowStatus != store.Archived
+	published := update.ScheduledTs != nil && *update.ScheduledTs == 0 && memo.ScheduledTs != 0
 
 	memo, err = s.Store.GetMemo(ctx, &store.FindMemo{
 		ID: &memo.ID,
@@ -338,10 +493,28 @@ func (s *APIV1Service) UpdateMemo(ctx context.Context, request *v1pb.UpdateMemoR
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to convert memo")
 	}
-	// Try to dispatch webhook when memo is updated.
-	if err := s.DispatchMemoUpdatedWebhook(ctx, memoMessage); err != nil {
+	// Webhooks are suppressed while the memo is scheduled.
+	if memo.ScheduledTs != 0 {
+		return memoMessage, nil
+	}
+	if published {
+		if err := s.DispatchMemoCreatedWebhook(ctx, memoMessage); err != nil {
+			slog.Warn("Failed to dispatch memo created webhook", slog.Any("err", err))
+		}
+	} else if err := s.DispatchMemoUpdatedWebhook(ctx, memoMessage); err != nil {
+		// Try to dispatch webhook when memo is updated.
 		slog.Warn("Failed to dispatch memo updated webhook", slog.Any("err", err))
 	}
+	if pinned {
+		if err := s.dispatchMemoRelatedWebhook(ctx, memoMessage, webhook.EventMemoPinned); err != nil {
+			slog.Warn("Failed to dispatch memo pinned webhook", slog.Any("err", err))
+		}
+	}
+	if archived {
+		if err := s.dispatchMemoRelatedWebhook(ctx, memoMessage, webhook.EventMemoArchived); err != nil {
+			slog.Warn("Failed to dispatch memo archived webhook", slog.Any("err", err))
+		}
+	}
 
 	return memoMessage, nil
 }
@@ -351,26 +524,12 @@ func (s *APIV1Service) DeleteMemo(ctx context.Context, request *v1pb.DeleteMemoR
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
 	}
-	memo, err := s.Store.GetMemo(ctx, &store.FindMemo{
-		UID: &memoUID,
-	})
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessOwner)
 	if err != nil {
 		return nil, err
 	}
-	if memo == nil {
-		return nil, status.Errorf(codes.NotFound, "memo not found")
-	}
-
-	user, err := s.GetCurrentUser(ctx)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get current user")
-	}
-	// Only the creator or admin can update the memo.
-	if memo.CreatorID != user.ID && !isSuperUser(user) {
-		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
-	}
 
-	if memoMessage, err := s.convertMemoFromStore(ctx, memo); err == nil {
+	if memoMessage, err := s.convertMemoFromStore(ctx, memo); err == nil && memo.ScheduledTs == 0 {
 		// Try to dispatch webhook when memo is deleted.
 		if err := s.DispatchMemoDeletedWebhook(ctx, memoMessage); err != nil {
 			slog.Warn("Failed to dispatch memo deleted webhook", slog.Any("err", err))
@@ -423,9 +582,9 @@ func (s *APIV1Service) CreateMemoComment(ctx context.Context, request *v1pb.Crea
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
 	}
-	relatedMemo, err := s.Store.GetMemo(ctx, &store.FindMemo{UID: &memoUID})
+	relatedMemo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessRead)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get memo")
+		return nil, err
 	}
 
 	// Create the memo comment first.
@@ -483,6 +642,18 @@ func (s *APIV1Service) CreateMemoComment(ctx context.Context, request *v1pb.Crea
 		}
 	}
 
+	// Try to dispatch webhook to the owner of the memo when a comment is created.
+	if commentMessage, err := s.convertMemoFromStore(ctx, memo); err == nil {
+		payload, err := convertMemoToWebhookPayload(commentMessage)
+		if err == nil {
+			payload.ActivityType = webhook.EventMemoCommentCreated
+			err = s.dispatchWebhookEvent(ctx, relatedMemo.CreatorID, payload)
+		}
+		if err != nil {
+			slog.Warn("Failed to dispatch memo comment created webhook", slog.Any("err", err))
+		}
+	}
+
 	return memoComment, nil
 }
 
@@ -491,136 +662,109 @@ func (s *APIV1Service) ListMemoComments(ctx context.Context, request *v1pb.ListM
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
 	}
-	memo, err := s.Store.GetMemo(ctx, &store.FindMemo{UID: &memoUID})
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessRead)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get memo")
-	}
-	memoRelationComment := store.MemoRelationComment
-	memoRelations, err := s.Store.ListMemoRelations(ctx, &store.FindMemoRelation{
-		RelatedMemoID: &memo.ID,
-		Type:          &memoRelationComment,
-	})
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to list memo relations")
+		return nil, err
 	}
 
-	var memos []*v1pb.Memo
-	for _, memoRelation := range memoRelations {
-		memo, err := s.Store.GetMemo(ctx, &store.FindMemo{
-			ID: &memoRelation.MemoID,
-		})
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to get memo")
+	memoFind := &store.FindMemo{
+		ParentID: &memo.ID,
+		// Comments are listed in the order they are written.
+		OrderByTimeAsc: true,
+	}
+	limit := int(request.PageSize)
+	if request.PageToken != "" {
+		var pageToken v1pb.PageToken
+		if err := unmarshalPageToken(request.PageToken, &pageToken); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token: %v", err)
 		}
-		if memo != nil {
-			memoMessage, err := s.convertMemoFromStore(ctx, memo)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to convert memo")
-			}
-			memos = append(memos, memoMessage)
+		limit = int(pageToken.Limit)
+		if pageToken.Cursor != nil {
+			memoFind.Cursor = convertMemoCursorFromPageToken(pageToken.Cursor)
 		}
 	}
-
-	response := &v1pb.ListMemoCommentsResponse{
-		Memos: memos,
+	limitPlusOne := limit + 1
+	if limit > 0 {
+		memoFind.Limit = &limitPlusOne
 	}
-	return response, nil
-}
-
-func (s *APIV1Service) RenameMemoTag(ctx context.Context, request *v1pb.RenameMemoTagRequest) (*emptypb.Empty, error) {
-	user, err := s.GetCurrentUser(ctx)
+	comments, err := s.Store.ListMemos(ctx, memoFind)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get current user")
+		return nil, status.Errorf(codes.Internal, "failed to list comments: %v", err)
 	}
 
-	memoFind := &store.FindMemo{
-		CreatorID:       &user.ID,
-		PayloadFind:     &store.FindMemoPayload{TagSearch: []string{request.OldTag}},
-		ExcludeComments: true,
-	}
-	if (request.Parent) != "memos/-" {
-		memoUID, err := ExtractMemoUIDFromName(request.Parent)
+	nextPageToken := ""
+	if limit > 0 && len(comments) == limitPlusOne {
+		comments = comments[:limit]
+		nextPageToken, err = getMemoPageToken(limit, memoFind.CursorOf(comments[limit-1]))
 		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
+			return nil, status.Errorf(codes.Internal, "failed to get next page token, error: %v", err)
 		}
-		memoFind.UID = &memoUID
-	}
-
-	memos, err := s.Store.ListMemos(ctx, memoFind)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to list memos")
 	}
-
-	for _, memo := range memos {
-		nodes, err := parser.Parse(tokenizer.Tokenize(memo.Content))
+	var memos []*v1pb.Memo
+	for _, comment := range comments {
+		memoMessage, err := s.convertMemoFromStore(ctx, comment)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to parse memo: %v", err)
-		}
-		memopayload.TraverseASTNodes(nodes, func(node ast.Node) {
-			if tag, ok := node.(*ast.Tag); ok && tag.Content == request.OldTag {
-				tag.Content = request.NewTag
-			}
-		})
-		memo.Content = restore.Restore(nodes)
-		if err := memopayload.RebuildMemoPayload(memo); err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to rebuild memo payload: %v", err)
-		}
-		if err := s.Store.UpdateMemo(ctx, &store.UpdateMemo{
-			ID:      memo.ID,
-			Content: &memo.Content,
-			Payload: memo.Payload,
-		}); err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to update memo: %v", err)
+			return nil, errors.Wrap(err, "failed to convert memo")
 		}
+		memos = append(memos, memoMessage)
 	}
 
-	return &emptypb.Empty{}, nil
+	response := &v1pb.ListMemoCommentsResponse{
+		Memos:         memos,
+		NextPageToken: nextPageToken,
+	}
+	return response, nil
 }
 
-func (s *APIV1Service) DeleteMemoTag(ctx context.Context, request *v1pb.DeleteMemoTagRequest) (*emptypb.Empty, error) {
-	user, err := s.GetCurrentUser(ctx)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get current user")
-	}
+// memoSortFields are the fields memos can be sorted by in ListMemos.
+var memoSortFields = map[string]store.MemoOrderField{
+	"pinned":         store.MemoOrderFieldPinned,
+	"create_time":    store.MemoOrderFieldCreatedTs,
+	"update_time":    store.MemoOrderFieldUpdatedTs,
+	"reaction_count": store.MemoOrderFieldReactionCount,
+	"comment_count":  store.MemoOrderFieldCommentCount,
+}
 
-	memoFind := &store.FindMemo{
-		CreatorID:       &user.ID,
-		PayloadFind:     &store.FindMemoPayload{TagSearch: []string{request.Tag}},
-		ExcludeContent:  true,
-		ExcludeComments: true,
-	}
-	if request.Parent != "memos/-" {
-		memoUID, err := ExtractMemoUIDFromName(request.Parent)
-		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
+// parseMemoSort parses a sort like "pinned desc, update_time desc" to the orders of memos.
+// Fields without a direction are sorted in the direction of the request.
+func parseMemoSort(sort string, direction v1pb.Direction, displayWithUpdateTime bool) ([]store.MemoOrder, error) {
+	orders := []store.MemoOrder{}
+	seen := map[store.MemoOrderField]bool{}
+	for _, item := range strings.Split(sort, ",") {
+		parts := strings.Fields(item)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, errors.Errorf("invalid sort item %q", strings.TrimSpace(item))
 		}
-		memoFind.UID = &memoUID
-	}
-
-	memos, err := s.Store.ListMemos(ctx, memoFind)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to list memos")
-	}
-
-	for _, memo := range memos {
-		if request.DeleteRelatedMemos {
-			err := s.Store.DeleteMemo(ctx, &store.DeleteMemo{ID: memo.ID})
-			if err != nil {
-				return nil, status.Errorf(codes.Internal, "failed to delete memo")
+		name := parts[0]
+		if name == "display_time" {
+			name = "create_time"
+			if displayWithUpdateTime {
+				name = "update_time"
 			}
-		} else {
-			archived := store.Archived
-			err := s.Store.UpdateMemo(ctx, &store.UpdateMemo{
-				ID:        memo.ID,
-				RowStatus: &archived,
-			})
-			if err != nil {
-				return nil, status.Errorf(codes.Internal, "failed to update memo")
+		}
+		field, ok := memoSortFields[name]
+		if !ok {
+			return nil, errors.Errorf("unsupported sort field %q", parts[0])
+		}
+		if seen[field] {
+			return nil, errors.Errorf("duplicate sort field %q", parts[0])
+		}
+		seen[field] = true
+
+		order := store.MemoOrder{Field: field, Desc: direction != v1pb.Direction_ASC}
+		if len(parts) == 2 {
+			switch strings.ToLower(parts[1]) {
+			case "asc":
+				order.Desc = false
+			case "desc":
+				order.Desc = true
+			default:
+				return nil, errors.Errorf("invalid sort direction %q", parts[1])
 			}
 		}
+		orders = append(orders, order)
 	}
-
-	return &emptypb.Empty{}, nil
+	return orders, nil
 }
 
 func (s *APIV1Service) getContentLengthLimit(ctx context.Context) (int, error) {
@@ -633,17 +777,26 @@ func (s *APIV1Service) getContentLengthLimit(ctx context.Context) (int, error) {
 
 // DispatchMemoCreatedWebhook dispatches webhook when memo is created.
 func (s *APIV1Service) DispatchMemoCreatedWebhook(ctx context.Context, memo *v1pb.Memo) error {
-	return s.dispatchMemoRelatedWebhook(ctx, memo, "memos.memo.created")
+	return s.dispatchMemoRelatedWebhook(ctx, memo, webhook.EventMemoCreated)
+}
+
+// DispatchMemoPublishedWebhook dispatches the memo created webhook when a scheduled memo is published.
+func (s *APIV1Service) DispatchMemoPublishedWebhook(ctx context.Context, memo *store.Memo) error {
+	memoMessage, err := s.convertMemoFromStore(ctx, memo)
+	if err != nil {
+		return errors.Wrap(err, "failed to convert memo")
+	}
+	return s.DispatchMemoCreatedWebhook(ctx, memoMessage)
 }
 
 // DispatchMemoUpdatedWebhook dispatches webhook when memo is updated.
 func (s *APIV1Service) DispatchMemoUpdatedWebhook(ctx context.Context, memo *v1pb.Memo) error {
-	return s.dispatchMemoRelatedWebhook(ctx, memo, "memos.memo.updated")
+	return s.dispatchMemoRelatedWebhook(ctx, memo, webhook.EventMemoUpdated)
 }
 
 // DispatchMemoDeletedWebhook dispatches webhook when memo is deleted.
 func (s *APIV1Service) DispatchMemoDeletedWebhook(ctx context.Context, memo *v1pb.Memo) error {
-	return s.dispatchMemoRelatedWebhook(ctx, memo, "memos.memo.deleted")
+	return s.dispatchMemoRelatedWebhook(ctx, memo, webhook.EventMemoDeleted)
 }
 
 func (s *APIV1Service) dispatchMemoRelatedWebhook(ctx context.Context, memo *v1pb.Memo, activityType string) error {
@@ -651,24 +804,12 @@ func (s *APIV1Service) dispatchMemoRelatedWebhook(ctx context.Context, memo *v1p
 	if err != nil {
 		return status.Errorf(codes.InvalidArgument, "invalid memo creator")
 	}
-	webhooks, err := s.Store.ListWebhooks(ctx, &store.FindWebhook{
-		CreatorID: &creatorID,
-	})
+	payload, err := convertMemoToWebhookPayload(memo)
 	if err != nil {
-		return err
+		return errors.Wrap(err, "failed to convert memo to webhook payload")
 	}
-	for _, hook := range webhooks {
-		payload, err := convertMemoToWebhookPayload(memo)
-		if err != nil {
-			return errors.Wrap(err, "failed to convert memo to webhook payload")
-		}
-		payload.ActivityType = activityType
-		payload.Url = hook.URL
-		if err := webhook.Post(payload); err != nil {
-			return errors.Wrap(err, "failed to post webhook")
-		}
-	}
-	return nil
+	payload.ActivityType = activityType
+	return s.dispatchWebhookEvent(ctx, creatorID, payload)
 }
 
 func convertMemoToWebhookPayload(memo *v1pb.Memo) (*v1pb.WebhookRequestPayload, error) {
@@ -696,6 +837,71 @@ func getMemoContentSnippet(content string) (string, error) {
 	return plainText, nil
 }
 
+// getMemoHighlightedSnippet returns the plain text around the first match of the search terms,
+// with every term matched at the start of a word wrapped in <mark></mark>.
+func getMemoHighlightedSnippet(content string, terms []string) (string, error) {
+	nodes, err := parser.Parse(tokenizer.Tokenize(content))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse content")
+	}
+
+	text := []rune(strings.TrimSpace(renderer.NewStringRenderer().Render(nodes)))
+	lowered := make([]rune, len(text))
+	for i, r := range text {
+		lowered[i] = unicode.ToLower(r)
+	}
+	isWordRune := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	// Each match is a [start, end) range of runes.
+	matches := [][2]int{}
+	for i := 0; i < len(lowered); {
+		if i > 0 && isWordRune(lowered[i-1]) {
+			i++
+			continue
+		}
+		length := 0
+		for _, term := range terms {
+			termRunes := []rune(term)
+			if len(termRunes) > length && len(termRunes) <= len(lowered)-i && slices.Equal(lowered[i:i+len(termRunes)], termRunes) {
+				length = len(termRunes)
+			}
+		}
+		if length == 0 {
+			i++
+			continue
+		}
+		matches = append(matches, [2]int{i, i + length})
+		i += length
+	}
+
+	const snippetLength, leadingLength = 64, 16
+	start := 0
+	if len(matches) > 0 {
+		start = max(0, matches[0][0]-leadingLength)
+	}
+	end := min(len(text), start+snippetLength)
+	var builder strings.Builder
+	if start > 0 {
+		builder.WriteString("...")
+	}
+	cursor := start
+	for _, match := range matches {
+		if match[0] >= end {
+			break
+		}
+		builder.WriteString(html.EscapeString(string(text[cursor:match[0]])))
+		builder.WriteString("<mark>" + html.EscapeString(string(text[match[0]:match[1]])) + "</mark>")
+		cursor = match[1]
+	}
+	end = max(end, cursor)
+	builder.WriteString(html.EscapeString(string(text[cursor:end])))
+	if end < len(text) {
+		builder.WriteString("...")
+	}
+	return builder.String(), nil
+}
+
 func substring(s string, length int) string {
 	if length <= 0 {
 		return ""
@@ -2,6 +2,8 @@ package v1
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -37,6 +39,10 @@ func (s *APIV1Service) convertMemoFromStore(ctx context.Context, memo *store.Mem
 		Content:     memo.Content,
 		Visibility:  convertVisibilityFromStore(memo.Visibility),
 		Pinned:      memo.Pinned,
+		Etag:        getMemoEtag(memo),
+	}
+	if memo.ScheduledTs != 0 {
+		memoMessage.ScheduleTime = timestamppb.New(time.Unix(memo.ScheduledTs, 0))
 	}
 	if memo.Payload != nil {
 		memoMessage.Tags = memo.Payload.Tags
@@ -55,23 +61,23 @@ func (s *APIV1Service) convertMemoFromStore(ctx context.Context, memo *store.Mem
 		memoMessage.Parent = &parentName
 	}
 
-	listMemoRelationsResponse, err := s.ListMemoRelations(ctx, &v1pb.ListMemoRelationsRequest{Name: name})
+	relations, err := s.listMemoRelations(ctx, memo)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list memo relations")
 	}
-	memoMessage.Relations = listMemoRelationsResponse.Relations
+	memoMessage.Relations = relations
 
-	listMemoResourcesResponse, err := s.ListMemoResources(ctx, &v1pb.ListMemoResourcesRequest{Name: name})
+	resources, err := s.listMemoResources(ctx, memo)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list memo resources")
 	}
-	memoMessage.Resources = listMemoResourcesResponse.Resources
+	memoMessage.Resources = resources
 
-	listMemoReactionsResponse, err := s.ListMemoReactions(ctx, &v1pb.ListMemoReactionsRequest{Name: name})
+	reactions, err := s.listMemoReactions(ctx, memo)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list memo reactions")
 	}
-	memoMessage.Reactions = listMemoReactionsResponse.Reactions
+	memoMessage.Reactions = reactions
 
 	nodes, err := parser.Parse(tokenizer.Tokenize(memo.Content))
 	if err != nil {
@@ -147,3 +153,14 @@ func convertVisibilityToStore(visibility v1pb.Visibility) store.Visibility {
 		return store.Private
 	}
 }
+
+// getMemoEtag returns the etag of the memo, a hash of its update time and of the fields that UpdateMemo changes,
+// so it changes even if the memo is updated twice within the same second.
+func getMemoEtag(memo *store.Memo) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d\x00%d\x00%d\x00%s\x00%s\x00%t\x00%s", memo.CreatedTs, memo.UpdatedTs, memo.ScheduledTs, memo.RowStatus, memo.Visibility, memo.Pinned, memo.Content)
+	if location := memo.Payload.GetLocation(); location != nil {
+		fmt.Fprintf(hash, "\x00%s\x00%f\x00%f", location.Placeholder, location.Latitude, location.Longitude)
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:8])
+}
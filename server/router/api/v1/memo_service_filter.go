@@ -1,75 +1,19 @@
 package v1
 
 import (
-	"context"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/google/cel-go/cel"
 	"github.com/pkg/errors"
 	exprv1 "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 
-	"github.com/usememos/memos/store"
+	"github.com/usememos/memos/plugin/filter"
 )
 
-func (s *APIV1Service) buildMemoFindWithFilter(ctx context.Context, find *store.FindMemo, filter string) error {
-	if find.PayloadFind == nil {
-		find.PayloadFind = &store.FindMemoPayload{}
-	}
-	if filter != "" {
-		filterExpr, err := parseMemoFilter(filter)
-		if err != nil {
-			return status.Errorf(codes.InvalidArgument, "invalid filter: %v", err)
-		}
-		if len(filterExpr.ContentSearch) > 0 {
-			find.ContentSearch = filterExpr.ContentSearch
-		}
-		if filterExpr.TagSearch != nil {
-			if find.PayloadFind == nil {
-				find.PayloadFind = &store.FindMemoPayload{}
-			}
-			find.PayloadFind.TagSearch = filterExpr.TagSearch
-		}
-		if filterExpr.DisplayTimeAfter != nil {
-			workspaceMemoRelatedSetting, err := s.Store.GetWorkspaceMemoRelatedSetting(ctx)
-			if err != nil {
-				return status.Errorf(codes.Internal, "failed to get workspace memo related setting")
-			}
-			if workspaceMemoRelatedSetting.DisplayWithUpdateTime {
-				find.UpdatedTsAfter = filterExpr.DisplayTimeAfter
-			} else {
-				find.CreatedTsAfter = filterExpr.DisplayTimeAfter
-			}
-		}
-		if filterExpr.DisplayTimeBefore != nil {
-			workspaceMemoRelatedSetting, err := s.Store.GetWorkspaceMemoRelatedSetting(ctx)
-			if err != nil {
-				return status.Errorf(codes.Internal, "failed to get workspace memo related setting")
-			}
-			if workspaceMemoRelatedSetting.DisplayWithUpdateTime {
-				find.UpdatedTsBefore = filterExpr.DisplayTimeBefore
-			} else {
-				find.CreatedTsBefore = filterExpr.DisplayTimeBefore
-			}
-		}
-		if filterExpr.HasLink {
-			find.PayloadFind.HasLink = true
-		}
-		if filterExpr.HasTaskList {
-			find.PayloadFind.HasTaskList = true
-		}
-		if filterExpr.HasCode {
-			find.PayloadFind.HasCode = true
-		}
-		if filterExpr.HasIncompleteTasks {
-			find.PayloadFind.HasIncompleteTasks = true
-		}
-	}
-	return nil
-}
-
-// MemoFilterCELAttributes are the CEL attributes.
-var MemoFilterCELAttributes = []cel.EnvOption{
+// LegacyMemoFilterCELAttributes are the CEL attributes of the old filter of memos.
+var LegacyMemoFilterCELAttributes = []cel.EnvOption{
 	cel.Variable("content_search", cel.ListType(cel.StringType)),
 	cel.Variable("tag_search", cel.ListType(cel.StringType)),
 	cel.Variable("display_time_before", cel.IntType),
@@ -80,80 +24,87 @@ var MemoFilterCELAttributes = []cel.EnvOption{
 	cel.Variable("has_incomplete_tasks", cel.BoolType),
 }
 
-type MemoFilter struct {
-	ContentSearch      []string
-	TagSearch          []string
-	DisplayTimeBefore  *int64
-	DisplayTimeAfter   *int64
-	HasLink            bool
-	HasTaskList        bool
-	HasCode            bool
-	HasIncompleteTasks bool
-}
-
-func parseMemoFilter(expression string) (*MemoFilter, error) {
-	e, err := cel.NewEnv(MemoFilterCELAttributes...)
+// convertLegacyMemoFilter converts an old filter like `content_search == ["hello"] && has_link == true`
+// to the filter of memos, e.g. `content.contains("hello") && has_link`.
+// The old filter is a conjunction of comparisons of its attributes with constants.
+func convertLegacyMemoFilter(expression string, displayWithUpdateTime bool) (string, error) {
+	parsedExpr, err := filter.Parse(expression, LegacyMemoFilterCELAttributes...)
 	if err != nil {
-		return nil, err
-	}
-	ast, issues := e.Compile(expression)
-	if issues != nil {
-		return nil, errors.Errorf("found issue %v", issues)
+		return "", err
 	}
-	filter := &MemoFilter{}
-	parsedExpr, err := cel.AstToParsedExpr(ast)
-	if err != nil {
-		return nil, err
+	displayTime := "create_time"
+	if displayWithUpdateTime {
+		displayTime = "update_time"
 	}
-	callExpr := parsedExpr.GetExpr().GetCallExpr()
-	findMemoField(callExpr, filter)
-	return filter, nil
-}
 
-func findMemoField(callExpr *exprv1.Expr_Call, filter *MemoFilter) {
-	if len(callExpr.Args) == 2 {
-		idExpr := callExpr.Args[0].GetIdentExpr()
-		if idExpr != nil {
-			if idExpr.Name == "content_search" {
-				contentSearch := []string{}
-				for _, expr := range callExpr.Args[1].GetListExpr().GetElements() {
-					value := expr.GetConstExpr().GetStringValue()
-					contentSearch = append(contentSearch, value)
+	conditions := []string{}
+	var convert func(expr *exprv1.Expr) error
+	convert = func(expr *exprv1.Expr) error {
+		callExpr := expr.GetCallExpr()
+		if callExpr == nil {
+			return errors.New("old filter must be comparisons joined by &&")
+		}
+		if callExpr.Function == "_&&_" {
+			for _, arg := range callExpr.Args {
+				if err := convert(arg); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if callExpr.Function != "_==_" || len(callExpr.Args) != 2 {
+			return errors.New("old filter must be comparisons joined by &&")
+		}
+		name, err := filter.GetIdentExprName(callExpr.Args[0])
+		if err != nil {
+			return errors.New("the left side of == must be an attribute")
+		}
+
+		switch name {
+		case "content_search", "tag_search":
+			for _, element := range callExpr.Args[1].GetListExpr().GetElements() {
+				value, err := filter.GetConstValue(element)
+				if err != nil {
+					return errors.Errorf("%s must be compared with a list of strings", name)
+				}
+				s, ok := value.(string)
+				if !ok {
+					return errors.Errorf("%s must be compared with a list of strings", name)
 				}
-				filter.ContentSearch = contentSearch
-			} else if idExpr.Name == "tag_search" {
-				tagSearch := []string{}
-				for _, expr := range callExpr.Args[1].GetListExpr().GetElements() {
-					value := expr.GetConstExpr().GetStringValue()
-					tagSearch = append(tagSearch, value)
+				if name == "content_search" {
+					conditions = append(conditions, fmt.Sprintf("content.contains(%s)", strconv.Quote(s)))
+				} else {
+					// The tag search matches the tag and its descendants.
+					conditions = append(conditions, fmt.Sprintf("tag.within(%s)", strconv.Quote(s)))
 				}
-				filter.TagSearch = tagSearch
-			} else if idExpr.Name == "display_time_before" {
-				displayTimeBefore := callExpr.Args[1].GetConstExpr().GetInt64Value()
-				filter.DisplayTimeBefore = &displayTimeBefore
-			} else if idExpr.Name == "display_time_after" {
-				displayTimeAfter := callExpr.Args[1].GetConstExpr().GetInt64Value()
-				filter.DisplayTimeAfter = &displayTimeAfter
-			} else if idExpr.Name == "has_link" {
-				value := callExpr.Args[1].GetConstExpr().GetBoolValue()
-				filter.HasLink = value
-			} else if idExpr.Name == "has_task_list" {
-				value := callExpr.Args[1].GetConstExpr().GetBoolValue()
-				filter.HasTaskList = value
-			} else if idExpr.Name == "has_code" {
-				value := callExpr.Args[1].GetConstExpr().GetBoolValue()
-				filter.HasCode = value
-			} else if idExpr.Name == "has_incomplete_tasks" {
-				value := callExpr.Args[1].GetConstExpr().GetBoolValue()
-				filter.HasIncompleteTasks = value
 			}
-			return
+		case "display_time_before", "display_time_after":
+			value, err := filter.GetConstValue(callExpr.Args[1])
+			if err != nil {
+				return errors.Errorf("%s must be compared with an integer", name)
+			}
+			operator := "<"
+			if name == "display_time_after" {
+				operator = ">"
+			}
+			conditions = append(conditions, fmt.Sprintf("%s %s %v", displayTime, operator, value))
+		case "has_link", "has_task_list", "has_code", "has_incomplete_tasks":
+			value, err := filter.GetConstValue(callExpr.Args[1])
+			if err != nil {
+				return errors.Errorf("%s must be compared with a boolean", name)
+			}
+			if value == true {
+				conditions = append(conditions, name)
+			} else {
+				conditions = append(conditions, "!"+name)
+			}
+		default:
+			return errors.Errorf("unsupported attribute %s", name)
 		}
+		return nil
 	}
-	for _, arg := range callExpr.Args {
-		callExpr := arg.GetCallExpr()
-		if callExpr != nil {
-			findMemoField(callExpr, filter)
-		}
+	if err := convert(parsedExpr.GetExpr()); err != nil {
+		return "", err
 	}
+	return strings.Join(conditions, " && "), nil
 }
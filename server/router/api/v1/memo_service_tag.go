@@ -0,0 +1,279 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/usememos/gomark/ast"
+	"github.com/usememos/gomark/parser"
+	"github.com/usememos/gomark/parser/tokenizer"
+	"github.com/usememos/gomark/restore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/usememos/memos/plugin/filter"
+	v1pb "github.com/usememos/memos/proto/gen/api/v1"
+	"github.com/usememos/memos/server/runner/memopayload"
+	"github.com/usememos/memos/store"
+)
+
+func (s *APIV1Service) ListTags(ctx context.Context, request *v1pb.ListTagsRequest) (*v1pb.ListTagsResponse, error) {
+	normalStatus := store.Normal
+	memoFind := &store.FindMemo{
+		// Exclude comments by default.
+		ExcludeComments: true,
+		ExcludeContent:  true,
+		RowStatus:       &normalStatus,
+	}
+	if request.Parent != "" && request.Parent != "users/-" {
+		userID, err := ExtractUserIDFromName(request.Parent)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid parent: %v", err)
+		}
+		memoFind.CreatorID = &userID
+	}
+	currentUser, err := s.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user")
+	}
+	if currentUser == nil {
+		memoFind.VisibilityList = []store.Visibility{store.Public}
+		memoFind.ExcludeScheduled = true
+	} else if memoFind.CreatorID == nil || *memoFind.CreatorID != currentUser.ID {
+		memoFind.VisibilityList = []store.Visibility{store.Public, store.Protected}
+		memoFind.GrantedUserID = &currentUser.ID
+		memoFind.ExcludeScheduled = true
+	}
+	if request.Filter != "" {
+		if err := filter.ValidateMemoFilter(request.Filter); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid filter: %v", err)
+		}
+		memoFind.Filter = &request.Filter
+	}
+	memos, err := s.Store.ListMemos(ctx, memoFind)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list memos: %v", err)
+	}
+	memoTags, err := s.listMemoTags(ctx, memos)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list memo tags: %v", err)
+	}
+	return &v1pb.ListTagsResponse{
+		Tags: buildTagTree(memoTags),
+	}, nil
+}
+
+// listMemoTags returns the tags of the memos by memo id.
+func (s *APIV1Service) listMemoTags(ctx context.Context, memos []*store.Memo) (map[int32][]string, error) {
+	memoIDs := []int32{}
+	for _, memo := range memos {
+		memoIDs = append(memoIDs, memo.ID)
+	}
+	list, err := s.Store.ListMemoTags(ctx, &store.FindMemoTag{MemoIDList: memoIDs})
+	if err != nil {
+		return nil, err
+	}
+	memoTags := map[int32][]string{}
+	for _, memoTag := range list {
+		memoTags[memoTag.MemoID] = append(memoTags[memoTag.MemoID], memoTag.Tag)
+	}
+	return memoTags, nil
+}
+
+// buildTagTree returns the top-level tags of the memos with their descendants.
+// A memo is counted once by every tag it has and by every ancestor of those tags.
+func buildTagTree(memoTags map[int32][]string) []*v1pb.MemoTag {
+	tags := map[string]*v1pb.MemoTag{}
+	for _, memoTagList := range memoTags {
+		counted := map[string]bool{}
+		for _, tag := range memoTagList {
+			segments := strings.Split(tag, "/")
+			for i := range segments {
+				path := strings.Join(segments[:i+1], "/")
+				node, ok := tags[path]
+				if !ok {
+					node = &v1pb.MemoTag{
+						Tag:         path,
+						DisplayName: segments[i],
+						Children:    []*v1pb.MemoTag{},
+					}
+					tags[path] = node
+				}
+				if path == tag {
+					node.DirectMemoCount++
+				}
+				if !counted[path] {
+					counted[path] = true
+					node.MemoCount++
+				}
+			}
+		}
+	}
+
+	roots := []*v1pb.MemoTag{}
+	for path, node := range tags {
+		index := strings.LastIndex(path, "/")
+		if index == -1 {
+			roots = append(roots, node)
+			continue
+		}
+		parent := tags[path[:index]]
+		parent.Children = append(parent.Children, node)
+	}
+	var sortTags func([]*v1pb.MemoTag)
+	sortTags = func(nodes []*v1pb.MemoTag) {
+		slices.SortFunc(nodes, func(a, b *v1pb.MemoTag) int {
+			return strings.Compare(a.Tag, b.Tag)
+		})
+		for _, node := range nodes {
+			sortTags(node.Children)
+		}
+	}
+	sortTags(roots)
+	return roots
+}
+
+func (s *APIV1Service) RenameMemoTag(ctx context.Context, request *v1pb.RenameMemoTagRequest) (*emptypb.Empty, error) {
+	user, err := s.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get current user")
+	}
+	if !isValidTag(request.OldTag) || !isValidTag(request.NewTag) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid tag")
+	}
+
+	memoFind, err := buildMemoTagFind(user, request.Parent, request.OldTag, request.Subtree)
+	if err != nil {
+		return nil, err
+	}
+	memos, err := s.Store.ListMemos(ctx, memoFind)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list memos")
+	}
+
+	for _, memo := range memos {
+		nodes, err := parser.Parse(tokenizer.Tokenize(memo.Content))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to parse memo: %v", err)
+		}
+		memopayload.TraverseASTNodes(nodes, func(node ast.Node) {
+			if tag, ok := node.(*ast.Tag); ok && matchTag(tag.Content, request.OldTag, request.Subtree) {
+				tag.Content = request.NewTag + strings.TrimPrefix(tag.Content, request.OldTag)
+			}
+		})
+		if err := s.updateMemoContent(ctx, memo, restore.Restore(nodes)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *APIV1Service) DeleteMemoTag(ctx context.Context, request *v1pb.DeleteMemoTagRequest) (*emptypb.Empty, error) {
+	user, err := s.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get current user")
+	}
+	if !isValidTag(request.Tag) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid tag")
+	}
+
+	memoFind, err := buildMemoTagFind(user, request.Parent, request.Tag, request.Subtree)
+	if err != nil {
+		return nil, err
+	}
+	memoFind.ExcludeContent = !request.KeepMemos
+	memos, err := s.Store.ListMemos(ctx, memoFind)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list memos")
+	}
+
+	for _, memo := range memos {
+		if request.KeepMemos {
+			nodes, err := parser.Parse(tokenizer.Tokenize(memo.Content))
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to parse memo: %v", err)
+			}
+			nodes = memopayload.RemoveASTNodes(nodes, func(node ast.Node) bool {
+				tag, ok := node.(*ast.Tag)
+				return ok && matchTag(tag.Content, request.Tag, request.Subtree)
+			})
+			if err := s.updateMemoContent(ctx, memo, restore.Restore(nodes)); err != nil {
+				return nil, err
+			}
+		} else if request.DeleteRelatedMemos {
+			err := s.Store.DeleteMemo(ctx, &store.DeleteMemo{ID: memo.ID})
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to delete memo")
+			}
+		} else {
+			archived := store.Archived
+			err := s.Store.UpdateMemo(ctx, &store.UpdateMemo{
+				ID:        memo.ID,
+				RowStatus: &archived,
+			})
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to update memo")
+			}
+		}
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// buildMemoTagFind returns the find of the memos of the user with the tag in the parent,
+// which is a memo name or "memos/-" for all memos.
+func buildMemoTagFind(user *store.User, parent, tag string, subtree bool) (*store.FindMemo, error) {
+	memoFind := &store.FindMemo{
+		CreatorID:       &user.ID,
+		ExcludeComments: true,
+	}
+	if subtree {
+		memoFind.PayloadFind = &store.FindMemoPayload{TagSearch: []string{tag}}
+	} else {
+		memoFilter := fmt.Sprintf("tag in [%s]", strconv.Quote(tag))
+		memoFind.Filter = &memoFilter
+	}
+	if parent != "memos/-" {
+		memoUID, err := ExtractMemoUIDFromName(parent)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
+		}
+		memoFind.UID = &memoUID
+	}
+	return memoFind, nil
+}
+
+// updateMemoContent updates the content of the memo and rebuilds its payload, if the content changed.
+func (s *APIV1Service) updateMemoContent(ctx context.Context, memo *store.Memo, content string) error {
+	if content == memo.Content {
+		return nil
+	}
+	memo.Content = content
+	if err := memopayload.RebuildMemoPayload(memo); err != nil {
+		return status.Errorf(codes.Internal, "failed to rebuild memo payload: %v", err)
+	}
+	if err := s.Store.UpdateMemo(ctx, &store.UpdateMemo{
+		ID:      memo.ID,
+		Content: &memo.Content,
+		Payload: memo.Payload,
+	}); err != nil {
+		return status.Errorf(codes.Internal, "failed to update memo: %v", err)
+	}
+	return nil
+}
+
+// matchTag returns true if the tag is the target, or a descendant of the target with subtree.
+func matchTag(tag, target string, subtree bool) bool {
+	return tag == target || (subtree && strings.HasPrefix(tag, target+"/"))
+}
+
+// isValidTag returns true if the tag can be written as "#tag" in the content of a memo, and memos can be found by it.
+func isValidTag(tag string) bool {
+	return tag != "" && utf8.RuneCountInString(tag) <= store.MaxMemoTagLength && !strings.HasPrefix(tag, "/") && !strings.HasSuffix(tag, "/") && !strings.ContainsAny(tag, " \t\r\n#")
+}
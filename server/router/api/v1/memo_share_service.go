@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/usememos/memos/internal/util"
+	v1pb "github.com/usememos/memos/proto/gen/api/v1"
+	"github.com/usememos/memos/store"
+)
+
+// memoShareTokenLength is the length of share tokens, 32 letters and digits are about 190 random bits.
+const memoShareTokenLength = 32
+
+func (s *APIV1Service) CreateMemoShare(ctx context.Context, request *v1pb.CreateMemoShareRequest) (*v1pb.MemoShare, error) {
+	memoUID, err := ExtractMemoUIDFromName(request.Parent)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
+	}
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessReveal)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get current user")
+	}
+
+	create := &store.MemoShare{
+		MemoID:    memo.ID,
+		CreatorID: user.ID,
+	}
+	if expireTime := request.MemoShare.GetExpireTime(); expireTime != nil {
+		if !expireTime.AsTime().After(time.Now()) {
+			return nil, status.Errorf(codes.InvalidArgument, "expire time must be in the future")
+		}
+		create.ExpiresTs = expireTime.AsTime().Unix()
+	}
+	create.Token, err = util.RandomString(memoShareTokenLength)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate share token: %v", err)
+	}
+	share, err := s.Store.CreateMemoShare(ctx, create)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create memo share: %v", err)
+	}
+	return convertMemoShareFromStore(memo, share), nil
+}
+
+func (s *APIV1Service) ListMemoShares(ctx context.Context, request *v1pb.ListMemoSharesRequest) (*v1pb.ListMemoSharesResponse, error) {
+	memoUID, err := ExtractMemoUIDFromName(request.Parent)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
+	}
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessReveal)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := s.Store.ListMemoShares(ctx, &store.FindMemoShare{MemoID: &memo.ID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list memo shares: %v", err)
+	}
+	response := &v1pb.ListMemoSharesResponse{
+		MemoShares: []*v1pb.MemoShare{},
+	}
+	for _, share := range shares {
+		response.MemoShares = append(response.MemoShares, convertMemoShareFromStore(memo, share))
+	}
+	return response, nil
+}
+
+func (s *APIV1Service) RevokeMemoShare(ctx context.Context, request *v1pb.RevokeMemoShareRequest) (*emptypb.Empty, error) {
+	memoUID, shareID, err := ExtractMemoShareFromName(request.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid share name: %v", err)
+	}
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessOwner)
+	if err != nil {
+		return nil, err
+	}
+	share, err := s.Store.GetMemoShare(ctx, &store.FindMemoShare{ID: &shareID, MemoID: &memo.ID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get memo share: %v", err)
+	}
+	if share == nil {
+		return nil, status.Errorf(codes.NotFound, "share not found")
+	}
+
+	if err := s.Store.DeleteMemoShares(ctx, &store.DeleteMemoShare{ID: &share.ID}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete memo share: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *APIV1Service) GetSharedMemo(ctx context.Context, request *v1pb.GetSharedMemoRequest) (*v1pb.Memo, error) {
+	share, err := s.getMemoShareByToken(ctx, request.Token)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get memo share: %v", err)
+	}
+	// Unknown, revoked and expired tokens are not told apart.
+	if share == nil {
+		return nil, status.Errorf(codes.NotFound, "share not found")
+	}
+	memo, err := s.Store.GetMemo(ctx, &store.FindMemo{ID: &share.MemoID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get memo: %v", err)
+	}
+	// Scheduled memos are not published yet, so they are not shared either.
+	if memo == nil || memo.RowStatus != store.Normal || memo.ScheduledTs != 0 {
+		return nil, status.Errorf(codes.NotFound, "share not found")
+	}
+
+	if err := s.Store.UpdateMemoShare(ctx, &store.UpdateMemoShare{ID: share.ID, IncreaseViewCount: true}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update memo share: %v", err)
+	}
+	memoMessage, err := s.convertMemoFromStore(ctx, memo)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert memo: %v", err)
+	}
+	// Only the memo is shared, the memos it relates to may not be visible to the viewer.
+	memoMessage.Relations = nil
+	return memoMessage, nil
+}
+
+// getMemoShareByToken returns the share with the token, or nil if there is none or it expired.
+func (s *APIV1Service) getMemoShareByToken(ctx context.Context, token string) (*store.MemoShare, error) {
+	if token == "" {
+		return nil, nil
+	}
+	share, err := s.Store.GetMemoShare(ctx, &store.FindMemoShare{Token: &token})
+	if err != nil {
+		return nil, err
+	}
+	if share == nil || (share.ExpiresTs != 0 && share.ExpiresTs <= time.Now().Unix()) {
+		return nil, nil
+	}
+	return share, nil
+}
+
+func convertMemoShareFromStore(memo *store.Memo, share *store.MemoShare) *v1pb.MemoShare {
+	memoShare := &v1pb.MemoShare{
+		Name:       fmt.Sprintf("%s%s/%s%d", MemoNamePrefix, memo.UID, ShareNamePrefix, share.ID),
+		Token:      share.Token,
+		CreateTime: timestamppb.New(time.Unix(share.CreatedTs, 0)),
+		ViewCount:  share.ViewCount,
+	}
+	if share.ExpiresTs != 0 {
+		memoShare.ExpireTime = timestamppb.New(time.Unix(share.ExpiresTs, 0))
+	}
+	return memoShare
+}
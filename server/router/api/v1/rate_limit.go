@@ -0,0 +1,263 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/usememos/memos/plugin/ratelimit"
+	storepb "github.com/usememos/memos/proto/gen/store"
+	"github.com/usememos/memos/server/profile"
+	"github.com/usememos/memos/store"
+)
+
+const (
+	// retryAfterHeader is the header that tells clients how many seconds to wait before they retry.
+	retryAfterHeader = "retry-after"
+	// maxLockoutDuration bounds the lockout that repeated failed sign-ins double.
+	maxLockoutDuration = time.Hour
+)
+
+// RateLimiter limits the rate of the authentication and write requests, and locks accounts out of
+// signing in after repeated failures, following the workspace rate limit setting. The limits are
+// kept in memory, or in the database if the setting persists them, which updates them atomically so
+// that the instances sharing the database share the limits.
+type RateLimiter struct {
+	Store *store.Store
+
+	memoryLimiter   *ratelimit.Limiter
+	databaseLimiter *ratelimit.Limiter
+}
+
+// NewRateLimiter returns a new rate limiter.
+func NewRateLimiter(store *store.Store) *RateLimiter {
+	return &RateLimiter{
+		Store:           store,
+		memoryLimiter:   ratelimit.NewLimiter(ratelimit.NewMemoryStore()),
+		databaseLimiter: ratelimit.NewLimiter(&rateLimitDatabaseStore{Store: store}),
+	}
+}
+
+// RateLimitInterceptor is the unary interceptor that rate limits the gRPC API. It runs after the auth
+// interceptor, so that the requests of users are limited per user.
+func (r *RateLimiter) RateLimitInterceptor(ctx context.Context, request any, serverInfo *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	class, ok := getMethodRateLimitClass(serverInfo.FullMethod)
+	if !ok {
+		return handler(ctx, request)
+	}
+	setting, err := r.Store.GetWorkspaceRateLimitSetting(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get workspace rate limit setting: %v", err)
+	}
+	if setting.Disabled {
+		return handler(ctx, request)
+	}
+
+	var key string
+	var limit *storepb.WorkspaceRateLimit
+	switch class {
+	case rateLimitClassAuth:
+		key, limit = "auth:ip:"+getRateLimitIP(ctx, r.Store.Profile), setting.AuthLimit
+	case rateLimitClassWrite:
+		if username, ok := ctx.Value(usernameContextKey).(string); ok {
+			key = "write:user:" + username
+		} else {
+			key = "write:ip:" + getRateLimitIP(ctx, r.Store.Profile)
+		}
+		limit = setting.WriteLimit
+	}
+	retryAfter, err := r.getLimiter(setting).Allow(ctx, key, ratelimit.Limit{
+		RequestsPerMinute: limit.GetRequestsPerMinute(),
+		Burst:             limit.GetBurst(),
+	})
+	if err != nil {
+		// Rate limiting must not take the API down with the database, so failures let requests through.
+		slog.Warn("failed to rate limit request", slog.String("method", serverInfo.FullMethod), slog.Any("err", err))
+		return handler(ctx, request)
+	}
+	if retryAfter > 0 {
+		return nil, newResourceExhaustedError(ctx, "too many requests", retryAfter)
+	}
+	return handler(ctx, request)
+}
+
+// checkSignInLockout returns a RESOURCE_EXHAUSTED error if the account of the username is locked out
+// of signing in.
+func (r *RateLimiter) checkSignInLockout(ctx context.Context, username string) error {
+	setting, err := r.Store.GetWorkspaceRateLimitSetting(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get workspace rate limit setting: %v", err)
+	}
+	if setting.Disabled {
+		return nil
+	}
+	// Unlike the rate limits, the lockout fails closed, since letting sign-ins through would let an
+	// attacker guess passwords whenever the database fails.
+	lockedOut, err := r.getLimiter(setting).LockedOut(ctx, getSignInLockoutKey(username))
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get sign-in lockout: %v", err)
+	}
+	if lockedOut > 0 {
+		return newResourceExhaustedError(ctx, "too many failed sign-ins", lockedOut)
+	}
+	return nil
+}
+
+// recordSignInFailure records a failed sign-in to the account of the username, which locks the
+// account out once the failures in a row reach the lockout threshold.
+func (r *RateLimiter) recordSignInFailure(ctx context.Context, username string) {
+	setting, err := r.Store.GetWorkspaceRateLimitSetting(ctx)
+	if err != nil || setting.Disabled {
+		return
+	}
+	lockedOut, err := r.getLimiter(setting).Fail(ctx, getSignInLockoutKey(username), ratelimit.Lockout{
+		Threshold:   setting.LockoutThreshold,
+		Duration:    time.Duration(setting.LockoutSeconds) * time.Second,
+		MaxDuration: maxLockoutDuration,
+	})
+	if err != nil {
+		slog.Warn("failed to record failed sign-in", slog.Any("err", err))
+		return
+	}
+	if lockedOut > 0 {
+		slog.Warn("account locked out of signing in", slog.String("username", username), slog.Duration("duration", lockedOut))
+	}
+}
+
+// resetSignInFailures forgets the failed sign-ins to the account of the username.
+func (r *RateLimiter) resetSignInFailures(ctx context.Context, username string) {
+	setting, err := r.Store.GetWorkspaceRateLimitSetting(ctx)
+	if err != nil || setting.Disabled {
+		return
+	}
+	if err := r.getLimiter(setting).Reset(ctx, getSignInLockoutKey(username)); err != nil {
+		slog.Warn("failed to reset failed sign-ins", slog.Any("err", err))
+	}
+}
+
+// Prune forgets the rate limits and the failed sign-ins that have not changed for a day.
+func (r *RateLimiter) Prune(ctx context.Context) {
+	if err := r.memoryLimiter.Prune(ctx); err != nil {
+		slog.Error("failed to prune rate limits", "err", err)
+	}
+	if err := r.databaseLimiter.Prune(ctx); err != nil {
+		slog.Error("failed to prune rate limits", "err", err)
+	}
+}
+
+func (r *RateLimiter) getLimiter(setting *storepb.WorkspaceRateLimitSetting) *ratelimit.Limiter {
+	if setting.Persist {
+		return r.databaseLimiter
+	}
+	return r.memoryLimiter
+}
+
+// getRateLimitIP returns the client IP that rate limits are keyed by. IPv6 clients are keyed by their
+// /64 network, since a client usually has the whole network and could rotate addresses to escape limits.
+func getRateLimitIP(ctx context.Context, profile *profile.Profile) string {
+	ip := getClientIP(ctx, profile)
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	if addr = addr.Unmap().WithZone(""); addr.Is4() {
+		return addr.String()
+	}
+	return netip.PrefixFrom(addr, 64).Masked().String()
+}
+
+// getSignInLockoutKey returns the lockout key of the account of the username. It is not keyed by
+// client IP, so that an attacker cannot escape the lockout by changing addresses.
+func getSignInLockoutKey(username string) string {
+	return "signin:" + strings.ToLower(username)
+}
+
+// newResourceExhaustedError returns a RESOURCE_EXHAUSTED error that tells the client when to retry,
+// both in the retry info details and in the retry-after header, which the gateway forwards as the
+// Retry-After HTTP header.
+func newResourceExhaustedError(ctx context.Context, message string, retryAfter time.Duration) error {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	if err := grpc.SetHeader(ctx, metadata.Pairs(retryAfterHeader, strconv.FormatInt(seconds, 10))); err != nil {
+		slog.Warn("failed to set retry-after header", slog.Any("err", err))
+	}
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("%s, retry after %d seconds", message, seconds))
+	if withDetails, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(time.Duration(seconds) * time.Second),
+	}); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// rateLimitDatabaseStore keeps the states of the rate limits in the database.
+type rateLimitDatabaseStore struct {
+	Store *store.Store
+}
+
+func (s *rateLimitDatabaseStore) Get(ctx context.Context, key string) (*ratelimit.State, error) {
+	rateLimit, err := s.Store.GetRateLimit(ctx, &store.FindRateLimit{Key: &key})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rate limit")
+	}
+	if rateLimit == nil {
+		return nil, nil
+	}
+	state := &ratelimit.State{
+		Tokens:      rateLimit.Tokens,
+		Failures:    rateLimit.Failures,
+		UpdatedTime: time.UnixMilli(rateLimit.UpdatedMs),
+	}
+	if rateLimit.LockedUntilTs > 0 {
+		state.LockedUntil = time.Unix(rateLimit.LockedUntilTs, 0)
+	}
+	return state, nil
+}
+
+func (s *rateLimitDatabaseStore) Take(ctx context.Context, key string, limit ratelimit.Limit, now time.Time) (bool, error) {
+	ok, err := s.Store.TakeRateLimitToken(ctx, &store.TakeRateLimitToken{
+		Key:         key,
+		Burst:       float64(limit.Burst),
+		TokensPerMs: float64(limit.RequestsPerMinute) / float64(time.Minute.Milliseconds()),
+		NowMs:       now.UnixMilli(),
+	})
+	return ok, errors.Wrap(err, "failed to take rate limit token")
+}
+
+func (s *rateLimitDatabaseStore) Fail(ctx context.Context, key string, now time.Time, window time.Duration) (int32, error) {
+	failures, err := s.Store.IncreaseRateLimitFailures(ctx, &store.IncreaseRateLimitFailures{
+		Key:      key,
+		NowMs:    now.UnixMilli(),
+		WindowMs: window.Milliseconds(),
+	})
+	return failures, errors.Wrap(err, "failed to increase rate limit failures")
+}
+
+func (s *rateLimitDatabaseStore) Lock(ctx context.Context, key string, until time.Time) error {
+	return errors.Wrap(s.Store.LockRateLimit(ctx, &store.LockRateLimit{
+		Key: key,
+		// Round up, so that the lockout does not end early.
+		LockedUntilTs: until.Add(time.Second - time.Nanosecond).Unix(),
+	}), "failed to lock rate limit")
+}
+
+func (s *rateLimitDatabaseStore) Delete(ctx context.Context, key string) error {
+	return errors.Wrap(s.Store.DeleteRateLimits(ctx, &store.DeleteRateLimit{Key: &key}), "failed to delete rate limit")
+}
+
+func (s *rateLimitDatabaseStore) Prune(ctx context.Context, before time.Time) error {
+	updatedMsBefore := before.UnixMilli()
+	return errors.Wrap(s.Store.DeleteRateLimits(ctx, &store.DeleteRateLimit{UpdatedMsBefore: &updatedMsBefore}), "failed to delete rate limits")
+}
@@ -3,37 +3,67 @@ package v1
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/usememos/memos/plugin/webhook"
 	v1pb "github.com/usememos/memos/proto/gen/api/v1"
 	"github.com/usememos/memos/store"
 )
 
 func (s *APIV1Service) ListMemoReactions(ctx context.Context, request *v1pb.ListMemoReactionsRequest) (*v1pb.ListMemoReactionsResponse, error) {
+	memoUID, err := ExtractMemoUIDFromName(request.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid memo name: %v", err)
+	}
+	memo, err := s.getMemoWithAccess(ctx, memoUID, memoAccessRead)
+	if err != nil {
+		return nil, err
+	}
+	reactions, err := s.listMemoReactions(ctx, memo)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list reactions: %v", err)
+	}
+	return &v1pb.ListMemoReactionsResponse{
+		Reactions: reactions,
+	}, nil
+}
+
+// listMemoReactions lists the reactions to the memo without checking the access to it.
+func (s *APIV1Service) listMemoReactions(ctx context.Context, memo *store.Memo) ([]*v1pb.Reaction, error) {
+	contentID := fmt.Sprintf("%s%s", MemoNamePrefix, memo.UID)
 	reactions, err := s.Store.ListReactions(ctx, &store.FindReaction{
-		ContentID: &request.Name,
+		ContentID: &contentID,
 	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to list reactions")
+		return nil, err
 	}
 
-	response := &v1pb.ListMemoReactionsResponse{
-		Reactions: []*v1pb.Reaction{},
-	}
+	list := []*v1pb.Reaction{}
 	for _, reaction := range reactions {
 		reactionMessage, err := s.convertReactionFromStore(ctx, reaction)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to convert reaction")
+			return nil, errors.Wrap(err, "failed to convert reaction")
 		}
-		response.Reactions = append(response.Reactions, reactionMessage)
+		list = append(list, reactionMessage)
 	}
-	return response, nil
+	return list, nil
 }
 
 func (s *APIV1Service) UpsertMemoReaction(ctx context.Context, request *v1pb.UpsertMemoReactionRequest) (*v1pb.Reaction, error) {
+	memoUID, err := ExtractMemoUIDFromName(request.Reaction.ContentId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid content id: %v", err)
+	}
+	if _, err := s.getMemoWithAccess(ctx, memoUID, memoAccessRead); err != nil {
+		return nil, err
+	}
 	user, err := s.GetCurrentUser(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get current user")
@@ -51,19 +81,70 @@ func (s *APIV1Service) UpsertMemoReaction(ctx context.Context, request *v1pb.Ups
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to convert reaction")
 	}
+	// Try to dispatch webhook when reaction is created.
+	if err := s.dispatchReactionRelatedWebhook(ctx, reactionMessage, webhook.EventReactionCreated); err != nil {
+		slog.Warn("Failed to dispatch reaction created webhook", slog.Any("err", err))
+	}
 	return reactionMessage, nil
 }
 
 func (s *APIV1Service) DeleteMemoReaction(ctx context.Context, request *v1pb.DeleteMemoReactionRequest) (*emptypb.Empty, error) {
+	reaction, err := s.Store.GetReaction(ctx, &store.FindReaction{
+		ID: &request.Id,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get reaction")
+	}
+	if reaction == nil {
+		return nil, status.Errorf(codes.NotFound, "reaction not found")
+	}
+	// Only the creator or the users who can manage memos can delete the reaction.
+	if err := s.Authorize(ctx, store.PermissionMemosManage, fmt.Sprintf("%s%d", UserNamePrefix, reaction.CreatorID)); err != nil {
+		return nil, err
+	}
+
 	if err := s.Store.DeleteReaction(ctx, &store.DeleteReaction{
 		ID: request.Id,
 	}); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to delete reaction")
 	}
 
+	if reactionMessage, err := s.convertReactionFromStore(ctx, reaction); err == nil {
+		// Try to dispatch webhook when reaction is deleted.
+		if err := s.dispatchReactionRelatedWebhook(ctx, reactionMessage, webhook.EventReactionDeleted); err != nil {
+			slog.Warn("Failed to dispatch reaction deleted webhook", slog.Any("err", err))
+		}
+	}
+
 	return &emptypb.Empty{}, nil
 }
 
+// dispatchReactionRelatedWebhook dispatches webhook to the creator of the memo reacted to.
+func (s *APIV1Service) dispatchReactionRelatedWebhook(ctx context.Context, reaction *v1pb.Reaction, activityType string) error {
+	memoUID, err := ExtractMemoUIDFromName(reaction.ContentId)
+	if err != nil {
+		return errors.Wrap(err, "invalid reaction content id")
+	}
+	memo, err := s.Store.GetMemo(ctx, &store.FindMemo{UID: &memoUID})
+	if err != nil {
+		return errors.Wrap(err, "failed to get memo")
+	}
+	if memo == nil {
+		return nil
+	}
+	memoMessage, err := s.convertMemoFromStore(ctx, memo)
+	if err != nil {
+		return errors.Wrap(err, "failed to convert memo")
+	}
+	return s.dispatchWebhookEvent(ctx, memo.CreatorID, &v1pb.WebhookRequestPayload{
+		ActivityType: activityType,
+		Creator:      reaction.Creator,
+		CreateTime:   timestamppb.New(time.Now()),
+		Memo:         memoMessage,
+		Reaction:     reaction,
+	})
+}
+
 func (s *APIV1Service) convertReactionFromStore(ctx context.Context, reaction *store.Reaction) (*v1pb.Reaction, error) {
 	creator, err := s.Store.GetUser(ctx, &store.FindUser{
 		ID: &reaction.CreatorID,
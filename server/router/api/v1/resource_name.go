@@ -17,6 +17,11 @@ const (
 	InboxNamePrefix            = "inboxes/"
 	IdentityProviderNamePrefix = "identityProviders/"
 	ActivityNamePrefix         = "activities/"
+	RevisionNamePrefix         = "revisions/"
+	ShareNamePrefix            = "shares/"
+	GroupNamePrefix            = "groups/"
+	RoleNamePrefix             = "roles/"
+	SessionNamePrefix          = "sessions/"
 )
 
 // GetNameParentTokens returns the tokens from a resource name.
@@ -60,6 +65,20 @@ func ExtractUserIDFromName(name string) (int32, error) {
 	return id, nil
 }
 
+// ExtractUserSessionFromName returns the user ID and the session ID from a resource name.
+// e.g., "users/1/sessions/uuid" -> 1, "uuid".
+func ExtractUserSessionFromName(name string) (int32, string, error) {
+	tokens, err := GetNameParentTokens(name, UserNamePrefix, SessionNamePrefix)
+	if err != nil {
+		return 0, "", err
+	}
+	id, err := util.ConvertStringToInt32(tokens[0])
+	if err != nil {
+		return 0, "", errors.Errorf("invalid user ID %q", tokens[0])
+	}
+	return id, tokens[1], nil
+}
+
 // ExtractMemoUIDFromName returns the memo UID from a resource name.
 // e.g., "memos/uuid" -> "uuid".
 func ExtractMemoUIDFromName(name string) (string, error) {
@@ -71,6 +90,34 @@ func ExtractMemoUIDFromName(name string) (string, error) {
 	return id, nil
 }
 
+// ExtractMemoRevisionFromName returns the memo UID and the revision ID from a resource name.
+// e.g., "memos/uuid/revisions/1" -> "uuid", 1.
+func ExtractMemoRevisionFromName(name string) (string, int32, error) {
+	tokens, err := GetNameParentTokens(name, MemoNamePrefix, RevisionNamePrefix)
+	if err != nil {
+		return "", 0, err
+	}
+	id, err := util.ConvertStringToInt32(tokens[1])
+	if err != nil {
+		return "", 0, errors.Errorf("invalid revision ID %q", tokens[1])
+	}
+	return tokens[0], id, nil
+}
+
+// ExtractMemoShareFromName returns the memo UID and the share ID from a resource name.
+// e.g., "memos/uuid/shares/1" -> "uuid", 1.
+func ExtractMemoShareFromName(name string) (string, int32, error) {
+	tokens, err := GetNameParentTokens(name, MemoNamePrefix, ShareNamePrefix)
+	if err != nil {
+		return "", 0, err
+	}
+	id, err := util.ConvertStringToInt32(tokens[1])
+	if err != nil {
+		return "", 0, errors.Errorf("invalid share ID %q", tokens[1])
+	}
+	return tokens[0], id, nil
+}
+
 // ExtractResourceUIDFromName returns the resource UID from a resource name.
 func ExtractResourceUIDFromName(name string) (string, error) {
 	tokens, err := GetNameParentTokens(name, ResourceNamePrefix)
@@ -106,6 +153,34 @@ func ExtractIdentityProviderIDFromName(name string) (int32, error) {
 	return id, nil
 }
 
+// ExtractGroupIDFromName returns the group ID from a resource name.
+// e.g., "groups/1" -> 1.
+func ExtractGroupIDFromName(name string) (int32, error) {
+	tokens, err := GetNameParentTokens(name, GroupNamePrefix)
+	if err != nil {
+		return 0, err
+	}
+	id, err := util.ConvertStringToInt32(tokens[0])
+	if err != nil {
+		return 0, errors.Errorf("invalid group ID %q", tokens[0])
+	}
+	return id, nil
+}
+
+// ExtractRoleIDFromName returns the custom role ID from a resource name.
+// e.g., "roles/1" -> 1.
+func ExtractRoleIDFromName(name string) (int32, error) {
+	tokens, err := GetNameParentTokens(name, RoleNamePrefix)
+	if err != nil {
+		return 0, err
+	}
+	id, err := util.ConvertStringToInt32(tokens[0])
+	if err != nil {
+		return 0, errors.Errorf("invalid role ID %q", tokens[0])
+	}
+	return id, nil
+}
+
 func ExtractActivityIDFromName(name string) (int32, error) {
 	tokens, err := GetNameParentTokens(name, ActivityNamePrefix)
 	if err != nil {
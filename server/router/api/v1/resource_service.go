@@ -24,6 +24,7 @@ import (
 
 	"github.com/usememos/memos/internal/util"
 	"github.com/usememos/memos/plugin/storage/s3"
+	"github.com/usememos/memos/plugin/webhook"
 	v1pb "github.com/usememos/memos/proto/gen/api/v1"
 	storepb "github.com/usememos/memos/proto/gen/store"
 	"github.com/usememos/memos/store"
@@ -57,15 +58,11 @@ func (s *APIV1Service) CreateResource(ctx context.Context, request *v1pb.CreateR
 		Type:      request.Resource.Type,
 	}
 
-	workspaceStorageSetting, err := s.Store.GetWorkspaceStorageSetting(ctx)
+	uploadSizeLimit, err := s.getUploadSizeLimit(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get workspace storage setting: %v", err)
+		return nil, err
 	}
 	size := binary.Size(request.Resource.Content)
-	uploadSizeLimit := int(workspaceStorageSetting.UploadSizeLimitMb) * MebiByte
-	if uploadSizeLimit == 0 {
-		uploadSizeLimit = MaxUploadBufferSizeBytes
-	}
 	if size > uploadSizeLimit {
 		return nil, status.Errorf(codes.InvalidArgument, "file size exceeds the limit")
 	}
@@ -91,7 +88,30 @@ func (s *APIV1Service) CreateResource(ctx context.Context, request *v1pb.CreateR
 		return nil, status.Errorf(codes.Internal, "failed to create resource: %v", err)
 	}
 
-	return s.convertResourceFromStore(ctx, resource), nil
+	resourceMessage := s.convertResourceFromStore(ctx, resource)
+	// Try to dispatch webhook when resource is uploaded.
+	if err := s.dispatchWebhookEvent(ctx, user.ID, &v1pb.WebhookRequestPayload{
+		ActivityType: webhook.EventResourceCreated,
+		Creator:      fmt.Sprintf("%s%d", UserNamePrefix, user.ID),
+		CreateTime:   timestamppb.New(time.Now()),
+		Resource:     resourceMessage,
+	}); err != nil {
+		slog.Warn("Failed to dispatch resource created webhook", slog.Any("err", err))
+	}
+	return resourceMessage, nil
+}
+
+// getUploadSizeLimit returns the maximum size of a resource blob, following the workspace storage setting.
+func (s *APIV1Service) getUploadSizeLimit(ctx context.Context) (int, error) {
+	workspaceStorageSetting, err := s.Store.GetWorkspaceStorageSetting(ctx)
+	if err != nil {
+		return 0, status.Errorf(codes.Internal, "failed to get workspace storage setting: %v", err)
+	}
+	uploadSizeLimit := int(workspaceStorageSetting.UploadSizeLimitMb) * MebiByte
+	if uploadSizeLimit == 0 {
+		uploadSizeLimit = MaxUploadBufferSizeBytes
+	}
+	return uploadSizeLimit, nil
 }
 
 func (s *APIV1Service) ListResources(ctx context.Context, _ *v1pb.ListResourcesRequest) (*v1pb.ListResourcesResponse, error) {
@@ -125,6 +145,9 @@ func (s *APIV1Service) GetResource(ctx context.Context, request *v1pb.GetResourc
 	if resource == nil {
 		return nil, status.Errorf(codes.NotFound, "resource not found")
 	}
+	if err := s.checkResourceAccess(ctx, resource, memoAccessRead); err != nil {
+		return nil, err
+	}
 	return s.convertResourceFromStore(ctx, resource), nil
 }
 
@@ -151,16 +174,25 @@ func (s *APIV1Service) GetResourceBinary(ctx context.Context, request *v1pb.GetR
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to find memo by ID: %v", resource.MemoID)
 		}
-		if memo != nil && memo.Visibility != store.Public {
-			user, err := s.GetCurrentUser(ctx)
+		// The resources of a public memo are visible to anyone once it is published.
+		if memo != nil && (memo.Visibility != store.Public || memo.ScheduledTs != 0) {
+			// The resources of a memo shared with a link are visible with its token, once it is published
+			// and as long as it is not archived.
+			share, err := s.getMemoShareByToken(ctx, request.ShareToken)
 			if err != nil {
-				return nil, status.Errorf(codes.Internal, "failed to get current user: %v", err)
-			}
-			if user == nil {
-				return nil, status.Errorf(codes.Unauthenticated, "unauthorized access")
+				return nil, status.Errorf(codes.Internal, "failed to get memo share: %v", err)
 			}
-			if memo.Visibility == store.Private && user.ID != resource.CreatorID {
-				return nil, status.Errorf(codes.Unauthenticated, "unauthorized access")
+			if share == nil || share.MemoID != memo.ID || memo.ScheduledTs != 0 || memo.RowStatus != store.Normal {
+				user, err := s.GetCurrentUser(ctx)
+				if err != nil {
+					return nil, status.Errorf(codes.Internal, "failed to get current user: %v", err)
+				}
+				if user == nil {
+					return nil, status.Errorf(codes.Unauthenticated, "unauthorized access")
+				}
+				if err := s.checkMemoAccess(ctx, user, memo, memoAccessRead); err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
@@ -207,6 +239,12 @@ func (s *APIV1Service) UpdateResource(ctx context.Context, request *v1pb.UpdateR
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get resource: %v", err)
 	}
+	if resource == nil {
+		return nil, status.Errorf(codes.NotFound, "resource not found")
+	}
+	if err := s.checkResourceAccess(ctx, resource, memoAccessWrite); err != nil {
+		return nil, err
+	}
 
 	currentTs := time.Now().Unix()
 	update := &store.UpdateResource{
@@ -363,14 +401,14 @@ func SaveResourceBlob(ctx context.Context, s *store.Store, create *store.Resourc
 }
 
 func (s *APIV1Service) GetResourceBlob(resource *store.Resource) ([]byte, error) {
+	return getResourceBlob(s.Profile.Data, resource)
+}
+
+// getResourceBlob returns the blob of a resource stored locally or in the database.
+func getResourceBlob(dataDir string, resource *store.Resource) ([]byte, error) {
 	// For local storage, read the file from the local disk.
 	if resource.StorageType == storepb.ResourceStorageType_LOCAL {
-		resourcePath := filepath.FromSlash(resource.Reference)
-		if !filepath.IsAbs(resourcePath) {
-			resourcePath = filepath.Join(s.Profile.Data, resourcePath)
-		}
-
-		file, err := os.Open(resourcePath)
+		file, err := os.Open(getResourceLocalPath(dataDir, resource))
 		if err != nil {
 			if os.IsNotExist(err) {
 				return nil, errors.Wrap(err, "file not found")
@@ -388,6 +426,15 @@ func (s *APIV1Service) GetResourceBlob(resource *store.Resource) ([]byte, error)
 	return resource.Blob, nil
 }
 
+// getResourceLocalPath returns the path of the file of a resource stored locally, relative paths are in the data directory.
+func getResourceLocalPath(dataDir string, resource *store.Resource) string {
+	resourcePath := filepath.FromSlash(resource.Reference)
+	if !filepath.IsAbs(resourcePath) {
+		resourcePath = filepath.Join(dataDir, resourcePath)
+	}
+	return resourcePath
+}
+
 const (
 	// thumbnailRatio is the ratio of the thumbnail image.
 	thumbnailRatio = 0.8
@@ -0,0 +1,255 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1pb "github.com/usememos/memos/proto/gen/api/v1"
+	"github.com/usememos/memos/store"
+)
+
+func (*APIV1Service) ListPermissions(_ context.Context, _ *v1pb.ListPermissionsRequest) (*v1pb.ListPermissionsResponse, error) {
+	response := &v1pb.ListPermissionsResponse{
+		Permissions: []string{},
+	}
+	for _, permission := range store.Permissions {
+		response.Permissions = append(response.Permissions, string(permission))
+	}
+	return response, nil
+}
+
+func (s *APIV1Service) ListRoles(ctx context.Context, _ *v1pb.ListRolesRequest) (*v1pb.ListRolesResponse, error) {
+	roles, err := s.Store.ListCustomRoles(ctx, &store.FindCustomRole{})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list roles: %v", err)
+	}
+	response := &v1pb.ListRolesResponse{
+		Roles: []*v1pb.Role{},
+	}
+	for _, role := range roles {
+		roleMessage, err := s.convertRoleFromStore(ctx, role)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to convert role: %v", err)
+		}
+		response.Roles = append(response.Roles, roleMessage)
+	}
+	return response, nil
+}
+
+func (s *APIV1Service) GetRole(ctx context.Context, request *v1pb.GetRoleRequest) (*v1pb.Role, error) {
+	role, err := s.getRoleByName(ctx, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	roleMessage, err := s.convertRoleFromStore(ctx, role)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert role: %v", err)
+	}
+	return roleMessage, nil
+}
+
+func (s *APIV1Service) CreateRole(ctx context.Context, request *v1pb.CreateRoleRequest) (*v1pb.Role, error) {
+	if err := s.Authorize(ctx, store.PermissionRolesManage, ""); err != nil {
+		return nil, err
+	}
+	if request.Role == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "role is required")
+	}
+	title, err := s.validateRoleTitle(ctx, request.Role.Title, 0)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := convertPermissionsToStore(request.Role.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	memberIDs, err := s.extractMemberIDs(ctx, request.Role.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.Store.CreateCustomRole(ctx, &store.CustomRole{
+		Name:        title,
+		Description: request.Role.Description,
+		Permissions: permissions,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create role: %v", err)
+	}
+	if err := s.setRoleMembers(ctx, role.ID, memberIDs); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to set role members: %v", err)
+	}
+	roleMessage, err := s.convertRoleFromStore(ctx, role)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert role: %v", err)
+	}
+	return roleMessage, nil
+}
+
+func (s *APIV1Service) UpdateRole(ctx context.Context, request *v1pb.UpdateRoleRequest) (*v1pb.Role, error) {
+	if err := s.Authorize(ctx, store.PermissionRolesManage, ""); err != nil {
+		return nil, err
+	}
+	if request.Role == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "role is required")
+	}
+	if request.UpdateMask == nil || len(request.UpdateMask.Paths) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "update mask is required")
+	}
+	role, err := s.getRoleByName(ctx, request.Role.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &store.UpdateCustomRole{
+		ID: role.ID,
+	}
+	var memberIDs []int32
+	for _, path := range request.UpdateMask.Paths {
+		switch path {
+		case "title":
+			title, err := s.validateRoleTitle(ctx, request.Role.Title, role.ID)
+			if err != nil {
+				return nil, err
+			}
+			update.Name = &title
+		case "description":
+			update.Description = &request.Role.Description
+		case "permissions":
+			update.Permissions, err = convertPermissionsToStore(request.Role.Permissions)
+			if err != nil {
+				return nil, err
+			}
+		case "members":
+			memberIDs, err = s.extractMemberIDs(ctx, request.Role.Members)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "invalid update path: %s", path)
+		}
+	}
+
+	role, err = s.Store.UpdateCustomRole(ctx, update)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update role: %v", err)
+	}
+	if memberIDs != nil {
+		if err := s.setRoleMembers(ctx, role.ID, memberIDs); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to set role members: %v", err)
+		}
+	}
+	roleMessage, err := s.convertRoleFromStore(ctx, role)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert role: %v", err)
+	}
+	return roleMessage, nil
+}
+
+func (s *APIV1Service) DeleteRole(ctx context.Context, request *v1pb.DeleteRoleRequest) (*emptypb.Empty, error) {
+	if err := s.Authorize(ctx, store.PermissionRolesManage, ""); err != nil {
+		return nil, err
+	}
+	role, err := s.getRoleByName(ctx, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Store.DeleteCustomRole(ctx, &store.DeleteCustomRole{ID: role.ID}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete role: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *APIV1Service) getRoleByName(ctx context.Context, name string) (*store.CustomRole, error) {
+	roleID, err := ExtractRoleIDFromName(name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid role name: %v", err)
+	}
+	role, err := s.Store.GetCustomRole(ctx, &store.FindCustomRole{ID: &roleID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get role: %v", err)
+	}
+	if role == nil {
+		return nil, status.Errorf(codes.NotFound, "role not found")
+	}
+	return role, nil
+}
+
+// validateRoleTitle returns the trimmed title, and an error if it is empty, the name of a built-in role,
+// or taken by a role other than roleID.
+func (s *APIV1Service) validateRoleTitle(ctx context.Context, title string, roleID int32) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", status.Errorf(codes.InvalidArgument, "title is required")
+	}
+	for _, builtinRole := range []store.Role{store.RoleHost, store.RoleAdmin, store.RoleUser} {
+		if strings.EqualFold(title, builtinRole.String()) {
+			return "", status.Errorf(codes.InvalidArgument, "%q is a built-in role", title)
+		}
+	}
+	role, err := s.Store.GetCustomRole(ctx, &store.FindCustomRole{Name: &title})
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "failed to get role: %v", err)
+	}
+	if role != nil && role.ID != roleID {
+		return "", status.Errorf(codes.AlreadyExists, "role %q already exists", title)
+	}
+	return title, nil
+}
+
+func convertPermissionsToStore(permissions []string) ([]store.Permission, error) {
+	list := []store.Permission{}
+	for _, permission := range permissions {
+		if !slices.Contains(store.Permissions, store.Permission(permission)) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid permission %q", permission)
+		}
+		if !slices.Contains(list, store.Permission(permission)) {
+			list = append(list, store.Permission(permission))
+		}
+	}
+	return list, nil
+}
+
+// setRoleMembers replaces the members of the role.
+func (s *APIV1Service) setRoleMembers(ctx context.Context, roleID int32, memberIDs []int32) error {
+	if err := s.Store.DeleteCustomRoleMembers(ctx, &store.DeleteCustomRoleMember{RoleID: &roleID}); err != nil {
+		return err
+	}
+	for _, userID := range memberIDs {
+		if _, err := s.Store.UpsertCustomRoleMember(ctx, &store.CustomRoleMember{RoleID: roleID, UserID: userID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *APIV1Service) convertRoleFromStore(ctx context.Context, role *store.CustomRole) (*v1pb.Role, error) {
+	members, err := s.Store.ListCustomRoleMembers(ctx, &store.FindCustomRoleMember{RoleID: &role.ID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list role members")
+	}
+	roleMessage := &v1pb.Role{
+		Name:        fmt.Sprintf("%s%d", RoleNamePrefix, role.ID),
+		Title:       role.Name,
+		Description: role.Description,
+		Permissions: []string{},
+		CreateTime:  timestamppb.New(time.Unix(role.CreatedTs, 0)),
+		Members:     []string{},
+	}
+	for _, permission := range role.Permissions {
+		roleMessage.Permissions = append(roleMessage.Permissions, string(permission))
+	}
+	for _, member := range members {
+		roleMessage.Members = append(roleMessage.Members, fmt.Sprintf("%s%d", UserNamePrefix, member.UserID))
+	}
+	return roleMessage, nil
+}
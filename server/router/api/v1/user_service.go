@@ -27,12 +27,8 @@ import (
 )
 
 func (s *APIV1Service) ListUsers(ctx context.Context, _ *v1pb.ListUsersRequest) (*v1pb.ListUsersResponse, error) {
-	currentUser, err := s.GetCurrentUser(ctx)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
-	}
-	if currentUser.Role != store.RoleHost && currentUser.Role != store.RoleAdmin {
-		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	if err := s.Authorize(ctx, store.PermissionUsersManage, ""); err != nil {
+		return nil, err
 	}
 
 	users, err := s.Store.ListUsers(ctx, &store.FindUser{})
@@ -115,12 +111,14 @@ func (s *APIV1Service) GetUserAvatarBinary(ctx context.Context, request *v1pb.Ge
 }
 
 func (s *APIV1Service) CreateUser(ctx context.Context, request *v1pb.CreateUserRequest) (*v1pb.User, error) {
-	currentUser, err := s.GetCurrentUser(ctx)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	if err := s.Authorize(ctx, store.PermissionUsersManage, ""); err != nil {
+		return nil, err
 	}
-	if currentUser.Role != store.RoleHost {
-		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	role := convertUserRoleToStore(request.User.Role)
+	if role != store.RoleUser {
+		if err := s.Authorize(ctx, store.PermissionRolesManage, ""); err != nil {
+			return nil, err
+		}
 	}
 	if !util.UIDMatcher.MatchString(strings.ToLower(request.User.Username)) {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid username: %s", request.User.Username)
@@ -132,7 +130,7 @@ func (s *APIV1Service) CreateUser(ctx context.Context, request *v1pb.CreateUserR
 
 	user, err := s.Store.CreateUser(ctx, &store.User{
 		Username:     request.User.Username,
-		Role:         convertUserRoleToStore(request.User.Role),
+		Role:         role,
 		Email:        request.User.Email,
 		Nickname:     request.User.Nickname,
 		PasswordHash: string(passwordHash),
@@ -154,12 +152,8 @@ func (s *APIV1Service) UpdateUser(ctx context.Context, request *v1pb.UpdateUserR
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user name: %v", err)
 	}
-	currentUser, err := s.GetCurrentUser(ctx)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
-	}
-	if currentUser.ID != userID && currentUser.Role != store.RoleAdmin && currentUser.Role != store.RoleHost {
-		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	if err := s.Authorize(ctx, store.PermissionUsersManage, request.User.Name); err != nil {
+		return nil, err
 	}
 	if request.UpdateMask == nil || len(request.UpdateMask.Paths) == 0 {
 		return nil, status.Errorf(codes.InvalidArgument, "update mask is empty")
@@ -172,6 +166,9 @@ func (s *APIV1Service) UpdateUser(ctx context.Context, request *v1pb.UpdateUserR
 	if user == nil {
 		return nil, status.Errorf(codes.NotFound, "user not found")
 	}
+	if err := s.checkUserRank(ctx, user); err != nil {
+		return nil, err
+	}
 
 	currentTs := time.Now().Unix()
 	update := &store.UpdateUser{
@@ -199,6 +196,9 @@ func (s *APIV1Service) UpdateUser(ctx context.Context, request *v1pb.UpdateUserR
 		} else if field == "description" {
 			update.Description = &request.User.Description
 		} else if field == "role" {
+			if err := s.Authorize(ctx, store.PermissionRolesManage, ""); err != nil {
+				return nil, err
+			}
 			role := convertUserRoleToStore(request.User.Role)
 			update.Role = &role
 		} else if field == "password" {
@@ -229,12 +229,8 @@ func (s *APIV1Service) DeleteUser(ctx context.Context, request *v1pb.DeleteUserR
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user name: %v", err)
 	}
-	currentUser, err := s.GetCurrentUser(ctx)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
-	}
-	if currentUser.ID != userID && currentUser.Role != store.RoleAdmin && currentUser.Role != store.RoleHost {
-		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	if err := s.Authorize(ctx, store.PermissionUsersManage, request.Name); err != nil {
+		return nil, err
 	}
 
 	user, err := s.Store.GetUser(ctx, &store.FindUser{ID: &userID})
@@ -244,6 +240,9 @@ func (s *APIV1Service) DeleteUser(ctx context.Context, request *v1pb.DeleteUserR
 	if user == nil {
 		return nil, status.Errorf(codes.NotFound, "user not found")
 	}
+	if err := s.checkUserRank(ctx, user); err != nil {
+		return nil, err
+	}
 
 	if err := s.Store.DeleteUser(ctx, &store.DeleteUser{
 		ID: user.ID,
@@ -254,6 +253,26 @@ func (s *APIV1Service) DeleteUser(ctx context.Context, request *v1pb.DeleteUserR
 	return &emptypb.Empty{}, nil
 }
 
+// checkUserRank returns an error unless the current user is the user, the host, or has a higher role than
+// the user. Custom roles can grant the users.manage permission, which must not let their holders take over
+// the accounts of their peers or of the host.
+func (s *APIV1Service) checkUserRank(ctx context.Context, user *store.User) error {
+	currentUser, err := s.GetCurrentUser(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get current user: %v", err)
+	}
+	if currentUser == nil {
+		return status.Errorf(codes.PermissionDenied, "permission denied")
+	}
+	if currentUser.ID == user.ID || currentUser.Role == store.RoleHost {
+		return nil
+	}
+	if roleRanks[user.Role] >= roleRanks[currentUser.Role] {
+		return status.Errorf(codes.PermissionDenied, "permission denied: only the host can manage users with the same or a higher role")
+	}
+	return nil
+}
+
 func getDefaultUserSetting() *v1pb.UserSetting {
 	return &v1pb.UserSetting{
 		Locale:         "en",
@@ -282,6 +301,8 @@ func (s *APIV1Service) GetUserSetting(ctx context.Context, _ *v1pb.GetUserSettin
 			userSettingMessage.Appearance = setting.GetAppearance()
 		} else if setting.Key == storepb.UserSettingKey_MEMO_VISIBILITY {
 			userSettingMessage.MemoVisibility = setting.GetMemoVisibility()
+		} else if setting.Key == storepb.UserSettingKey_TWO_FACTOR_AUTH {
+			userSettingMessage.TwoFactorEnabled = setting.GetTwoFactorAuth().Enabled
 		}
 	}
 	return userSettingMessage, nil
@@ -377,15 +398,18 @@ func (s *APIV1Service) ListUserAccessTokens(ctx context.Context, request *v1pb.L
 			continue
 		}
 
-		userAccessToken := &v1pb.UserAccessToken{
-			AccessToken: userAccessToken.AccessToken,
-			Description: userAccessToken.Description,
-			IssuedAt:    timestamppb.New(claims.IssuedAt.Time),
+		accessToken := &v1pb.UserAccessToken{
+			AccessToken:  userAccessToken.AccessToken,
+			Description:  userAccessToken.Description,
+			IssuedAt:     timestamppb.New(claims.IssuedAt.Time),
+			Scopes:       userAccessToken.Scopes,
+			LastUsedTime: userAccessToken.LastUsedTime,
+			LastUsedIp:   userAccessToken.LastUsedIp,
 		}
 		if claims.ExpiresAt != nil {
-			userAccessToken.ExpiresAt = timestamppb.New(claims.ExpiresAt.Time)
+			accessToken.ExpiresAt = timestamppb.New(claims.ExpiresAt.Time)
 		}
-		accessTokens = append(accessTokens, userAccessToken)
+		accessTokens = append(accessTokens, accessToken)
 	}
 
 	// Sort by issued time in descending order.
@@ -414,6 +438,12 @@ func (s *APIV1Service) CreateUserAccessToken(ctx context.Context, request *v1pb.
 		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
 	}
 
+	for _, scope := range request.Scopes {
+		if !slices.Contains(accessTokenScopes, scope) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid scope %q", scope)
+		}
+	}
+
 	expiresAt := time.Time{}
 	if request.ExpiresAt != nil {
 		expiresAt = request.ExpiresAt.AsTime()
@@ -441,7 +471,11 @@ func (s *APIV1Service) CreateUserAccessToken(ctx context.Context, request *v1pb.
 	}
 
 	// Upsert the access token to user setting store.
-	if err := s.UpsertAccessTokenToStore(ctx, currentUser, accessToken, request.Description); err != nil {
+	if err := s.UpsertAccessTokenToStore(ctx, currentUser, &storepb.AccessTokensUserSetting_AccessToken{
+		AccessToken: accessToken,
+		Description: request.Description,
+		Scopes:      request.Scopes,
+	}); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to upsert access token to store: %v", err)
 	}
 
@@ -449,6 +483,7 @@ func (s *APIV1Service) CreateUserAccessToken(ctx context.Context, request *v1pb.
 		AccessToken: accessToken,
 		Description: request.Description,
 		IssuedAt:    timestamppb.New(claims.IssuedAt.Time),
+		Scopes:      request.Scopes,
 	}
 	if claims.ExpiresAt != nil {
 		userAccessToken.ExpiresAt = timestamppb.New(claims.ExpiresAt.Time)
@@ -472,52 +507,16 @@ func (s *APIV1Service) DeleteUserAccessToken(ctx context.Context, request *v1pb.
 		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
 	}
 
-	userAccessTokens, err := s.Store.GetUserAccessTokens(ctx, currentUser.ID)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to list access tokens: %v", err)
-	}
-	updatedUserAccessTokens := []*storepb.AccessTokensUserSetting_AccessToken{}
-	for _, userAccessToken := range userAccessTokens {
-		if userAccessToken.AccessToken == request.AccessToken {
-			continue
-		}
-		updatedUserAccessTokens = append(updatedUserAccessTokens, userAccessToken)
-	}
-	if _, err := s.Store.UpsertUserSetting(ctx, &storepb.UserSetting{
-		UserId: currentUser.ID,
-		Key:    storepb.UserSettingKey_ACCESS_TOKENS,
-		Value: &storepb.UserSetting_AccessTokens{
-			AccessTokens: &storepb.AccessTokensUserSetting{
-				AccessTokens: updatedUserAccessTokens,
-			},
-		},
-	}); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to upsert user setting: %v", err)
+	if err := s.Store.RemoveUserAccessToken(ctx, currentUser.ID, request.AccessToken); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to remove access token: %v", err)
 	}
 
 	return &emptypb.Empty{}, nil
 }
 
-func (s *APIV1Service) UpsertAccessTokenToStore(ctx context.Context, user *store.User, accessToken, description string) error {
-	userAccessTokens, err := s.Store.GetUserAccessTokens(ctx, user.ID)
-	if err != nil {
-		return errors.Wrap(err, "failed to get user access tokens")
-	}
-	userAccessToken := storepb.AccessTokensUserSetting_AccessToken{
-		AccessToken: accessToken,
-		Description: description,
-	}
-	userAccessTokens = append(userAccessTokens, &userAccessToken)
-	if _, err := s.Store.UpsertUserSetting(ctx, &storepb.UserSetting{
-		UserId: user.ID,
-		Key:    storepb.UserSettingKey_ACCESS_TOKENS,
-		Value: &storepb.UserSetting_AccessTokens{
-			AccessTokens: &storepb.AccessTokensUserSetting{
-				AccessTokens: userAccessTokens,
-			},
-		},
-	}); err != nil {
-		return errors.Wrap(err, "failed to upsert user setting")
+func (s *APIV1Service) UpsertAccessTokenToStore(ctx context.Context, user *store.User, userAccessToken *storepb.AccessTokensUserSetting_AccessToken) error {
+	if err := s.Store.AddUserAccessToken(ctx, user.ID, userAccessToken); err != nil {
+		return errors.Wrap(err, "failed to add user access token")
 	}
 	return nil
 }
@@ -0,0 +1,577 @@
+package v1
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/usememos/memos/internal/util"
+	"github.com/usememos/memos/plugin/archive"
+	"github.com/usememos/memos/plugin/storage/s3"
+	v1pb "github.com/usememos/memos/proto/gen/api/v1"
+	storepb "github.com/usememos/memos/proto/gen/store"
+	"github.com/usememos/memos/server/runner/memopayload"
+	"github.com/usememos/memos/store"
+)
+
+// exportUserDataMethod is the method that exporting user data counts as in the access control. It is served
+// by handleExportUserData rather than the gRPC server, as an HttpBody response would hold the whole archive.
+const exportUserDataMethod = "/memos.api.v1.UserService/ExportUserData"
+
+// handleExportUserData serves GET /api/v1/{name=users/*}:export, streaming the memos and resources of
+// the user as a zip archive.
+func (s *APIV1Service) handleExportUserData(gwMux *runtime.ServeMux) runtime.HandlerFunc {
+	authInterceptor := NewGRPCAuthInterceptor(s.Store, s.Secret)
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, outboundMarshaler := runtime.MarshalerForRequest(gwMux, r)
+		// The gateway error handler expects the metadata of a gRPC call.
+		r = r.WithContext(runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{}))
+		ctx, err := authInterceptor.AuthenticateHTTPRequest(r, exportUserDataMethod)
+		if err != nil {
+			runtime.HTTPError(r.Context(), gwMux, outboundMarshaler, w, r, err)
+			return
+		}
+		user, err := s.getUserWithDataAccess(ctx, pathParams["name"])
+		if err != nil {
+			runtime.HTTPError(ctx, gwMux, outboundMarshaler, w, r, err)
+			return
+		}
+		userArchive, err := ExportUserArchive(ctx, s.Store, user)
+		if err != nil {
+			runtime.HTTPError(ctx, gwMux, outboundMarshaler, w, r, status.Errorf(codes.Internal, "failed to export user data: %v", err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		// The response is already sent once the archive is being written, so errors can only be logged.
+		if err := archive.Write(w, userArchive); err != nil {
+			slog.Error("failed to write archive", slog.String("user", user.Username), slog.Any("err", err))
+		}
+	}
+}
+
+func (s *APIV1Service) ImportUserData(ctx context.Context, request *v1pb.ImportUserDataRequest) (*v1pb.ImportUserDataResponse, error) {
+	user, err := s.getUserWithDataAccess(ctx, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := s.getArchiveLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userArchive, err := archive.Read(bytes.NewReader(request.Archive), int64(len(request.Archive)), limits)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid archive: %v", err)
+	}
+	if err := s.checkArchiveResourceSizes(ctx, userArchive); err != nil {
+		return nil, err
+	}
+	result, err := ImportUserArchive(ctx, s.Store, user, userArchive)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to import user data: %v", err)
+	}
+	return &v1pb.ImportUserDataResponse{
+		ImportedMemos:     int32(result.ImportedMemos),
+		SkippedMemos:      int32(result.SkippedMemos),
+		ImportedResources: int32(result.ImportedResources),
+		SkippedResources:  int32(result.SkippedResources),
+	}, nil
+}
+
+func (s *APIV1Service) ImportUserNotes(ctx context.Context, request *v1pb.ImportUserNotesRequest) (*v1pb.ImportUserNotesResponse, error) {
+	user, err := s.getUserWithDataAccess(ctx, request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes *archive.Archive
+	switch request.Source {
+	case v1pb.ImportUserNotesRequest_OBSIDIAN, v1pb.ImportUserNotesRequest_GOOGLE_KEEP:
+		zipReader, err := zip.NewReader(bytes.NewReader(request.Data), int64(len(request.Data)))
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid zip: %v", err)
+		}
+		limits, err := s.getArchiveLimits(ctx)
+		if err != nil {
+			return nil, err
+		}
+		fsys := archive.LimitFS(zipReader, limits)
+		if request.Source == v1pb.ImportUserNotesRequest_OBSIDIAN {
+			notes, err = archive.ReadObsidianVault(fsys)
+		} else {
+			notes, err = archive.ReadGoogleKeepTakeout(fsys)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to read notes: %v", err)
+		}
+	case v1pb.ImportUserNotesRequest_EVERNOTE:
+		notes, err = archive.ReadEvernoteENEX(bytes.NewReader(request.Data))
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to read notes: %v", err)
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported source %s", request.Source)
+	}
+	if err := s.checkArchiveResourceSizes(ctx, notes); err != nil {
+		return nil, err
+	}
+
+	response := &v1pb.ImportUserNotesResponse{
+		Memos:     int32(len(notes.Memos)),
+		Resources: int32(len(notes.Resources)),
+		Relations: int32(CountArchiveRelations(notes)),
+		Warnings:  notes.Warnings,
+	}
+	if request.DryRun {
+		return response, nil
+	}
+	result, err := ImportUserArchive(ctx, s.Store, user, notes)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to import notes: %v", err)
+	}
+	response.Result = &v1pb.ImportUserDataResponse{
+		ImportedMemos:     int32(result.ImportedMemos),
+		SkippedMemos:      int32(result.SkippedMemos),
+		ImportedResources: int32(result.ImportedResources),
+		SkippedResources:  int32(result.SkippedResources),
+	}
+	return response, nil
+}
+
+// archiveUploadsLimit is the number of resource uploads that the files read from an uploaded archive can add up to.
+const archiveUploadsLimit = 16
+
+// getArchiveLimits returns the limits of reading the archives and notes that users upload, following the upload
+// size limit so that no file is read that would be too large to upload as a resource.
+func (s *APIV1Service) getArchiveLimits(ctx context.Context) (archive.Limits, error) {
+	uploadSizeLimit, err := s.getUploadSizeLimit(ctx)
+	if err != nil {
+		return archive.Limits{}, err
+	}
+	return archive.Limits{
+		MaxSize:     min(int64(uploadSizeLimit)*archiveUploadsLimit, archive.DefaultLimits.MaxSize),
+		MaxFileSize: int64(uploadSizeLimit),
+		MaxFiles:    archive.DefaultLimits.MaxFiles,
+	}, nil
+}
+
+// checkArchiveResourceSizes returns an error if a resource of the archive exceeds the upload size limit,
+// so that importing does not get around it.
+func (s *APIV1Service) checkArchiveResourceSizes(ctx context.Context, userArchive *archive.Archive) error {
+	uploadSizeLimit, err := s.getUploadSizeLimit(ctx)
+	if err != nil {
+		return err
+	}
+	for _, resource := range userArchive.Resources {
+		if len(resource.Blob) > uploadSizeLimit {
+			return status.Errorf(codes.InvalidArgument, "file size of resource %s exceeds the limit", resource.Filename)
+		}
+	}
+	return nil
+}
+
+// getUserWithDataAccess returns the user if the current user can export or import their data.
+func (s *APIV1Service) getUserWithDataAccess(ctx context.Context, name string) (*store.User, error) {
+	userID, err := ExtractUserIDFromName(name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user name: %v", err)
+	}
+	if err := s.Authorize(ctx, store.PermissionUsersManage, name); err != nil {
+		return nil, err
+	}
+	user, err := s.Store.GetUser(ctx, &store.FindUser{ID: &userID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+	if user == nil {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+	if err := s.checkUserRank(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ArchiveImportResult is the outcome of importing an archive.
+type ArchiveImportResult struct {
+	ImportedMemos     int
+	SkippedMemos      int
+	ImportedResources int
+	SkippedResources  int
+}
+
+// CountArchiveRelations returns the number of relations between the memos of an archive.
+func CountArchiveRelations(userArchive *archive.Archive) int {
+	count := 0
+	for _, memo := range userArchive.Memos {
+		count += len(memo.Relations)
+	}
+	return count
+}
+
+// ExportUserArchive returns the memos and resources created by the user as an archive.
+// The blobs of the resources are included regardless of where they are stored, and are read
+// one at a time while the archive is written.
+func ExportUserArchive(ctx context.Context, s *store.Store, user *store.User) (*archive.Archive, error) {
+	memos, err := s.ListMemos(ctx, &store.FindMemo{
+		CreatorID:      &user.ID,
+		OrderByTimeAsc: true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list memos")
+	}
+	memoUIDs := map[int32]string{}
+	for _, memo := range memos {
+		memoUIDs[memo.ID] = memo.UID
+	}
+	getMemoUID := func(id int32) (string, error) {
+		if uid, ok := memoUIDs[id]; ok {
+			return uid, nil
+		}
+		memo, err := s.GetMemo(ctx, &store.FindMemo{ID: &id, ExcludeContent: true})
+		if err != nil {
+			return "", err
+		}
+		if memo == nil {
+			return "", nil
+		}
+		memoUIDs[id] = memo.UID
+		return memo.UID, nil
+	}
+
+	resources, err := s.ListResources(ctx, &store.FindResource{
+		CreatorID: &user.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list resources")
+	}
+	resourceUIDs := map[int32][]string{}
+	userArchive := &archive.Archive{
+		ExportTime: time.Now().UTC(),
+		Username:   user.Username,
+		Memos:      []*archive.Memo{},
+		Resources:  []*archive.Resource{},
+	}
+	for _, resource := range resources {
+		archiveResource := &archive.Resource{
+			UID:        resource.UID,
+			Filename:   resource.Filename,
+			Type:       resource.Type,
+			Size:       resource.Size,
+			CreateTime: time.Unix(resource.CreatedTs, 0).UTC(),
+		}
+		if resource.MemoID != nil {
+			memoUID, err := getMemoUID(*resource.MemoID)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get memo")
+			}
+			archiveResource.Memo = memoUID
+			resourceUIDs[*resource.MemoID] = append(resourceUIDs[*resource.MemoID], resource.UID)
+		}
+		if resource.StorageType == storepb.ResourceStorageType_EXTERNAL {
+			archiveResource.ExternalLink = resource.Reference
+		} else {
+			archiveResource.Open = func() (io.ReadCloser, error) {
+				return openResourceBlob(ctx, s, resource)
+			}
+		}
+		userArchive.Resources = append(userArchive.Resources, archiveResource)
+	}
+
+	for _, memo := range memos {
+		archiveMemo := &archive.Memo{
+			UID:        memo.UID,
+			Visibility: memo.Visibility.String(),
+			State:      memo.RowStatus.String(),
+			Pinned:     memo.Pinned,
+			CreateTime: time.Unix(memo.CreatedTs, 0).UTC(),
+			UpdateTime: time.Unix(memo.UpdatedTs, 0).UTC(),
+			Resources:  resourceUIDs[memo.ID],
+			Content:    memo.Content,
+		}
+		if memo.Payload != nil {
+			archiveMemo.Tags = memo.Payload.Tags
+			if location := memo.Payload.Location; location != nil {
+				archiveMemo.Location = &archive.Location{
+					Placeholder: location.Placeholder,
+					Latitude:    location.Latitude,
+					Longitude:   location.Longitude,
+				}
+			}
+		}
+		if memo.ScheduledTs != 0 {
+			scheduleTime := time.Unix(memo.ScheduledTs, 0).UTC()
+			archiveMemo.ScheduleTime = &scheduleTime
+		}
+		relations, err := s.ListMemoRelations(ctx, &store.FindMemoRelation{MemoID: &memo.ID})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list memo relations")
+		}
+		for _, relation := range relations {
+			relatedMemoUID, err := getMemoUID(relation.RelatedMemoID)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get memo")
+			}
+			if relatedMemoUID == "" {
+				continue
+			}
+			archiveMemo.Relations = append(archiveMemo.Relations, &archive.Relation{
+				Memo: relatedMemoUID,
+				Type: string(relation.Type),
+			})
+		}
+		userArchive.Memos = append(userArchive.Memos, archiveMemo)
+	}
+	return userArchive, nil
+}
+
+// ImportUserArchive creates the memos and resources of the archive for the user.
+//
+// Memos and resources keep their UID unless it is taken by another user, in which case a UID derived
+// from it is used. Those that the user already has are skipped, so importing an archive again is a no-op.
+// Relations are remapped to the imported memos, or kept if the related memo exists outside the archive.
+func ImportUserArchive(ctx context.Context, s *store.Store, user *store.User, userArchive *archive.Archive) (*ArchiveImportResult, error) {
+	result := &ArchiveImportResult{}
+	memoIDs := map[string]int32{}
+	for _, archiveMemo := range userArchive.Memos {
+		uid, existing, err := resolveImportUID(user.ID, archiveMemo.UID, func(uid string) (int32, int32, error) {
+			memo, err := s.GetMemo(ctx, &store.FindMemo{UID: &uid, ExcludeContent: true})
+			if err != nil || memo == nil {
+				return 0, 0, err
+			}
+			return memo.ID, memo.CreatorID, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if existing != 0 {
+			memoIDs[archiveMemo.UID] = existing
+			result.SkippedMemos++
+			continue
+		}
+		memo, err := importMemo(ctx, s, user, uid, archiveMemo)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to import memo %s", archiveMemo.UID)
+		}
+		memoIDs[archiveMemo.UID] = memo.ID
+		result.ImportedMemos++
+	}
+
+	for _, archiveMemo := range userArchive.Memos {
+		for _, relation := range archiveMemo.Relations {
+			relationType := store.MemoRelationType(relation.Type)
+			if relationType != store.MemoRelationReference && relationType != store.MemoRelationComment {
+				continue
+			}
+			relatedMemoID, ok := memoIDs[relation.Memo]
+			if !ok {
+				relatedMemo, err := s.GetMemo(ctx, &store.FindMemo{UID: &relation.Memo, ExcludeContent: true})
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to get memo")
+				}
+				if relatedMemo == nil {
+					continue
+				}
+				relatedMemoID = relatedMemo.ID
+			}
+			memoID := memoIDs[archiveMemo.UID]
+			relations, err := s.ListMemoRelations(ctx, &store.FindMemoRelation{
+				MemoID:        &memoID,
+				RelatedMemoID: &relatedMemoID,
+				Type:          &relationType,
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list memo relations")
+			}
+			if len(relations) > 0 {
+				continue
+			}
+			if _, err := s.UpsertMemoRelation(ctx, &store.MemoRelation{
+				MemoID:        memoID,
+				RelatedMemoID: relatedMemoID,
+				Type:          relationType,
+			}); err != nil {
+				return nil, errors.Wrap(err, "failed to upsert memo relation")
+			}
+		}
+	}
+
+	for _, archiveResource := range userArchive.Resources {
+		uid, existing, err := resolveImportUID(user.ID, archiveResource.UID, func(uid string) (int32, int32, error) {
+			resource, err := s.GetResource(ctx, &store.FindResource{UID: &uid})
+			if err != nil || resource == nil {
+				return 0, 0, err
+			}
+			return resource.ID, resource.CreatorID, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if existing != 0 {
+			result.SkippedResources++
+			continue
+		}
+		create := &store.Resource{
+			UID:       uid,
+			CreatorID: user.ID,
+			Filename:  archiveResource.Filename,
+			Type:      archiveResource.Type,
+		}
+		if memoID, ok := memoIDs[archiveResource.Memo]; ok {
+			create.MemoID = &memoID
+		}
+		if archiveResource.ExternalLink != "" {
+			create.StorageType = storepb.ResourceStorageType_EXTERNAL
+			create.Reference = archiveResource.ExternalLink
+		} else {
+			create.Blob = archiveResource.Blob
+			create.Size = int64(len(archiveResource.Blob))
+			if err := SaveResourceBlob(ctx, s, create); err != nil {
+				return nil, errors.Wrapf(err, "failed to save resource %s", archiveResource.UID)
+			}
+		}
+		if _, err := s.CreateResource(ctx, create); err != nil {
+			return nil, errors.Wrapf(err, "failed to import resource %s", archiveResource.UID)
+		}
+		result.ImportedResources++
+	}
+	return result, nil
+}
+
+func importMemo(ctx context.Context, s *store.Store, user *store.User, uid string, archiveMemo *archive.Memo) (*store.Memo, error) {
+	create := &store.Memo{
+		UID:        uid,
+		CreatorID:  user.ID,
+		Content:    archiveMemo.Content,
+		Visibility: store.Private,
+	}
+	if visibility := store.Visibility(archiveMemo.Visibility); slices.Contains([]store.Visibility{store.Public, store.Protected, store.Private}, visibility) {
+		create.Visibility = visibility
+	}
+	if err := memopayload.RebuildMemoPayload(create); err != nil {
+		return nil, errors.Wrap(err, "failed to rebuild memo payload")
+	}
+	if location := archiveMemo.Location; location != nil {
+		create.Payload.Location = &storepb.MemoPayload_Location{
+			Placeholder: location.Placeholder,
+			Latitude:    location.Latitude,
+			Longitude:   location.Longitude,
+		}
+	}
+	if archiveMemo.ScheduleTime != nil && archiveMemo.ScheduleTime.After(time.Now()) {
+		create.ScheduledTs = archiveMemo.ScheduleTime.Unix()
+	}
+	memo, err := s.CreateMemo(ctx, create)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &store.UpdateMemo{
+		ID:     memo.ID,
+		Pinned: &archiveMemo.Pinned,
+	}
+	if !archiveMemo.CreateTime.IsZero() {
+		createdTs := archiveMemo.CreateTime.Unix()
+		update.CreatedTs = &createdTs
+	}
+	if !archiveMemo.UpdateTime.IsZero() {
+		updatedTs := archiveMemo.UpdateTime.Unix()
+		update.UpdatedTs = &updatedTs
+	}
+	if store.RowStatus(archiveMemo.State) == store.Archived {
+		rowStatus := store.Archived
+		update.RowStatus = &rowStatus
+	}
+	if err := s.UpdateMemo(ctx, update); err != nil {
+		return nil, err
+	}
+	return memo, nil
+}
+
+// resolveImportUID returns the UID to import an archived memo or resource with.
+// lookup returns the ID and creator of the entity with a UID, 0 if there is none.
+// If the user already has the entity, its ID is returned as well.
+func resolveImportUID(userID int32, uid string, lookup func(uid string) (int32, int32, error)) (string, int32, error) {
+	candidates := []string{deriveImportUID(userID, uid)}
+	if util.UIDMatcher.MatchString(uid) {
+		candidates = append([]string{uid}, candidates...)
+	}
+	for _, candidate := range candidates {
+		id, creatorID, err := lookup(candidate)
+		if err != nil {
+			return "", 0, err
+		}
+		if id == 0 {
+			return candidate, 0, nil
+		}
+		if creatorID == userID {
+			return candidate, id, nil
+		}
+	}
+	return "", 0, errors.Errorf("uid %q is taken", uid)
+}
+
+// deriveImportUID derives a UID for importing an entity whose UID is taken or invalid.
+// It is stable, so importing the same archive again maps to the same UID.
+func deriveImportUID(userID int32, uid string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d/%s", userID, uid)))
+	return hex.EncodeToString(hash[:])[:32]
+}
+
+// openResourceBlob opens the blob of a resource from the storage it is kept in, the caller must close it.
+func openResourceBlob(ctx context.Context, s *store.Store, resource *store.Resource) (io.ReadCloser, error) {
+	switch resource.StorageType {
+	case storepb.ResourceStorageType_LOCAL:
+		file, err := os.Open(getResourceLocalPath(s.Profile.Data, resource))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open the file")
+		}
+		return file, nil
+	case storepb.ResourceStorageType_S3:
+		s3Object := resource.Payload.GetS3Object()
+		if s3Object == nil {
+			return nil, errors.New("missing s3 object")
+		}
+		s3Config := s3Object.S3Config
+		if s3Config == nil {
+			workspaceStorageSetting, err := s.GetWorkspaceStorageSetting(ctx)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get workspace storage setting")
+			}
+			s3Config = workspaceStorageSetting.S3Config
+		}
+		if s3Config == nil {
+			return nil, errors.New("no s3 config found")
+		}
+		s3Client, err := s3.NewClient(ctx, s3Config)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create s3 client")
+		}
+		return s3Client.OpenObject(ctx, s3Object.Key)
+	default:
+		// The blobs in the database are listed without them, so only one is loaded at a time.
+		withBlob, err := s.GetResource(ctx, &store.FindResource{ID: &resource.ID, GetBlob: true})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get resource")
+		}
+		if withBlob == nil {
+			return nil, errors.New("resource not found")
+		}
+		return io.NopCloser(bytes.NewReader(withBlob.Blob)), nil
+	}
+}
@@ -0,0 +1,147 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1pb "github.com/usememos/memos/proto/gen/api/v1"
+	storepb "github.com/usememos/memos/proto/gen/store"
+	"github.com/usememos/memos/store"
+)
+
+func (s *APIV1Service) ListUserSessions(ctx context.Context, request *v1pb.ListUserSessionsRequest) (*v1pb.ListUserSessionsResponse, error) {
+	userID, err := ExtractUserIDFromName(request.Parent)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user name: %v", err)
+	}
+	if err := s.Authorize(ctx, store.PermissionUsersManage, request.Parent); err != nil {
+		return nil, err
+	}
+
+	userAccessTokens, err := s.Store.GetUserAccessTokens(ctx, userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list access tokens: %v", err)
+	}
+	currentAccessToken, _ := ctx.Value(accessTokenContextKey).(string)
+	sessions := []*v1pb.UserSession{}
+	for _, userAccessToken := range userAccessTokens {
+		if userAccessToken.Session == nil {
+			continue
+		}
+		claims, err := s.parseAccessTokenClaims(userAccessToken.AccessToken)
+		if err != nil {
+			// If the access token is invalid or expired, the session has ended.
+			continue
+		}
+		session := convertUserSessionFromStore(userID, userAccessToken)
+		if claims.ExpiresAt != nil {
+			session.ExpireTime = timestamppb.New(claims.ExpiresAt.Time)
+		}
+		session.Current = userAccessToken.AccessToken == currentAccessToken
+		sessions = append(sessions, session)
+	}
+
+	// Sort by create time in descending order.
+	slices.SortFunc(sessions, func(i, j *v1pb.UserSession) int {
+		return j.CreateTime.AsTime().Compare(i.CreateTime.AsTime())
+	})
+	return &v1pb.ListUserSessionsResponse{
+		Sessions: sessions,
+	}, nil
+}
+
+func (s *APIV1Service) RevokeUserSession(ctx context.Context, request *v1pb.RevokeUserSessionRequest) (*emptypb.Empty, error) {
+	userID, sessionID, err := ExtractUserSessionFromName(request.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid session name: %v", err)
+	}
+	if err := s.Authorize(ctx, store.PermissionUsersManage, fmt.Sprintf("%s%d", UserNamePrefix, userID)); err != nil {
+		return nil, err
+	}
+	user, err := s.Store.GetUser(ctx, &store.FindUser{ID: &userID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+	if user == nil {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+	if err := s.checkUserRank(ctx, user); err != nil {
+		return nil, err
+	}
+
+	removed, err := s.Store.RemoveUserAccessTokens(ctx, userID, func(userAccessToken *storepb.AccessTokensUserSetting_AccessToken) bool {
+		return userAccessToken.Session.GetId() == sessionID
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to remove access token: %v", err)
+	}
+	if removed == 0 {
+		return nil, status.Errorf(codes.NotFound, "session not found")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *APIV1Service) RevokeAllUserSessions(ctx context.Context, request *v1pb.RevokeAllUserSessionsRequest) (*emptypb.Empty, error) {
+	userID, err := ExtractUserIDFromName(request.Parent)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user name: %v", err)
+	}
+	if err := s.Authorize(ctx, store.PermissionUsersManage, request.Parent); err != nil {
+		return nil, err
+	}
+
+	user, err := s.Store.GetUser(ctx, &store.FindUser{ID: &userID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+	if user == nil {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+	if err := s.checkUserRank(ctx, user); err != nil {
+		return nil, err
+	}
+	if _, err := s.Store.RemoveUserAccessTokens(ctx, user.ID, func(userAccessToken *storepb.AccessTokensUserSetting_AccessToken) bool {
+		return userAccessToken.Session != nil || request.IncludeAccessTokens
+	}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to remove access tokens: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// parseAccessTokenClaims returns the claims of the access token if it is signed by the server and not expired.
+func (s *APIV1Service) parseAccessTokenClaims(accessToken string) (*ClaimsMessage, error) {
+	claims := &ClaimsMessage{}
+	if _, err := jwt.ParseWithClaims(accessToken, claims, func(t *jwt.Token) (any, error) {
+		if t.Method.Alg() != jwt.SigningMethodHS256.Name {
+			return nil, errors.Errorf("unexpected access token signing method=%v, expect %v", t.Header["alg"], jwt.SigningMethodHS256)
+		}
+		if kid, ok := t.Header["kid"].(string); ok {
+			if kid == KeyID {
+				return []byte(s.Secret), nil
+			}
+		}
+		return nil, errors.Errorf("unexpected access token kid=%v", t.Header["kid"])
+	}); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func convertUserSessionFromStore(userID int32, userAccessToken *storepb.AccessTokensUserSetting_AccessToken) *v1pb.UserSession {
+	return &v1pb.UserSession{
+		Name:         fmt.Sprintf("%s%d/%s%s", UserNamePrefix, userID, SessionNamePrefix, userAccessToken.Session.Id),
+		UserAgent:    userAccessToken.Session.UserAgent,
+		Ip:           userAccessToken.Session.Ip,
+		CreateTime:   userAccessToken.Session.CreateTime,
+		LastSeenTime: userAccessToken.LastUsedTime,
+		LastSeenIp:   userAccessToken.LastUsedIp,
+	}
+}
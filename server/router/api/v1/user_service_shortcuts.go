@@ -243,15 +243,8 @@ func (s *APIV1Service) validateFilter(_ context.Context, filterStr string) error
 	if filterStr == "" {
 		return errors.New("filter cannot be empty")
 	}
-	// Validate the filter.
-	parsedExpr, err := filter.Parse(filterStr, filter.MemoFilterCELAttributes...)
-	if err != nil {
-		return errors.Wrap(err, "failed to parse filter")
-	}
-	convertCtx := filter.NewConvertContext()
-	err = s.Store.GetDriver().ConvertExprToSQL(convertCtx, parsedExpr.GetExpr())
-	if err != nil {
-		return errors.Wrap(err, "failed to convert filter to SQL")
+	if err := filter.ValidateMemoFilter(filterStr); err != nil {
+		return errors.Wrap(err, "invalid filter")
 	}
 	return nil
 }
@@ -32,15 +32,20 @@ func (s *APIV1Service) ListAllUserStats(ctx context.Context, _ *v1pb.ListAllUser
 	normalStatus := store.Normal
 	memoFind := &store.FindMemo{
 		// Exclude comments by default.
-		ExcludeComments: true,
-		ExcludeContent:  true,
-		VisibilityList:  visibilities,
-		RowStatus:       &normalStatus,
+		ExcludeComments:  true,
+		ExcludeContent:   true,
+		ExcludeScheduled: true,
+		VisibilityList:   visibilities,
+		RowStatus:        &normalStatus,
 	}
 	memos, err := s.Store.ListMemos(ctx, memoFind)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to list memos: %v", err)
 	}
+	memoTags, err := s.listMemoTags(ctx, memos)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list memo tags: %v", err)
+	}
 	userStatsMap := map[string]*v1pb.UserStats{}
 	for _, memo := range memos {
 		creator := fmt.Sprintf("%s%d", UserNamePrefix, memo.CreatorID)
@@ -58,8 +63,7 @@ func (s *APIV1Service) ListAllUserStats(ctx context.Context, _ *v1pb.ListAllUser
 		}
 		userStats := userStatsMap[creator]
 		userStats.MemoDisplayTimestamps = append(userStats.MemoDisplayTimestamps, timestamppb.New(time.Unix(displayTs, 0)))
-		// Handle duplicated tags.
-		for _, tag := range memo.Payload.Tags {
+		for _, tag := range memoTags[memo.ID] {
 			userStats.TagCount[tag]++
 		}
 		if memo.Payload.Property.GetHasLink() {
@@ -115,10 +119,15 @@ func (s *APIV1Service) GetUserStats(ctx context.Context, request *v1pb.GetUserSt
 		}
 	}
 	memoFind.VisibilityList = visibilities
+	memoFind.ExcludeScheduled = currentUser == nil || currentUser.ID != user.ID
 	memos, err := s.Store.ListMemos(ctx, memoFind)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to list memos: %v", err)
 	}
+	memoTags, err := s.listMemoTags(ctx, memos)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list memo tags: %v", err)
+	}
 
 	workspaceMemoRelatedSetting, err := s.Store.GetWorkspaceMemoRelatedSetting(ctx)
 	if err != nil {
@@ -136,8 +145,7 @@ func (s *APIV1Service) GetUserStats(ctx context.Context, request *v1pb.GetUserSt
 			displayTs = memo.UpdatedTs
 		}
 		userStats.MemoDisplayTimestamps = append(userStats.MemoDisplayTimestamps, timestamppb.New(time.Unix(displayTs, 0)))
-		// Handle duplicated tags.
-		for _, tag := range memo.Payload.Tags {
+		for _, tag := range memoTags[memo.ID] {
 			userStats.TagCount[tag]++
 		}
 		if memo.Payload.Property.GetHasLink() {
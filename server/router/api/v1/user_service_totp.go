@@ -0,0 +1,253 @@
+package v1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/usememos/memos/internal/util"
+	"github.com/usememos/memos/plugin/totp"
+	v1pb "github.com/usememos/memos/proto/gen/api/v1"
+	storepb "github.com/usememos/memos/proto/gen/store"
+	"github.com/usememos/memos/store"
+)
+
+const (
+	// recoveryCodeCount is the number of recovery codes that a user gets.
+	recoveryCodeCount = 10
+	// totpIssuer is the issuer that authenticator apps show for the account.
+	totpIssuer = "Memos"
+)
+
+func (s *APIV1Service) EnrollTOTP(ctx context.Context, request *v1pb.EnrollTOTPRequest) (*v1pb.EnrollTOTPResponse, error) {
+	user, err := s.getTwoFactorAuthUser(ctx, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	twoFactorAuth, err := s.Store.GetUserTwoFactorAuth(ctx, user.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get two-factor authentication: %v", err)
+	}
+	if twoFactorAuth != nil && twoFactorAuth.Enabled {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate secret: %v", err)
+	}
+	if err := s.Store.UpsertUserTwoFactorAuth(ctx, user.ID, &storepb.TwoFactorAuthUserSetting{
+		TotpSecret: secret,
+	}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save two-factor authentication: %v", err)
+	}
+	return &v1pb.EnrollTOTPResponse{
+		Secret: secret,
+		Uri:    totp.ProvisioningURI(totpIssuer, user.Username, secret),
+	}, nil
+}
+
+func (s *APIV1Service) VerifyTOTP(ctx context.Context, request *v1pb.VerifyTOTPRequest) (*v1pb.RecoveryCodesResponse, error) {
+	user, err := s.getTwoFactorAuthUser(ctx, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	twoFactorAuth, err := s.Store.GetUserTwoFactorAuth(ctx, user.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get two-factor authentication: %v", err)
+	}
+	if twoFactorAuth == nil || twoFactorAuth.TotpSecret == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enrolled")
+	}
+	if twoFactorAuth.Enabled {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+	step, ok := totp.Validate(twoFactorAuth.TotpSecret, request.Code, time.Now())
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid code")
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate recovery codes: %v", err)
+	}
+	if err := s.Store.UpsertUserTwoFactorAuth(ctx, user.ID, &storepb.TwoFactorAuthUserSetting{
+		TotpSecret:         twoFactorAuth.TotpSecret,
+		Enabled:            true,
+		RecoveryCodeHashes: recoveryCodeHashes,
+		LastUsedStep:       step,
+	}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save two-factor authentication: %v", err)
+	}
+	return &v1pb.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (s *APIV1Service) DisableTOTP(ctx context.Context, request *v1pb.DisableTOTPRequest) (*emptypb.Empty, error) {
+	userID, err := ExtractUserIDFromName(request.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user name: %v", err)
+	}
+	currentUser, err := s.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get current user: %v", err)
+	}
+	if currentUser == nil {
+		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	}
+	// Users disable their own two-factor authentication with a code, while the users who can manage
+	// users can disable it for others, e.g. after they lost their device and recovery codes.
+	if currentUser.ID == userID {
+		ok, err := s.verifyTwoFactorCode(ctx, userID, request.Code)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to verify code: %v", err)
+		}
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid code")
+		}
+	} else {
+		if err := s.Authorize(ctx, store.PermissionUsersManage, ""); err != nil {
+			return nil, err
+		}
+		user, err := s.Store.GetUser(ctx, &store.FindUser{ID: &userID})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+		}
+		if user == nil {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		// Otherwise an admin could weaken the sign-in of another admin, or the host, to take over their account.
+		if err := s.checkUserRank(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Store.UpsertUserTwoFactorAuth(ctx, userID, &storepb.TwoFactorAuthUserSetting{}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save two-factor authentication: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *APIV1Service) RegenerateRecoveryCodes(ctx context.Context, request *v1pb.RegenerateRecoveryCodesRequest) (*v1pb.RecoveryCodesResponse, error) {
+	user, err := s.getTwoFactorAuthUser(ctx, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	recoveryCodes, recoveryCodeHashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate recovery codes: %v", err)
+	}
+	// The code is checked and recorded at once, so that it cannot be used by a concurrent sign-in too.
+	if err := s.Store.UpdateUserTwoFactorAuth(ctx, user.ID, func(twoFactorAuth *storepb.TwoFactorAuthUserSetting) (*storepb.TwoFactorAuthUserSetting, error) {
+		if twoFactorAuth == nil || !twoFactorAuth.Enabled {
+			return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enabled")
+		}
+		step, ok := totp.Validate(twoFactorAuth.TotpSecret, request.Code, time.Now())
+		if !ok || step <= twoFactorAuth.LastUsedStep {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid code")
+		}
+		return &storepb.TwoFactorAuthUserSetting{
+			TotpSecret:         twoFactorAuth.TotpSecret,
+			Enabled:            true,
+			RecoveryCodeHashes: recoveryCodeHashes,
+			LastUsedStep:       step,
+		}, nil
+	}); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Internal, "failed to save two-factor authentication: %v", err)
+	}
+	return &v1pb.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// getTwoFactorAuthUser returns the user if it is the current user, who is the only one that can
+// manage their two-factor authentication.
+func (s *APIV1Service) getTwoFactorAuthUser(ctx context.Context, name string) (*store.User, error) {
+	userID, err := ExtractUserIDFromName(name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user name: %v", err)
+	}
+	currentUser, err := s.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get current user: %v", err)
+	}
+	if currentUser == nil || currentUser.ID != userID {
+		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	}
+	return currentUser, nil
+}
+
+// isTwoFactorAuthEnabled returns whether the user has enabled two-factor authentication.
+func (s *APIV1Service) isTwoFactorAuthEnabled(ctx context.Context, userID int32) (bool, error) {
+	twoFactorAuth, err := s.Store.GetUserTwoFactorAuth(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return twoFactorAuth != nil && twoFactorAuth.Enabled, nil
+}
+
+// verifyTwoFactorCode returns whether the code is a TOTP code or an unused recovery code of the user,
+// and records its use so that it cannot be used again.
+func (s *APIV1Service) verifyTwoFactorCode(ctx context.Context, userID int32, code string) (bool, error) {
+	verified := false
+	if err := s.Store.UpdateUserTwoFactorAuth(ctx, userID, func(twoFactorAuth *storepb.TwoFactorAuthUserSetting) (*storepb.TwoFactorAuthUserSetting, error) {
+		if twoFactorAuth == nil || !twoFactorAuth.Enabled || code == "" {
+			return nil, nil
+		}
+		update := &storepb.TwoFactorAuthUserSetting{
+			TotpSecret:         twoFactorAuth.TotpSecret,
+			Enabled:            true,
+			RecoveryCodeHashes: twoFactorAuth.RecoveryCodeHashes,
+			LastUsedStep:       twoFactorAuth.LastUsedStep,
+		}
+		if step, ok := totp.Validate(twoFactorAuth.TotpSecret, code, time.Now()); ok {
+			if step <= twoFactorAuth.LastUsedStep {
+				return nil, nil
+			}
+			update.LastUsedStep = step
+		} else {
+			hash := hashRecoveryCode(code)
+			index := slices.Index(twoFactorAuth.RecoveryCodeHashes, hash)
+			if index < 0 {
+				return nil, nil
+			}
+			update.RecoveryCodeHashes = slices.Delete(slices.Clone(twoFactorAuth.RecoveryCodeHashes), index, index+1)
+		}
+		verified = true
+		return update, nil
+	}); err != nil {
+		return false, err
+	}
+	return verified, nil
+}
+
+// generateRecoveryCodes returns new recovery codes and their hashes.
+func generateRecoveryCodes() ([]string, []string, error) {
+	recoveryCodes, recoveryCodeHashes := []string{}, []string{}
+	for i := 0; i < recoveryCodeCount; i++ {
+		random, err := util.RandomString(10)
+		if err != nil {
+			return nil, nil, err
+		}
+		random = strings.ToLower(random)
+		recoveryCode := fmt.Sprintf("%s-%s", random[:5], random[5:])
+		recoveryCodes = append(recoveryCodes, recoveryCode)
+		recoveryCodeHashes = append(recoveryCodeHashes, hashRecoveryCode(recoveryCode))
+	}
+	return recoveryCodes, recoveryCodeHashes, nil
+}
+
+// hashRecoveryCode returns the hash of the recovery code, ignoring its case and dashes.
+func hashRecoveryCode(recoveryCode string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(recoveryCode), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net/http"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/improbable-eng/grpc-web/go/grpcweb"
@@ -30,21 +31,27 @@ type APIV1Service struct {
 	v1pb.UnimplementedWebhookServiceServer
 	v1pb.UnimplementedMarkdownServiceServer
 	v1pb.UnimplementedIdentityProviderServiceServer
+	v1pb.UnimplementedGroupServiceServer
+	v1pb.UnimplementedRoleServiceServer
 
 	Secret  string
 	Profile *profile.Profile
 	Store   *store.Store
 
-	grpcServer *grpc.Server
+	grpcServer  *grpc.Server
+	authorizer  *Authorizer
+	rateLimiter *RateLimiter
 }
 
-func NewAPIV1Service(secret string, profile *profile.Profile, store *store.Store, grpcServer *grpc.Server) *APIV1Service {
+func NewAPIV1Service(secret string, profile *profile.Profile, store *store.Store, grpcServer *grpc.Server, rateLimiter *RateLimiter) *APIV1Service {
 	grpc.EnableTracing = true
 	apiv1Service := &APIV1Service{
-		Secret:     secret,
-		Profile:    profile,
-		Store:      store,
-		grpcServer: grpcServer,
+		Secret:      secret,
+		Profile:     profile,
+		Store:       store,
+		grpcServer:  grpcServer,
+		authorizer:  NewAuthorizer(store),
+		rateLimiter: rateLimiter,
 	}
 	v1pb.RegisterWorkspaceServiceServer(grpcServer, apiv1Service)
 	v1pb.RegisterWorkspaceSettingServiceServer(grpcServer, apiv1Service)
@@ -57,6 +64,8 @@ func NewAPIV1Service(secret string, profile *profile.Profile, store *store.Store
 	v1pb.RegisterWebhookServiceServer(grpcServer, apiv1Service)
 	v1pb.RegisterMarkdownServiceServer(grpcServer, apiv1Service)
 	v1pb.RegisterIdentityProviderServiceServer(grpcServer, apiv1Service)
+	v1pb.RegisterGroupServiceServer(grpcServer, apiv1Service)
+	v1pb.RegisterRoleServiceServer(grpcServer, apiv1Service)
 	reflection.Register(grpcServer)
 	return apiv1Service
 }
@@ -72,7 +81,13 @@ func (s *APIV1Service) RegisterGateway(ctx context.Context, echoServer *echo.Ech
 		return err
 	}
 
-	gwMux := runtime.NewServeMux()
+	gwMux := runtime.NewServeMux(runtime.WithOutgoingHeaderMatcher(func(key string) (string, bool) {
+		// Forward the retry hint of rate limited requests as the standard HTTP header.
+		if key == retryAfterHeader {
+			return "Retry-After", true
+		}
+		return fmt.Sprintf("%s%s", runtime.MetadataHeaderPrefix, key), true
+	}))
 	if err := v1pb.RegisterWorkspaceServiceHandler(ctx, gwMux, conn); err != nil {
 		return err
 	}
@@ -106,6 +121,15 @@ func (s *APIV1Service) RegisterGateway(ctx context.Context, echoServer *echo.Ech
 	if err := v1pb.RegisterIdentityProviderServiceHandler(ctx, gwMux, conn); err != nil {
 		return err
 	}
+	if err := v1pb.RegisterGroupServiceHandler(ctx, gwMux, conn); err != nil {
+		return err
+	}
+	if err := v1pb.RegisterRoleServiceHandler(ctx, gwMux, conn); err != nil {
+		return err
+	}
+	if err := gwMux.HandlePath(http.MethodGet, "/api/v1/{name=users/*}:export", s.handleExportUserData(gwMux)); err != nil {
+		return err
+	}
 	gwGroup := echoServer.Group("")
 	gwGroup.Use(middleware.CORS())
 	handler := echo.WrapHandler(gwMux)
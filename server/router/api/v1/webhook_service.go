@@ -3,27 +3,49 @@ package v1
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"slices"
 	"time"
 
+	"github.com/pkg/errors"
+	"golang.org/x/net/http/httpguts"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/usememos/memos/plugin/webhook"
 	v1pb "github.com/usememos/memos/proto/gen/api/v1"
+	storepb "github.com/usememos/memos/proto/gen/store"
 	"github.com/usememos/memos/store"
 )
 
+// webhookHeaderMask replaces the values of the webhook headers in responses.
+const webhookHeaderMask = "********"
+
 func (s *APIV1Service) CreateWebhook(ctx context.Context, request *v1pb.CreateWebhookRequest) (*v1pb.Webhook, error) {
 	currentUser, err := s.GetCurrentUser(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
 	}
 
+	if err := validateWebhookHeaders(request.Headers); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid headers: %v", err)
+	}
+	if err := validateWebhookEventTypes(request.EventTypes); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid event types: %v", err)
+	}
+
 	webhook, err := s.Store.CreateWebhook(ctx, &store.Webhook{
 		CreatorID: currentUser.ID,
 		Name:      request.Name,
 		URL:       request.Url,
+		Secret:    request.Secret,
+		Payload: &storepb.WebhookPayload{
+			Headers:    request.Headers,
+			EventTypes: request.EventTypes,
+		},
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create webhook, error: %+v", err)
@@ -36,6 +58,9 @@ func (s *APIV1Service) ListWebhooks(ctx context.Context, request *v1pb.ListWebho
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid creator name: %v", err)
 	}
+	if err := s.Authorize(ctx, store.PermissionUsersManage, request.Creator); err != nil {
+		return nil, err
+	}
 
 	webhooks, err := s.Store.ListWebhooks(ctx, &store.FindWebhook{
 		CreatorID: &creatorID,
@@ -77,17 +102,46 @@ func (s *APIV1Service) UpdateWebhook(ctx context.Context, request *v1pb.UpdateWe
 		return nil, status.Errorf(codes.InvalidArgument, "update_mask is required")
 	}
 
-	update := &store.UpdateWebhook{}
+	webhook, err := s.getCurrentUserWebhook(ctx, request.Webhook.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &store.UpdateWebhook{
+		ID: webhook.ID,
+	}
 	for _, field := range request.UpdateMask.Paths {
 		switch field {
 		case "name":
 			update.Name = &request.Webhook.Name
 		case "url":
 			update.URL = &request.Webhook.Url
+		case "secret":
+			update.Secret = &request.Webhook.Secret
+		case "headers":
+			if err := validateWebhookHeaders(request.Webhook.Headers); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid headers: %v", err)
+			}
+			headers, err := unmaskWebhookHeaders(request.Webhook.Headers, webhook.Payload.GetHeaders())
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid headers: %v", err)
+			}
+			if update.Payload == nil {
+				update.Payload = webhook.Payload
+			}
+			update.Payload.Headers = headers
+		case "event_types":
+			if err := validateWebhookEventTypes(request.Webhook.EventTypes); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid event types: %v", err)
+			}
+			if update.Payload == nil {
+				update.Payload = webhook.Payload
+			}
+			update.Payload.EventTypes = request.Webhook.EventTypes
 		}
 	}
 
-	webhook, err := s.Store.UpdateWebhook(ctx, update)
+	webhook, err = s.Store.UpdateWebhook(ctx, update)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to update webhook, error: %+v", err)
 	}
@@ -95,15 +149,126 @@ func (s *APIV1Service) UpdateWebhook(ctx context.Context, request *v1pb.UpdateWe
 }
 
 func (s *APIV1Service) DeleteWebhook(ctx context.Context, request *v1pb.DeleteWebhookRequest) (*emptypb.Empty, error) {
+	if _, err := s.getCurrentUserWebhook(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
 	err := s.Store.DeleteWebhook(ctx, &store.DeleteWebhook{
 		ID: request.Id,
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to delete webhook, error: %+v", err)
 	}
+	if err := s.Store.DeleteWebhookDeliveries(ctx, &store.DeleteWebhookDelivery{
+		WebhookID: &request.Id,
+	}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete webhook deliveries, error: %+v", err)
+	}
 	return &emptypb.Empty{}, nil
 }
 
+func (s *APIV1Service) ListWebhookDeliveries(ctx context.Context, request *v1pb.ListWebhookDeliveriesRequest) (*v1pb.ListWebhookDeliveriesResponse, error) {
+	webhook, err := s.getCurrentUserWebhook(ctx, request.WebhookId)
+	if err != nil {
+		return nil, err
+	}
+
+	var limit, offset int
+	if request.PageToken != "" {
+		var pageToken v1pb.PageToken
+		if err := unmarshalPageToken(request.PageToken, &pageToken); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token: %v", err)
+		}
+		limit = int(pageToken.Limit)
+		offset = int(pageToken.Offset)
+	} else {
+		limit = int(request.PageSize)
+	}
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limitPlusOne := limit + 1
+	deliveries, err := s.Store.ListWebhookDeliveries(ctx, &store.FindWebhookDelivery{
+		WebhookID: &webhook.ID,
+		Limit:     &limitPlusOne,
+		Offset:    &offset,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list webhook deliveries, error: %+v", err)
+	}
+
+	nextPageToken := ""
+	if len(deliveries) == limitPlusOne {
+		deliveries = deliveries[:limit]
+		nextPageToken, err = getPageToken(limit, offset+limit)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get next page token, error: %v", err)
+		}
+	}
+
+	response := &v1pb.ListWebhookDeliveriesResponse{
+		Deliveries:    []*v1pb.WebhookDelivery{},
+		NextPageToken: nextPageToken,
+	}
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, convertWebhookDeliveryFromStore(delivery))
+	}
+	return response, nil
+}
+
+func (s *APIV1Service) RedeliverWebhookDelivery(ctx context.Context, request *v1pb.RedeliverWebhookDeliveryRequest) (*v1pb.WebhookDelivery, error) {
+	webhook, err := s.getCurrentUserWebhook(ctx, request.WebhookId)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.Store.GetWebhookDelivery(ctx, &store.FindWebhookDelivery{
+		ID:        &request.Id,
+		WebhookID: &webhook.ID,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get webhook delivery, error: %+v", err)
+	}
+	if delivery == nil {
+		return nil, status.Errorf(codes.NotFound, "webhook delivery not found")
+	}
+
+	redelivery, err := s.Store.CreateWebhookDelivery(ctx, &store.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		ActivityType:  delivery.ActivityType,
+		Payload:       delivery.Payload,
+		Status:        store.WebhookDeliveryPending,
+		NextAttemptTs: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create webhook delivery, error: %+v", err)
+	}
+	return convertWebhookDeliveryFromStore(redelivery), nil
+}
+
+// getCurrentUserWebhook returns the webhook with the given id if it is owned by the current user.
+func (s *APIV1Service) getCurrentUserWebhook(ctx context.Context, id int32) (*store.Webhook, error) {
+	currentUser, err := s.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+	if currentUser == nil {
+		return nil, status.Errorf(codes.Unauthenticated, "user not authenticated")
+	}
+
+	webhook, err := s.Store.GetWebhook(ctx, &store.FindWebhook{
+		ID:        &id,
+		CreatorID: &currentUser.ID,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get webhook, error: %+v", err)
+	}
+	if webhook == nil {
+		return nil, status.Errorf(codes.NotFound, "webhook not found")
+	}
+	return webhook, nil
+}
+
 func convertWebhookFromStore(webhook *store.Webhook) *v1pb.Webhook {
 	return &v1pb.Webhook{
 		Id:         webhook.ID,
@@ -112,5 +277,141 @@ func convertWebhookFromStore(webhook *store.Webhook) *v1pb.Webhook {
 		Creator:    fmt.Sprintf("%s%d", UserNamePrefix, webhook.CreatorID),
 		Name:       webhook.Name,
 		Url:        webhook.URL,
+		Headers:    maskWebhookHeaders(webhook.Payload.GetHeaders()),
+		EventTypes: webhook.Payload.GetEventTypes(),
+	}
+}
+
+// maskWebhookHeaders returns the headers with their values masked, since they often hold credentials.
+func maskWebhookHeaders(headers map[string]string) map[string]string {
+	masked := make(map[string]string, len(headers))
+	for key := range headers {
+		masked[key] = webhookHeaderMask
+	}
+	return masked
+}
+
+// unmaskWebhookHeaders returns the headers with the masked values replaced by the current values, so that
+// clients can send back the headers they got to keep their values.
+func unmaskWebhookHeaders(headers, currentHeaders map[string]string) (map[string]string, error) {
+	unmasked := make(map[string]string, len(headers))
+	for key, value := range headers {
+		if value == webhookHeaderMask {
+			currentValue, ok := currentHeaders[key]
+			if !ok {
+				return nil, errors.Errorf("header %q has no value to keep", key)
+			}
+			value = currentValue
+		}
+		unmasked[key] = value
+	}
+	return unmasked, nil
+}
+
+func validateWebhookEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if !slices.Contains(webhook.EventTypes, eventType) {
+			return errors.Errorf("unknown event type %q", eventType)
+		}
+	}
+	return nil
+}
+
+// dispatchWebhookEvent queues a delivery of the payload for every webhook of the user subscribed to its activity type.
+func (s *APIV1Service) dispatchWebhookEvent(ctx context.Context, userID int32, payload *v1pb.WebhookRequestPayload) error {
+	webhooks, err := s.Store.ListWebhooks(ctx, &store.FindWebhook{
+		CreatorID: &userID,
+	})
+	if err != nil {
+		return err
+	}
+	for _, hook := range webhooks {
+		if !webhook.IsSubscribed(hook.Payload.GetEventTypes(), payload.ActivityType) {
+			continue
+		}
+		payload.Url = hook.URL
+		body, err := protojson.Marshal(payload)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal webhook payload")
+		}
+		// Deliveries are sent by the webhook delivery runner, so a slow endpoint never blocks the request.
+		if _, err := s.Store.CreateWebhookDelivery(ctx, &store.WebhookDelivery{
+			WebhookID:     hook.ID,
+			ActivityType:  payload.ActivityType,
+			Payload:       string(body),
+			Status:        store.WebhookDeliveryPending,
+			NextAttemptTs: time.Now().Unix(),
+		}); err != nil {
+			return errors.Wrap(err, "failed to create webhook delivery")
+		}
+	}
+	return nil
+}
+
+// dispatchAdminWebhookEvent queues a delivery of the payload for the webhooks of every admin.
+func (s *APIV1Service) dispatchAdminWebhookEvent(ctx context.Context, payload *v1pb.WebhookRequestPayload) error {
+	for _, role := range []store.Role{store.RoleHost, store.RoleAdmin} {
+		users, err := s.Store.ListUsers(ctx, &store.FindUser{
+			Role: &role,
+		})
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			if err := s.dispatchWebhookEvent(ctx, user.ID, payload); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateWebhookHeaders checks that the headers are well-formed and do not override the headers set by the sender.
+func validateWebhookHeaders(headers map[string]string) error {
+	reservedHeaders := []string{"Content-Type", "Content-Length", "Host", webhook.TimestampHeader, webhook.SignatureHeader}
+	for key, value := range headers {
+		if !httpguts.ValidHeaderFieldName(key) {
+			return errors.Errorf("invalid header name %q", key)
+		}
+		if !httpguts.ValidHeaderFieldValue(value) {
+			return errors.Errorf("invalid value of header %q", key)
+		}
+		if slices.Contains(reservedHeaders, http.CanonicalHeaderKey(key)) {
+			return errors.Errorf("header %q is reserved", key)
+		}
+	}
+	return nil
+}
+
+func convertWebhookDeliveryFromStore(delivery *store.WebhookDelivery) *v1pb.WebhookDelivery {
+	webhookDelivery := &v1pb.WebhookDelivery{
+		Id:                 delivery.ID,
+		WebhookId:          delivery.WebhookID,
+		CreateTime:         timestamppb.New(time.Unix(delivery.CreatedTs, 0)),
+		UpdateTime:         timestamppb.New(time.Unix(delivery.UpdatedTs, 0)),
+		ActivityType:       delivery.ActivityType,
+		Payload:            delivery.Payload,
+		Status:             convertWebhookDeliveryStatusFromStore(delivery.Status),
+		Attempts:           delivery.Attempts,
+		ResponseStatusCode: delivery.ResponseCode,
+		ResponseBody:       delivery.ResponseBody,
+		Error:              delivery.Error,
+	}
+	if delivery.Status == store.WebhookDeliveryPending {
+		webhookDelivery.NextAttemptTime = timestamppb.New(time.Unix(delivery.NextAttemptTs, 0))
+	}
+	return webhookDelivery
+}
+
+func convertWebhookDeliveryStatusFromStore(status store.WebhookDeliveryStatus) v1pb.WebhookDelivery_Status {
+	switch status {
+	case store.WebhookDeliveryPending:
+		return v1pb.WebhookDelivery_PENDING
+	case store.WebhookDeliverySucceeded:
+		return v1pb.WebhookDelivery_SUCCEEDED
+	case store.WebhookDeliveryFailed:
+		return v1pb.WebhookDelivery_FAILED
+	default:
+		return v1pb.WebhookDelivery_STATUS_UNSPECIFIED
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/usememos/memos/plugin/cron"
 	v1pb "github.com/usememos/memos/proto/gen/api/v1"
 	storepb "github.com/usememos/memos/proto/gen/store"
 	"github.com/usememos/memos/store"
@@ -29,6 +30,10 @@ func (s *APIV1Service) GetWorkspaceSetting(ctx context.Context, request *v1pb.Ge
 		_, err = s.Store.GetWorkspaceMemoRelatedSetting(ctx)
 	case storepb.WorkspaceSettingKey_STORAGE:
 		_, err = s.Store.GetWorkspaceStorageSetting(ctx)
+	case storepb.WorkspaceSettingKey_SCHEDULER:
+		_, err = s.Store.GetWorkspaceSchedulerSetting(ctx)
+	case storepb.WorkspaceSettingKey_RATE_LIMIT:
+		_, err = s.Store.GetWorkspaceRateLimitSetting(ctx)
 	default:
 		return nil, status.Errorf(codes.InvalidArgument, "unsupported workspace setting key: %v", workspaceSettingKey)
 	}
@@ -46,14 +51,10 @@ func (s *APIV1Service) GetWorkspaceSetting(ctx context.Context, request *v1pb.Ge
 		return nil, status.Errorf(codes.NotFound, "workspace setting not found")
 	}
 
-	// For storage setting, only host can get it.
+	// For storage setting, only the users who can manage the workspace can get it.
 	if workspaceSetting.Key == storepb.WorkspaceSettingKey_STORAGE {
-		user, err := s.GetCurrentUser(ctx)
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to get current user: %v", err)
-		}
-		if user == nil || user.Role != store.RoleHost {
-			return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+		if err := s.Authorize(ctx, store.PermissionWorkspaceManage, ""); err != nil {
+			return nil, err
 		}
 	}
 
@@ -61,12 +62,8 @@ func (s *APIV1Service) GetWorkspaceSetting(ctx context.Context, request *v1pb.Ge
 }
 
 func (s *APIV1Service) SetWorkspaceSetting(ctx context.Context, request *v1pb.SetWorkspaceSettingRequest) (*v1pb.WorkspaceSetting, error) {
-	user, err := s.GetCurrentUser(ctx)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get current user: %v", err)
-	}
-	if user.Role != store.RoleHost {
-		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	if err := s.Authorize(ctx, store.PermissionWorkspaceManage, ""); err != nil {
+		return nil, err
 	}
 
 	updateSetting := convertWorkspaceSettingToStore(request.Setting)
@@ -75,6 +72,31 @@ func (s *APIV1Service) SetWorkspaceSetting(ctx context.Context, request *v1pb.Se
 	if s.Profile.Mode == "demo" && updateSetting.Key == storepb.WorkspaceSettingKey_GENERAL {
 		return nil, status.Errorf(codes.InvalidArgument, "setting workspace setting is not allowed in demo mode")
 	}
+	if updateSetting.Key == storepb.WorkspaceSettingKey_SCHEDULER {
+		for name, spec := range updateSetting.GetSchedulerSetting().GetJobSpecs() {
+			if spec == "" {
+				continue
+			}
+			if _, err := cron.ParseStandard(spec); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid cron spec of job %s: %v", name, err)
+			}
+		}
+	}
+
+	if updateSetting.Key == storepb.WorkspaceSettingKey_RATE_LIMIT {
+		rateLimitSetting := updateSetting.GetRateLimitSetting()
+		for name, limit := range map[string]*storepb.WorkspaceRateLimit{
+			"auth":  rateLimitSetting.GetAuthLimit(),
+			"write": rateLimitSetting.GetWriteLimit(),
+		} {
+			if limit.GetRequestsPerMinute() < 0 || limit.GetBurst() < 0 {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid %s limit: requests per minute and burst cannot be negative", name)
+			}
+		}
+		if rateLimitSetting.GetLockoutThreshold() < 0 || rateLimitSetting.GetLockoutSeconds() < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid lockout: threshold and seconds cannot be negative")
+		}
+	}
 
 	workspaceSetting, err := s.Store.UpsertWorkspaceSetting(ctx, updateSetting)
 	if err != nil {
@@ -101,6 +123,14 @@ func convertWorkspaceSettingFromStore(setting *storepb.WorkspaceSetting) *v1pb.W
 		workspaceSetting.Value = &v1pb.WorkspaceSetting_MemoRelatedSetting{
 			MemoRelatedSetting: convertWorkspaceMemoRelatedSettingFromStore(setting.GetMemoRelatedSetting()),
 		}
+	case *storepb.WorkspaceSetting_SchedulerSetting:
+		workspaceSetting.Value = &v1pb.WorkspaceSetting_SchedulerSetting{
+			SchedulerSetting: convertWorkspaceSchedulerSettingFromStore(setting.GetSchedulerSetting()),
+		}
+	case *storepb.WorkspaceSetting_RateLimitSetting:
+		workspaceSetting.Value = &v1pb.WorkspaceSetting_RateLimitSetting{
+			RateLimitSetting: convertWorkspaceRateLimitSettingFromStore(setting.GetRateLimitSetting()),
+		}
 	}
 	return workspaceSetting
 }
@@ -126,6 +156,14 @@ func convertWorkspaceSettingToStore(setting *v1pb.WorkspaceSetting) *storepb.Wor
 		workspaceSetting.Value = &storepb.WorkspaceSetting_MemoRelatedSetting{
 			MemoRelatedSetting: convertWorkspaceMemoRelatedSettingToStore(setting.GetMemoRelatedSetting()),
 		}
+	case storepb.WorkspaceSettingKey_SCHEDULER:
+		workspaceSetting.Value = &storepb.WorkspaceSetting_SchedulerSetting{
+			SchedulerSetting: convertWorkspaceSchedulerSettingToStore(setting.GetSchedulerSetting()),
+		}
+	case storepb.WorkspaceSettingKey_RATE_LIMIT:
+		workspaceSetting.Value = &storepb.WorkspaceSetting_RateLimitSetting{
+			RateLimitSetting: convertWorkspaceRateLimitSettingToStore(setting.GetRateLimitSetting()),
+		}
 	}
 	return workspaceSetting
 }
@@ -135,13 +173,14 @@ func convertWorkspaceGeneralSettingFromStore(setting *storepb.WorkspaceGeneralSe
 		return nil
 	}
 	generalSetting := &v1pb.WorkspaceGeneralSetting{
-		DisallowUserRegistration: setting.DisallowUserRegistration,
-		DisallowPasswordAuth:     setting.DisallowPasswordAuth,
-		AdditionalScript:         setting.AdditionalScript,
-		AdditionalStyle:          setting.AdditionalStyle,
-		WeekStartDayOffset:       setting.WeekStartDayOffset,
-		DisallowChangeUsername:   setting.DisallowChangeUsername,
-		DisallowChangeNickname:   setting.DisallowChangeNickname,
+		DisallowUserRegistration:  setting.DisallowUserRegistration,
+		DisallowPasswordAuth:      setting.DisallowPasswordAuth,
+		AdditionalScript:          setting.AdditionalScript,
+		AdditionalStyle:           setting.AdditionalStyle,
+		WeekStartDayOffset:        setting.WeekStartDayOffset,
+		DisallowChangeUsername:    setting.DisallowChangeUsername,
+		DisallowChangeNickname:    setting.DisallowChangeNickname,
+		RequireTwoFactorForAdmins: setting.RequireTwoFactorForAdmins,
 	}
 	if setting.CustomProfile != nil {
 		generalSetting.CustomProfile = &v1pb.WorkspaceCustomProfile{
@@ -160,13 +199,14 @@ func convertWorkspaceGeneralSettingToStore(setting *v1pb.WorkspaceGeneralSetting
 		return nil
 	}
 	generalSetting := &storepb.WorkspaceGeneralSetting{
-		DisallowUserRegistration: setting.DisallowUserRegistration,
-		DisallowPasswordAuth:     setting.DisallowPasswordAuth,
-		AdditionalScript:         setting.AdditionalScript,
-		AdditionalStyle:          setting.AdditionalStyle,
-		WeekStartDayOffset:       setting.WeekStartDayOffset,
-		DisallowChangeUsername:   setting.DisallowChangeUsername,
-		DisallowChangeNickname:   setting.DisallowChangeNickname,
+		DisallowUserRegistration:  setting.DisallowUserRegistration,
+		DisallowPasswordAuth:      setting.DisallowPasswordAuth,
+		AdditionalScript:          setting.AdditionalScript,
+		AdditionalStyle:           setting.AdditionalStyle,
+		WeekStartDayOffset:        setting.WeekStartDayOffset,
+		DisallowChangeUsername:    setting.DisallowChangeUsername,
+		DisallowChangeNickname:    setting.DisallowChangeNickname,
+		RequireTwoFactorForAdmins: setting.RequireTwoFactorForAdmins,
 	}
 	if setting.CustomProfile != nil {
 		generalSetting.CustomProfile = &storepb.WorkspaceCustomProfile{
@@ -259,3 +299,71 @@ func convertWorkspaceMemoRelatedSettingToStore(setting *v1pb.WorkspaceMemoRelate
 		DisableMarkdownShortcuts: setting.DisableMarkdownShortcuts,
 	}
 }
+
+func convertWorkspaceSchedulerSettingFromStore(setting *storepb.WorkspaceSchedulerSetting) *v1pb.WorkspaceSchedulerSetting {
+	if setting == nil {
+		return nil
+	}
+	return &v1pb.WorkspaceSchedulerSetting{
+		JobSpecs: setting.JobSpecs,
+	}
+}
+
+func convertWorkspaceSchedulerSettingToStore(setting *v1pb.WorkspaceSchedulerSetting) *storepb.WorkspaceSchedulerSetting {
+	if setting == nil {
+		return nil
+	}
+	return &storepb.WorkspaceSchedulerSetting{
+		JobSpecs: setting.JobSpecs,
+	}
+}
+
+func convertWorkspaceRateLimitSettingFromStore(setting *storepb.WorkspaceRateLimitSetting) *v1pb.WorkspaceRateLimitSetting {
+	if setting == nil {
+		return nil
+	}
+	rateLimitSetting := &v1pb.WorkspaceRateLimitSetting{
+		Disabled:         setting.Disabled,
+		LockoutThreshold: setting.LockoutThreshold,
+		LockoutSeconds:   setting.LockoutSeconds,
+		Persist:          setting.Persist,
+	}
+	if setting.AuthLimit != nil {
+		rateLimitSetting.AuthLimit = &v1pb.WorkspaceRateLimit{
+			RequestsPerMinute: setting.AuthLimit.RequestsPerMinute,
+			Burst:             setting.AuthLimit.Burst,
+		}
+	}
+	if setting.WriteLimit != nil {
+		rateLimitSetting.WriteLimit = &v1pb.WorkspaceRateLimit{
+			RequestsPerMinute: setting.WriteLimit.RequestsPerMinute,
+			Burst:             setting.WriteLimit.Burst,
+		}
+	}
+	return rateLimitSetting
+}
+
+func convertWorkspaceRateLimitSettingToStore(setting *v1pb.WorkspaceRateLimitSetting) *storepb.WorkspaceRateLimitSetting {
+	if setting == nil {
+		return nil
+	}
+	rateLimitSetting := &storepb.WorkspaceRateLimitSetting{
+		Disabled:         setting.Disabled,
+		LockoutThreshold: setting.LockoutThreshold,
+		LockoutSeconds:   setting.LockoutSeconds,
+		Persist:          setting.Persist,
+	}
+	if setting.AuthLimit != nil {
+		rateLimitSetting.AuthLimit = &storepb.WorkspaceRateLimit{
+			RequestsPerMinute: setting.AuthLimit.RequestsPerMinute,
+			Burst:             setting.AuthLimit.Burst,
+		}
+	}
+	if setting.WriteLimit != nil {
+		rateLimitSetting.WriteLimit = &storepb.WorkspaceRateLimit{
+			RequestsPerMinute: setting.WriteLimit.RequestsPerMinute,
+			Burst:             setting.WriteLimit.Burst,
+		}
+	}
+	return rateLimitSetting
+}
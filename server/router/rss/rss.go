@@ -42,8 +42,9 @@ func (s *RSSService) GetExploreRSS(c echo.Context) error {
 	ctx := c.Request().Context()
 	normalStatus := store.Normal
 	memoFind := store.FindMemo{
-		RowStatus:      &normalStatus,
-		VisibilityList: []store.Visibility{store.Public},
+		RowStatus:        &normalStatus,
+		VisibilityList:   []store.Visibility{store.Public},
+		ExcludeScheduled: true,
 	}
 	memoList, err := s.Store.ListMemos(ctx, &memoFind)
 	if err != nil {
@@ -74,9 +75,10 @@ func (s *RSSService) GetUserRSS(c echo.Context) error {
 
 	normalStatus := store.Normal
 	memoFind := store.FindMemo{
-		CreatorID:      &user.ID,
-		RowStatus:      &normalStatus,
-		VisibilityList: []store.Visibility{store.Public},
+		CreatorID:        &user.ID,
+		RowStatus:        &normalStatus,
+		VisibilityList:   []store.Visibility{store.Public},
+		ExcludeScheduled: true,
 	}
 	memoList, err := s.Store.ListMemos(ctx, &memoFind)
 	if err != nil {
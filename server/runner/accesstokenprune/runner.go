@@ -0,0 +1,62 @@
+package accesstokenprune
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	storepb "github.com/usememos/memos/proto/gen/store"
+	"github.com/usememos/memos/store"
+)
+
+type Runner struct {
+	Store *store.Store
+}
+
+func NewRunner(store *store.Store) *Runner {
+	return &Runner{
+		Store: store,
+	}
+}
+
+// RunOnce removes the expired access tokens of all users, which ends their expired sign-in sessions.
+func (r *Runner) RunOnce(ctx context.Context) {
+	userSettings, err := r.Store.ListUserSettings(ctx, &store.FindUserSetting{
+		Key: storepb.UserSettingKey_ACCESS_TOKENS,
+	})
+	if err != nil {
+		slog.Error("failed to list access tokens", "err", err)
+		return
+	}
+
+	now := time.Now()
+	for _, userSetting := range userSettings {
+		if ctx.Err() != nil {
+			return
+		}
+		removed, err := r.Store.RemoveUserAccessTokens(ctx, userSetting.UserId, func(userAccessToken *storepb.AccessTokensUserSetting_AccessToken) bool {
+			return isExpired(userAccessToken.AccessToken, now)
+		})
+		if err != nil {
+			slog.Error("failed to prune access tokens", "user", userSetting.UserId, "err", err)
+			continue
+		}
+		if removed > 0 {
+			slog.Info("pruned expired access tokens", "user", userSetting.UserId, "count", removed)
+		}
+	}
+}
+
+// isExpired returns whether the access token expired before now. The signature is not verified, since
+// the access token comes from the store rather than a client; malformed access tokens never authenticate,
+// so they count as expired.
+func isExpired(accessToken string, now time.Time) bool {
+	claims := &jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(accessToken, claims); err != nil {
+		return true
+	}
+	// Access tokens without an expiration time never expire.
+	return claims.ExpiresAt != nil && !claims.ExpiresAt.After(now)
+}
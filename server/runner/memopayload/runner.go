@@ -4,6 +4,8 @@ import (
 	"context"
 	"log/slog"
 	"slices"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 	"github.com/usememos/gomark/ast"
@@ -61,6 +63,10 @@ func RebuildMemoPayload(memo *store.Memo) error {
 		switch n := node.(type) {
 		case *ast.Tag:
 			tag := n.Content
+			// Longer tags do not fit the tag index, so memos cannot be found by them.
+			if utf8.RuneCountInString(tag) > store.MaxMemoTagLength {
+				return
+			}
 			if !slices.Contains(tags, tag) {
 				tags = append(tags, tag)
 			}
@@ -106,3 +112,58 @@ func TraverseASTNodes(nodes []ast.Node, fn func(ast.Node)) {
 		}
 	}
 }
+
+// RemoveASTNodes removes the nodes for which fn returns true from the nodes and their children.
+// The whitespace left by a removed inline node is collapsed, so "a #tag b" becomes "a b".
+func RemoveASTNodes(nodes []ast.Node, fn func(ast.Node) bool) []ast.Node {
+	result := []ast.Node{}
+	removed := false
+	for _, node := range nodes {
+		if fn(node) {
+			removed = true
+			continue
+		}
+		switch n := node.(type) {
+		case *ast.Paragraph:
+			n.Children = RemoveASTNodes(n.Children, fn)
+		case *ast.Heading:
+			n.Children = RemoveASTNodes(n.Children, fn)
+		case *ast.Blockquote:
+			n.Children = RemoveASTNodes(n.Children, fn)
+		case *ast.List:
+			n.Children = RemoveASTNodes(n.Children, fn)
+		case *ast.OrderedListItem:
+			n.Children = RemoveASTNodes(n.Children, fn)
+		case *ast.UnorderedListItem:
+			n.Children = RemoveASTNodes(n.Children, fn)
+		case *ast.TaskListItem:
+			n.Children = RemoveASTNodes(n.Children, fn)
+		case *ast.Bold:
+			n.Children = RemoveASTNodes(n.Children, fn)
+		case *ast.Text:
+			if removed && isSpaceBefore(result) {
+				n.Content = strings.TrimPrefix(n.Content, " ")
+				if n.Content == "" {
+					continue
+				}
+			}
+		}
+		removed = false
+		result = append(result, node)
+	}
+	if removed && len(result) > 0 {
+		if previous, ok := result[len(result)-1].(*ast.Text); ok {
+			previous.Content = strings.TrimRight(previous.Content, " ")
+		}
+	}
+	return result
+}
+
+// isSpaceBefore returns true if the nodes are empty or end with a space.
+func isSpaceBefore(nodes []ast.Node) bool {
+	if len(nodes) == 0 {
+		return true
+	}
+	previous, ok := nodes[len(nodes)-1].(*ast.Text)
+	return ok && strings.HasSuffix(previous.Content, " ")
+}
@@ -0,0 +1,68 @@
+package memoschedule
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/usememos/memos/store"
+)
+
+type Runner struct {
+	Store *store.Store
+	// OnPublished is called with every memo once it has been published.
+	OnPublished func(ctx context.Context, memo *store.Memo)
+}
+
+func NewRunner(store *store.Store, onPublished func(ctx context.Context, memo *store.Memo)) *Runner {
+	return &Runner{
+		Store:       store,
+		OnPublished: onPublished,
+	}
+}
+
+// RunOnce publishes the scheduled memos that are due.
+func (r *Runner) RunOnce(ctx context.Context) {
+	// Archived memos are published once they are restored.
+	now, normal := time.Now().Unix(), store.Normal
+	memos, err := r.Store.ListMemos(ctx, &store.FindMemo{
+		RowStatus:         &normal,
+		ScheduledTsBefore: &now,
+	})
+	if err != nil {
+		slog.Error("failed to list scheduled memos", "err", err)
+		return
+	}
+
+	for _, memo := range memos {
+		if err := r.Publish(ctx, memo); err != nil {
+			slog.Error("failed to publish scheduled memo", "id", memo.ID, "err", err)
+		}
+	}
+}
+
+// Publish makes the scheduled memo visible, as if it had been created at its publish time. A memo that
+// changed since it was read, e.g. was rescheduled or archived, is left for the next run to decide.
+func (r *Runner) Publish(ctx context.Context, memo *store.Memo) error {
+	publishedTs, scheduledTs := memo.ScheduledTs, int64(0)
+	if err := r.Store.UpdateMemo(ctx, &store.UpdateMemo{
+		ID:          memo.ID,
+		CreatedTs:   &publishedTs,
+		UpdatedTs:   &publishedTs,
+		ScheduledTs: &scheduledTs,
+		Current:     memo,
+	}); err != nil {
+		if errors.Is(err, store.ErrMemoModified) {
+			return nil
+		}
+		return err
+	}
+
+	memo.CreatedTs, memo.UpdatedTs, memo.ScheduledTs = publishedTs, publishedTs, scheduledTs
+	if r.OnPublished != nil {
+		r.OnPublished(ctx, memo)
+	}
+	return nil
+}
@@ -22,23 +22,6 @@ func NewRunner(store *store.Store) *Runner {
 	}
 }
 
-// Schedule runner every 12 hours.
-const runnerInterval = time.Hour * 12
-
-func (r *Runner) Run(ctx context.Context) {
-	ticker := time.NewTicker(runnerInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			r.RunOnce(ctx)
-		case <-ctx.Done():
-			return
-		}
-	}
-}
-
 func (r *Runner) RunOnce(ctx context.Context) {
 	r.CheckAndPresign(ctx)
 }
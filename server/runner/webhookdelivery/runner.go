@@ -0,0 +1,164 @@
+package webhookdelivery
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/usememos/memos/plugin/webhook"
+	"github.com/usememos/memos/store"
+)
+
+const (
+	// MaxAttempts is the number of attempts made before a delivery is marked as failed.
+	MaxAttempts = 8
+	// initialBackoff is the delay before the first retry, doubled after every failed attempt.
+	initialBackoff = 30 * time.Second
+	// retention is how long deliveries are kept in the delivery log.
+	retention = 30 * 24 * time.Hour
+	// maxResponseBodyLength is the maximum length of the recorded response body.
+	maxResponseBodyLength = 4096
+	// maxBatchSize is the maximum number of deliveries sent in a run, the others wait for the next runs.
+	maxBatchSize = 100
+	// maxConcurrentWebhooks is the maximum number of webhooks sent to at the same time.
+	maxConcurrentWebhooks = 8
+)
+
+type Runner struct {
+	Store *store.Store
+}
+
+func NewRunner(store *store.Store) *Runner {
+	return &Runner{
+		Store: store,
+	}
+}
+
+// RunOnce sends the due deliveries and prunes expired ones.
+func (r *Runner) RunOnce(ctx context.Context) {
+	pending, now, limit := store.WebhookDeliveryPending, time.Now().Unix(), maxBatchSize
+	deliveries, err := r.Store.ListWebhookDeliveries(ctx, &store.FindWebhookDelivery{
+		Status:              &pending,
+		NextAttemptTsBefore: &now,
+		OrderByIDAsc:        true,
+		Limit:               &limit,
+	})
+	if err != nil {
+		slog.Error("failed to list webhook deliveries", "err", err)
+		return
+	}
+
+	// Every webhook is sent its deliveries one at a time in the order they were queued, so that a slow
+	// endpoint only holds up its own deliveries.
+	webhookIDs, queues := []int32{}, map[int32][]*store.WebhookDelivery{}
+	for _, delivery := range deliveries {
+		if _, ok := queues[delivery.WebhookID]; !ok {
+			webhookIDs = append(webhookIDs, delivery.WebhookID)
+		}
+		queues[delivery.WebhookID] = append(queues[delivery.WebhookID], delivery)
+	}
+	semaphore := make(chan struct{}, maxConcurrentWebhooks)
+	var wg sync.WaitGroup
+	for _, webhookID := range webhookIDs {
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(queue []*store.WebhookDelivery) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			r.deliverQueue(ctx, queue)
+		}(queues[webhookID])
+	}
+	wg.Wait()
+
+	createdTsBefore := time.Now().Add(-retention).Unix()
+	if err := r.Store.DeleteWebhookDeliveries(ctx, &store.DeleteWebhookDelivery{
+		CreatedTsBefore: &createdTsBefore,
+	}); err != nil {
+		slog.Error("failed to prune webhook deliveries", "err", err)
+	}
+}
+
+// deliverQueue sends the deliveries of a webhook in order. It stops at the first failed attempt, since
+// the endpoint is likely to fail the others too, which are retried in the next runs.
+func (r *Runner) deliverQueue(ctx context.Context, queue []*store.WebhookDelivery) {
+	for _, delivery := range queue {
+		if ctx.Err() != nil {
+			return
+		}
+		sent, err := r.deliver(ctx, delivery)
+		if err != nil {
+			slog.Error("failed to deliver webhook", "id", delivery.ID, "err", err)
+		}
+		if err != nil || !sent {
+			return
+		}
+	}
+}
+
+// Deliver makes one attempt to send the delivery and records its outcome.
+func (r *Runner) Deliver(ctx context.Context, delivery *store.WebhookDelivery) error {
+	_, err := r.deliver(ctx, delivery)
+	return err
+}
+
+// deliver makes one attempt to send the delivery and records its outcome. It returns whether the
+// attempt succeeded.
+func (r *Runner) deliver(ctx context.Context, delivery *store.WebhookDelivery) (bool, error) {
+	attempts := delivery.Attempts + 1
+	update := &store.UpdateWebhookDelivery{
+		ID:       delivery.ID,
+		Attempts: &attempts,
+	}
+
+	hook, err := r.Store.GetWebhook(ctx, &store.FindWebhook{ID: &delivery.WebhookID})
+	if err != nil {
+		return false, err
+	}
+	if hook == nil {
+		failed, message := store.WebhookDeliveryFailed, "webhook not found"
+		update.Status, update.Error = &failed, &message
+		_, err := r.Store.UpdateWebhookDelivery(ctx, update)
+		return false, err
+	}
+
+	response, sendErr := webhook.Send(&webhook.Request{
+		URL:     hook.URL,
+		Body:    []byte(delivery.Payload),
+		Secret:  hook.Secret,
+		Headers: hook.Payload.GetHeaders(),
+	})
+	if response != nil {
+		code, body := int32(response.StatusCode), truncate(string(response.Body), maxResponseBodyLength)
+		update.ResponseCode, update.ResponseBody = &code, &body
+	}
+	message := ""
+	if sendErr != nil {
+		message = sendErr.Error()
+	}
+	update.Error = &message
+
+	status := store.WebhookDeliverySucceeded
+	if sendErr != nil {
+		status = store.WebhookDeliveryFailed
+		if attempts < MaxAttempts {
+			status = store.WebhookDeliveryPending
+			nextAttemptTs := time.Now().Add(initialBackoff << (attempts - 1)).Unix()
+			update.NextAttemptTs = &nextAttemptTs
+		}
+	}
+	update.Status = &status
+
+	_, err = r.Store.UpdateWebhookDelivery(ctx, update)
+	return sendErr == nil, err
+}
+
+func truncate(s string, length int) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+	return string(runes[:length])
+}
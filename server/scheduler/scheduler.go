@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/usememos/memos/plugin/cron"
+	"github.com/usememos/memos/store"
+)
+
+// syncSpec is the spec on which the jobs are synced with the workspace scheduler setting.
+const syncSpec = "@every 1m"
+
+// Job is a background job run on a cron spec.
+type Job struct {
+	// Name is the key of the job in the workspace scheduler setting.
+	Name string
+	// DefaultSpec is the spec used when the workspace scheduler setting has none for the job.
+	DefaultSpec string
+	Run         func(ctx context.Context)
+}
+
+type entry struct {
+	spec string
+	id   cron.EntryID
+}
+
+// Scheduler runs the registered jobs on the specs of the workspace scheduler setting.
+type Scheduler struct {
+	Store *store.Store
+
+	cron    *cron.Cron
+	mutex   sync.Mutex
+	jobs    []*Job
+	entries map[string]*entry
+}
+
+func NewScheduler(store *store.Store) *Scheduler {
+	logger := &logger{}
+	return &Scheduler{
+		Store: store,
+		cron: cron.New(
+			cron.WithLogger(logger),
+			cron.WithChain(cron.Recover(logger), cron.SkipIfStillRunning(logger)),
+		),
+		entries: map[string]*entry{},
+	}
+}
+
+// Register adds a job to the scheduler, it is scheduled on the next sync.
+func (s *Scheduler) Register(job *Job) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.jobs = append(s.jobs, job)
+}
+
+// Start schedules the registered jobs and runs them until the context is done.
+// Changes of the workspace scheduler setting are picked up within a minute.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.Sync(ctx)
+	if _, err := s.cron.AddFunc(syncSpec, func() { s.Sync(ctx) }); err != nil {
+		slog.Error("failed to schedule scheduler sync", "err", err)
+	}
+	s.cron.Start()
+
+	go func() {
+		<-ctx.Done()
+		s.cron.Stop()
+	}()
+}
+
+// Sync reschedules the jobs whose spec has changed in the workspace scheduler setting.
+// A job with an invalid spec keeps its previous schedule.
+func (s *Scheduler) Sync(ctx context.Context) {
+	setting, err := s.Store.GetWorkspaceSchedulerSetting(ctx)
+	if err != nil {
+		slog.Error("failed to get workspace scheduler setting", "err", err)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, job := range s.jobs {
+		spec := setting.JobSpecs[job.Name]
+		if spec == "" {
+			spec = job.DefaultSpec
+		}
+		if e, ok := s.entries[job.Name]; ok && e.spec == spec {
+			continue
+		}
+
+		schedule, err := cron.ParseStandard(spec)
+		if err != nil {
+			slog.Error("invalid cron spec", "job", job.Name, "spec", spec, "err", err)
+			continue
+		}
+		if e, ok := s.entries[job.Name]; ok {
+			s.cron.Remove(e.id)
+		}
+		id := s.cron.Schedule(schedule, cron.FuncJob(func() { job.Run(ctx) }))
+		s.entries[job.Name] = &entry{spec: spec, id: id}
+	}
+}
+
+// Spec returns the spec the job is currently scheduled on, empty if it is not scheduled.
+func (s *Scheduler) Spec(name string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if e, ok := s.entries[name]; ok {
+		return e.spec
+	}
+	return ""
+}
+
+// logger adapts slog to the logger of the cron scheduler.
+type logger struct{}
+
+func (*logger) Info(msg string, keysAndValues ...any) {
+	slog.Debug(msg, keysAndValues...)
+}
+
+func (*logger) Error(err error, msg string, keysAndValues ...any) {
+	slog.Error(msg, append(keysAndValues, "err", err)...)
+}
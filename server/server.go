@@ -22,8 +22,12 @@ import (
 	apiv1 "github.com/usememos/memos/server/router/api/v1"
 	"github.com/usememos/memos/server/router/frontend"
 	"github.com/usememos/memos/server/router/rss"
+	"github.com/usememos/memos/server/runner/accesstokenprune"
 	"github.com/usememos/memos/server/runner/memopayload"
+	"github.com/usememos/memos/server/runner/memoschedule"
 	"github.com/usememos/memos/server/runner/s3presign"
+	"github.com/usememos/memos/server/runner/webhookdelivery"
+	"github.com/usememos/memos/server/scheduler"
 	"github.com/usememos/memos/store"
 )
 
@@ -32,14 +36,18 @@ type Server struct {
 	Profile *profile.Profile
 	Store   *store.Store
 
-	echoServer *echo.Echo
-	grpcServer *grpc.Server
+	echoServer   *echo.Echo
+	grpcServer   *grpc.Server
+	apiV1Service *apiv1.APIV1Service
+	rateLimiter  *apiv1.RateLimiter
+	scheduler    *scheduler.Scheduler
 }
 
 func NewServer(ctx context.Context, profile *profile.Profile, store *store.Store) (*Server, error) {
 	s := &Server{
-		Store:   store,
-		Profile: profile,
+		Store:     store,
+		Profile:   profile,
+		scheduler: scheduler.NewScheduler(store),
 	}
 
 	echoServer := echo.New()
@@ -73,6 +81,8 @@ func NewServer(ctx context.Context, profile *profile.Profile, store *store.Store
 	// Create and register RSS routes.
 	rss.NewRSSService(s.Profile, s.Store).RegisterRoutes(rootGroup)
 
+	rateLimiter := apiv1.NewRateLimiter(store)
+	s.rateLimiter = rateLimiter
 	grpcServer := grpc.NewServer(
 		// Override the maximum receiving message size to math.MaxInt32 for uploading large resources.
 		grpc.MaxRecvMsgSize(math.MaxInt32),
@@ -80,10 +90,12 @@ func NewServer(ctx context.Context, profile *profile.Profile, store *store.Store
 			apiv1.NewLoggerInterceptor().LoggerInterceptor,
 			grpcrecovery.UnaryServerInterceptor(),
 			apiv1.NewGRPCAuthInterceptor(store, secret).AuthenticationInterceptor,
+			rateLimiter.RateLimitInterceptor,
 		))
 	s.grpcServer = grpcServer
 
-	apiV1Service := apiv1.NewAPIV1Service(s.Secret, profile, store, grpcServer)
+	apiV1Service := apiv1.NewAPIV1Service(s.Secret, profile, store, grpcServer, rateLimiter)
+	s.apiV1Service = apiV1Service
 	// Register gRPC gateway as api v1.
 	if err := apiV1Service.RegisterGateway(ctx, echoServer); err != nil {
 		return nil, errors.Wrap(err, "failed to register gRPC gateway")
@@ -146,8 +158,41 @@ func (s *Server) StartBackgroundRunners(ctx context.Context) {
 	memopayloadRunner := memopayload.NewRunner(s.Store)
 	// Rebuild all memos' payload after server starts.
 	memopayloadRunner.RunOnce(ctx)
+	webhookdeliveryRunner := webhookdelivery.NewRunner(s.Store)
+	memoscheduleRunner := memoschedule.NewRunner(s.Store, func(ctx context.Context, memo *store.Memo) {
+		if err := s.apiV1Service.DispatchMemoPublishedWebhook(ctx, memo); err != nil {
+			slog.Warn("Failed to dispatch memo published webhook", slog.Any("err", err))
+		}
+	})
+	accesstokenpruneRunner := accesstokenprune.NewRunner(s.Store)
 
-	go s3presignRunner.Run(ctx)
+	// The specs can be overridden with the workspace scheduler setting.
+	s.scheduler.Register(&scheduler.Job{
+		Name:        "s3presign",
+		DefaultSpec: "0 */12 * * *",
+		Run:         s3presignRunner.RunOnce,
+	})
+	s.scheduler.Register(&scheduler.Job{
+		Name:        "webhook_delivery",
+		DefaultSpec: "@every 10s",
+		Run:         webhookdeliveryRunner.RunOnce,
+	})
+	s.scheduler.Register(&scheduler.Job{
+		Name:        "memo_schedule",
+		DefaultSpec: "@every 15s",
+		Run:         memoscheduleRunner.RunOnce,
+	})
+	s.scheduler.Register(&scheduler.Job{
+		Name:        "access_token_prune",
+		DefaultSpec: "@every 1h",
+		Run:         accesstokenpruneRunner.RunOnce,
+	})
+	s.scheduler.Register(&scheduler.Job{
+		Name:        "rate_limit_prune",
+		DefaultSpec: "@every 1h",
+		Run:         s.rateLimiter.Prune,
+	})
+	s.scheduler.Start(ctx)
 }
 
 func (s *Server) getOrUpsertWorkspaceBasicSetting(ctx context.Context) (*storepb.WorkspaceBasicSetting, error) {
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// Permission is a named action that a role allows.
+type Permission string
+
+const (
+	// PermissionWorkspaceManage allows changing the workspace settings and identity providers.
+	PermissionWorkspaceManage Permission = "workspace.manage"
+	// PermissionUsersManage allows creating, listing, updating and deleting other users, and exporting their data.
+	PermissionUsersManage Permission = "users.manage"
+	// PermissionRolesManage allows defining custom roles and assigning roles to users. Since it allows
+	// granting any permission, only the host has it by default.
+	PermissionRolesManage Permission = "roles.manage"
+	// PermissionMemosManage allows editing, archiving and deleting the memos, resources and reactions of other users.
+	PermissionMemosManage Permission = "memos.manage"
+	// PermissionGroupsManage allows changing and deleting the groups of other users.
+	PermissionGroupsManage Permission = "groups.manage"
+)
+
+// Permissions is the list of all permissions.
+var Permissions = []Permission{
+	PermissionWorkspaceManage,
+	PermissionUsersManage,
+	PermissionRolesManage,
+	PermissionMemosManage,
+	PermissionGroupsManage,
+}
+
+// builtinRolePermissions is the permissions of the built-in roles, which cannot be changed.
+var builtinRolePermissions = map[Role][]Permission{
+	RoleHost:  Permissions,
+	RoleAdmin: {PermissionUsersManage, PermissionMemosManage, PermissionGroupsManage},
+	RoleUser:  {},
+}
+
+// CustomRole is a role defined by the workspace admins, e.g. a moderator. Its permissions are added to
+// the permissions of the built-in role of its members.
+type CustomRole struct {
+	ID          int32
+	Name        string
+	Description string
+	Permissions []Permission
+	CreatedTs   int64
+}
+
+type FindCustomRole struct {
+	ID   *int32
+	Name *string
+	// MemberID finds the roles that are assigned to the user.
+	MemberID *int32
+}
+
+type UpdateCustomRole struct {
+	ID          int32
+	Name        *string
+	Description *string
+	Permissions []Permission
+}
+
+type DeleteCustomRole struct {
+	ID int32
+}
+
+type CustomRoleMember struct {
+	RoleID int32
+	UserID int32
+}
+
+type FindCustomRoleMember struct {
+	RoleID *int32
+	UserID *int32
+}
+
+type DeleteCustomRoleMember struct {
+	RoleID *int32
+	UserID *int32
+}
+
+func (s *Store) CreateCustomRole(ctx context.Context, create *CustomRole) (*CustomRole, error) {
+	return s.driver.CreateCustomRole(ctx, create)
+}
+
+func (s *Store) ListCustomRoles(ctx context.Context, find *FindCustomRole) ([]*CustomRole, error) {
+	return s.driver.ListCustomRoles(ctx, find)
+}
+
+func (s *Store) GetCustomRole(ctx context.Context, find *FindCustomRole) (*CustomRole, error) {
+	list, err := s.ListCustomRoles(ctx, find)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+func (s *Store) UpdateCustomRole(ctx context.Context, update *UpdateCustomRole) (*CustomRole, error) {
+	return s.driver.UpdateCustomRole(ctx, update)
+}
+
+// DeleteCustomRole deletes the role with its members.
+func (s *Store) DeleteCustomRole(ctx context.Context, delete *DeleteCustomRole) error {
+	if err := s.driver.DeleteCustomRole(ctx, delete); err != nil {
+		return err
+	}
+	return s.DeleteCustomRoleMembers(ctx, &DeleteCustomRoleMember{RoleID: &delete.ID})
+}
+
+func (s *Store) UpsertCustomRoleMember(ctx context.Context, upsert *CustomRoleMember) (*CustomRoleMember, error) {
+	return s.driver.UpsertCustomRoleMember(ctx, upsert)
+}
+
+func (s *Store) ListCustomRoleMembers(ctx context.Context, find *FindCustomRoleMember) ([]*CustomRoleMember, error) {
+	return s.driver.ListCustomRoleMembers(ctx, find)
+}
+
+func (s *Store) DeleteCustomRoleMembers(ctx context.Context, delete *DeleteCustomRoleMember) error {
+	return s.driver.DeleteCustomRoleMembers(ctx, delete)
+}
+
+// ListUserPermissions returns the permissions of the user's built-in role and custom roles.
+func (s *Store) ListUserPermissions(ctx context.Context, user *User) ([]Permission, error) {
+	permissions := slices.Clone(builtinRolePermissions[user.Role])
+	roles, err := s.ListCustomRoles(ctx, &FindCustomRole{MemberID: &user.ID})
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions, nil
+}
+
+// HasPermission returns whether the user has the permission through their built-in role or custom roles.
+func (s *Store) HasPermission(ctx context.Context, user *User, permission Permission) (bool, error) {
+	if slices.Contains(builtinRolePermissions[user.Role], permission) {
+		return true, nil
+	}
+	permissions, err := s.ListUserPermissions(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(permissions, permission), nil
+}
+
+// JoinPermissions returns the comma-separated permissions that drivers store in the permissions column.
+func JoinPermissions(permissions []Permission) string {
+	list := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		list = append(list, string(permission))
+	}
+	return strings.Join(list, ",")
+}
+
+// SplitPermissions returns the permissions of the comma-separated permissions column.
+func SplitPermissions(s string) []Permission {
+	permissions := []Permission{}
+	for _, permission := range strings.Split(s, ",") {
+		if permission != "" {
+			permissions = append(permissions, Permission(permission))
+		}
+	}
+	return permissions
+}
@@ -11,9 +11,9 @@ import (
 )
 
 func (d *DB) CreateIdentityProvider(ctx context.Context, create *store.IdentityProvider) (*store.IdentityProvider, error) {
-	placeholders := []string{"?", "?", "?", "?"}
-	fields := []string{"`name`", "`type`", "`identifier_filter`", "`config`"}
-	args := []any{create.Name, create.Type.String(), create.IdentifierFilter, create.Config}
+	placeholders := []string{"?", "?", "?", "?", "?"}
+	fields := []string{"`name`", "`type`", "`identifier_filter`", "`config`", "`provisioning`"}
+	args := []any{create.Name, create.Type.String(), create.IdentifierFilter, create.Config, create.Provisioning}
 
 	stmt := "INSERT INTO `idp` (" + strings.Join(fields, ", ") + ") VALUES (" + strings.Join(placeholders, ", ") + ")"
 	result, err := d.db.ExecContext(ctx, stmt, args...)
@@ -36,7 +36,7 @@ func (d *DB) ListIdentityProviders(ctx context.Context, find *store.FindIdentity
 		where, args = append(where, "`id` = ?"), append(args, *v)
 	}
 
-	rows, err := d.db.QueryContext(ctx, "SELECT `id`, `name`, `type`, `identifier_filter`, `config` FROM `idp` WHERE "+strings.Join(where, " AND ")+" ORDER BY `id` ASC",
+	rows, err := d.db.QueryContext(ctx, "SELECT `id`, `name`, `type`, `identifier_filter`, `config`, `provisioning` FROM `idp` WHERE "+strings.Join(where, " AND ")+" ORDER BY `id` ASC",
 		args...,
 	)
 	if err != nil {
@@ -54,6 +54,7 @@ func (d *DB) ListIdentityProviders(ctx context.Context, find *store.FindIdentity
 			&typeString,
 			&identityProvider.IdentifierFilter,
 			&identityProvider.Config,
+			&identityProvider.Provisioning,
 		); err != nil {
 			return nil, err
 		}
@@ -92,6 +93,9 @@ func (d *DB) UpdateIdentityProvider(ctx context.Context, update *store.UpdateIde
 	if v := update.Config; v != nil {
 		set, args = append(set, "`config` = ?"), append(args, *v)
 	}
+	if v := update.Provisioning; v != nil {
+		set, args = append(set, "`provisioning` = ?"), append(args, *v)
+	}
 	args = append(args, update.ID)
 
 	stmt := "UPDATE `idp` SET " + strings.Join(set, ", ") + " WHERE `id` = ?"
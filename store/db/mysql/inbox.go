@@ -60,8 +60,11 @@ func (d *DB) ListInboxes(ctx context.Context, find *store.FindInbox) ([]*store.I
 	if find.Status != nil {
 		where, args = append(where, "`status` = ?"), append(args, *find.Status)
 	}
+	if v := find.Cursor; v != nil {
+		where, args = append(where, "(UNIX_TIMESTAMP(`created_ts`) < ? OR (UNIX_TIMESTAMP(`created_ts`) = ? AND `id` < ?))"), append(args, v.CreatedTs, v.CreatedTs, v.ID)
+	}
 
-	query := "SELECT `id`, UNIX_TIMESTAMP(`created_ts`), `sender_id`, `receiver_id`, `status`, `message` FROM `inbox` WHERE " + strings.Join(where, " AND ") + " ORDER BY `created_ts` DESC"
+	query := "SELECT `id`, UNIX_TIMESTAMP(`created_ts`), `sender_id`, `receiver_id`, `status`, `message` FROM `inbox` WHERE " + strings.Join(where, " AND ") + " ORDER BY `created_ts` DESC, `id` DESC"
 	if find.Limit != nil {
 		query = fmt.Sprintf("%s LIMIT %d", query, *find.Limit)
 		if find.Offset != nil {
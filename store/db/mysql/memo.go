@@ -14,8 +14,8 @@ import (
 )
 
 func (d *DB) CreateMemo(ctx context.Context, create *store.Memo) (*store.Memo, error) {
-	fields := []string{"`uid`", "`creator_id`", "`content`", "`visibility`", "`payload`"}
-	placeholder := []string{"?", "?", "?", "?", "?"}
+	fields := []string{"`uid`", "`creator_id`", "`content`", "`visibility`", "`payload`", "`scheduled_ts`"}
+	placeholder := []string{"?", "?", "?", "?", "?", "?"}
 	payload := "{}"
 	if create.Payload != nil {
 		payloadBytes, err := protojson.Marshal(create.Payload)
@@ -24,10 +24,16 @@ func (d *DB) CreateMemo(ctx context.Context, create *store.Memo) (*store.Memo, e
 		}
 		payload = string(payloadBytes)
 	}
-	args := []any{create.UID, create.CreatorID, create.Content, create.Visibility, payload}
+	args := []any{create.UID, create.CreatorID, create.Content, create.Visibility, payload, create.ScheduledTs}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
 	stmt := "INSERT INTO `memo` (" + strings.Join(fields, ", ") + ") VALUES (" + strings.Join(placeholder, ", ") + ")"
-	result, err := d.db.ExecContext(ctx, stmt, args...)
+	result, err := tx.ExecContext(ctx, stmt, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +43,15 @@ func (d *DB) CreateMemo(ctx context.Context, create *store.Memo) (*store.Memo, e
 		return nil, err
 	}
 	id := int32(rawID)
+	if err := setMemoTags(ctx, tx, id, create.Payload.GetTags()); err != nil {
+		return nil, err
+	}
+	if err := recordMemoRevision(ctx, tx, id, nil); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 	memo, err := d.GetMemo(ctx, &store.FindMemo{ID: &id})
 	if err != nil {
 		return nil, err
@@ -47,6 +62,15 @@ func (d *DB) CreateMemo(ctx context.Context, create *store.Memo) (*store.Memo, e
 	return memo, nil
 }
 
+// memoOrderColumns are the expressions of the memo list query that memos can be ordered by.
+var memoOrderColumns = map[store.MemoOrderField]string{
+	store.MemoOrderFieldPinned:        "`pinned`",
+	store.MemoOrderFieldCreatedTs:     "`created_ts`",
+	store.MemoOrderFieldUpdatedTs:     "`updated_ts`",
+	store.MemoOrderFieldReactionCount: filter.ReactionCount(filterDialect{}),
+	store.MemoOrderFieldCommentCount:  filter.CommentCount(filterDialect{}),
+}
+
 func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo, error) {
 	where, having, args := []string{"1 = 1"}, []string{"1 = 1"}, []any{}
 
@@ -62,6 +86,12 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 	if v := find.RowStatus; v != nil {
 		where, args = append(where, "`memo`.`row_status` = ?"), append(args, *v)
 	}
+	if find.ExcludeScheduled {
+		where = append(where, "`memo`.`scheduled_ts` = 0")
+	}
+	if v := find.ScheduledTsBefore; v != nil {
+		where, args = append(where, "`memo`.`scheduled_ts` != 0 AND `memo`.`scheduled_ts` <= ?"), append(args, *v)
+	}
 	if v := find.CreatedTsBefore; v != nil {
 		where, args = append(where, "UNIX_TIMESTAMP(`memo`.`created_ts`) < ?"), append(args, *v)
 	}
@@ -79,22 +109,32 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 			where, args = append(where, "`memo`.`content` LIKE ?"), append(args, "%"+s+"%")
 		}
 	}
+	if v := find.FullTextSearch; v != nil {
+		where, args = append(where, "MATCH(`memo`.`content`) AGAINST (? IN BOOLEAN MODE)"), append(args, buildFullTextQuery(*v))
+	}
 	if v := find.VisibilityList; len(v) != 0 {
 		placeholder := []string{}
 		for _, visibility := range v {
 			placeholder = append(placeholder, "?")
 			args = append(args, visibility.String())
 		}
-		where = append(where, fmt.Sprintf("`memo`.`visibility` in (%s)", strings.Join(placeholder, ",")))
+		condition := fmt.Sprintf("`memo`.`visibility` in (%s)", strings.Join(placeholder, ","))
+		if find.GrantedUserID != nil {
+			condition = fmt.Sprintf("(%s OR EXISTS (SELECT 1 FROM `memo_grant` WHERE `memo_grant`.`memo_id` = `memo`.`id` AND ((`memo_grant`.`principal_type` = 'USER' AND `memo_grant`.`principal_id` = ?) OR (`memo_grant`.`principal_type` = 'GROUP' AND `memo_grant`.`principal_id` IN (SELECT `group_id` FROM `user_group_member` WHERE `user_id` = ?)))))", condition)
+			args = append(args, *find.GrantedUserID, *find.GrantedUserID)
+		}
+		where = append(where, condition)
 	}
 	if v := find.PayloadFind; v != nil {
 		if v.Raw != nil {
 			where, args = append(where, "`memo`.`payload` = ?"), append(args, *v.Raw)
 		}
 		if len(v.TagSearch) != 0 {
-			for _, tag := range v.TagSearch {
-				where, args = append(where, "(JSON_CONTAINS(JSON_EXTRACT(`memo`.`payload`, '$.tags'), ?) OR JSON_CONTAINS(JSON_EXTRACT(`memo`.`payload`, '$.tags'), ?))"), append(args, fmt.Sprintf(`"%s"`, tag), fmt.Sprintf(`"%s/"`, tag))
+			condition, tagArgs, err := filter.ConvertMemoFilter(filter.TagSearchFilter(v.TagSearch), filterDialect{}, len(args))
+			if err != nil {
+				return nil, err
 			}
+			where, args = append(where, condition), append(args, tagArgs...)
 		}
 		if v.HasLink {
 			where = append(where, "JSON_EXTRACT(`memo`.`payload`, '$.property.hasLink') IS TRUE")
@@ -110,41 +150,72 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 		}
 	}
 	if v := find.Filter; v != nil {
-		// Parse filter string and return the parsed expression.
-		// The filter string should be a CEL expression.
-		parsedExpr, err := filter.Parse(*v, filter.MemoFilterCELAttributes...)
+		condition, filterArgs, err := filter.ConvertMemoFilter(*v, filterDialect{}, len(args))
 		if err != nil {
 			return nil, err
 		}
-		convertCtx := filter.NewConvertContext()
-		// ConvertExprToSQL converts the parsed expression to a SQL condition string.
-		if err := d.ConvertExprToSQL(convertCtx, parsedExpr.GetExpr()); err != nil {
-			return nil, err
-		}
-		condition := convertCtx.Buffer.String()
 		if condition != "" {
 			where = append(where, fmt.Sprintf("(%s)", condition))
-			args = append(args, convertCtx.Args...)
+			args = append(args, filterArgs...)
 		}
 	}
 	if find.ExcludeComments {
 		having = append(having, "`parent_id` IS NULL")
 	}
-
-	orders := []string{}
-	if find.OrderByPinned {
-		orders = append(orders, "`pinned` DESC")
+	if v := find.ParentID; v != nil {
+		where, args = append(where, "`memo_relation`.`related_memo_id` = ?"), append(args, *v)
 	}
-	order := "DESC"
-	if find.OrderByTimeAsc {
-		order = "ASC"
+	if v := find.Cursor; v != nil {
+		tsColumn := "UNIX_TIMESTAMP(`memo`.`created_ts`)"
+		if find.OrderByUpdatedTs {
+			tsColumn = "UNIX_TIMESTAMP(`memo`.`updated_ts`)"
+		}
+		operator := "<"
+		if find.OrderByTimeAsc {
+			operator = ">"
+		}
+		condition := fmt.Sprintf("(%s %s ? OR (%s = ? AND `memo`.`id` %s ?))", tsColumn, operator, tsColumn, operator)
+		cursorArgs := []any{v.DisplayTs, v.DisplayTs, v.ID}
+		if find.OrderByPinned {
+			condition = fmt.Sprintf("(`memo`.`pinned` < ? OR (`memo`.`pinned` = ? AND %s))", condition)
+			cursorArgs = append([]any{v.Pinned, v.Pinned}, cursorArgs...)
+		}
+		where, args = append(where, condition), append(args, cursorArgs...)
 	}
-	if find.OrderByUpdatedTs {
-		orders = append(orders, "`updated_ts` "+order)
+
+	orders := []string{}
+	if find.FullTextSearch != nil {
+		orders = append(orders, "`search_score` DESC")
+	}
+	if len(find.OrderBy) > 0 {
+		order := "ASC"
+		for _, memoOrder := range find.OrderBy {
+			column, ok := memoOrderColumns[memoOrder.Field]
+			if !ok {
+				return nil, errors.Errorf("unsupported order field %q", memoOrder.Field)
+			}
+			order = "ASC"
+			if memoOrder.Desc {
+				order = "DESC"
+			}
+			orders = append(orders, column+" "+order)
+		}
+		orders = append(orders, "`id` "+order)
 	} else {
-		orders = append(orders, "`created_ts` "+order)
+		if find.OrderByPinned {
+			orders = append(orders, "`pinned` DESC")
+		}
+		order := "DESC"
+		if find.OrderByTimeAsc {
+			order = "ASC"
+		}
+		if find.OrderByUpdatedTs {
+			orders = append(orders, "`updated_ts` "+order)
+		} else {
+			orders = append(orders, "`created_ts` "+order)
+		}
+		orders = append(orders, "`id` "+order)
 	}
-	orders = append(orders, "`id` "+order)
 	fields := []string{
 		"`memo`.`id` AS `id`",
 		"`memo`.`uid` AS `uid`",
@@ -155,11 +226,17 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 		"`memo`.`visibility` AS `visibility`",
 		"`memo`.`pinned` AS `pinned`",
 		"`memo`.`payload` AS `payload`",
+		"`memo`.`scheduled_ts` AS `scheduled_ts`",
 		"`memo_relation`.`related_memo_id` AS `parent_id`",
 	}
 	if !find.ExcludeContent {
 		fields = append(fields, "`memo`.`content` AS `content`")
 	}
+	if v := find.FullTextSearch; v != nil {
+		fields = append(fields, "MATCH(`memo`.`content`) AGAINST (? IN BOOLEAN MODE) AS `search_score`")
+		// The select list comes before the WHERE clause, so its argument goes first.
+		args = append([]any{buildFullTextQuery(*v)}, args...)
+	}
 
 	query := "SELECT " + strings.Join(fields, ", ") + " FROM `memo`" + " " +
 		"LEFT JOIN `memo_relation` ON `memo`.`id` = `memo_relation`.`memo_id` AND `memo_relation`.`type` = 'COMMENT'" + " " +
@@ -193,11 +270,15 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 			&memo.Visibility,
 			&memo.Pinned,
 			&payloadBytes,
+			&memo.ScheduledTs,
 			&memo.ParentID,
 		}
 		if !find.ExcludeContent {
 			dests = append(dests, &memo.Content)
 		}
+		if find.FullTextSearch != nil {
+			dests = append(dests, &memo.SearchScore)
+		}
 		if err := rows.Scan(dests...); err != nil {
 			return nil, err
 		}
@@ -216,6 +297,15 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 	return list, nil
 }
 
+// buildFullTextQuery converts the search query to a boolean mode query requiring all terms by prefix.
+func buildFullTextQuery(query string) string {
+	terms := []string{}
+	for _, term := range store.SplitSearchTerms(query) {
+		terms = append(terms, fmt.Sprintf("+%s*", term))
+	}
+	return strings.Join(terms, " ")
+}
+
 func (d *DB) GetMemo(ctx context.Context, find *store.FindMemo) (*store.Memo, error) {
 	list, err := d.ListMemos(ctx, find)
 	if err != nil {
@@ -252,6 +342,9 @@ func (d *DB) UpdateMemo(ctx context.Context, update *store.UpdateMemo) error {
 	if v := update.Pinned; v != nil {
 		set, args = append(set, "`pinned` = ?"), append(args, *v)
 	}
+	if v := update.ScheduledTs; v != nil {
+		set, args = append(set, "`scheduled_ts` = ?"), append(args, *v)
+	}
 	if v := update.Payload; v != nil {
 		payloadBytes, err := protojson.Marshal(v)
 		if err != nil {
@@ -260,15 +353,50 @@ func (d *DB) UpdateMemo(ctx context.Context, update *store.UpdateMemo) error {
 		set, args = append(set, "`payload` = ?"), append(args, string(payloadBytes))
 	}
 	if len(set) == 0 {
-		return nil
+		if update.Current == nil {
+			return nil
+		}
+		// Nothing is set, but the memo is still checked to be unchanged.
+		set = append(set, "`id` = `id`")
+	}
+	where, whereArgs := []string{"`id` = ?"}, []any{update.ID}
+	if v := update.Current; v != nil {
+		where = append(where, "UNIX_TIMESTAMP(`created_ts`) = ?", "UNIX_TIMESTAMP(`updated_ts`) = ?", "`scheduled_ts` = ?", "`row_status` = ?", "`visibility` = ?", "`pinned` = ?", "`content` = ?")
+		whereArgs = append(whereArgs, v.CreatedTs, v.UpdatedTs, v.ScheduledTs, v.RowStatus, v.Visibility, v.Pinned, v.Content)
 	}
-	args = append(args, update.ID)
+	args = append(args, whereArgs...)
 
-	stmt := "UPDATE `memo` SET " + strings.Join(set, ", ") + " WHERE `id` = ?"
-	if _, err := d.db.ExecContext(ctx, stmt, args...); err != nil {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
 		return err
 	}
-	return nil
+	defer tx.Rollback()
+
+	stmt := "UPDATE `memo` SET " + strings.Join(set, ", ") + " WHERE " + strings.Join(where, " AND ")
+	result, err := tx.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	if update.Current != nil {
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return store.ErrMemoModified
+		}
+	}
+	if update.Payload != nil {
+		if err := setMemoTags(ctx, tx, update.ID, update.Payload.Tags); err != nil {
+			return err
+		}
+	}
+	if update.Content != nil || update.Visibility != nil {
+		if err := recordMemoRevision(ctx, tx, update.ID, update.EditorID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 func (d *DB) DeleteMemo(ctx context.Context, delete *store.DeleteMemo) error {
@@ -16,24 +16,19 @@ func TestConvertExprToSQL(t *testing.T) {
 	}{
 		{
 			filter: `tag in ["tag1", "tag2"]`,
-			want:   "(JSON_CONTAINS(JSON_EXTRACT(`memo`.`payload`, '$.tags'), ?) OR JSON_CONTAINS(JSON_EXTRACT(`memo`.`payload`, '$.tags'), ?))",
+			want:   "EXISTS (SELECT 1 FROM `memo_tag` WHERE `memo_tag`.`memo_id` = `memo`.`id` AND `memo_tag`.`tag` IN (?,?))",
 			args:   []any{"tag1", "tag2"},
 		},
 		{
-			filter: `!(tag in ["tag1", "tag2"])`,
-			want:   "NOT ((JSON_CONTAINS(JSON_EXTRACT(`memo`.`payload`, '$.tags'), ?) OR JSON_CONTAINS(JSON_EXTRACT(`memo`.`payload`, '$.tags'), ?)))",
-			args:   []any{"tag1", "tag2"},
+			filter: `tag.startsWith("work")`,
+			want:   "EXISTS (SELECT 1 FROM `memo_tag` WHERE `memo_tag`.`memo_id` = `memo`.`id` AND SUBSTR(`memo_tag`.`tag`, 1, 4) = ?)",
+			args:   []any{"work"},
 		},
 		{
 			filter: `content.contains("memos")`,
-			want:   "`memo`.`content` LIKE ?",
+			want:   "LOWER(`memo`.`content`) LIKE LOWER(?)",
 			args:   []any{"%memos%"},
 		},
-		{
-			filter: `visibility in ["PUBLIC"]`,
-			want:   "`memo`.`visibility` IN (?)",
-			args:   []any{"PUBLIC"},
-		},
 		{
 			filter: `visibility in ["PUBLIC", "PRIVATE"]`,
 			want:   "`memo`.`visibility` IN (?,?)",
@@ -45,20 +40,31 @@ func TestConvertExprToSQL(t *testing.T) {
 			args:   []any{int64(1136189045)},
 		},
 		{
-			filter: `tag in ['tag1'] || content.contains('hello')`,
-			want:   "(JSON_CONTAINS(JSON_EXTRACT(`memo`.`payload`, '$.tags'), ?) OR `memo`.`content` LIKE ?)",
-			args:   []any{"tag1", "%hello%"},
+			filter: `pinned && creator == "users/1"`,
+			want:   "(`memo`.`pinned` = TRUE AND `memo`.`creator_id` = ?)",
+			args:   []any{int32(1)},
+		},
+		{
+			filter: `has_link && !has_code`,
+			want:   "(JSON_EXTRACT(`memo`.`payload`, '$.property.hasLink') IS TRUE AND NOT (JSON_EXTRACT(`memo`.`payload`, '$.property.hasCode') IS TRUE))",
+			args:   []any{},
+		},
+		{
+			filter: `location.latitude > 10 && location.placeholder.contains("Paris")`,
+			want:   "(JSON_EXTRACT(`memo`.`payload`, '$.location.latitude') > ? AND LOWER(JSON_UNQUOTE(JSON_EXTRACT(`memo`.`payload`, '$.location.placeholder'))) LIKE LOWER(?))",
+			args:   []any{float64(10), "%Paris%"},
+		},
+		{
+			filter: `reaction_count > 0`,
+			want:   "(SELECT COUNT(*) FROM `reaction` WHERE `reaction`.`content_id` = CONCAT('memos/', `memo`.`uid`)) > ?",
+			args:   []any{int64(0)},
 		},
 	}
 
 	for _, tt := range tests {
-		db := &DB{}
-		parsedExpr, err := filter.Parse(tt.filter, filter.MemoFilterCELAttributes...)
-		require.NoError(t, err)
-		convertCtx := filter.NewConvertContext()
-		err = db.ConvertExprToSQL(convertCtx, parsedExpr.GetExpr())
+		condition, args, err := filter.ConvertMemoFilter(tt.filter, filterDialect{}, 0)
 		require.NoError(t, err)
-		require.Equal(t, tt.want, convertCtx.Buffer.String())
-		require.Equal(t, tt.args, convertCtx.Args)
+		require.Equal(t, tt.want, condition)
+		require.Equal(t, tt.args, args)
 	}
 }
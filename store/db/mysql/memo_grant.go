@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/usememos/memos/store"
+)
+
+func (d *DB) UpsertMemoGrant(ctx context.Context, upsert *store.MemoGrant) (*store.MemoGrant, error) {
+	stmt := "INSERT INTO `memo_grant` (`memo_id`, `principal_type`, `principal_id`, `permission`) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE `permission` = ?"
+	if _, err := d.db.ExecContext(ctx, stmt, upsert.MemoID, upsert.PrincipalType, upsert.PrincipalID, upsert.Permission, upsert.Permission); err != nil {
+		return nil, err
+	}
+	return upsert, nil
+}
+
+func (d *DB) ListMemoGrants(ctx context.Context, find *store.FindMemoGrant) ([]*store.MemoGrant, error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if find.MemoID != nil {
+		where, args = append(where, "`memo_id` = ?"), append(args, *find.MemoID)
+	}
+	if find.PrincipalType != nil {
+		where, args = append(where, "`principal_type` = ?"), append(args, *find.PrincipalType)
+	}
+	if find.PrincipalID != nil {
+		where, args = append(where, "`principal_id` = ?"), append(args, *find.PrincipalID)
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT `memo_id`, `principal_type`, `principal_id`, `permission` FROM `memo_grant` WHERE "+strings.Join(where, " AND ")+" ORDER BY `memo_id` ASC, `principal_type` DESC, `principal_id` ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*store.MemoGrant{}
+	for rows.Next() {
+		grant := &store.MemoGrant{}
+		if err := rows.Scan(
+			&grant.MemoID,
+			&grant.PrincipalType,
+			&grant.PrincipalID,
+			&grant.Permission,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, grant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (d *DB) DeleteMemoGrants(ctx context.Context, delete *store.DeleteMemoGrant) error {
+	where, args := []string{"1 = 1"}, []any{}
+	if delete.MemoID != nil {
+		where, args = append(where, "`memo_id` = ?"), append(args, *delete.MemoID)
+	}
+	if delete.PrincipalType != nil {
+		where, args = append(where, "`principal_type` = ?"), append(args, *delete.PrincipalType)
+	}
+	if delete.PrincipalID != nil {
+		where, args = append(where, "`principal_id` = ?"), append(args, *delete.PrincipalID)
+	}
+	_, err := d.db.ExecContext(ctx, "DELETE FROM `memo_grant` WHERE "+strings.Join(where, " AND "), args...)
+	return err
+}
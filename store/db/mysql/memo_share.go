@@ -0,0 +1,105 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/usememos/memos/store"
+)
+
+func (d *DB) CreateMemoShare(ctx context.Context, create *store.MemoShare) (*store.MemoShare, error) {
+	fields := []string{"`token`", "`memo_id`", "`creator_id`", "`expires_ts`"}
+	placeholder := []string{"?", "?", "?", "?"}
+	args := []any{create.Token, create.MemoID, create.CreatorID, create.ExpiresTs}
+
+	stmt := "INSERT INTO `memo_share` (" + strings.Join(fields, ", ") + ") VALUES (" + strings.Join(placeholder, ", ") + ")"
+	result, err := d.db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	create.ID = int32(id)
+	return d.getMemoShare(ctx, create.ID)
+}
+
+func (d *DB) ListMemoShares(ctx context.Context, find *store.FindMemoShare) ([]*store.MemoShare, error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if find.ID != nil {
+		where, args = append(where, "`id` = ?"), append(args, *find.ID)
+	}
+	if find.Token != nil {
+		where, args = append(where, "`token` = ?"), append(args, *find.Token)
+	}
+	if find.MemoID != nil {
+		where, args = append(where, "`memo_id` = ?"), append(args, *find.MemoID)
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT `id`, `token`, `memo_id`, `creator_id`, UNIX_TIMESTAMP(`created_ts`), `expires_ts`, `view_count` FROM `memo_share` WHERE "+strings.Join(where, " AND ")+" ORDER BY `id` DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*store.MemoShare{}
+	for rows.Next() {
+		share := &store.MemoShare{}
+		if err := rows.Scan(
+			&share.ID,
+			&share.Token,
+			&share.MemoID,
+			&share.CreatorID,
+			&share.CreatedTs,
+			&share.ExpiresTs,
+			&share.ViewCount,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, share)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (d *DB) getMemoShare(ctx context.Context, id int32) (*store.MemoShare, error) {
+	list, err := d.ListMemoShares(ctx, &store.FindMemoShare{ID: &id})
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+func (d *DB) UpdateMemoShare(ctx context.Context, update *store.UpdateMemoShare) error {
+	set := []string{}
+	if update.IncreaseViewCount {
+		set = append(set, "`view_count` = `view_count` + 1")
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	_, err := d.db.ExecContext(ctx, "UPDATE `memo_share` SET "+strings.Join(set, ", ")+" WHERE `id` = ?", update.ID)
+	return err
+}
+
+func (d *DB) DeleteMemoShares(ctx context.Context, delete *store.DeleteMemoShare) error {
+	where, args := []string{"1 = 1"}, []any{}
+	if delete.ID != nil {
+		where, args = append(where, "`id` = ?"), append(args, *delete.ID)
+	}
+	if delete.MemoID != nil {
+		where, args = append(where, "`memo_id` = ?"), append(args, *delete.MemoID)
+	}
+	_, err := d.db.ExecContext(ctx, "DELETE FROM `memo_share` WHERE "+strings.Join(where, " AND "), args...)
+	return err
+}
@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+
+	"github.com/usememos/memos/store"
+)
+
+func (d *DB) CreateMemoTag(ctx context.Context, create *store.MemoTag) (*store.MemoTag, error) {
+	stmt := "INSERT INTO `memo_tag` (`memo_id`, `tag`) VALUES (?, ?)"
+	if _, err := d.db.ExecContext(ctx, stmt, create.MemoID, create.Tag); err != nil {
+		return nil, err
+	}
+	memoTag := create
+	return memoTag, nil
+}
+
+func (d *DB) ListMemoTags(ctx context.Context, find *store.FindMemoTag) ([]*store.MemoTag, error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if find.MemoID != nil {
+		where, args = append(where, "`memo_id` = ?"), append(args, *find.MemoID)
+	}
+	if len(find.MemoIDList) > 0 {
+		placeholders := []string{}
+		for _, id := range find.MemoIDList {
+			placeholders, args = append(placeholders, "?"), append(args, id)
+		}
+		where = append(where, "`memo_id` IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if find.Tag != nil {
+		where, args = append(where, "`tag` = ?"), append(args, *find.Tag)
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT `memo_id`, `tag` FROM `memo_tag` WHERE "+strings.Join(where, " AND ")+" ORDER BY `memo_id`, `tag`", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*store.MemoTag{}
+	for rows.Next() {
+		memoTag := &store.MemoTag{}
+		if err := rows.Scan(
+			&memoTag.MemoID,
+			&memoTag.Tag,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, memoTag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (d *DB) DeleteMemoTags(ctx context.Context, delete *store.DeleteMemoTag) error {
+	where, args := []string{"1 = 1"}, []any{}
+	if delete.MemoID != nil {
+		where, args = append(where, "`memo_id` = ?"), append(args, *delete.MemoID)
+	}
+	_, err := d.db.ExecContext(ctx, "DELETE FROM `memo_tag` WHERE "+strings.Join(where, " AND "), args...)
+	return err
+}
+
+// setMemoTags replaces the tags of the memo in the transaction.
+func setMemoTags(ctx context.Context, tx *sql.Tx, memoID int32, tags []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM `memo_tag` WHERE `memo_id` = ?", memoID); err != nil {
+		return err
+	}
+	created := []string{}
+	for _, tag := range tags {
+		if slices.Contains(created, tag) {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO `memo_tag` (`memo_id`, `tag`) VALUES (?, ?)", memoID, tag); err != nil {
+			return err
+		}
+		created = append(created, tag)
+	}
+	return nil
+}
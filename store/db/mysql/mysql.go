@@ -54,5 +54,7 @@ func mergeDSN(baseDSN string) (string, error) {
 	}
 
 	config.MultiStatements = true
+	// Count the rows that updates match rather than change, like the other drivers do.
+	config.ClientFoundRows = true
 	return config.FormatDSN(), nil
 }
@@ -0,0 +1,108 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/usememos/memos/store"
+)
+
+func (d *DB) UpsertRateLimit(ctx context.Context, upsert *store.RateLimit) error {
+	stmt := "INSERT INTO `rate_limit` (`key`, `tokens`, `failures`, `locked_until_ts`, `updated_ms`) VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `tokens` = ?, `failures` = ?, `locked_until_ts` = ?, `updated_ms` = ?"
+	_, err := d.db.ExecContext(ctx, stmt, upsert.Key, upsert.Tokens, upsert.Failures, upsert.LockedUntilTs, upsert.UpdatedMs, upsert.Tokens, upsert.Failures, upsert.LockedUntilTs, upsert.UpdatedMs)
+	return err
+}
+
+func (d *DB) TakeRateLimitToken(ctx context.Context, take *store.TakeRateLimitToken) (bool, error) {
+	if _, err := d.db.ExecContext(ctx, "INSERT IGNORE INTO `rate_limit` (`key`, `tokens`, `updated_ms`) VALUES (?, ?, ?)", take.Key, take.Burst, take.NowMs); err != nil {
+		return false, err
+	}
+	// The bucket is refilled and taken from in a single statement, so that concurrent requests do not take
+	// the same token. The tokens are set first, since MySQL assigns the columns in order.
+	tokens := "LEAST(?, `tokens` + GREATEST(? - `updated_ms`, 0) * ?)"
+	stmt := "UPDATE `rate_limit` SET `tokens` = " + tokens + " - 1, `updated_ms` = ? WHERE `key` = ? AND " + tokens + " >= 1"
+	result, err := d.db.ExecContext(ctx, stmt, take.Burst, take.NowMs, take.TokensPerMs, take.NowMs, take.Key, take.Burst, take.NowMs, take.TokensPerMs)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (d *DB) IncreaseRateLimitFailures(ctx context.Context, increase *store.IncreaseRateLimitFailures) (int32, error) {
+	if _, err := d.db.ExecContext(ctx, "INSERT IGNORE INTO `rate_limit` (`key`, `updated_ms`) VALUES (?, ?)", increase.Key, increase.NowMs); err != nil {
+		return 0, err
+	}
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// The failures are set first, since MySQL assigns the columns in order. The update locks the row
+	// until the commit, so that the select reads the failures it set.
+	stmt := "UPDATE `rate_limit` SET `failures` = CASE WHEN `updated_ms` < ? THEN 1 ELSE `failures` + 1 END, `updated_ms` = ? WHERE `key` = ?"
+	if _, err := tx.ExecContext(ctx, stmt, increase.NowMs-increase.WindowMs, increase.NowMs, increase.Key); err != nil {
+		return 0, err
+	}
+	var failures int32
+	if err := tx.QueryRowContext(ctx, "SELECT `failures` FROM `rate_limit` WHERE `key` = ?", increase.Key).Scan(&failures); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return failures, nil
+}
+
+func (d *DB) LockRateLimit(ctx context.Context, lock *store.LockRateLimit) error {
+	_, err := d.db.ExecContext(ctx, "UPDATE `rate_limit` SET `locked_until_ts` = GREATEST(`locked_until_ts`, ?) WHERE `key` = ?", lock.LockedUntilTs, lock.Key)
+	return err
+}
+
+func (d *DB) ListRateLimits(ctx context.Context, find *store.FindRateLimit) ([]*store.RateLimit, error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if find.Key != nil {
+		where, args = append(where, "`key` = ?"), append(args, *find.Key)
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT `key`, `tokens`, `failures`, `locked_until_ts`, `updated_ms` FROM `rate_limit` WHERE "+strings.Join(where, " AND "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*store.RateLimit{}
+	for rows.Next() {
+		rateLimit := &store.RateLimit{}
+		if err := rows.Scan(
+			&rateLimit.Key,
+			&rateLimit.Tokens,
+			&rateLimit.Failures,
+			&rateLimit.LockedUntilTs,
+			&rateLimit.UpdatedMs,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, rateLimit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (d *DB) DeleteRateLimits(ctx context.Context, delete *store.DeleteRateLimit) error {
+	where, args := []string{"1 = 1"}, []any{}
+	if delete.Key != nil {
+		where, args = append(where, "`key` = ?"), append(args, *delete.Key)
+	}
+	if delete.UpdatedMsBefore != nil {
+		where, args = append(where, "`updated_ms` < ?"), append(args, *delete.UpdatedMsBefore)
+	}
+	_, err := d.db.ExecContext(ctx, "DELETE FROM `rate_limit` WHERE "+strings.Join(where, " AND "), args...)
+	return err
+}
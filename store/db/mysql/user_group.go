@@ -0,0 +1,148 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/usememos/memos/store"
+)
+
+func (d *DB) CreateUserGroup(ctx context.Context, create *store.UserGroup) (*store.UserGroup, error) {
+	stmt := "INSERT INTO `user_group` (`name`, `creator_id`) VALUES (?, ?)"
+	result, err := d.db.ExecContext(ctx, stmt, create.Name, create.CreatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	create.ID = int32(id)
+	return d.getUserGroup(ctx, create.ID)
+}
+
+func (d *DB) ListUserGroups(ctx context.Context, find *store.FindUserGroup) ([]*store.UserGroup, error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if find.ID != nil {
+		where, args = append(where, "`id` = ?"), append(args, *find.ID)
+	}
+	if find.Name != nil {
+		where, args = append(where, "`name` = ?"), append(args, *find.Name)
+	}
+	if find.MemberID != nil {
+		where, args = append(where, "`id` IN (SELECT `group_id` FROM `user_group_member` WHERE `user_id` = ?)"), append(args, *find.MemberID)
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT `id`, `name`, `creator_id`, UNIX_TIMESTAMP(`created_ts`) FROM `user_group` WHERE "+strings.Join(where, " AND ")+" ORDER BY `name` ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*store.UserGroup{}
+	for rows.Next() {
+		group := &store.UserGroup{}
+		if err := rows.Scan(
+			&group.ID,
+			&group.Name,
+			&group.CreatorID,
+			&group.CreatedTs,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (d *DB) UpdateUserGroup(ctx context.Context, update *store.UpdateUserGroup) (*store.UserGroup, error) {
+	set, args := []string{}, []any{}
+	if v := update.Name; v != nil {
+		set, args = append(set, "`name` = ?"), append(args, *v)
+	}
+	if len(set) != 0 {
+		args = append(args, update.ID)
+		if _, err := d.db.ExecContext(ctx, "UPDATE `user_group` SET "+strings.Join(set, ", ")+" WHERE `id` = ?", args...); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.getUserGroup(ctx, update.ID)
+}
+
+func (d *DB) getUserGroup(ctx context.Context, id int32) (*store.UserGroup, error) {
+	list, err := d.ListUserGroups(ctx, &store.FindUserGroup{ID: &id})
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+func (d *DB) DeleteUserGroup(ctx context.Context, delete *store.DeleteUserGroup) error {
+	_, err := d.db.ExecContext(ctx, "DELETE FROM `user_group` WHERE `id` = ?", delete.ID)
+	return err
+}
+
+func (d *DB) UpsertUserGroupMember(ctx context.Context, upsert *store.UserGroupMember) (*store.UserGroupMember, error) {
+	stmt := "INSERT IGNORE INTO `user_group_member` (`group_id`, `user_id`) VALUES (?, ?)"
+	if _, err := d.db.ExecContext(ctx, stmt, upsert.GroupID, upsert.UserID); err != nil {
+		return nil, err
+	}
+	return upsert, nil
+}
+
+func (d *DB) ListUserGroupMembers(ctx context.Context, find *store.FindUserGroupMember) ([]*store.UserGroupMember, error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if find.GroupID != nil {
+		where, args = append(where, "`group_id` = ?"), append(args, *find.GroupID)
+	}
+	if find.UserID != nil {
+		where, args = append(where, "`user_id` = ?"), append(args, *find.UserID)
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT `group_id`, `user_id` FROM `user_group_member` WHERE "+strings.Join(where, " AND ")+" ORDER BY `group_id` ASC, `user_id` ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*store.UserGroupMember{}
+	for rows.Next() {
+		member := &store.UserGroupMember{}
+		if err := rows.Scan(
+			&member.GroupID,
+			&member.UserID,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (d *DB) DeleteUserGroupMembers(ctx context.Context, delete *store.DeleteUserGroupMember) error {
+	where, args := []string{"1 = 1"}, []any{}
+	if delete.GroupID != nil {
+		where, args = append(where, "`group_id` = ?"), append(args, *delete.GroupID)
+	}
+	if delete.UserID != nil {
+		where, args = append(where, "`user_id` = ?"), append(args, *delete.UserID)
+	}
+	_, err := d.db.ExecContext(ctx, "DELETE FROM `user_group_member` WHERE "+strings.Join(where, " AND "), args...)
+	return err
+}
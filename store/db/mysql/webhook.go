@@ -4,13 +4,23 @@ import (
 	"context"
 	"strings"
 
+	"google.golang.org/protobuf/encoding/protojson"
+
+	storepb "github.com/usememos/memos/proto/gen/store"
 	"github.com/usememos/memos/store"
 )
 
 func (d *DB) CreateWebhook(ctx context.Context, create *store.Webhook) (*store.Webhook, error) {
-	fields := []string{"`name`", "`url`", "`creator_id`"}
-	placeholder := []string{"?", "?", "?"}
-	args := []any{create.Name, create.URL, create.CreatorID}
+	fields := []string{"`name`", "`url`", "`creator_id`", "`secret`", "`payload`"}
+	placeholder := []string{"?", "?", "?", "?", "?"}
+	if create.Payload == nil {
+		create.Payload = &storepb.WebhookPayload{}
+	}
+	payloadBytes, err := protojson.Marshal(create.Payload)
+	if err != nil {
+		return nil, err
+	}
+	args := []any{create.Name, create.URL, create.CreatorID, create.Secret, string(payloadBytes)}
 
 	stmt := "INSERT INTO `webhook` (" + strings.Join(fields, ", ") + ") VALUES (" + strings.Join(placeholder, ", ") + ")"
 	result, err := d.db.ExecContext(ctx, stmt, args...)
@@ -36,7 +46,7 @@ func (d *DB) ListWebhooks(ctx context.Context, find *store.FindWebhook) ([]*stor
 		where, args = append(where, "`creator_id` = ?"), append(args, *find.CreatorID)
 	}
 
-	rows, err := d.db.QueryContext(ctx, "SELECT `id`, UNIX_TIMESTAMP(`created_ts`), UNIX_TIMESTAMP(`updated_ts`),  `creator_id`, `name`, `url` FROM `webhook` WHERE "+strings.Join(where, " AND ")+" ORDER BY `id` DESC",
+	rows, err := d.db.QueryContext(ctx, "SELECT `id`, UNIX_TIMESTAMP(`created_ts`), UNIX_TIMESTAMP(`updated_ts`),  `creator_id`, `name`, `url`, `secret`, `payload` FROM `webhook` WHERE "+strings.Join(where, " AND ")+" ORDER BY `id` DESC",
 		args...,
 	)
 	if err != nil {
@@ -47,6 +57,7 @@ func (d *DB) ListWebhooks(ctx context.Context, find *store.FindWebhook) ([]*stor
 	list := []*store.Webhook{}
 	for rows.Next() {
 		webhook := &store.Webhook{}
+		var payload string
 		if err := rows.Scan(
 			&webhook.ID,
 			&webhook.CreatedTs,
@@ -54,9 +65,15 @@ func (d *DB) ListWebhooks(ctx context.Context, find *store.FindWebhook) ([]*stor
 			&webhook.CreatorID,
 			&webhook.Name,
 			&webhook.URL,
+			&webhook.Secret,
+			&payload,
 		); err != nil {
 			return nil, err
 		}
+		webhook.Payload = &storepb.WebhookPayload{}
+		if err := protojsonUnmarshaler.Unmarshal([]byte(payload), webhook.Payload); err != nil {
+			return nil, err
+		}
 		list = append(list, webhook)
 	}
 
@@ -86,6 +103,16 @@ func (d *DB) UpdateWebhook(ctx context.Context, update *store.UpdateWebhook) (*s
 	if update.URL != nil {
 		set, args = append(set, "`url` = ?"), append(args, *update.URL)
 	}
+	if update.Secret != nil {
+		set, args = append(set, "`secret` = ?"), append(args, *update.Secret)
+	}
+	if update.Payload != nil {
+		payloadBytes, err := protojson.Marshal(update.Payload)
+		if err != nil {
+			return nil, err
+		}
+		set, args = append(set, "`payload` = ?"), append(args, string(payloadBytes))
+	}
 	args = append(args, update.ID)
 
 	stmt := "UPDATE `webhook` SET " + strings.Join(set, ", ") + " WHERE `id` = ?"
@@ -0,0 +1,143 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/usememos/memos/store"
+)
+
+func (d *DB) CreateWebhookDelivery(ctx context.Context, create *store.WebhookDelivery) (*store.WebhookDelivery, error) {
+	fields := []string{"`webhook_id`", "`activity_type`", "`payload`", "`status`", "`next_attempt_ts`", "`response_body`", "`error`"}
+	placeholder := []string{"?", "?", "?", "?", "FROM_UNIXTIME(?)", "?", "?"}
+	args := []any{create.WebhookID, create.ActivityType, create.Payload, create.Status, create.NextAttemptTs, create.ResponseBody, create.Error}
+
+	stmt := "INSERT INTO `webhook_delivery` (" + strings.Join(fields, ", ") + ") VALUES (" + strings.Join(placeholder, ", ") + ")"
+	result, err := d.db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	create.ID = int32(id)
+	return d.getWebhookDelivery(ctx, create.ID)
+}
+
+func (d *DB) ListWebhookDeliveries(ctx context.Context, find *store.FindWebhookDelivery) ([]*store.WebhookDelivery, error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if find.ID != nil {
+		where, args = append(where, "`id` = ?"), append(args, *find.ID)
+	}
+	if find.WebhookID != nil {
+		where, args = append(where, "`webhook_id` = ?"), append(args, *find.WebhookID)
+	}
+	if find.Status != nil {
+		where, args = append(where, "`status` = ?"), append(args, *find.Status)
+	}
+	if find.NextAttemptTsBefore != nil {
+		where, args = append(where, "UNIX_TIMESTAMP(`next_attempt_ts`) <= ?"), append(args, *find.NextAttemptTsBefore)
+	}
+
+	order := "DESC"
+	if find.OrderByIDAsc {
+		order = "ASC"
+	}
+	query := "SELECT `id`, UNIX_TIMESTAMP(`created_ts`), UNIX_TIMESTAMP(`updated_ts`), `webhook_id`, `activity_type`, `payload`, `status`, `attempts`, UNIX_TIMESTAMP(`next_attempt_ts`), `response_code`, `response_body`, `error` FROM `webhook_delivery` WHERE " + strings.Join(where, " AND ") + " ORDER BY `id` " + order
+	if find.Limit != nil {
+		query = fmt.Sprintf("%s LIMIT %d", query, *find.Limit)
+		if find.Offset != nil {
+			query = fmt.Sprintf("%s OFFSET %d", query, *find.Offset)
+		}
+	}
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*store.WebhookDelivery{}
+	for rows.Next() {
+		delivery := &store.WebhookDelivery{}
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.CreatedTs,
+			&delivery.UpdatedTs,
+			&delivery.WebhookID,
+			&delivery.ActivityType,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptTs,
+			&delivery.ResponseCode,
+			&delivery.ResponseBody,
+			&delivery.Error,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (d *DB) getWebhookDelivery(ctx context.Context, id int32) (*store.WebhookDelivery, error) {
+	list, err := d.ListWebhookDeliveries(ctx, &store.FindWebhookDelivery{ID: &id})
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+func (d *DB) UpdateWebhookDelivery(ctx context.Context, update *store.UpdateWebhookDelivery) (*store.WebhookDelivery, error) {
+	set, args := []string{"`updated_ts` = CURRENT_TIMESTAMP"}, []any{}
+	if update.Status != nil {
+		set, args = append(set, "`status` = ?"), append(args, *update.Status)
+	}
+	if update.Attempts != nil {
+		set, args = append(set, "`attempts` = ?"), append(args, *update.Attempts)
+	}
+	if update.NextAttemptTs != nil {
+		set, args = append(set, "`next_attempt_ts` = FROM_UNIXTIME(?)"), append(args, *update.NextAttemptTs)
+	}
+	if update.ResponseCode != nil {
+		set, args = append(set, "`response_code` = ?"), append(args, *update.ResponseCode)
+	}
+	if update.ResponseBody != nil {
+		set, args = append(set, "`response_body` = ?"), append(args, *update.ResponseBody)
+	}
+	if update.Error != nil {
+		set, args = append(set, "`error` = ?"), append(args, *update.Error)
+	}
+	args = append(args, update.ID)
+
+	stmt := "UPDATE `webhook_delivery` SET " + strings.Join(set, ", ") + " WHERE `id` = ?"
+	if _, err := d.db.ExecContext(ctx, stmt, args...); err != nil {
+		return nil, err
+	}
+
+	return d.getWebhookDelivery(ctx, update.ID)
+}
+
+func (d *DB) DeleteWebhookDeliveries(ctx context.Context, delete *store.DeleteWebhookDelivery) error {
+	where, args := []string{"1 = 1"}, []any{}
+	if delete.WebhookID != nil {
+		where, args = append(where, "`webhook_id` = ?"), append(args, *delete.WebhookID)
+	}
+	if delete.CreatedTsBefore != nil {
+		where, args = append(where, "UNIX_TIMESTAMP(`created_ts`) < ?"), append(args, *delete.CreatedTsBefore)
+	}
+	_, err := d.db.ExecContext(ctx, "DELETE FROM `webhook_delivery` WHERE "+strings.Join(where, " AND "), args...)
+	return err
+}
@@ -9,8 +9,8 @@ import (
 )
 
 func (d *DB) CreateIdentityProvider(ctx context.Context, create *store.IdentityProvider) (*store.IdentityProvider, error) {
-	fields := []string{"name", "type", "identifier_filter", "config"}
-	args := []any{create.Name, create.Type.String(), create.IdentifierFilter, create.Config}
+	fields := []string{"name", "type", "identifier_filter", "config", "provisioning"}
+	args := []any{create.Name, create.Type.String(), create.IdentifierFilter, create.Config, create.Provisioning}
 	stmt := "INSERT INTO idp (" + strings.Join(fields, ", ") + ") VALUES (" + placeholders(len(args)) + ") RETURNING id"
 	if err := d.db.QueryRowContext(ctx, stmt, args...).Scan(&create.ID); err != nil {
 		return nil, err
@@ -32,7 +32,8 @@ func (d *DB) ListIdentityProviders(ctx context.Context, find *store.FindIdentity
 			name,
 			type,
 			identifier_filter,
-			config
+			config,
+			provisioning
 		FROM idp
 		WHERE `+strings.Join(where, " AND ")+` ORDER BY id ASC`,
 		args...,
@@ -52,6 +53,7 @@ func (d *DB) ListIdentityProviders(ctx context.Context, find *store.FindIdentity
 			&typeString,
 			&identityProvider.IdentifierFilter,
 			&identityProvider.Config,
+			&identityProvider.Provisioning,
 		); err != nil {
 			return nil, err
 		}
@@ -78,12 +80,15 @@ func (d *DB) UpdateIdentityProvider(ctx context.Context, update *store.UpdateIde
 	if v := update.Config; v != nil {
 		set, args = append(set, "config = "+placeholder(len(args)+1)), append(args, *v)
 	}
+	if v := update.Provisioning; v != nil {
+		set, args = append(set, "provisioning = "+placeholder(len(args)+1)), append(args, *v)
+	}
 
 	stmt := `
 		UPDATE idp
 		SET ` + strings.Join(set, ", ") + `
 		WHERE id = ` + placeholder(len(args)+1) + `
-		RETURNING id, name, type, identifier_filter, config
+		RETURNING id, name, type, identifier_filter, config, provisioning
 	`
 	args = append(args, update.ID)
 
@@ -95,6 +100,7 @@ func (d *DB) UpdateIdentityProvider(ctx context.Context, update *store.UpdateIde
 		&typeString,
 		&identityProvider.IdentifierFilter,
 		&identityProvider.Config,
+		&identityProvider.Provisioning,
 	); err != nil {
 		return nil, err
 	}
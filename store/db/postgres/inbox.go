@@ -50,8 +50,11 @@ func (d *DB) ListInboxes(ctx context.Context, find *store.FindInbox) ([]*store.I
 	if find.Status != nil {
 		where, args = append(where, "status = "+placeholder(len(args)+1)), append(args, *find.Status)
 	}
+	if v := find.Cursor; v != nil {
+		where, args = append(where, fmt.Sprintf("(created_ts < %s OR (created_ts = %s AND id < %s))", placeholder(len(args)+1), placeholder(len(args)+1), placeholder(len(args)+2))), append(args, v.CreatedTs, v.ID)
+	}
 
-	query := "SELECT id, created_ts, sender_id, receiver_id, status, message FROM inbox WHERE " + strings.Join(where, " AND ") + " ORDER BY created_ts DESC"
+	query := "SELECT id, created_ts, sender_id, receiver_id, status, message FROM inbox WHERE " + strings.Join(where, " AND ") + " ORDER BY created_ts DESC, id DESC"
 	if find.Limit != nil {
 		query = fmt.Sprintf("%s LIMIT %d", query, *find.Limit)
 		if find.Offset != nil {
@@ -14,7 +14,7 @@ import (
 )
 
 func (d *DB) CreateMemo(ctx context.Context, create *store.Memo) (*store.Memo, error) {
-	fields := []string{"uid", "creator_id", "content", "visibility", "payload"}
+	fields := []string{"uid", "creator_id", "content", "visibility", "payload", "scheduled_ts"}
 	payload := "{}"
 	if create.Payload != nil {
 		payloadBytes, err := protojson.Marshal(create.Payload)
@@ -23,10 +23,16 @@ func (d *DB) CreateMemo(ctx context.Context, create *store.Memo) (*store.Memo, e
 		}
 		payload = string(payloadBytes)
 	}
-	args := []any{create.UID, create.CreatorID, create.Content, create.Visibility, payload}
+	args := []any{create.UID, create.CreatorID, create.Content, create.Visibility, payload, create.ScheduledTs}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
 	stmt := "INSERT INTO memo (" + strings.Join(fields, ", ") + ") VALUES (" + placeholders(len(args)) + ") RETURNING id, created_ts, updated_ts, row_status"
-	if err := d.db.QueryRowContext(ctx, stmt, args...).Scan(
+	if err := tx.QueryRowContext(ctx, stmt, args...).Scan(
 		&create.ID,
 		&create.CreatedTs,
 		&create.UpdatedTs,
@@ -34,10 +40,28 @@ func (d *DB) CreateMemo(ctx context.Context, create *store.Memo) (*store.Memo, e
 	); err != nil {
 		return nil, err
 	}
+	if err := setMemoTags(ctx, tx, create.ID, create.Payload.GetTags()); err != nil {
+		return nil, err
+	}
+	if err := recordMemoRevision(ctx, tx, create.ID, nil); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
 	return create, nil
 }
 
+// memoOrderColumns are the expressions of the memo list query that memos can be ordered by.
+var memoOrderColumns = map[store.MemoOrderField]string{
+	store.MemoOrderFieldPinned:        "pinned",
+	store.MemoOrderFieldCreatedTs:     "created_ts",
+	store.MemoOrderFieldUpdatedTs:     "updated_ts",
+	store.MemoOrderFieldReactionCount: filter.ReactionCount(filterDialect{}),
+	store.MemoOrderFieldCommentCount:  filter.CommentCount(filterDialect{}),
+}
+
 func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo, error) {
 	where, args := []string{"1 = 1"}, []any{}
 
@@ -53,6 +77,12 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 	if v := find.RowStatus; v != nil {
 		where, args = append(where, "memo.row_status = "+placeholder(len(args)+1)), append(args, *v)
 	}
+	if find.ExcludeScheduled {
+		where = append(where, "memo.scheduled_ts = 0")
+	}
+	if v := find.ScheduledTsBefore; v != nil {
+		where, args = append(where, "memo.scheduled_ts != 0 AND memo.scheduled_ts <= "+placeholder(len(args)+1)), append(args, *v)
+	}
 	if v := find.CreatedTsBefore; v != nil {
 		where, args = append(where, "memo.created_ts < "+placeholder(len(args)+1)), append(args, *v)
 	}
@@ -70,22 +100,35 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 			where, args = append(where, "memo.content ILIKE "+placeholder(len(args)+1)), append(args, fmt.Sprintf("%%%s%%", s))
 		}
 	}
+	// The full-text query placeholder is referenced again by the rank in the select list.
+	fullTextQueryPlaceholder := ""
+	if v := find.FullTextSearch; v != nil {
+		fullTextQueryPlaceholder = placeholder(len(args) + 1)
+		where, args = append(where, "memo.content_tsv @@ to_tsquery('simple', "+fullTextQueryPlaceholder+")"), append(args, buildFullTextQuery(*v))
+	}
 	if v := find.VisibilityList; len(v) != 0 {
 		holders := []string{}
 		for _, visibility := range v {
 			holders = append(holders, placeholder(len(args)+1))
 			args = append(args, visibility.String())
 		}
-		where = append(where, fmt.Sprintf("memo.visibility in (%s)", strings.Join(holders, ", ")))
+		condition := fmt.Sprintf("memo.visibility in (%s)", strings.Join(holders, ", "))
+		if find.GrantedUserID != nil {
+			condition = fmt.Sprintf("(%s OR EXISTS (SELECT 1 FROM memo_grant WHERE memo_grant.memo_id = memo.id AND ((memo_grant.principal_type = 'USER' AND memo_grant.principal_id = %s) OR (memo_grant.principal_type = 'GROUP' AND memo_grant.principal_id IN (SELECT group_id FROM user_group_member WHERE user_id = %s)))))", condition, placeholder(len(args)+1), placeholder(len(args)+2))
+			args = append(args, *find.GrantedUserID, *find.GrantedUserID)
+		}
+		where = append(where, condition)
 	}
 	if v := find.PayloadFind; v != nil {
 		if v.Raw != nil {
 			where, args = append(where, "memo.payload = "+placeholder(len(args)+1)), append(args, *v.Raw)
 		}
 		if len(v.TagSearch) != 0 {
-			for _, tag := range v.TagSearch {
-				where, args = append(where, "EXISTS (SELECT 1 FROM jsonb_array_elements(memo.payload->'tags') AS tag WHERE tag::text = "+placeholder(len(args)+1)+" OR tag::text LIKE "+placeholder(len(args)+2)+")"), append(args, fmt.Sprintf(`"%s"`, tag), fmt.Sprintf(`"%s/%%"`, tag))
+			condition, tagArgs, err := filter.ConvertMemoFilter(filter.TagSearchFilter(v.TagSearch), filterDialect{}, len(args))
+			if err != nil {
+				return nil, err
 			}
+			where, args = append(where, condition), append(args, tagArgs...)
 		}
 		if v.HasLink {
 			where = append(where, "(memo.payload->'property'->>'hasLink')::BOOLEAN IS TRUE")
@@ -101,42 +144,72 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 		}
 	}
 	if v := find.Filter; v != nil {
-		// Parse filter string and return the parsed expression.
-		// The filter string should be a CEL expression.
-		parsedExpr, err := filter.Parse(*v, filter.MemoFilterCELAttributes...)
+		condition, filterArgs, err := filter.ConvertMemoFilter(*v, filterDialect{}, len(args))
 		if err != nil {
 			return nil, err
 		}
-		convertCtx := filter.NewConvertContext()
-		convertCtx.ArgsOffset = len(args)
-		// ConvertExprToSQL converts the parsed expression to a SQL condition string.
-		if err := d.ConvertExprToSQL(convertCtx, parsedExpr.GetExpr()); err != nil {
-			return nil, err
-		}
-		condition := convertCtx.Buffer.String()
 		if condition != "" {
 			where = append(where, fmt.Sprintf("(%s)", condition))
-			args = append(args, convertCtx.Args...)
+			args = append(args, filterArgs...)
 		}
 	}
 	if find.ExcludeComments {
 		where = append(where, "memo_relation.related_memo_id IS NULL")
 	}
-
-	orders := []string{}
-	if find.OrderByPinned {
-		orders = append(orders, "pinned DESC")
+	if v := find.ParentID; v != nil {
+		where, args = append(where, "memo_relation.related_memo_id = "+placeholder(len(args)+1)), append(args, *v)
 	}
-	order := "DESC"
-	if find.OrderByTimeAsc {
-		order = "ASC"
+	if v := find.Cursor; v != nil {
+		tsColumn := "memo.created_ts"
+		if find.OrderByUpdatedTs {
+			tsColumn = "memo.updated_ts"
+		}
+		operator := "<"
+		if find.OrderByTimeAsc {
+			operator = ">"
+		}
+		if find.OrderByPinned {
+			where, args = append(where, fmt.Sprintf("(memo.pinned < %s OR (memo.pinned = %s AND (%s %s %s OR (%s = %s AND memo.id %s %s))))",
+				placeholder(len(args)+1), placeholder(len(args)+1), tsColumn, operator, placeholder(len(args)+2), tsColumn, placeholder(len(args)+2), operator, placeholder(len(args)+3))), append(args, v.Pinned, v.DisplayTs, v.ID)
+		} else {
+			where, args = append(where, fmt.Sprintf("(%s %s %s OR (%s = %s AND memo.id %s %s))",
+				tsColumn, operator, placeholder(len(args)+1), tsColumn, placeholder(len(args)+1), operator, placeholder(len(args)+2))), append(args, v.DisplayTs, v.ID)
+		}
 	}
-	if find.OrderByUpdatedTs {
-		orders = append(orders, "updated_ts "+order)
+
+	orders := []string{}
+	if find.FullTextSearch != nil {
+		orders = append(orders, "search_score DESC")
+	}
+	if len(find.OrderBy) > 0 {
+		order := "ASC"
+		for _, memoOrder := range find.OrderBy {
+			column, ok := memoOrderColumns[memoOrder.Field]
+			if !ok {
+				return nil, errors.Errorf("unsupported order field %q", memoOrder.Field)
+			}
+			order = "ASC"
+			if memoOrder.Desc {
+				order = "DESC"
+			}
+			orders = append(orders, column+" "+order)
+		}
+		orders = append(orders, "id "+order)
 	} else {
-		orders = append(orders, "created_ts "+order)
+		if find.OrderByPinned {
+			orders = append(orders, "pinned DESC")
+		}
+		order := "DESC"
+		if find.OrderByTimeAsc {
+			order = "ASC"
+		}
+		if find.OrderByUpdatedTs {
+			orders = append(orders, "updated_ts "+order)
+		} else {
+			orders = append(orders, "created_ts "+order)
+		}
+		orders = append(orders, "id "+order)
 	}
-	orders = append(orders, "id "+order)
 	fields := []string{
 		`memo.id AS id`,
 		`memo.uid AS uid`,
@@ -147,11 +220,15 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 		`memo.visibility AS visibility`,
 		`memo.pinned AS pinned`,
 		`memo.payload AS payload`,
+		`memo.scheduled_ts AS scheduled_ts`,
 		`memo_relation.related_memo_id AS parent_id`,
 	}
 	if !find.ExcludeContent {
 		fields = append(fields, `memo.content AS content`)
 	}
+	if find.FullTextSearch != nil {
+		fields = append(fields, `ts_rank(memo.content_tsv, to_tsquery('simple', `+fullTextQueryPlaceholder+`)) AS search_score`)
+	}
 
 	query := `SELECT ` + strings.Join(fields, ", ") + `
 		FROM memo
@@ -185,11 +262,15 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 			&memo.Visibility,
 			&memo.Pinned,
 			&payloadBytes,
+			&memo.ScheduledTs,
 			&memo.ParentID,
 		}
 		if !find.ExcludeContent {
 			dests = append(dests, &memo.Content)
 		}
+		if find.FullTextSearch != nil {
+			dests = append(dests, &memo.SearchScore)
+		}
 		if err := rows.Scan(dests...); err != nil {
 			return nil, err
 		}
@@ -208,6 +289,15 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 	return list, nil
 }
 
+// buildFullTextQuery converts the search query to a tsquery matching all terms by prefix.
+func buildFullTextQuery(query string) string {
+	terms := []string{}
+	for _, term := range store.SplitSearchTerms(query) {
+		terms = append(terms, term+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
 func (d *DB) GetMemo(ctx context.Context, find *store.FindMemo) (*store.Memo, error) {
 	list, err := d.ListMemos(ctx, find)
 	if err != nil {
@@ -244,6 +334,9 @@ func (d *DB) UpdateMemo(ctx context.Context, update *store.UpdateMemo) error {
 	if v := update.Pinned; v != nil {
 		set, args = append(set, "pinned = "+placeholder(len(args)+1)), append(args, *v)
 	}
+	if v := update.ScheduledTs; v != nil {
+		set, args = append(set, "scheduled_ts = "+placeholder(len(args)+1)), append(args, *v)
+	}
 	if v := update.Payload; v != nil {
 		payloadBytes, err := protojson.Marshal(v)
 		if err != nil {
@@ -252,15 +345,52 @@ func (d *DB) UpdateMemo(ctx context.Context, update *store.UpdateMemo) error {
 		set, args = append(set, "payload = "+placeholder(len(args)+1)), append(args, string(payloadBytes))
 	}
 	if len(set) == 0 {
-		return nil
+		if update.Current == nil {
+			return nil
+		}
+		// Nothing is set, but the memo is still checked to be unchanged.
+		set = append(set, "id = id")
 	}
 
-	stmt := `UPDATE memo SET ` + strings.Join(set, ", ") + ` WHERE id = ` + placeholder(len(args)+1)
+	where := []string{"id = " + placeholder(len(args)+1)}
 	args = append(args, update.ID)
-	if _, err := d.db.ExecContext(ctx, stmt, args...); err != nil {
+	if v := update.Current; v != nil {
+		for _, column := range []string{"created_ts", "updated_ts", "scheduled_ts", "row_status", "visibility", "pinned", "content"} {
+			where = append(where, column+" = "+placeholder(len(args)+1))
+		}
+		args = append(args, v.CreatedTs, v.UpdatedTs, v.ScheduledTs, v.RowStatus, v.Visibility, v.Pinned, v.Content)
+	}
+	stmt := `UPDATE memo SET ` + strings.Join(set, ", ") + ` WHERE ` + strings.Join(where, " AND ")
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
 		return err
 	}
-	return nil
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	if update.Current != nil {
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return store.ErrMemoModified
+		}
+	}
+	if update.Payload != nil {
+		if err := setMemoTags(ctx, tx, update.ID, update.Payload.Tags); err != nil {
+			return err
+		}
+	}
+	if update.Content != nil || update.Visibility != nil {
+		if err := recordMemoRevision(ctx, tx, update.ID, update.EditorID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 func (d *DB) DeleteMemo(ctx context.Context, delete *store.DeleteMemo) error {
@@ -16,49 +16,60 @@ func TestRestoreExprToSQL(t *testing.T) {
 	}{
 		{
 			filter: `tag in ["tag1", "tag2"]`,
-			want:   "(memo.payload->'tags' @> $1::jsonb OR memo.payload->'tags' @> $2::jsonb)",
-			args:   []any{[]any{"tag1"}, []any{"tag2"}},
+			want:   `EXISTS (SELECT 1 FROM memo_tag WHERE memo_tag.memo_id = memo.id AND memo_tag.tag IN ($1,$2))`,
+			args:   []any{"tag1", "tag2"},
 		},
 		{
-			filter: `!(tag in ["tag1", "tag2"])`,
-			want:   `NOT ((memo.payload->'tags' @> $1::jsonb OR memo.payload->'tags' @> $2::jsonb))`,
-			args:   []any{[]any{"tag1"}, []any{"tag2"}},
+			filter: `tag.startsWith("work")`,
+			want:   `EXISTS (SELECT 1 FROM memo_tag WHERE memo_tag.memo_id = memo.id AND SUBSTR(memo_tag.tag, 1, 4) = $1)`,
+			args:   []any{"work"},
 		},
 		{
 			filter: `content.contains("memos")`,
-			want:   "memo.content ILIKE LIKE $1",
+			want:   `LOWER(memo.content) LIKE LOWER($1)`,
 			args:   []any{"%memos%"},
 		},
-		{
-			filter: `visibility in ["PUBLIC"]`,
-			want:   "memo.visibility IN ($1)",
-			args:   []any{"PUBLIC"},
-		},
 		{
 			filter: `visibility in ["PUBLIC", "PRIVATE"]`,
-			want:   "memo.visibility IN ($1,$2)",
+			want:   `memo.visibility IN ($1,$2)`,
 			args:   []any{"PUBLIC", "PRIVATE"},
 		},
 		{
 			filter: `create_time == "2006-01-02T15:04:05+07:00"`,
-			want:   "memo.created_ts = $1",
+			want:   `memo.created_ts = $1`,
 			args:   []any{int64(1136189045)},
 		},
 		{
 			filter: `tag in ['tag1'] || content.contains('hello')`,
-			want:   "(memo.payload->'tags' @> $1::jsonb OR memo.content ILIKE LIKE $2)",
-			args:   []any{[]any{"tag1"}, "%hello%"},
+			want:   `(EXISTS (SELECT 1 FROM memo_tag WHERE memo_tag.memo_id = memo.id AND memo_tag.tag IN ($1)) OR LOWER(memo.content) LIKE LOWER($2))`,
+			args:   []any{"tag1", "%hello%"},
+		},
+		{
+			filter: `pinned && creator == "users/1"`,
+			want:   `(memo.pinned = TRUE AND memo.creator_id = $1)`,
+			args:   []any{int32(1)},
+		},
+		{
+			filter: `has_link && !has_code`,
+			want:   `((memo.payload->'property'->>'hasLink')::BOOLEAN IS TRUE AND NOT ((memo.payload->'property'->>'hasCode')::BOOLEAN IS TRUE))`,
+			args:   []any{},
+		},
+		{
+			filter: `location.latitude > 10 && location.placeholder.contains("Paris")`,
+			want:   `((memo.payload->'location'->>'latitude')::DOUBLE PRECISION > $1 AND LOWER(memo.payload->'location'->>'placeholder') LIKE LOWER($2))`,
+			args:   []any{float64(10), "%Paris%"},
+		},
+		{
+			filter: `reaction_count > 0`,
+			want:   `(SELECT COUNT(*) FROM reaction WHERE reaction.content_id = 'memos/' || memo.uid) > $1`,
+			args:   []any{int64(0)},
 		},
 	}
 
 	for _, tt := range tests {
-		db := &DB{}
-		parsedExpr, err := filter.Parse(tt.filter, filter.MemoFilterCELAttributes...)
-		require.NoError(t, err)
-		convertCtx := filter.NewConvertContext()
-		err = db.ConvertExprToSQL(convertCtx, parsedExpr.GetExpr())
+		condition, args, err := filter.ConvertMemoFilter(tt.filter, filterDialect{}, 0)
 		require.NoError(t, err)
-		require.Equal(t, tt.want, convertCtx.Buffer.String())
-		require.Equal(t, tt.args, convertCtx.Args)
+		require.Equal(t, tt.want, condition)
+		require.Equal(t, tt.args, args)
 	}
 }
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/usememos/memos/store"
+)
+
+func (d *DB) CreateMemoRevision(ctx context.Context, create *store.MemoRevision) (*store.MemoRevision, error) {
+	fields := []string{"memo_id", "content", "visibility", "editor_id"}
+	args := []any{create.MemoID, create.Content, create.Visibility, create.EditorID}
+	stmt := "INSERT INTO memo_revision (" + strings.Join(fields, ", ") + ") VALUES (" + placeholders(len(args)) + ") RETURNING id, created_ts"
+	if err := d.db.QueryRowContext(ctx, stmt, args...).Scan(
+		&create.ID,
+		&create.CreatedTs,
+	); err != nil {
+		return nil, err
+	}
+	revision := create
+	return revision, nil
+}
+
+func (d *DB) ListMemoRevisions(ctx context.Context, find *store.FindMemoRevision) ([]*store.MemoRevision, error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if find.ID != nil {
+		where, args = append(where, "id = "+placeholder(len(args)+1)), append(args, *find.ID)
+	}
+	if find.MemoID != nil {
+		where, args = append(where, "memo_id = "+placeholder(len(args)+1)), append(args, *find.MemoID)
+	}
+
+	query := "SELECT id, memo_id, created_ts, content, visibility, editor_id FROM memo_revision WHERE " + strings.Join(where, " AND ") + " ORDER BY id DESC"
+	if find.Limit != nil {
+		query = fmt.Sprintf("%s LIMIT %d", query, *find.Limit)
+		if find.Offset != nil {
+			query = fmt.Sprintf("%s OFFSET %d", query, *find.Offset)
+		}
+	}
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*store.MemoRevision{}
+	for rows.Next() {
+		revision := &store.MemoRevision{}
+		if err := rows.Scan(
+			&revision.ID,
+			&revision.MemoID,
+			&revision.CreatedTs,
+			&revision.Content,
+			&revision.Visibility,
+			&revision.EditorID,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (d *DB) DeleteMemoRevisions(ctx context.Context, delete *store.DeleteMemoRevision) error {
+	where, args := []string{"1 = 1"}, []any{}
+	if delete.MemoID != nil {
+		where, args = append(where, "memo_id = "+placeholder(len(args)+1)), append(args, *delete.MemoID)
+	}
+	_, err := d.db.ExecContext(ctx, "DELETE FROM memo_revision WHERE "+strings.Join(where, " AND "), args...)
+	return err
+}
+
+// recordMemoRevision records the content and visibility of the memo made by the editor in the transaction,
+// unless they are the same as in its latest revision. The editor defaults to the creator of the memo.
+func recordMemoRevision(ctx context.Context, tx *sql.Tx, memoID int32, editorID *int32) error {
+	var content, visibility string
+	var creatorID int32
+	if err := tx.QueryRowContext(ctx, "SELECT content, visibility, creator_id FROM memo WHERE id = "+placeholder(1), memoID).Scan(&content, &visibility, &creatorID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	var latestContent, latestVisibility string
+	err := tx.QueryRowContext(ctx, "SELECT content, visibility FROM memo_revision WHERE memo_id = "+placeholder(1)+" ORDER BY id DESC LIMIT 1", memoID).Scan(&latestContent, &latestVisibility)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err == nil && latestContent == content && latestVisibility == visibility {
+		return nil
+	}
+	if editorID == nil {
+		editorID = &creatorID
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO memo_revision (memo_id, content, visibility, editor_id) VALUES ("+placeholders(4)+")", memoID, content, visibility, *editorID)
+	return err
+}
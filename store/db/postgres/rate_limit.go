@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/usememos/memos/store"
+)
+
+func (d *DB) UpsertRateLimit(ctx context.Context, upsert *store.RateLimit) error {
+	stmt := "INSERT INTO rate_limit (key, tokens, failures, locked_until_ts, updated_ms) VALUES (" + placeholders(5) + ") ON CONFLICT(key) DO UPDATE SET tokens = EXCLUDED.tokens, failures = EXCLUDED.failures, locked_until_ts = EXCLUDED.locked_until_ts, updated_ms = EXCLUDED.updated_ms"
+	_, err := d.db.ExecContext(ctx, stmt, upsert.Key, upsert.Tokens, upsert.Failures, upsert.LockedUntilTs, upsert.UpdatedMs)
+	return err
+}
+
+func (d *DB) TakeRateLimitToken(ctx context.Context, take *store.TakeRateLimitToken) (bool, error) {
+	if _, err := d.db.ExecContext(ctx, "INSERT INTO rate_limit (key, tokens, updated_ms) VALUES ("+placeholders(3)+") ON CONFLICT(key) DO NOTHING", take.Key, take.Burst, take.NowMs); err != nil {
+		return false, err
+	}
+	// The bucket is refilled and taken from in a single statement, so that concurrent requests do not take the same token.
+	tokens := "LEAST(" + placeholder(1) + ", tokens + GREATEST(" + placeholder(2) + " - updated_ms, 0) * CAST(" + placeholder(3) + " AS DOUBLE PRECISION))"
+	stmt := "UPDATE rate_limit SET tokens = " + tokens + " - 1, updated_ms = " + placeholder(2) + " WHERE key = " + placeholder(4) + " AND " + tokens + " >= 1"
+	result, err := d.db.ExecContext(ctx, stmt, take.Burst, take.NowMs, take.TokensPerMs, take.Key)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (d *DB) IncreaseRateLimitFailures(ctx context.Context, increase *store.IncreaseRateLimitFailures) (int32, error) {
+	if _, err := d.db.ExecContext(ctx, "INSERT INTO rate_limit (key, updated_ms) VALUES ("+placeholders(2)+") ON CONFLICT(key) DO NOTHING", increase.Key, increase.NowMs); err != nil {
+		return 0, err
+	}
+	stmt := "UPDATE rate_limit SET failures = CASE WHEN updated_ms < " + placeholder(1) + " THEN 1 ELSE failures + 1 END, updated_ms = " + placeholder(2) + " WHERE key = " + placeholder(3) + " RETURNING failures"
+	var failures int32
+	if err := d.db.QueryRowContext(ctx, stmt, increase.NowMs-increase.WindowMs, increase.NowMs, increase.Key).Scan(&failures); err != nil {
+		return 0, err
+	}
+	return failures, nil
+}
+
+func (d *DB) LockRateLimit(ctx context.Context, lock *store.LockRateLimit) error {
+	_, err := d.db.ExecContext(ctx, "UPDATE rate_limit SET locked_until_ts = GREATEST(locked_until_ts, "+placeholder(1)+") WHERE key = "+placeholder(2), lock.LockedUntilTs, lock.Key)
+	return err
+}
+
+func (d *DB) ListRateLimits(ctx context.Context, find *store.FindRateLimit) ([]*store.RateLimit, error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if find.Key != nil {
+		where, args = append(where, "key = "+placeholder(len(args)+1)), append(args, *find.Key)
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT key, tokens, failures, locked_until_ts, updated_ms FROM rate_limit WHERE "+strings.Join(where, " AND "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*store.RateLimit{}
+	for rows.Next() {
+		rateLimit := &store.RateLimit{}
+		if err := rows.Scan(
+			&rateLimit.Key,
+			&rateLimit.Tokens,
+			&rateLimit.Failures,
+			&rateLimit.LockedUntilTs,
+			&rateLimit.UpdatedMs,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, rateLimit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (d *DB) DeleteRateLimits(ctx context.Context, delete *store.DeleteRateLimit) error {
+	where, args := []string{"1 = 1"}, []any{}
+	if delete.Key != nil {
+		where, args = append(where, "key = "+placeholder(len(args)+1)), append(args, *delete.Key)
+	}
+	if delete.UpdatedMsBefore != nil {
+		where, args = append(where, "updated_ms < "+placeholder(len(args)+1)), append(args, *delete.UpdatedMsBefore)
+	}
+	_, err := d.db.ExecContext(ctx, "DELETE FROM rate_limit WHERE "+strings.Join(where, " AND "), args...)
+	return err
+}
@@ -4,12 +4,22 @@ import (
 	"context"
 	"strings"
 
+	"google.golang.org/protobuf/encoding/protojson"
+
+	storepb "github.com/usememos/memos/proto/gen/store"
 	"github.com/usememos/memos/store"
 )
 
 func (d *DB) CreateWebhook(ctx context.Context, create *store.Webhook) (*store.Webhook, error) {
-	fields := []string{"name", "url", "creator_id"}
-	args := []any{create.Name, create.URL, create.CreatorID}
+	fields := []string{"name", "url", "creator_id", "secret", "payload"}
+	if create.Payload == nil {
+		create.Payload = &storepb.WebhookPayload{}
+	}
+	payloadBytes, err := protojson.Marshal(create.Payload)
+	if err != nil {
+		return nil, err
+	}
+	args := []any{create.Name, create.URL, create.CreatorID, create.Secret, string(payloadBytes)}
 	stmt := "INSERT INTO webhook (" + strings.Join(fields, ", ") + ") VALUES (" + placeholders(len(args)) + ") RETURNING id, created_ts, updated_ts"
 	if err := d.db.QueryRowContext(ctx, stmt, args...).Scan(
 		&create.ID,
@@ -38,7 +48,9 @@ func (d *DB) ListWebhooks(ctx context.Context, find *store.FindWebhook) ([]*stor
 			updated_ts,
 			creator_id,
 			name,
-			url
+			url,
+			secret,
+			payload
 		FROM webhook
 		WHERE `+strings.Join(where, " AND ")+`
 		ORDER BY id DESC`,
@@ -52,6 +64,7 @@ func (d *DB) ListWebhooks(ctx context.Context, find *store.FindWebhook) ([]*stor
 	list := []*store.Webhook{}
 	for rows.Next() {
 		webhook := &store.Webhook{}
+		var payload string
 		if err := rows.Scan(
 			&webhook.ID,
 			&webhook.CreatedTs,
@@ -59,9 +72,15 @@ func (d *DB) ListWebhooks(ctx context.Context, find *store.FindWebhook) ([]*stor
 			&webhook.CreatorID,
 			&webhook.Name,
 			&webhook.URL,
+			&webhook.Secret,
+			&payload,
 		); err != nil {
 			return nil, err
 		}
+		webhook.Payload = &storepb.WebhookPayload{}
+		if err := protojsonUnmarshaler.Unmarshal([]byte(payload), webhook.Payload); err != nil {
+			return nil, err
+		}
 		list = append(list, webhook)
 	}
 
@@ -80,10 +99,21 @@ func (d *DB) UpdateWebhook(ctx context.Context, update *store.UpdateWebhook) (*s
 	if update.URL != nil {
 		set, args = append(set, "url = "+placeholder(len(args)+1)), append(args, *update.URL)
 	}
+	if update.Secret != nil {
+		set, args = append(set, "secret = "+placeholder(len(args)+1)), append(args, *update.Secret)
+	}
+	if update.Payload != nil {
+		payloadBytes, err := protojson.Marshal(update.Payload)
+		if err != nil {
+			return nil, err
+		}
+		set, args = append(set, "payload = "+placeholder(len(args)+1)), append(args, string(payloadBytes))
+	}
 
-	stmt := "UPDATE webhook SET " + strings.Join(set, ", ") + " WHERE id = " + placeholder(len(args)+1) + " RETURNING id, created_ts, updated_ts, creator_id, name, url"
+	stmt := "UPDATE webhook SET " + strings.Join(set, ", ") + " WHERE id = " + placeholder(len(args)+1) + " RETURNING id, created_ts, updated_ts, creator_id, name, url, secret, payload"
 	args = append(args, update.ID)
 	webhook := &store.Webhook{}
+	var payload string
 	if err := d.db.QueryRowContext(ctx, stmt, args...).Scan(
 		&webhook.ID,
 		&webhook.CreatedTs,
@@ -91,9 +121,15 @@ func (d *DB) UpdateWebhook(ctx context.Context, update *store.UpdateWebhook) (*s
 		&webhook.CreatorID,
 		&webhook.Name,
 		&webhook.URL,
+		&webhook.Secret,
+		&payload,
 	); err != nil {
 		return nil, err
 	}
+	webhook.Payload = &storepb.WebhookPayload{}
+	if err := protojsonUnmarshaler.Unmarshal([]byte(payload), webhook.Payload); err != nil {
+		return nil, err
+	}
 	return webhook, nil
 }
 
@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/usememos/memos/store"
+)
+
+func (d *DB) CreateCustomRole(ctx context.Context, create *store.CustomRole) (*store.CustomRole, error) {
+	stmt := "INSERT INTO `custom_role` (`name`, `description`, `permissions`) VALUES (?, ?, ?) RETURNING `id`, `created_ts`"
+	if err := d.db.QueryRowContext(ctx, stmt, create.Name, create.Description, store.JoinPermissions(create.Permissions)).Scan(
+		&create.ID,
+		&create.CreatedTs,
+	); err != nil {
+		return nil, err
+	}
+	return create, nil
+}
+
+func (d *DB) ListCustomRoles(ctx context.Context, find *store.FindCustomRole) ([]*store.CustomRole, error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if find.ID != nil {
+		where, args = append(where, "`id` = ?"), append(args, *find.ID)
+	}
+	if find.Name != nil {
+		where, args = append(where, "`name` = ?"), append(args, *find.Name)
+	}
+	if find.MemberID != nil {
+		where, args = append(where, "`id` IN (SELECT `role_id` FROM `custom_role_member` WHERE `user_id` = ?)"), append(args, *find.MemberID)
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT `id`, `name`, `description`, `permissions`, `created_ts` FROM `custom_role` WHERE "+strings.Join(where, " AND ")+" ORDER BY `name` ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*store.CustomRole{}
+	for rows.Next() {
+		role := &store.CustomRole{}
+		var permissions string
+		if err := rows.Scan(
+			&role.ID,
+			&role.Name,
+			&role.Description,
+			&permissions,
+			&role.CreatedTs,
+		); err != nil {
+			return nil, err
+		}
+		role.Permissions = store.SplitPermissions(permissions)
+		list = append(list, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (d *DB) UpdateCustomRole(ctx context.Context, update *store.UpdateCustomRole) (*store.CustomRole, error) {
+	set, args := []string{}, []any{}
+	if v := update.Name; v != nil {
+		set, args = append(set, "`name` = ?"), append(args, *v)
+	}
+	if v := update.Description; v != nil {
+		set, args = append(set, "`description` = ?"), append(args, *v)
+	}
+	if v := update.Permissions; v != nil {
+		set, args = append(set, "`permissions` = ?"), append(args, store.JoinPermissions(v))
+	}
+	if len(set) != 0 {
+		args = append(args, update.ID)
+		if _, err := d.db.ExecContext(ctx, "UPDATE `custom_role` SET "+strings.Join(set, ", ")+" WHERE `id` = ?", args...); err != nil {
+			return nil, err
+		}
+	}
+
+	list, err := d.ListCustomRoles(ctx, &store.FindCustomRole{ID: &update.ID})
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+func (d *DB) DeleteCustomRole(ctx context.Context, delete *store.DeleteCustomRole) error {
+	_, err := d.db.ExecContext(ctx, "DELETE FROM `custom_role` WHERE `id` = ?", delete.ID)
+	return err
+}
+
+func (d *DB) UpsertCustomRoleMember(ctx context.Context, upsert *store.CustomRoleMember) (*store.CustomRoleMember, error) {
+	stmt := "INSERT INTO `custom_role_member` (`role_id`, `user_id`) VALUES (?, ?) ON CONFLICT(`role_id`, `user_id`) DO NOTHING"
+	if _, err := d.db.ExecContext(ctx, stmt, upsert.RoleID, upsert.UserID); err != nil {
+		return nil, err
+	}
+	return upsert, nil
+}
+
+func (d *DB) ListCustomRoleMembers(ctx context.Context, find *store.FindCustomRoleMember) ([]*store.CustomRoleMember, error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if find.RoleID != nil {
+		where, args = append(where, "`role_id` = ?"), append(args, *find.RoleID)
+	}
+	if find.UserID != nil {
+		where, args = append(where, "`user_id` = ?"), append(args, *find.UserID)
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT `role_id`, `user_id` FROM `custom_role_member` WHERE "+strings.Join(where, " AND ")+" ORDER BY `role_id` ASC, `user_id` ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*store.CustomRoleMember{}
+	for rows.Next() {
+		member := &store.CustomRoleMember{}
+		if err := rows.Scan(
+			&member.RoleID,
+			&member.UserID,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (d *DB) DeleteCustomRoleMembers(ctx context.Context, delete *store.DeleteCustomRoleMember) error {
+	where, args := []string{"1 = 1"}, []any{}
+	if delete.RoleID != nil {
+		where, args = append(where, "`role_id` = ?"), append(args, *delete.RoleID)
+	}
+	if delete.UserID != nil {
+		where, args = append(where, "`user_id` = ?"), append(args, *delete.UserID)
+	}
+	_, err := d.db.ExecContext(ctx, "DELETE FROM `custom_role_member` WHERE "+strings.Join(where, " AND "), args...)
+	return err
+}
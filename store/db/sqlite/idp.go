@@ -10,9 +10,9 @@ import (
 )
 
 func (d *DB) CreateIdentityProvider(ctx context.Context, create *store.IdentityProvider) (*store.IdentityProvider, error) {
-	placeholders := []string{"?", "?", "?", "?"}
-	fields := []string{"`name`", "`type`", "`identifier_filter`", "`config`"}
-	args := []any{create.Name, create.Type.String(), create.IdentifierFilter, create.Config}
+	placeholders := []string{"?", "?", "?", "?", "?"}
+	fields := []string{"`name`", "`type`", "`identifier_filter`", "`config`", "`provisioning`"}
+	args := []any{create.Name, create.Type.String(), create.IdentifierFilter, create.Config, create.Provisioning}
 
 	stmt := "INSERT INTO `idp` (" + strings.Join(fields, ", ") + ") VALUES (" + strings.Join(placeholders, ", ") + ") RETURNING `id`"
 	if err := d.db.QueryRowContext(ctx, stmt, args...).Scan(&create.ID); err != nil {
@@ -35,7 +35,8 @@ func (d *DB) ListIdentityProviders(ctx context.Context, find *store.FindIdentity
 			name,
 			type,
 			identifier_filter,
-			config
+			config,
+			provisioning
 		FROM idp
 		WHERE `+strings.Join(where, " AND ")+` ORDER BY id ASC`,
 		args...,
@@ -55,6 +56,7 @@ func (d *DB) ListIdentityProviders(ctx context.Context, find *store.FindIdentity
 			&typeString,
 			&identityProvider.IdentifierFilter,
 			&identityProvider.Config,
+			&identityProvider.Provisioning,
 		); err != nil {
 			return nil, err
 		}
@@ -80,13 +82,16 @@ func (d *DB) UpdateIdentityProvider(ctx context.Context, update *store.UpdateIde
 	if v := update.Config; v != nil {
 		set, args = append(set, "config = ?"), append(args, *v)
 	}
+	if v := update.Provisioning; v != nil {
+		set, args = append(set, "provisioning = ?"), append(args, *v)
+	}
 	args = append(args, update.ID)
 
 	stmt := `
 		UPDATE idp
 		SET ` + strings.Join(set, ", ") + `
 		WHERE id = ?
-		RETURNING id, name, type, identifier_filter, config
+		RETURNING id, name, type, identifier_filter, config, provisioning
 	`
 	var identityProvider store.IdentityProvider
 	var typeString string
@@ -96,6 +101,7 @@ func (d *DB) UpdateIdentityProvider(ctx context.Context, update *store.UpdateIde
 		&typeString,
 		&identityProvider.IdentifierFilter,
 		&identityProvider.Config,
+		&identityProvider.Provisioning,
 	); err != nil {
 		return nil, err
 	}
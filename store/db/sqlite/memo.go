@@ -14,8 +14,8 @@ import (
 )
 
 func (d *DB) CreateMemo(ctx context.Context, create *store.Memo) (*store.Memo, error) {
-	fields := []string{"`uid`", "`creator_id`", "`content`", "`visibility`", "`payload`"}
-	placeholder := []string{"?", "?", "?", "?", "?"}
+	fields := []string{"`uid`", "`creator_id`", "`content`", "`visibility`", "`payload`", "`scheduled_ts`"}
+	placeholder := []string{"?", "?", "?", "?", "?", "?"}
 	payload := "{}"
 	if create.Payload != nil {
 		payloadBytes, err := protojson.Marshal(create.Payload)
@@ -24,10 +24,16 @@ func (d *DB) CreateMemo(ctx context.Context, create *store.Memo) (*store.Memo, e
 		}
 		payload = string(payloadBytes)
 	}
-	args := []any{create.UID, create.CreatorID, create.Content, create.Visibility, payload}
+	args := []any{create.UID, create.CreatorID, create.Content, create.Visibility, payload, create.ScheduledTs}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
 	stmt := "INSERT INTO `memo` (" + strings.Join(fields, ", ") + ") VALUES (" + strings.Join(placeholder, ", ") + ") RETURNING `id`, `created_ts`, `updated_ts`, `row_status`"
-	if err := d.db.QueryRowContext(ctx, stmt, args...).Scan(
+	if err := tx.QueryRowContext(ctx, stmt, args...).Scan(
 		&create.ID,
 		&create.CreatedTs,
 		&create.UpdatedTs,
@@ -35,10 +41,28 @@ func (d *DB) CreateMemo(ctx context.Context, create *store.Memo) (*store.Memo, e
 	); err != nil {
 		return nil, err
 	}
+	if err := setMemoTags(ctx, tx, create.ID, create.Payload.GetTags()); err != nil {
+		return nil, err
+	}
+	if err := recordMemoRevision(ctx, tx, create.ID, nil); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
 	return create, nil
 }
 
+// memoOrderColumns are the expressions of the memo list query that memos can be ordered by.
+var memoOrderColumns = map[store.MemoOrderField]string{
+	store.MemoOrderFieldPinned:        "`pinned`",
+	store.MemoOrderFieldCreatedTs:     "`created_ts`",
+	store.MemoOrderFieldUpdatedTs:     "`updated_ts`",
+	store.MemoOrderFieldReactionCount: filter.ReactionCount(filterDialect{}),
+	store.MemoOrderFieldCommentCount:  filter.CommentCount(filterDialect{}),
+}
+
 func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo, error) {
 	where, args := []string{"1 = 1"}, []any{}
 
@@ -54,6 +78,12 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 	if v := find.RowStatus; v != nil {
 		where, args = append(where, "`memo`.`row_status` = ?"), append(args, *v)
 	}
+	if find.ExcludeScheduled {
+		where = append(where, "`memo`.`scheduled_ts` = 0")
+	}
+	if v := find.ScheduledTsBefore; v != nil {
+		where, args = append(where, "`memo`.`scheduled_ts` != 0 AND `memo`.`scheduled_ts` <= ?"), append(args, *v)
+	}
 	if v := find.CreatedTsBefore; v != nil {
 		where, args = append(where, "`memo`.`created_ts` < ?"), append(args, *v)
 	}
@@ -71,22 +101,32 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 			where, args = append(where, "`memo`.`content` LIKE ?"), append(args, fmt.Sprintf("%%%s%%", s))
 		}
 	}
+	if v := find.FullTextSearch; v != nil {
+		where, args = append(where, "`memo_fts` MATCH ?"), append(args, buildFullTextQuery(*v))
+	}
 	if v := find.VisibilityList; len(v) != 0 {
 		placeholder := []string{}
 		for _, visibility := range v {
 			placeholder = append(placeholder, "?")
 			args = append(args, visibility.String())
 		}
-		where = append(where, fmt.Sprintf("`memo`.`visibility` IN (%s)", strings.Join(placeholder, ",")))
+		condition := fmt.Sprintf("`memo`.`visibility` IN (%s)", strings.Join(placeholder, ","))
+		if find.GrantedUserID != nil {
+			condition = fmt.Sprintf("(%s OR EXISTS (SELECT 1 FROM `memo_grant` WHERE `memo_grant`.`memo_id` = `memo`.`id` AND ((`memo_grant`.`principal_type` = 'USER' AND `memo_grant`.`principal_id` = ?) OR (`memo_grant`.`principal_type` = 'GROUP' AND `memo_grant`.`principal_id` IN (SELECT `group_id` FROM `user_group_member` WHERE `user_id` = ?)))))", condition)
+			args = append(args, *find.GrantedUserID, *find.GrantedUserID)
+		}
+		where = append(where, condition)
 	}
 	if v := find.PayloadFind; v != nil {
 		if v.Raw != nil {
 			where, args = append(where, "`memo`.`payload` = ?"), append(args, *v.Raw)
 		}
 		if len(v.TagSearch) != 0 {
-			for _, tag := range v.TagSearch {
-				where, args = append(where, "(JSON_EXTRACT(`memo`.`payload`, '$.tags') LIKE ? OR JSON_EXTRACT(`memo`.`payload`, '$.tags') LIKE ?)"), append(args, fmt.Sprintf(`%%"%s"%%`, tag), fmt.Sprintf(`%%"%s/%%`, tag))
+			condition, tagArgs, err := filter.ConvertMemoFilter(filter.TagSearchFilter(v.TagSearch), filterDialect{}, len(args))
+			if err != nil {
+				return nil, err
 			}
+			where, args = append(where, condition), append(args, tagArgs...)
 		}
 		if v.HasLink {
 			where = append(where, "JSON_EXTRACT(`memo`.`payload`, '$.property.hasLink') IS TRUE")
@@ -102,41 +142,72 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 		}
 	}
 	if v := find.Filter; v != nil {
-		// Parse filter string and return the parsed expression.
-		// The filter string should be a CEL expression.
-		parsedExpr, err := filter.Parse(*v, filter.MemoFilterCELAttributes...)
+		condition, filterArgs, err := filter.ConvertMemoFilter(*v, filterDialect{}, len(args))
 		if err != nil {
 			return nil, err
 		}
-		convertCtx := filter.NewConvertContext()
-		// ConvertExprToSQL converts the parsed expression to a SQL condition string.
-		if err := d.ConvertExprToSQL(convertCtx, parsedExpr.GetExpr()); err != nil {
-			return nil, err
-		}
-		condition := convertCtx.Buffer.String()
 		if condition != "" {
 			where = append(where, fmt.Sprintf("(%s)", condition))
-			args = append(args, convertCtx.Args...)
+			args = append(args, filterArgs...)
 		}
 	}
 	if find.ExcludeComments {
 		where = append(where, "`parent_id` IS NULL")
 	}
-
-	orderBy := []string{}
-	if find.OrderByPinned {
-		orderBy = append(orderBy, "`pinned` DESC")
+	if v := find.ParentID; v != nil {
+		where, args = append(where, "`memo_relation`.`related_memo_id` = ?"), append(args, *v)
 	}
-	order := "DESC"
-	if find.OrderByTimeAsc {
-		order = "ASC"
+	if v := find.Cursor; v != nil {
+		tsColumn := "`memo`.`created_ts`"
+		if find.OrderByUpdatedTs {
+			tsColumn = "`memo`.`updated_ts`"
+		}
+		operator := "<"
+		if find.OrderByTimeAsc {
+			operator = ">"
+		}
+		condition := fmt.Sprintf("(%s %s ? OR (%s = ? AND `memo`.`id` %s ?))", tsColumn, operator, tsColumn, operator)
+		cursorArgs := []any{v.DisplayTs, v.DisplayTs, v.ID}
+		if find.OrderByPinned {
+			condition = fmt.Sprintf("(`memo`.`pinned` < ? OR (`memo`.`pinned` = ? AND %s))", condition)
+			cursorArgs = append([]any{v.Pinned, v.Pinned}, cursorArgs...)
+		}
+		where, args = append(where, condition), append(args, cursorArgs...)
 	}
-	if find.OrderByUpdatedTs {
-		orderBy = append(orderBy, "`updated_ts` "+order)
+
+	orderBy := []string{}
+	if find.FullTextSearch != nil {
+		orderBy = append(orderBy, "`search_score` DESC")
+	}
+	if len(find.OrderBy) > 0 {
+		order := "ASC"
+		for _, memoOrder := range find.OrderBy {
+			column, ok := memoOrderColumns[memoOrder.Field]
+			if !ok {
+				return nil, errors.Errorf("unsupported order field %q", memoOrder.Field)
+			}
+			order = "ASC"
+			if memoOrder.Desc {
+				order = "DESC"
+			}
+			orderBy = append(orderBy, column+" "+order)
+		}
+		orderBy = append(orderBy, "`id` "+order)
 	} else {
-		orderBy = append(orderBy, "`created_ts` "+order)
+		if find.OrderByPinned {
+			orderBy = append(orderBy, "`pinned` DESC")
+		}
+		order := "DESC"
+		if find.OrderByTimeAsc {
+			order = "ASC"
+		}
+		if find.OrderByUpdatedTs {
+			orderBy = append(orderBy, "`updated_ts` "+order)
+		} else {
+			orderBy = append(orderBy, "`created_ts` "+order)
+		}
+		orderBy = append(orderBy, "`id` "+order)
 	}
-	orderBy = append(orderBy, "`id` "+order)
 	fields := []string{
 		"`memo`.`id` AS `id`",
 		"`memo`.`uid` AS `uid`",
@@ -147,14 +218,21 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 		"`memo`.`visibility` AS `visibility`",
 		"`memo`.`pinned` AS `pinned`",
 		"`memo`.`payload` AS `payload`",
+		"`memo`.`scheduled_ts` AS `scheduled_ts`",
 		"`memo_relation`.`related_memo_id` AS `parent_id`",
 	}
 	if !find.ExcludeContent {
 		fields = append(fields, "`memo`.`content` AS `content`")
 	}
+	joins := "LEFT JOIN `memo_relation` ON `memo`.`id` = `memo_relation`.`memo_id` AND `memo_relation`.`type` = \"COMMENT\" "
+	if find.FullTextSearch != nil {
+		// bm25 returns lower values for better matches, so negate it to get a score.
+		fields = append(fields, "-bm25(`memo_fts`) AS `search_score`")
+		joins += "INNER JOIN `memo_fts` ON `memo_fts`.`rowid` = `memo`.`id` "
+	}
 
 	query := "SELECT " + strings.Join(fields, ", ") + "FROM `memo` " +
-		"LEFT JOIN `memo_relation` ON `memo`.`id` = `memo_relation`.`memo_id` AND `memo_relation`.`type` = \"COMMENT\" " +
+		joins +
 		"WHERE " + strings.Join(where, " AND ") + " " +
 		"ORDER BY " + strings.Join(orderBy, ", ")
 	if find.Limit != nil {
@@ -184,11 +262,15 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 			&memo.Visibility,
 			&memo.Pinned,
 			&payloadBytes,
+			&memo.ScheduledTs,
 			&memo.ParentID,
 		}
 		if !find.ExcludeContent {
 			dests = append(dests, &memo.Content)
 		}
+		if find.FullTextSearch != nil {
+			dests = append(dests, &memo.SearchScore)
+		}
 		if err := rows.Scan(dests...); err != nil {
 			return nil, err
 		}
@@ -207,6 +289,15 @@ func (d *DB) ListMemos(ctx context.Context, find *store.FindMemo) ([]*store.Memo
 	return list, nil
 }
 
+// buildFullTextQuery converts the search query to an FTS5 query matching all terms by prefix.
+func buildFullTextQuery(query string) string {
+	terms := []string{}
+	for _, term := range store.SplitSearchTerms(query) {
+		terms = append(terms, fmt.Sprintf(`"%s"*`, term))
+	}
+	return strings.Join(terms, " ")
+}
+
 func (d *DB) UpdateMemo(ctx context.Context, update *store.UpdateMemo) error {
 	set, args := []string{}, []any{}
 	if v := update.UID; v != nil {
@@ -230,6 +321,9 @@ func (d *DB) UpdateMemo(ctx context.Context, update *store.UpdateMemo) error {
 	if v := update.Pinned; v != nil {
 		set, args = append(set, "`pinned` = ?"), append(args, *v)
 	}
+	if v := update.ScheduledTs; v != nil {
+		set, args = append(set, "`scheduled_ts` = ?"), append(args, *v)
+	}
 	if v := update.Payload; v != nil {
 		payloadBytes, err := protojson.Marshal(v)
 		if err != nil {
@@ -238,15 +332,50 @@ func (d *DB) UpdateMemo(ctx context.Context, update *store.UpdateMemo) error {
 		set, args = append(set, "`payload` = ?"), append(args, string(payloadBytes))
 	}
 	if len(set) == 0 {
-		return nil
+		if update.Current == nil {
+			return nil
+		}
+		// Nothing is set, but the memo is still checked to be unchanged.
+		set = append(set, "`id` = `id`")
+	}
+	where, whereArgs := []string{"`id` = ?"}, []any{update.ID}
+	if v := update.Current; v != nil {
+		where = append(where, "`created_ts` = ?", "`updated_ts` = ?", "`scheduled_ts` = ?", "`row_status` = ?", "`visibility` = ?", "`pinned` = ?", "`content` = ?")
+		whereArgs = append(whereArgs, v.CreatedTs, v.UpdatedTs, v.ScheduledTs, v.RowStatus, v.Visibility, v.Pinned, v.Content)
 	}
-	args = append(args, update.ID)
+	args = append(args, whereArgs...)
 
-	stmt := "UPDATE `memo` SET " + strings.Join(set, ", ") + " WHERE `id` = ?"
-	if _, err := d.db.ExecContext(ctx, stmt, args...); err != nil {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
 		return err
 	}
-	return nil
+	defer tx.Rollback()
+
+	stmt := "UPDATE `memo` SET " + strings.Join(set, ", ") + " WHERE " + strings.Join(where, " AND ")
+	result, err := tx.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	if update.Current != nil {
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return store.ErrMemoModified
+		}
+	}
+	if update.Payload != nil {
+		if err := setMemoTags(ctx, tx, update.ID, update.Payload.Tags); err != nil {
+			return err
+		}
+	}
+	if update.Content != nil || update.Visibility != nil {
+		if err := recordMemoRevision(ctx, tx, update.ID, update.EditorID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 func (d *DB) DeleteMemo(ctx context.Context, delete *store.DeleteMemo) error {
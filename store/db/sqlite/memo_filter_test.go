@@ -16,28 +16,28 @@ func TestConvertExprToSQL(t *testing.T) {
 	}{
 		{
 			filter: `tag in ["tag1", "tag2"]`,
-			want:   "(JSON_EXTRACT(`memo`.`payload`, '$.tags') LIKE ? OR JSON_EXTRACT(`memo`.`payload`, '$.tags') LIKE ?)",
-			args:   []any{`%"tag1"%`, `%"tag2"%`},
+			want:   "EXISTS (SELECT 1 FROM `memo_tag` WHERE `memo_tag`.`memo_id` = `memo`.`id` AND `memo_tag`.`tag` IN (?,?))",
+			args:   []any{"tag1", "tag2"},
 		},
 		{
 			filter: `!(tag in ["tag1", "tag2"])`,
-			want:   "NOT ((JSON_EXTRACT(`memo`.`payload`, '$.tags') LIKE ? OR JSON_EXTRACT(`memo`.`payload`, '$.tags') LIKE ?))",
-			args:   []any{`%"tag1"%`, `%"tag2"%`},
+			want:   "NOT (EXISTS (SELECT 1 FROM `memo_tag` WHERE `memo_tag`.`memo_id` = `memo`.`id` AND `memo_tag`.`tag` IN (?,?)))",
+			args:   []any{"tag1", "tag2"},
 		},
 		{
-			filter: `tag in ["tag1", "tag2"] || tag in ["tag3", "tag4"]`,
-			want:   "((JSON_EXTRACT(`memo`.`payload`, '$.tags') LIKE ? OR JSON_EXTRACT(`memo`.`payload`, '$.tags') LIKE ?) OR (JSON_EXTRACT(`memo`.`payload`, '$.tags') LIKE ? OR JSON_EXTRACT(`memo`.`payload`, '$.tags') LIKE ?))",
-			args:   []any{`%"tag1"%`, `%"tag2"%`, `%"tag3"%`, `%"tag4"%`},
+			filter: `tag.startsWith("work")`,
+			want:   "EXISTS (SELECT 1 FROM `memo_tag` WHERE `memo_tag`.`memo_id` = `memo`.`id` AND SUBSTR(`memo_tag`.`tag`, 1, 4) = ?)",
+			args:   []any{"work"},
 		},
 		{
-			filter: `content.contains("memos")`,
-			want:   "`memo`.`content` LIKE ?",
-			args:   []any{"%memos%"},
+			filter: `tag.within("work")`,
+			want:   "EXISTS (SELECT 1 FROM `memo_tag` WHERE `memo_tag`.`memo_id` = `memo`.`id` AND (`memo_tag`.`tag` = ? OR SUBSTR(`memo_tag`.`tag`, 1, 5) = ?))",
+			args:   []any{"work", "work/"},
 		},
 		{
-			filter: `visibility in ["PUBLIC"]`,
-			want:   "`memo`.`visibility` IN (?)",
-			args:   []any{"PUBLIC"},
+			filter: `content.contains("memos")`,
+			want:   "LOWER(`memo`.`content`) LIKE LOWER(?)",
+			args:   []any{"%memos%"},
 		},
 		{
 			filter: `visibility in ["PUBLIC", "PRIVATE"]`,
@@ -50,25 +50,36 @@ func TestConvertExprToSQL(t *testing.T) {
 			args:   []any{int64(1136189045)},
 		},
 		{
-			filter: `tag in ['tag1'] || content.contains('hello')`,
-			want:   "(JSON_EXTRACT(`memo`.`payload`, '$.tags') LIKE ? OR `memo`.`content` LIKE ?)",
-			args:   []any{`%"tag1"%`, "%hello%"},
+			filter: `pinned && creator == "users/1"`,
+			want:   "(`memo`.`pinned` = 1 AND `memo`.`creator_id` = ?)",
+			args:   []any{int32(1)},
 		},
 		{
-			filter: `1`,
-			want:   "",
+			filter: `has_link && !has_code`,
+			want:   "(JSON_EXTRACT(`memo`.`payload`, '$.property.hasLink') IS TRUE AND NOT (JSON_EXTRACT(`memo`.`payload`, '$.property.hasCode') IS TRUE))",
 			args:   []any{},
 		},
+		{
+			filter: `location.latitude > 10 && location.placeholder.contains("Paris")`,
+			want:   "(JSON_EXTRACT(`memo`.`payload`, '$.location.latitude') > ? AND LOWER(JSON_EXTRACT(`memo`.`payload`, '$.location.placeholder')) LIKE LOWER(?))",
+			args:   []any{float64(10), "%Paris%"},
+		},
+		{
+			filter: `reaction_count > 0 || comment_count >= 2`,
+			want:   "((SELECT COUNT(*) FROM `reaction` WHERE `reaction`.`content_id` = 'memos/' || `memo`.`uid`) > ? OR (SELECT COUNT(*) FROM `memo_relation` AS `comment` WHERE `comment`.`related_memo_id` = `memo`.`id` AND `comment`.`type` = 'COMMENT') >= ?)",
+			args:   []any{int64(0), int64(2)},
+		},
+		{
+			filter: `row_status == "ARCHIVED" && update_time < 1700000000`,
+			want:   "(`memo`.`row_status` = ? AND `memo`.`updated_ts` < ?)",
+			args:   []any{"ARCHIVED", int64(1700000000)},
+		},
 	}
 
 	for _, tt := range tests {
-		db := &DB{}
-		parsedExpr, err := filter.Parse(tt.filter, filter.MemoFilterCELAttributes...)
-		require.NoError(t, err)
-		convertCtx := filter.NewConvertContext()
-		err = db.ConvertExprToSQL(convertCtx, parsedExpr.GetExpr())
+		condition, args, err := filter.ConvertMemoFilter(tt.filter, filterDialect{}, 0)
 		require.NoError(t, err)
-		require.Equal(t, tt.want, convertCtx.Buffer.String())
-		require.Equal(t, tt.args, convertCtx.Args)
+		require.Equal(t, tt.want, condition)
+		require.Equal(t, tt.args, args)
 	}
 }
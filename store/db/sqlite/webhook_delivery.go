@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/usememos/memos/store"
+)
+
+func (d *DB) CreateWebhookDelivery(ctx context.Context, create *store.WebhookDelivery) (*store.WebhookDelivery, error) {
+	fields := []string{"`webhook_id`", "`activity_type`", "`payload`", "`status`", "`next_attempt_ts`"}
+	placeholder := []string{"?", "?", "?", "?", "?"}
+	args := []any{create.WebhookID, create.ActivityType, create.Payload, create.Status, create.NextAttemptTs}
+	stmt := "INSERT INTO `webhook_delivery` (" + strings.Join(fields, ", ") + ") VALUES (" + strings.Join(placeholder, ", ") + ") RETURNING `id`, `created_ts`, `updated_ts`"
+	if err := d.db.QueryRowContext(ctx, stmt, args...).Scan(
+		&create.ID,
+		&create.CreatedTs,
+		&create.UpdatedTs,
+	); err != nil {
+		return nil, err
+	}
+	delivery := create
+	return delivery, nil
+}
+
+func (d *DB) ListWebhookDeliveries(ctx context.Context, find *store.FindWebhookDelivery) ([]*store.WebhookDelivery, error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if find.ID != nil {
+		where, args = append(where, "`id` = ?"), append(args, *find.ID)
+	}
+	if find.WebhookID != nil {
+		where, args = append(where, "`webhook_id` = ?"), append(args, *find.WebhookID)
+	}
+	if find.Status != nil {
+		where, args = append(where, "`status` = ?"), append(args, *find.Status)
+	}
+	if find.NextAttemptTsBefore != nil {
+		where, args = append(where, "`next_attempt_ts` <= ?"), append(args, *find.NextAttemptTsBefore)
+	}
+
+	order := "DESC"
+	if find.OrderByIDAsc {
+		order = "ASC"
+	}
+	query := "SELECT `id`, `created_ts`, `updated_ts`, `webhook_id`, `activity_type`, `payload`, `status`, `attempts`, `next_attempt_ts`, `response_code`, `response_body`, `error` FROM `webhook_delivery` WHERE " + strings.Join(where, " AND ") + " ORDER BY `id` " + order
+	if find.Limit != nil {
+		query = fmt.Sprintf("%s LIMIT %d", query, *find.Limit)
+		if find.Offset != nil {
+			query = fmt.Sprintf("%s OFFSET %d", query, *find.Offset)
+		}
+	}
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*store.WebhookDelivery{}
+	for rows.Next() {
+		delivery := &store.WebhookDelivery{}
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.CreatedTs,
+			&delivery.UpdatedTs,
+			&delivery.WebhookID,
+			&delivery.ActivityType,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptTs,
+			&delivery.ResponseCode,
+			&delivery.ResponseBody,
+			&delivery.Error,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (d *DB) UpdateWebhookDelivery(ctx context.Context, update *store.UpdateWebhookDelivery) (*store.WebhookDelivery, error) {
+	set, args := []string{"`updated_ts` = strftime('%s', 'now')"}, []any{}
+	if update.Status != nil {
+		set, args = append(set, "`status` = ?"), append(args, *update.Status)
+	}
+	if update.Attempts != nil {
+		set, args = append(set, "`attempts` = ?"), append(args, *update.Attempts)
+	}
+	if update.NextAttemptTs != nil {
+		set, args = append(set, "`next_attempt_ts` = ?"), append(args, *update.NextAttemptTs)
+	}
+	if update.ResponseCode != nil {
+		set, args = append(set, "`response_code` = ?"), append(args, *update.ResponseCode)
+	}
+	if update.ResponseBody != nil {
+		set, args = append(set, "`response_body` = ?"), append(args, *update.ResponseBody)
+	}
+	if update.Error != nil {
+		set, args = append(set, "`error` = ?"), append(args, *update.Error)
+	}
+	args = append(args, update.ID)
+
+	stmt := "UPDATE `webhook_delivery` SET " + strings.Join(set, ", ") + " WHERE `id` = ? RETURNING `id`, `created_ts`, `updated_ts`, `webhook_id`, `activity_type`, `payload`, `status`, `attempts`, `next_attempt_ts`, `response_code`, `response_body`, `error`"
+	delivery := &store.WebhookDelivery{}
+	if err := d.db.QueryRowContext(ctx, stmt, args...).Scan(
+		&delivery.ID,
+		&delivery.CreatedTs,
+		&delivery.UpdatedTs,
+		&delivery.WebhookID,
+		&delivery.ActivityType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptTs,
+		&delivery.ResponseCode,
+		&delivery.ResponseBody,
+		&delivery.Error,
+	); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (d *DB) DeleteWebhookDeliveries(ctx context.Context, delete *store.DeleteWebhookDelivery) error {
+	where, args := []string{"1 = 1"}, []any{}
+	if delete.WebhookID != nil {
+		where, args = append(where, "`webhook_id` = ?"), append(args, *delete.WebhookID)
+	}
+	if delete.CreatedTsBefore != nil {
+		where, args = append(where, "`created_ts` < ?"), append(args, *delete.CreatedTsBefore)
+	}
+	_, err := d.db.ExecContext(ctx, "DELETE FROM `webhook_delivery` WHERE "+strings.Join(where, " AND "), args...)
+	return err
+}
@@ -3,10 +3,6 @@ package store
 import (
 	"context"
 	"database/sql"
-
-	exprv1 "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
-
-	"github.com/usememos/memos/plugin/filter"
 )
 
 // Driver is an interface for store driver.
@@ -30,11 +26,33 @@ type Driver interface {
 	DeleteResource(ctx context.Context, delete *DeleteResource) error
 
 	// Memo model related methods.
+	// CreateMemo and UpdateMemo write the tags of the payload in the same transaction as the memo.
 	CreateMemo(ctx context.Context, create *Memo) (*Memo, error)
 	ListMemos(ctx context.Context, find *FindMemo) ([]*Memo, error)
 	UpdateMemo(ctx context.Context, update *UpdateMemo) error
 	DeleteMemo(ctx context.Context, delete *DeleteMemo) error
 
+	// MemoRevision model related methods.
+	CreateMemoRevision(ctx context.Context, create *MemoRevision) (*MemoRevision, error)
+	ListMemoRevisions(ctx context.Context, find *FindMemoRevision) ([]*MemoRevision, error)
+	DeleteMemoRevisions(ctx context.Context, delete *DeleteMemoRevision) error
+
+	// MemoTag model related methods.
+	CreateMemoTag(ctx context.Context, create *MemoTag) (*MemoTag, error)
+	ListMemoTags(ctx context.Context, find *FindMemoTag) ([]*MemoTag, error)
+	DeleteMemoTags(ctx context.Context, delete *DeleteMemoTag) error
+
+	// MemoShare model related methods.
+	CreateMemoShare(ctx context.Context, create *MemoShare) (*MemoShare, error)
+	ListMemoShares(ctx context.Context, find *FindMemoShare) ([]*MemoShare, error)
+	UpdateMemoShare(ctx context.Context, update *UpdateMemoShare) error
+	DeleteMemoShares(ctx context.Context, delete *DeleteMemoShare) error
+
+	// MemoGrant model related methods.
+	UpsertMemoGrant(ctx context.Context, upsert *MemoGrant) (*MemoGrant, error)
+	ListMemoGrants(ctx context.Context, find *FindMemoGrant) ([]*MemoGrant, error)
+	DeleteMemoGrants(ctx context.Context, delete *DeleteMemoGrant) error
+
 	// MemoRelation model related methods.
 	UpsertMemoRelation(ctx context.Context, create *MemoRelation) (*MemoRelation, error)
 	ListMemoRelations(ctx context.Context, find *FindMemoRelation) ([]*MemoRelation, error)
@@ -55,6 +73,24 @@ type Driver interface {
 	UpsertUserSetting(ctx context.Context, upsert *UserSetting) (*UserSetting, error)
 	ListUserSettings(ctx context.Context, find *FindUserSetting) ([]*UserSetting, error)
 
+	// UserGroup model related methods.
+	CreateUserGroup(ctx context.Context, create *UserGroup) (*UserGroup, error)
+	ListUserGroups(ctx context.Context, find *FindUserGroup) ([]*UserGroup, error)
+	UpdateUserGroup(ctx context.Context, update *UpdateUserGroup) (*UserGroup, error)
+	DeleteUserGroup(ctx context.Context, delete *DeleteUserGroup) error
+	UpsertUserGroupMember(ctx context.Context, upsert *UserGroupMember) (*UserGroupMember, error)
+	ListUserGroupMembers(ctx context.Context, find *FindUserGroupMember) ([]*UserGroupMember, error)
+	DeleteUserGroupMembers(ctx context.Context, delete *DeleteUserGroupMember) error
+
+	// CustomRole model related methods.
+	CreateCustomRole(ctx context.Context, create *CustomRole) (*CustomRole, error)
+	ListCustomRoles(ctx context.Context, find *FindCustomRole) ([]*CustomRole, error)
+	UpdateCustomRole(ctx context.Context, update *UpdateCustomRole) (*CustomRole, error)
+	DeleteCustomRole(ctx context.Context, delete *DeleteCustomRole) error
+	UpsertCustomRoleMember(ctx context.Context, upsert *CustomRoleMember) (*CustomRoleMember, error)
+	ListCustomRoleMembers(ctx context.Context, find *FindCustomRoleMember) ([]*CustomRoleMember, error)
+	DeleteCustomRoleMembers(ctx context.Context, delete *DeleteCustomRoleMember) error
+
 	// IdentityProvider model related methods.
 	CreateIdentityProvider(ctx context.Context, create *IdentityProvider) (*IdentityProvider, error)
 	ListIdentityProviders(ctx context.Context, find *FindIdentityProvider) ([]*IdentityProvider, error)
@@ -73,11 +109,22 @@ type Driver interface {
 	UpdateWebhook(ctx context.Context, update *UpdateWebhook) (*Webhook, error)
 	DeleteWebhook(ctx context.Context, delete *DeleteWebhook) error
 
+	// WebhookDelivery model related methods.
+	CreateWebhookDelivery(ctx context.Context, create *WebhookDelivery) (*WebhookDelivery, error)
+	ListWebhookDeliveries(ctx context.Context, find *FindWebhookDelivery) ([]*WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, update *UpdateWebhookDelivery) (*WebhookDelivery, error)
+	DeleteWebhookDeliveries(ctx context.Context, delete *DeleteWebhookDelivery) error
+
+	// RateLimit model related methods.
+	UpsertRateLimit(ctx context.Context, upsert *RateLimit) error
+	TakeRateLimitToken(ctx context.Context, take *TakeRateLimitToken) (bool, error)
+	IncreaseRateLimitFailures(ctx context.Context, increase *IncreaseRateLimitFailures) (int32, error)
+	LockRateLimit(ctx context.Context, lock *LockRateLimit) error
+	ListRateLimits(ctx context.Context, find *FindRateLimit) ([]*RateLimit, error)
+	DeleteRateLimits(ctx context.Context, delete *DeleteRateLimit) error
+
 	// Reaction model related methods.
 	UpsertReaction(ctx context.Context, create *Reaction) (*Reaction, error)
 	ListReactions(ctx context.Context, find *FindReaction) ([]*Reaction, error)
 	DeleteReaction(ctx context.Context, delete *DeleteReaction) error
-
-	// Shortcut related methods.
-	ConvertExprToSQL(ctx *filter.ConvertContext, expr *exprv1.Expr) error
 }
@@ -15,6 +15,7 @@ type IdentityProvider struct {
 	Type             storepb.IdentityProvider_Type
 	IdentifierFilter string
 	Config           string
+	Provisioning     string
 }
 
 type FindIdentityProvider struct {
@@ -26,6 +27,7 @@ type UpdateIdentityProvider struct {
 	Name             *string
 	IdentifierFilter *string
 	Config           *string
+	Provisioning     *string
 }
 
 type DeleteIdentityProvider struct {
@@ -99,6 +101,7 @@ type UpdateIdentityProviderV1 struct {
 	Name             *string
 	IdentifierFilter *string
 	Config           *storepb.IdentityProviderConfig
+	Provisioning     *storepb.ProvisioningRules
 }
 
 func (s *Store) UpdateIdentityProvider(ctx context.Context, update *UpdateIdentityProviderV1) (*storepb.IdentityProvider, error) {
@@ -118,6 +121,14 @@ func (s *Store) UpdateIdentityProvider(ctx context.Context, update *UpdateIdenti
 		}
 		updateRaw.Config = &configRaw
 	}
+	if update.Provisioning != nil {
+		provisioningRaw, err := protojson.Marshal(update.Provisioning)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to marshal ProvisioningRules")
+		}
+		provisioning := string(provisioningRaw)
+		updateRaw.Provisioning = &provisioning
+	}
 	identityProviderRaw, err := s.driver.UpdateIdentityProvider(ctx, updateRaw)
 	if err != nil {
 		return nil, err
@@ -153,6 +164,12 @@ func convertIdentityProviderFromRaw(raw *IdentityProvider) (*storepb.IdentityPro
 		return nil, err
 	}
 	identityProvider.Config = config
+	identityProvider.Provisioning = &storepb.ProvisioningRules{}
+	if raw.Provisioning != "" {
+		if err := protojsonUnmarshaler.Unmarshal([]byte(raw.Provisioning), identityProvider.Provisioning); err != nil {
+			return nil, errors.Wrap(err, "Failed to unmarshal ProvisioningRules")
+		}
+	}
 	return identityProvider, nil
 }
 
@@ -168,29 +185,60 @@ func convertIdentityProviderToRaw(identityProvider *storepb.IdentityProvider) (*
 		return nil, err
 	}
 	raw.Config = configRaw
+	provisioningRaw, err := protojson.Marshal(identityProvider.GetProvisioning())
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to marshal ProvisioningRules")
+	}
+	raw.Provisioning = string(provisioningRaw)
 	return raw, nil
 }
 
 func convertIdentityProviderConfigFromRaw(identityProviderType storepb.IdentityProvider_Type, raw string) (*storepb.IdentityProviderConfig, error) {
 	config := &storepb.IdentityProviderConfig{}
-	if identityProviderType == storepb.IdentityProvider_OAUTH2 {
+	switch identityProviderType {
+	case storepb.IdentityProvider_OAUTH2:
 		oauth2Config := &storepb.OAuth2Config{}
 		if err := protojsonUnmarshaler.Unmarshal([]byte(raw), oauth2Config); err != nil {
 			return nil, errors.Wrap(err, "Failed to unmarshal OAuth2Config")
 		}
 		config.Config = &storepb.IdentityProviderConfig_Oauth2Config{Oauth2Config: oauth2Config}
+	case storepb.IdentityProvider_OIDC:
+		oidcConfig := &storepb.OIDCConfig{}
+		if err := protojsonUnmarshaler.Unmarshal([]byte(raw), oidcConfig); err != nil {
+			return nil, errors.Wrap(err, "Failed to unmarshal OIDCConfig")
+		}
+		config.Config = &storepb.IdentityProviderConfig_OidcConfig{OidcConfig: oidcConfig}
+	case storepb.IdentityProvider_LDAP:
+		ldapConfig := &storepb.LDAPConfig{}
+		if err := protojsonUnmarshaler.Unmarshal([]byte(raw), ldapConfig); err != nil {
+			return nil, errors.Wrap(err, "Failed to unmarshal LDAPConfig")
+		}
+		config.Config = &storepb.IdentityProviderConfig_LdapConfig{LdapConfig: ldapConfig}
 	}
 	return config, nil
 }
 
 func convertIdentityProviderConfigToRaw(identityProviderType storepb.IdentityProvider_Type, config *storepb.IdentityProviderConfig) (string, error) {
 	raw := ""
-	if identityProviderType == storepb.IdentityProvider_OAUTH2 {
+	switch identityProviderType {
+	case storepb.IdentityProvider_OAUTH2:
 		bytes, err := protojson.Marshal(config.GetOauth2Config())
 		if err != nil {
 			return "", errors.Wrap(err, "Failed to marshal OAuth2Config")
 		}
 		raw = string(bytes)
+	case storepb.IdentityProvider_OIDC:
+		bytes, err := protojson.Marshal(config.GetOidcConfig())
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to marshal OIDCConfig")
+		}
+		raw = string(bytes)
+	case storepb.IdentityProvider_LDAP:
+		bytes, err := protojson.Marshal(config.GetLdapConfig())
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to marshal LDAPConfig")
+		}
+		raw = string(bytes)
 	}
 	return raw, nil
 }
@@ -41,6 +41,14 @@ type FindInbox struct {
 	// Pagination
 	Limit  *int
 	Offset *int
+	// Cursor finds the inboxes after the cursor, the list is ordered from newest to oldest.
+	Cursor *InboxCursor
+}
+
+// InboxCursor is the position of an inbox in a list of inboxes, for keyset pagination.
+type InboxCursor struct {
+	CreatedTs int64
+	ID        int32
 }
 
 type DeleteInbox struct {
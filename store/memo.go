@@ -2,7 +2,10 @@ package store
 
 import (
 	"context"
-	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
 
 	"github.com/usememos/memos/internal/util"
 
@@ -50,9 +53,13 @@ type Memo struct {
 	Visibility Visibility
 	Pinned     bool
 	Payload    *storepb.MemoPayload
+	// ScheduledTs is the time at which a scheduled memo is published, 0 if the memo is published.
+	ScheduledTs int64
 
 	// Composed fields
 	ParentID *int32
+	// SearchScore is the relevance of the memo to FindMemo.FullTextSearch, higher is better.
+	SearchScore float64
 }
 
 type FindMemo struct {
@@ -68,25 +75,80 @@ type FindMemo struct {
 	UpdatedTsBefore *int64
 
 	// Domain specific fields
-	ContentSearch   []string
-	VisibilityList  []Visibility
+	ContentSearch  []string
+	VisibilityList []Visibility
+	// GrantedUserID also finds the memos outside VisibilityList that are granted to the user,
+	// directly or through a user group.
+	GrantedUserID   *int32
 	PayloadFind     *FindMemoPayload
 	ExcludeContent  bool
 	ExcludeComments bool
 	Filter          *string
+	// FullTextSearch matches memos against the full-text index and orders them by relevance.
+	FullTextSearch *string
+	// ExcludeScheduled excludes the memos that are not published yet.
+	ExcludeScheduled bool
+	// ScheduledTsBefore finds the scheduled memos due to be published before the time.
+	ScheduledTsBefore *int64
+	// ParentID finds the comments of the memo.
+	ParentID *int32
 
 	// Pagination
 	Limit  *int
 	Offset *int
+	// Cursor finds the memos after the cursor in the order of the list.
+	// It is not used with FullTextSearch, which orders by relevance, or with OrderBy.
+	Cursor *MemoCursor
 
 	// Ordering
 	OrderByUpdatedTs bool
 	OrderByPinned    bool
 	OrderByTimeAsc   bool
+	// OrderBy orders the memos by the fields in turn, instead of the orderings above.
+	// Memos with equal fields are ordered by id.
+	OrderBy []MemoOrder
+}
+
+// MemoOrderField is a field memos can be ordered by.
+type MemoOrderField string
+
+const (
+	MemoOrderFieldPinned        MemoOrderField = "pinned"
+	MemoOrderFieldCreatedTs     MemoOrderField = "created_ts"
+	MemoOrderFieldUpdatedTs     MemoOrderField = "updated_ts"
+	MemoOrderFieldReactionCount MemoOrderField = "reaction_count"
+	MemoOrderFieldCommentCount  MemoOrderField = "comment_count"
+)
+
+type MemoOrder struct {
+	Field MemoOrderField
+	Desc  bool
+}
+
+// MemoCursor is the position of a memo in a list of memos, for keyset pagination.
+type MemoCursor struct {
+	Pinned bool
+	// DisplayTs is the updated_ts of the memo if the list is ordered by it, otherwise the created_ts.
+	DisplayTs int64
+	ID        int32
+}
+
+// CursorOf returns the cursor of a memo in the list of memos found by find.
+func (find *FindMemo) CursorOf(memo *Memo) *MemoCursor {
+	cursor := &MemoCursor{
+		Pinned:    memo.Pinned,
+		DisplayTs: memo.CreatedTs,
+		ID:        memo.ID,
+	}
+	if find.OrderByUpdatedTs {
+		cursor.DisplayTs = memo.UpdatedTs
+	}
+	return cursor
 }
 
 type FindMemoPayload struct {
-	Raw                *string
+	Raw *string
+	// TagSearch matches memos with all of the tags, a tag matches itself and its descendants.
 	TagSearch          []string
 	HasLink            bool
 	HasTaskList        bool
@@ -104,8 +166,18 @@ type UpdateMemo struct {
 	Visibility *Visibility
 	Pinned     *bool
 	Payload    *storepb.MemoPayload
+	// ScheduledTs sets the publish time, 0 publishes the memo.
+	ScheduledTs *int64
+	// EditorID is the user making the update, recorded on the revision. It defaults to the creator.
+	EditorID *int32
+	// Current is the memo as the caller read it. If set, the memo is only updated if its times, state,
+	// visibility, pinned and content have not changed since, and ErrMemoModified is returned otherwise.
+	Current *Memo
 }
 
+// ErrMemoModified is returned by UpdateMemo if the memo was changed since the caller read it.
+var ErrMemoModified = errors.New("memo has been modified")
+
 type DeleteMemo struct {
 	ID int32
 }
@@ -118,6 +190,12 @@ func (s *Store) CreateMemo(ctx context.Context, create *Memo) (*Memo, error) {
 }
 
 func (s *Store) ListMemos(ctx context.Context, find *FindMemo) ([]*Memo, error) {
+	if find.FullTextSearch != nil && len(SplitSearchTerms(*find.FullTextSearch)) == 0 {
+		return []*Memo{}, nil
+	}
+	if find.Cursor != nil && (find.FullTextSearch != nil || len(find.OrderBy) > 0) {
+		return nil, errors.New("cursor is not supported with full-text search or custom ordering")
+	}
 	return s.driver.ListMemos(ctx, find)
 }
 
@@ -142,5 +220,29 @@ func (s *Store) UpdateMemo(ctx context.Context, update *UpdateMemo) error {
 }
 
 func (s *Store) DeleteMemo(ctx context.Context, delete *DeleteMemo) error {
-	return s.driver.DeleteMemo(ctx, delete)
+	if err := s.driver.DeleteMemo(ctx, delete); err != nil {
+		return err
+	}
+	if err := s.DeleteMemoTags(ctx, &DeleteMemoTag{MemoID: &delete.ID}); err != nil {
+		return err
+	}
+	if err := s.DeleteMemoShares(ctx, &DeleteMemoShare{MemoID: &delete.ID}); err != nil {
+		return err
+	}
+	if err := s.DeleteMemoGrants(ctx, &DeleteMemoGrant{MemoID: &delete.ID}); err != nil {
+		return err
+	}
+	return s.DeleteMemoRevisions(ctx, &DeleteMemoRevision{MemoID: &delete.ID})
+}
+
+// SplitSearchTerms splits a full-text search query into lower-cased terms.
+// Only letters and digits are kept, so the terms are safe to embed in every driver's query syntax.
+func SplitSearchTerms(query string) []string {
+	terms := []string{}
+	for _, field := range strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		terms = append(terms, strings.ToLower(field))
+	}
+	return terms
 }
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"slices"
+)
+
+// MemoGrantPrincipalType is the type of the principal that a memo is granted to.
+type MemoGrantPrincipalType string
+
+const (
+	// MemoGrantPrincipalUser grants the memo to a user.
+	MemoGrantPrincipalUser MemoGrantPrincipalType = "USER"
+	// MemoGrantPrincipalGroup grants the memo to every member of a user group.
+	MemoGrantPrincipalGroup MemoGrantPrincipalType = "GROUP"
+)
+
+// MemoPermission is the access that a memo grant gives.
+type MemoPermission string
+
+const (
+	// MemoPermissionRead allows to view, comment on and react to the memo.
+	MemoPermissionRead MemoPermission = "READ"
+	// MemoPermissionWrite allows to edit the memo in addition to MemoPermissionRead.
+	MemoPermissionWrite MemoPermission = "WRITE"
+)
+
+// MemoGrant gives a user or a user group access to a memo regardless of its visibility.
+type MemoGrant struct {
+	MemoID        int32
+	PrincipalType MemoGrantPrincipalType
+	PrincipalID   int32
+	Permission    MemoPermission
+}
+
+type FindMemoGrant struct {
+	MemoID        *int32
+	PrincipalType *MemoGrantPrincipalType
+	PrincipalID   *int32
+}
+
+type DeleteMemoGrant struct {
+	MemoID        *int32
+	PrincipalType *MemoGrantPrincipalType
+	PrincipalID   *int32
+}
+
+// UpsertMemoGrant creates the grant or updates its permission if the principal already has one on the memo.
+func (s *Store) UpsertMemoGrant(ctx context.Context, upsert *MemoGrant) (*MemoGrant, error) {
+	return s.driver.UpsertMemoGrant(ctx, upsert)
+}
+
+func (s *Store) ListMemoGrants(ctx context.Context, find *FindMemoGrant) ([]*MemoGrant, error) {
+	return s.driver.ListMemoGrants(ctx, find)
+}
+
+func (s *Store) DeleteMemoGrants(ctx context.Context, delete *DeleteMemoGrant) error {
+	return s.driver.DeleteMemoGrants(ctx, delete)
+}
+
+// GetMemoPermission returns the permission that the grants of the memo give to the user,
+// directly or through the groups the user is a member of, or an empty permission if none.
+func (s *Store) GetMemoPermission(ctx context.Context, memoID int32, userID int32) (MemoPermission, error) {
+	grants, err := s.ListMemoGrants(ctx, &FindMemoGrant{MemoID: &memoID})
+	if err != nil {
+		return "", err
+	}
+	var groupIDs []int32
+	permission := MemoPermission("")
+	for _, grant := range grants {
+		switch grant.PrincipalType {
+		case MemoGrantPrincipalUser:
+			if grant.PrincipalID == userID && permission != MemoPermissionWrite {
+				permission = grant.Permission
+			}
+		case MemoGrantPrincipalGroup:
+			if groupIDs == nil {
+				members, err := s.ListUserGroupMembers(ctx, &FindUserGroupMember{UserID: &userID})
+				if err != nil {
+					return "", err
+				}
+				groupIDs = []int32{}
+				for _, member := range members {
+					groupIDs = append(groupIDs, member.GroupID)
+				}
+			}
+			if slices.Contains(groupIDs, grant.PrincipalID) && permission != MemoPermissionWrite {
+				permission = grant.Permission
+			}
+		}
+	}
+	return permission, nil
+}
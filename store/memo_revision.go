@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+)
+
+// MemoRevision is a snapshot of a memo's content and visibility.
+// A revision is recorded when a memo is created and whenever either of them changes.
+type MemoRevision struct {
+	ID         int32
+	MemoID     int32
+	CreatedTs  int64
+	Content    string
+	Visibility Visibility
+	// EditorID is the user who made the revision.
+	EditorID int32
+}
+
+type FindMemoRevision struct {
+	ID     *int32
+	MemoID *int32
+
+	// Pagination
+	Limit  *int
+	Offset *int
+}
+
+type DeleteMemoRevision struct {
+	MemoID *int32
+}
+
+func (s *Store) CreateMemoRevision(ctx context.Context, create *MemoRevision) (*MemoRevision, error) {
+	return s.driver.CreateMemoRevision(ctx, create)
+}
+
+// ListMemoRevisions returns the revisions with the newest first.
+func (s *Store) ListMemoRevisions(ctx context.Context, find *FindMemoRevision) ([]*MemoRevision, error) {
+	return s.driver.ListMemoRevisions(ctx, find)
+}
+
+func (s *Store) GetMemoRevision(ctx context.Context, find *FindMemoRevision) (*MemoRevision, error) {
+	list, err := s.ListMemoRevisions(ctx, find)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+func (s *Store) DeleteMemoRevisions(ctx context.Context, delete *DeleteMemoRevision) error {
+	return s.driver.DeleteMemoRevisions(ctx, delete)
+}
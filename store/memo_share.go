@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+)
+
+// MemoShare is a link to view a memo without signing in, identified by an unguessable token.
+type MemoShare struct {
+	ID        int32
+	Token     string
+	MemoID    int32
+	CreatorID int32
+	CreatedTs int64
+	// ExpiresTs is the time the share expires, 0 for never.
+	ExpiresTs int64
+	ViewCount int32
+}
+
+type FindMemoShare struct {
+	ID     *int32
+	Token  *string
+	MemoID *int32
+}
+
+type UpdateMemoShare struct {
+	ID int32
+	// IncreaseViewCount increases the view count by one.
+	IncreaseViewCount bool
+}
+
+type DeleteMemoShare struct {
+	ID     *int32
+	MemoID *int32
+}
+
+func (s *Store) CreateMemoShare(ctx context.Context, create *MemoShare) (*MemoShare, error) {
+	return s.driver.CreateMemoShare(ctx, create)
+}
+
+// ListMemoShares returns the shares with the newest first.
+func (s *Store) ListMemoShares(ctx context.Context, find *FindMemoShare) ([]*MemoShare, error) {
+	return s.driver.ListMemoShares(ctx, find)
+}
+
+func (s *Store) GetMemoShare(ctx context.Context, find *FindMemoShare) (*MemoShare, error) {
+	list, err := s.ListMemoShares(ctx, find)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+func (s *Store) UpdateMemoShare(ctx context.Context, update *UpdateMemoShare) error {
+	return s.driver.UpdateMemoShare(ctx, update)
+}
+
+func (s *Store) DeleteMemoShares(ctx context.Context, delete *DeleteMemoShare) error {
+	return s.driver.DeleteMemoShares(ctx, delete)
+}
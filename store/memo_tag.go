@@ -0,0 +1,40 @@
+package store
+
+import (
+	"context"
+)
+
+// MemoTag is a tag of a memo, kept in sync with the tags in the memo's payload,
+// so memos can be found by their tags with an index.
+type MemoTag struct {
+	MemoID int32
+	Tag    string
+}
+
+// MaxMemoTagLength is the maximum number of characters of a tag that memos are found by.
+const MaxMemoTagLength = 256
+
+type FindMemoTag struct {
+	MemoID     *int32
+	MemoIDList []int32
+	Tag        *string
+}
+
+type DeleteMemoTag struct {
+	MemoID *int32
+}
+
+func (s *Store) CreateMemoTag(ctx context.Context, create *MemoTag) (*MemoTag, error) {
+	return s.driver.CreateMemoTag(ctx, create)
+}
+
+func (s *Store) ListMemoTags(ctx context.Context, find *FindMemoTag) ([]*MemoTag, error) {
+	if find.MemoIDList != nil && len(find.MemoIDList) == 0 {
+		return []*MemoTag{}, nil
+	}
+	return s.driver.ListMemoTags(ctx, find)
+}
+
+func (s *Store) DeleteMemoTags(ctx context.Context, delete *DeleteMemoTag) error {
+	return s.driver.DeleteMemoTags(ctx, delete)
+}
@@ -0,0 +1,2 @@
+-- Full-text index over memo content.
+ALTER TABLE `memo` ADD FULLTEXT INDEX `idx_memo_content_fulltext` (`content`);
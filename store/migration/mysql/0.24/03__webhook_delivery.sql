@@ -0,0 +1,18 @@
+CREATE TABLE `webhook_delivery` (
+  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `created_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `webhook_id` INT NOT NULL,
+  `activity_type` VARCHAR(256) NOT NULL,
+  `payload` TEXT NOT NULL,
+  `status` VARCHAR(256) NOT NULL DEFAULT 'PENDING',
+  `attempts` INT NOT NULL DEFAULT 0,
+  `next_attempt_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `response_code` INT NOT NULL DEFAULT 0,
+  `response_body` TEXT NOT NULL,
+  `error` TEXT NOT NULL
+);
+
+CREATE INDEX `idx_webhook_delivery_webhook_id` ON `webhook_delivery` (`webhook_id`);
+
+CREATE INDEX `idx_webhook_delivery_status_next_attempt_ts` ON `webhook_delivery` (`status`, `next_attempt_ts`);
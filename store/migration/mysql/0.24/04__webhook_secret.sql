@@ -0,0 +1,5 @@
+ALTER TABLE `webhook` ADD COLUMN `secret` VARCHAR(256) NOT NULL DEFAULT '';
+
+ALTER TABLE `webhook` ADD COLUMN `payload` TEXT NOT NULL;
+
+UPDATE `webhook` SET `payload` = '{}';
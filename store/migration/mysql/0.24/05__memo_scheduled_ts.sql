@@ -0,0 +1,2 @@
+-- Add scheduled_ts column, 0 for published memos.
+ALTER TABLE `memo` ADD COLUMN `scheduled_ts` BIGINT NOT NULL DEFAULT 0;
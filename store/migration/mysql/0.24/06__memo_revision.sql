@@ -0,0 +1,12 @@
+CREATE TABLE `memo_revision` (
+  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `memo_id` INT NOT NULL,
+  `created_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `content` TEXT NOT NULL,
+  `visibility` VARCHAR(256) NOT NULL DEFAULT 'PRIVATE'
+);
+
+CREATE INDEX `idx_memo_revision_memo_id` ON `memo_revision` (`memo_id`);
+
+INSERT INTO `memo_revision` (`memo_id`, `created_ts`, `content`, `visibility`)
+SELECT `id`, `updated_ts`, `content`, `visibility` FROM `memo`;
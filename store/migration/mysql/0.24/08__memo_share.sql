@@ -0,0 +1,11 @@
+CREATE TABLE `memo_share` (
+  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `token` VARCHAR(256) NOT NULL UNIQUE,
+  `memo_id` INT NOT NULL,
+  `creator_id` INT NOT NULL,
+  `created_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `expires_ts` BIGINT NOT NULL DEFAULT 0,
+  `view_count` INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX `idx_memo_share_memo_id` ON `memo_share` (`memo_id`);
@@ -0,0 +1,24 @@
+CREATE TABLE `user_group` (
+  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `name` VARCHAR(256) NOT NULL UNIQUE,
+  `creator_id` INT NOT NULL,
+  `created_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE `user_group_member` (
+  `group_id` INT NOT NULL,
+  `user_id` INT NOT NULL,
+  UNIQUE(`group_id`, `user_id`)
+);
+
+CREATE INDEX `idx_user_group_member_user_id` ON `user_group_member` (`user_id`);
+
+CREATE TABLE `memo_grant` (
+  `memo_id` INT NOT NULL,
+  `principal_type` VARCHAR(256) NOT NULL,
+  `principal_id` INT NOT NULL,
+  `permission` VARCHAR(256) NOT NULL,
+  UNIQUE(`memo_id`, `principal_type`, `principal_id`)
+);
+
+CREATE INDEX `idx_memo_grant_principal` ON `memo_grant` (`principal_type`, `principal_id`);
@@ -0,0 +1,3 @@
+ALTER TABLE `memo_revision` ADD COLUMN `editor_id` INT NOT NULL DEFAULT 0;
+
+UPDATE `memo_revision` JOIN `memo` ON `memo`.`id` = `memo_revision`.`memo_id` SET `memo_revision`.`editor_id` = `memo`.`creator_id`;
@@ -0,0 +1,15 @@
+CREATE TABLE `custom_role` (
+  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `name` VARCHAR(256) NOT NULL UNIQUE,
+  `description` TEXT NOT NULL,
+  `permissions` TEXT NOT NULL,
+  `created_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE `custom_role_member` (
+  `role_id` INT NOT NULL,
+  `user_id` INT NOT NULL,
+  UNIQUE(`role_id`, `user_id`)
+);
+
+CREATE INDEX `idx_custom_role_member_user_id` ON `custom_role_member` (`user_id`);
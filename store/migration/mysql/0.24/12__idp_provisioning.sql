@@ -0,0 +1,3 @@
+ALTER TABLE `idp` ADD COLUMN `provisioning` TEXT NOT NULL;
+
+UPDATE `idp` SET `provisioning` = '{}';
@@ -0,0 +1,7 @@
+CREATE TABLE `rate_limit` (
+  `key` VARCHAR(256) NOT NULL PRIMARY KEY,
+  `tokens` DOUBLE NOT NULL DEFAULT 0,
+  `failures` INT NOT NULL DEFAULT 0,
+  `locked_until_ts` BIGINT NOT NULL DEFAULT 0,
+  `updated_ms` BIGINT NOT NULL
+);
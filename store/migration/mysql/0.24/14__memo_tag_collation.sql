@@ -0,0 +1,7 @@
+-- Tags are case-sensitive, so that #Go and #go are different tags.
+ALTER TABLE `memo_tag` MODIFY `tag` VARCHAR(256) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL;
+
+-- Add back the tags that the case-insensitive collation or failed tag writes left out.
+INSERT IGNORE INTO `memo_tag` (`memo_id`, `tag`)
+SELECT `memo`.`id`, `tags`.`tag` FROM `memo`, JSON_TABLE(`memo`.`payload`, '$.tags[*]' COLUMNS (`tag` VARCHAR(1024) PATH '$')) AS `tags`
+WHERE CHAR_LENGTH(`tags`.`tag`) <= 256;
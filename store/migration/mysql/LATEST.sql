@@ -45,7 +45,9 @@ CREATE TABLE `memo` (
   `content` TEXT NOT NULL,
   `visibility` VARCHAR(256) NOT NULL DEFAULT 'PRIVATE',
   `pinned` BOOLEAN NOT NULL DEFAULT FALSE,
-  `payload` JSON NOT NULL
+  `payload` JSON NOT NULL,
+  `scheduled_ts` BIGINT NOT NULL DEFAULT 0,
+  FULLTEXT INDEX `idx_memo_content_fulltext` (`content`)
 );
 
 -- memo_organizer
@@ -64,6 +66,86 @@ CREATE TABLE `memo_relation` (
   UNIQUE(`memo_id`,`related_memo_id`,`type`)
 );
 
+-- memo_revision
+CREATE TABLE `memo_revision` (
+  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `memo_id` INT NOT NULL,
+  `created_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `content` TEXT NOT NULL,
+  `visibility` VARCHAR(256) NOT NULL DEFAULT 'PRIVATE',
+  `editor_id` INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX `idx_memo_revision_memo_id` ON `memo_revision` (`memo_id`);
+
+-- memo_tag
+CREATE TABLE `memo_tag` (
+  `memo_id` INT NOT NULL,
+  `tag` VARCHAR(256) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  UNIQUE(`memo_id`,`tag`)
+);
+
+CREATE INDEX `idx_memo_tag_tag` ON `memo_tag` (`tag`);
+
+-- memo_share
+CREATE TABLE `memo_share` (
+  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `token` VARCHAR(256) NOT NULL UNIQUE,
+  `memo_id` INT NOT NULL,
+  `creator_id` INT NOT NULL,
+  `created_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `expires_ts` BIGINT NOT NULL DEFAULT 0,
+  `view_count` INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX `idx_memo_share_memo_id` ON `memo_share` (`memo_id`);
+
+-- user_group
+CREATE TABLE `user_group` (
+  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `name` VARCHAR(256) NOT NULL UNIQUE,
+  `creator_id` INT NOT NULL,
+  `created_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- user_group_member
+CREATE TABLE `user_group_member` (
+  `group_id` INT NOT NULL,
+  `user_id` INT NOT NULL,
+  UNIQUE(`group_id`, `user_id`)
+);
+
+CREATE INDEX `idx_user_group_member_user_id` ON `user_group_member` (`user_id`);
+
+-- custom_role
+CREATE TABLE `custom_role` (
+  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `name` VARCHAR(256) NOT NULL UNIQUE,
+  `description` TEXT NOT NULL,
+  `permissions` TEXT NOT NULL,
+  `created_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- custom_role_member
+CREATE TABLE `custom_role_member` (
+  `role_id` INT NOT NULL,
+  `user_id` INT NOT NULL,
+  UNIQUE(`role_id`, `user_id`)
+);
+
+CREATE INDEX `idx_custom_role_member_user_id` ON `custom_role_member` (`user_id`);
+
+-- memo_grant
+CREATE TABLE `memo_grant` (
+  `memo_id` INT NOT NULL,
+  `principal_type` VARCHAR(256) NOT NULL,
+  `principal_id` INT NOT NULL,
+  `permission` VARCHAR(256) NOT NULL,
+  UNIQUE(`memo_id`, `principal_type`, `principal_id`)
+);
+
+CREATE INDEX `idx_memo_grant_principal` ON `memo_grant` (`principal_type`, `principal_id`);
+
 -- resource
 CREATE TABLE `resource` (
   `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
@@ -97,7 +179,8 @@ CREATE TABLE `idp` (
   `name` TEXT NOT NULL,
   `type` TEXT NOT NULL,
   `identifier_filter` VARCHAR(256) NOT NULL DEFAULT '',
-  `config` TEXT NOT NULL
+  `config` TEXT NOT NULL,
+  `provisioning` TEXT NOT NULL
 );
 
 -- inbox
@@ -118,9 +201,31 @@ CREATE TABLE `webhook` (
   `row_status` VARCHAR(256) NOT NULL DEFAULT 'NORMAL',
   `creator_id` INT NOT NULL,
   `name` TEXT NOT NULL,
-  `url` TEXT NOT NULL
+  `url` TEXT NOT NULL,
+  `secret` VARCHAR(256) NOT NULL DEFAULT '',
+  `payload` TEXT NOT NULL
+);
+
+-- webhook_delivery
+CREATE TABLE `webhook_delivery` (
+  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `created_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `webhook_id` INT NOT NULL,
+  `activity_type` VARCHAR(256) NOT NULL,
+  `payload` TEXT NOT NULL,
+  `status` VARCHAR(256) NOT NULL DEFAULT 'PENDING',
+  `attempts` INT NOT NULL DEFAULT 0,
+  `next_attempt_ts` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `response_code` INT NOT NULL DEFAULT 0,
+  `response_body` TEXT NOT NULL,
+  `error` TEXT NOT NULL
 );
 
+CREATE INDEX `idx_webhook_delivery_webhook_id` ON `webhook_delivery` (`webhook_id`);
+
+CREATE INDEX `idx_webhook_delivery_status_next_attempt_ts` ON `webhook_delivery` (`status`, `next_attempt_ts`);
+
 -- reaction
 CREATE TABLE `reaction` (
   `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
@@ -130,3 +235,12 @@ CREATE TABLE `reaction` (
   `reaction_type` VARCHAR(256) NOT NULL,
   UNIQUE(`creator_id`,`content_id`,`reaction_type`)  
 );
+
+-- rate_limit
+CREATE TABLE `rate_limit` (
+  `key` VARCHAR(256) NOT NULL PRIMARY KEY,
+  `tokens` DOUBLE NOT NULL DEFAULT 0,
+  `failures` INT NOT NULL DEFAULT 0,
+  `locked_until_ts` BIGINT NOT NULL DEFAULT 0,
+  `updated_ms` BIGINT NOT NULL
+);
@@ -0,0 +1,4 @@
+-- Full-text search vector over memo content.
+ALTER TABLE memo ADD COLUMN content_tsv TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED;
+
+CREATE INDEX idx_memo_content_tsv ON memo USING GIN (content_tsv);
@@ -0,0 +1,12 @@
+CREATE TABLE memo_tag (
+  memo_id INTEGER NOT NULL,
+  tag TEXT NOT NULL,
+  UNIQUE(memo_id, tag)
+);
+
+CREATE INDEX idx_memo_tag_tag ON memo_tag (tag);
+
+INSERT INTO memo_tag (memo_id, tag)
+SELECT DISTINCT id, jsonb_array_elements_text(payload->'tags') FROM memo
+WHERE jsonb_typeof(payload->'tags') = 'array'
+ON CONFLICT DO NOTHING;
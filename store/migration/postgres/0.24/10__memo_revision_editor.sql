@@ -0,0 +1,3 @@
+ALTER TABLE memo_revision ADD COLUMN editor_id INTEGER NOT NULL DEFAULT 0;
+
+UPDATE memo_revision SET editor_id = memo.creator_id FROM memo WHERE memo.id = memo_revision.memo_id;
@@ -0,0 +1 @@
+ALTER TABLE idp ADD COLUMN provisioning JSONB NOT NULL DEFAULT '{}';
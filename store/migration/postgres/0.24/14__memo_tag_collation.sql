@@ -0,0 +1,5 @@
+-- Add back the tags that failed tag writes left out.
+INSERT INTO memo_tag (memo_id, tag)
+SELECT DISTINCT id, jsonb_array_elements_text(payload->'tags') FROM memo
+WHERE jsonb_typeof(payload->'tags') = 'array'
+ON CONFLICT DO NOTHING;
@@ -45,9 +45,13 @@ CREATE TABLE memo (
   content TEXT NOT NULL,
   visibility TEXT NOT NULL DEFAULT 'PRIVATE',
   pinned BOOLEAN NOT NULL DEFAULT FALSE,
-  payload JSONB NOT NULL DEFAULT '{}'
+  payload JSONB NOT NULL DEFAULT '{}',
+  scheduled_ts BIGINT NOT NULL DEFAULT 0,
+  content_tsv TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED
 );
 
+CREATE INDEX idx_memo_content_tsv ON memo USING GIN (content_tsv);
+
 -- memo_organizer
 CREATE TABLE memo_organizer (
   memo_id INTEGER NOT NULL,
@@ -64,6 +68,86 @@ CREATE TABLE memo_relation (
   UNIQUE(memo_id, related_memo_id, type)
 );
 
+-- memo_revision
+CREATE TABLE memo_revision (
+  id SERIAL PRIMARY KEY,
+  memo_id INTEGER NOT NULL,
+  created_ts BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+  content TEXT NOT NULL DEFAULT '',
+  visibility TEXT NOT NULL DEFAULT 'PRIVATE',
+  editor_id INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_memo_revision_memo_id ON memo_revision (memo_id);
+
+-- memo_tag
+CREATE TABLE memo_tag (
+  memo_id INTEGER NOT NULL,
+  tag TEXT NOT NULL,
+  UNIQUE(memo_id, tag)
+);
+
+CREATE INDEX idx_memo_tag_tag ON memo_tag (tag);
+
+-- memo_share
+CREATE TABLE memo_share (
+  id SERIAL PRIMARY KEY,
+  token TEXT NOT NULL UNIQUE,
+  memo_id INTEGER NOT NULL,
+  creator_id INTEGER NOT NULL,
+  created_ts BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+  expires_ts BIGINT NOT NULL DEFAULT 0,
+  view_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_memo_share_memo_id ON memo_share (memo_id);
+
+-- user_group
+CREATE TABLE user_group (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE,
+  creator_id INTEGER NOT NULL,
+  created_ts BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
+
+-- user_group_member
+CREATE TABLE user_group_member (
+  group_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  UNIQUE(group_id, user_id)
+);
+
+CREATE INDEX idx_user_group_member_user_id ON user_group_member (user_id);
+
+-- custom_role
+CREATE TABLE custom_role (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE,
+  description TEXT NOT NULL DEFAULT '',
+  permissions TEXT NOT NULL DEFAULT '',
+  created_ts BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())
+);
+
+-- custom_role_member
+CREATE TABLE custom_role_member (
+  role_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  UNIQUE(role_id, user_id)
+);
+
+CREATE INDEX idx_custom_role_member_user_id ON custom_role_member (user_id);
+
+-- memo_grant
+CREATE TABLE memo_grant (
+  memo_id INTEGER NOT NULL,
+  principal_type TEXT NOT NULL,
+  principal_id INTEGER NOT NULL,
+  permission TEXT NOT NULL,
+  UNIQUE(memo_id, principal_type, principal_id)
+);
+
+CREATE INDEX idx_memo_grant_principal ON memo_grant (principal_type, principal_id);
+
 -- resource
 CREATE TABLE resource (
   id SERIAL PRIMARY KEY,
@@ -97,7 +181,8 @@ CREATE TABLE idp (
   name TEXT NOT NULL,
   type TEXT NOT NULL,
   identifier_filter TEXT NOT NULL DEFAULT '',
-  config JSONB NOT NULL DEFAULT '{}'
+  config JSONB NOT NULL DEFAULT '{}',
+  provisioning JSONB NOT NULL DEFAULT '{}'
 );
 
 -- inbox
@@ -118,9 +203,31 @@ CREATE TABLE webhook (
   row_status TEXT NOT NULL DEFAULT 'NORMAL',
   creator_id INTEGER NOT NULL,
   name TEXT NOT NULL,
-  url TEXT NOT NULL
+  url TEXT NOT NULL,
+  secret TEXT NOT NULL DEFAULT '',
+  payload TEXT NOT NULL DEFAULT '{}'
+);
+
+-- webhook_delivery
+CREATE TABLE webhook_delivery (
+  id SERIAL PRIMARY KEY,
+  created_ts BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+  updated_ts BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+  webhook_id INTEGER NOT NULL,
+  activity_type TEXT NOT NULL,
+  payload TEXT NOT NULL DEFAULT '{}',
+  status TEXT NOT NULL DEFAULT 'PENDING',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_ts BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW()),
+  response_code INTEGER NOT NULL DEFAULT 0,
+  response_body TEXT NOT NULL DEFAULT '',
+  error TEXT NOT NULL DEFAULT ''
 );
 
+CREATE INDEX idx_webhook_delivery_webhook_id ON webhook_delivery (webhook_id);
+
+CREATE INDEX idx_webhook_delivery_status_next_attempt_ts ON webhook_delivery (status, next_attempt_ts);
+
 -- reaction
 CREATE TABLE reaction (
   id SERIAL PRIMARY KEY,
@@ -130,3 +237,12 @@ CREATE TABLE reaction (
   reaction_type TEXT NOT NULL,
   UNIQUE(creator_id, content_id, reaction_type)
 );
+
+-- rate_limit
+CREATE TABLE rate_limit (
+  key TEXT NOT NULL PRIMARY KEY,
+  tokens DOUBLE PRECISION NOT NULL DEFAULT 0,
+  failures INTEGER NOT NULL DEFAULT 0,
+  locked_until_ts BIGINT NOT NULL DEFAULT 0,
+  updated_ms BIGINT NOT NULL
+);
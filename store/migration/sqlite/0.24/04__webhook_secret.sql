@@ -0,0 +1,3 @@
+ALTER TABLE webhook ADD COLUMN secret TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE webhook ADD COLUMN payload TEXT NOT NULL DEFAULT '{}';
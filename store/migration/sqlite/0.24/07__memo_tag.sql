@@ -0,0 +1,11 @@
+CREATE TABLE memo_tag (
+  memo_id INTEGER NOT NULL,
+  tag TEXT NOT NULL,
+  UNIQUE(memo_id, tag)
+);
+
+CREATE INDEX idx_memo_tag_tag ON memo_tag (tag);
+
+INSERT OR IGNORE INTO memo_tag (memo_id, tag)
+SELECT memo.id, tags.value FROM memo, JSON_EACH(memo.payload, '$.tags') AS tags
+WHERE JSON_TYPE(memo.payload, '$.tags') = 'array';
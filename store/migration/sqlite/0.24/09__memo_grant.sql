@@ -0,0 +1,24 @@
+CREATE TABLE user_group (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL UNIQUE,
+  creator_id INTEGER NOT NULL,
+  created_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now'))
+);
+
+CREATE TABLE user_group_member (
+  group_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  UNIQUE(group_id, user_id)
+);
+
+CREATE INDEX idx_user_group_member_user_id ON user_group_member (user_id);
+
+CREATE TABLE memo_grant (
+  memo_id INTEGER NOT NULL,
+  principal_type TEXT NOT NULL CHECK (principal_type IN ('USER', 'GROUP')),
+  principal_id INTEGER NOT NULL,
+  permission TEXT NOT NULL CHECK (permission IN ('READ', 'WRITE')),
+  UNIQUE(memo_id, principal_type, principal_id)
+);
+
+CREATE INDEX idx_memo_grant_principal ON memo_grant (principal_type, principal_id);
@@ -0,0 +1,15 @@
+CREATE TABLE custom_role (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL UNIQUE,
+  description TEXT NOT NULL DEFAULT '',
+  permissions TEXT NOT NULL DEFAULT '',
+  created_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now'))
+);
+
+CREATE TABLE custom_role_member (
+  role_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  UNIQUE(role_id, user_id)
+);
+
+CREATE INDEX idx_custom_role_member_user_id ON custom_role_member (user_id);
@@ -0,0 +1 @@
+ALTER TABLE idp ADD COLUMN provisioning TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1,7 @@
+CREATE TABLE rate_limit (
+  key TEXT NOT NULL PRIMARY KEY,
+  tokens REAL NOT NULL DEFAULT 0,
+  failures INTEGER NOT NULL DEFAULT 0,
+  locked_until_ts BIGINT NOT NULL DEFAULT 0,
+  updated_ms BIGINT NOT NULL
+);
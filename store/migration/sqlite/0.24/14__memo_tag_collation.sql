@@ -0,0 +1,4 @@
+-- Add back the tags that failed tag writes left out.
+INSERT OR IGNORE INTO memo_tag (memo_id, tag)
+SELECT memo.id, tags.value FROM memo, JSON_EACH(memo.payload, '$.tags') AS tags
+WHERE JSON_TYPE(memo.payload, '$.tags') = 'array';
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+)
+
+// RateLimit is the state of a rate limit key, either the token bucket of a rate limit or the failures
+// of a lockout.
+type RateLimit struct {
+	Key      string
+	Tokens   float64
+	Failures int32
+	// LockedUntilTs is the unix time the lockout of the key ends.
+	LockedUntilTs int64
+	// UpdatedMs is the unix time in milliseconds the state last changed, precise enough to refill
+	// token buckets with.
+	UpdatedMs int64
+}
+
+type FindRateLimit struct {
+	Key *string
+}
+
+// TakeRateLimitToken takes a token from the token bucket of a key, after refilling it up to the time.
+// A key without state has a full bucket.
+type TakeRateLimitToken struct {
+	Key         string
+	Burst       float64
+	TokensPerMs float64
+	NowMs       int64
+}
+
+// IncreaseRateLimitFailures records a failure of a key at the time. The failures start over if the
+// key last changed before the window.
+type IncreaseRateLimitFailures struct {
+	Key      string
+	NowMs    int64
+	WindowMs int64
+}
+
+// LockRateLimit locks a key out until the time, unless it is locked out longer already.
+type LockRateLimit struct {
+	Key           string
+	LockedUntilTs int64
+}
+
+type DeleteRateLimit struct {
+	Key             *string
+	UpdatedMsBefore *int64
+}
+
+func (s *Store) UpsertRateLimit(ctx context.Context, upsert *RateLimit) error {
+	return s.driver.UpsertRateLimit(ctx, upsert)
+}
+
+// TakeRateLimitToken returns whether it took a token. Concurrent calls take every token once.
+func (s *Store) TakeRateLimitToken(ctx context.Context, take *TakeRateLimitToken) (bool, error) {
+	return s.driver.TakeRateLimitToken(ctx, take)
+}
+
+// IncreaseRateLimitFailures returns the failures in a row of the key, counting concurrent failures.
+func (s *Store) IncreaseRateLimitFailures(ctx context.Context, increase *IncreaseRateLimitFailures) (int32, error) {
+	return s.driver.IncreaseRateLimitFailures(ctx, increase)
+}
+
+func (s *Store) LockRateLimit(ctx context.Context, lock *LockRateLimit) error {
+	return s.driver.LockRateLimit(ctx, lock)
+}
+
+func (s *Store) ListRateLimits(ctx context.Context, find *FindRateLimit) ([]*RateLimit, error) {
+	return s.driver.ListRateLimits(ctx, find)
+}
+
+func (s *Store) GetRateLimit(ctx context.Context, find *FindRateLimit) (*RateLimit, error) {
+	list, err := s.ListRateLimits(ctx, find)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+func (s *Store) DeleteRateLimits(ctx context.Context, delete *DeleteRateLimit) error {
+	return s.driver.DeleteRateLimits(ctx, delete)
+}
@@ -32,6 +32,17 @@ func (s *Store) ListReactions(ctx context.Context, find *FindReaction) ([]*React
 	return s.driver.ListReactions(ctx, find)
 }
 
+func (s *Store) GetReaction(ctx context.Context, find *FindReaction) (*Reaction, error) {
+	list, err := s.ListReactions(ctx, find)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
 func (s *Store) DeleteReaction(ctx context.Context, delete *DeleteReaction) error {
 	return s.driver.DeleteReaction(ctx, delete)
 }
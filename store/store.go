@@ -14,6 +14,11 @@ type Store struct {
 	userCache             sync.Map // map[int]*User
 	userSettingCache      sync.Map // map[string]*storepb.UserSetting
 	idpCache              sync.Map // map[int]*storepb.IdentityProvider
+
+	// accessTokenMutexes serializes the updates of the access tokens of each user.
+	accessTokenMutexes sync.Map // map[int32]*sync.Mutex
+	// twoFactorAuthMutexes serializes the updates of the two-factor authentication of each user.
+	twoFactorAuthMutexes sync.Map // map[int32]*sync.Mutex
 }
 
 // New creates a new instance of Store.
@@ -154,6 +154,9 @@ func (s *Store) DeleteUser(ctx context.Context, delete *DeleteUser) error {
 	if err != nil {
 		return err
 	}
+	if err := s.DeleteCustomRoleMembers(ctx, &DeleteCustomRoleMember{UserID: &delete.ID}); err != nil {
+		return err
+	}
 
 	s.userCache.Delete(delete.ID)
 	return nil
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"context"
+)
+
+// UserGroup is a named set of users that memos can be granted to.
+type UserGroup struct {
+	ID        int32
+	Name      string
+	CreatorID int32
+	CreatedTs int64
+}
+
+type FindUserGroup struct {
+	ID   *int32
+	Name *string
+	// MemberID finds the groups that the user is a member of.
+	MemberID *int32
+}
+
+type UpdateUserGroup struct {
+	ID   int32
+	Name *string
+}
+
+type DeleteUserGroup struct {
+	ID int32
+}
+
+type UserGroupMember struct {
+	GroupID int32
+	UserID  int32
+}
+
+type FindUserGroupMember struct {
+	GroupID *int32
+	UserID  *int32
+}
+
+type DeleteUserGroupMember struct {
+	GroupID *int32
+	UserID  *int32
+}
+
+func (s *Store) CreateUserGroup(ctx context.Context, create *UserGroup) (*UserGroup, error) {
+	return s.driver.CreateUserGroup(ctx, create)
+}
+
+func (s *Store) ListUserGroups(ctx context.Context, find *FindUserGroup) ([]*UserGroup, error) {
+	return s.driver.ListUserGroups(ctx, find)
+}
+
+func (s *Store) GetUserGroup(ctx context.Context, find *FindUserGroup) (*UserGroup, error) {
+	list, err := s.ListUserGroups(ctx, find)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+func (s *Store) UpdateUserGroup(ctx context.Context, update *UpdateUserGroup) (*UserGroup, error) {
+	return s.driver.UpdateUserGroup(ctx, update)
+}
+
+// DeleteUserGroup deletes the group with its members and the memo grants to it.
+func (s *Store) DeleteUserGroup(ctx context.Context, delete *DeleteUserGroup) error {
+	if err := s.driver.DeleteUserGroup(ctx, delete); err != nil {
+		return err
+	}
+	if err := s.DeleteUserGroupMembers(ctx, &DeleteUserGroupMember{GroupID: &delete.ID}); err != nil {
+		return err
+	}
+	principalType := MemoGrantPrincipalGroup
+	return s.DeleteMemoGrants(ctx, &DeleteMemoGrant{PrincipalType: &principalType, PrincipalID: &delete.ID})
+}
+
+func (s *Store) UpsertUserGroupMember(ctx context.Context, upsert *UserGroupMember) (*UserGroupMember, error) {
+	return s.driver.UpsertUserGroupMember(ctx, upsert)
+}
+
+func (s *Store) ListUserGroupMembers(ctx context.Context, find *FindUserGroupMember) ([]*UserGroupMember, error) {
+	return s.driver.ListUserGroupMembers(ctx, find)
+}
+
+func (s *Store) DeleteUserGroupMembers(ctx context.Context, delete *DeleteUserGroupMember) error {
+	return s.driver.DeleteUserGroupMembers(ctx, delete)
+}
@@ -2,9 +2,14 @@ package store
 
 import (
 	"context"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	storepb "github.com/usememos/memos/proto/gen/store"
 )
@@ -105,20 +110,130 @@ func (s *Store) GetUserAccessTokens(ctx context.Context, userID int32) ([]*store
 	return accessTokensUserSetting.AccessTokens, nil
 }
 
+// GetUserTwoFactorAuth returns the two-factor authentication of the user, or nil if the user has never enrolled.
+func (s *Store) GetUserTwoFactorAuth(ctx context.Context, userID int32) (*storepb.TwoFactorAuthUserSetting, error) {
+	userSetting, err := s.GetUserSetting(ctx, &FindUserSetting{
+		UserID: &userID,
+		Key:    storepb.UserSettingKey_TWO_FACTOR_AUTH,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if userSetting == nil {
+		return nil, nil
+	}
+	return userSetting.GetTwoFactorAuth(), nil
+}
+
+// UpsertUserTwoFactorAuth saves the two-factor authentication of the user.
+func (s *Store) UpsertUserTwoFactorAuth(ctx context.Context, userID int32, twoFactorAuth *storepb.TwoFactorAuthUserSetting) error {
+	return s.UpdateUserTwoFactorAuth(ctx, userID, func(*storepb.TwoFactorAuthUserSetting) (*storepb.TwoFactorAuthUserSetting, error) {
+		return twoFactorAuth, nil
+	})
+}
+
+// UpdateUserTwoFactorAuth replaces the two-factor authentication of the user with the one that update returns
+// from the current one, which is nil if the user has never enrolled, unless it returns nil. The updates of a
+// user are serialized, so that concurrent sign-ins cannot both use the same code.
+func (s *Store) UpdateUserTwoFactorAuth(ctx context.Context, userID int32, update func(*storepb.TwoFactorAuthUserSetting) (*storepb.TwoFactorAuthUserSetting, error)) error {
+	mutex, _ := s.twoFactorAuthMutexes.LoadOrStore(userID, &sync.Mutex{})
+	mutex.(*sync.Mutex).Lock()
+	defer mutex.(*sync.Mutex).Unlock()
+
+	twoFactorAuth, err := s.GetUserTwoFactorAuth(ctx, userID)
+	if err != nil {
+		return err
+	}
+	newTwoFactorAuth, err := update(twoFactorAuth)
+	if err != nil || newTwoFactorAuth == nil {
+		return err
+	}
+	_, err = s.UpsertUserSetting(ctx, &storepb.UserSetting{
+		UserId: userID,
+		Key:    storepb.UserSettingKey_TWO_FACTOR_AUTH,
+		Value: &storepb.UserSetting_TwoFactorAuth{
+			TwoFactorAuth: newTwoFactorAuth,
+		},
+	})
+	return err
+}
+
+// AddUserAccessToken adds the access token to the user.
+func (s *Store) AddUserAccessToken(ctx context.Context, userID int32, accessToken *storepb.AccessTokensUserSetting_AccessToken) error {
+	return s.updateUserAccessTokens(ctx, userID, func(accessTokens []*storepb.AccessTokensUserSetting_AccessToken) ([]*storepb.AccessTokensUserSetting_AccessToken, error) {
+		return append(slices.Clone(accessTokens), accessToken), nil
+	})
+}
+
 // RemoveUserAccessToken remove the access token of the user.
 func (s *Store) RemoveUserAccessToken(ctx context.Context, userID int32, token string) error {
-	oldAccessTokens, err := s.GetUserAccessTokens(ctx, userID)
+	_, err := s.RemoveUserAccessTokens(ctx, userID, func(t *storepb.AccessTokensUserSetting_AccessToken) bool {
+		return token == t.AccessToken
+	})
+	return err
+}
+
+// RemoveUserAccessTokens removes the access tokens of the user that match, and returns the number of
+// removed access tokens. The user settings are only written if any access token matches.
+func (s *Store) RemoveUserAccessTokens(ctx context.Context, userID int32, match func(*storepb.AccessTokensUserSetting_AccessToken) bool) (int, error) {
+	removed := 0
+	err := s.updateUserAccessTokens(ctx, userID, func(accessTokens []*storepb.AccessTokensUserSetting_AccessToken) ([]*storepb.AccessTokensUserSetting_AccessToken, error) {
+		newAccessTokens := make([]*storepb.AccessTokensUserSetting_AccessToken, 0, len(accessTokens))
+		for _, t := range accessTokens {
+			if !match(t) {
+				newAccessTokens = append(newAccessTokens, t)
+			}
+		}
+		removed = len(accessTokens) - len(newAccessTokens)
+		if removed == 0 {
+			return nil, nil
+		}
+		return newAccessTokens, nil
+	})
 	if err != nil {
-		return err
+		return 0, err
 	}
+	return removed, nil
+}
 
-	newAccessTokens := make([]*storepb.AccessTokensUserSetting_AccessToken, 0, len(oldAccessTokens))
-	for _, t := range oldAccessTokens {
-		if token != t.AccessToken {
+// UpdateUserAccessTokenUsage records the time and the client IP of the last use of the access token of the user.
+func (s *Store) UpdateUserAccessTokenUsage(ctx context.Context, userID int32, token string, usedTime time.Time, ip string) error {
+	return s.updateUserAccessTokens(ctx, userID, func(accessTokens []*storepb.AccessTokensUserSetting_AccessToken) ([]*storepb.AccessTokensUserSetting_AccessToken, error) {
+		newAccessTokens := make([]*storepb.AccessTokensUserSetting_AccessToken, 0, len(accessTokens))
+		found := false
+		for _, t := range accessTokens {
+			if token == t.AccessToken {
+				t = proto.Clone(t).(*storepb.AccessTokensUserSetting_AccessToken)
+				t.LastUsedTime = timestamppb.New(usedTime)
+				t.LastUsedIp = ip
+				found = true
+			}
 			newAccessTokens = append(newAccessTokens, t)
 		}
-	}
+		// The access token may have been revoked meanwhile, which must not bring it back.
+		if !found {
+			return nil, errors.Errorf("access token not found")
+		}
+		return newAccessTokens, nil
+	})
+}
+
+// updateUserAccessTokens replaces the access tokens of the user with the ones that update returns from the
+// current ones, unless it returns nil. The updates of a user are serialized, so that concurrent sign-ins,
+// revocations and usage records do not overwrite each other.
+func (s *Store) updateUserAccessTokens(ctx context.Context, userID int32, update func([]*storepb.AccessTokensUserSetting_AccessToken) ([]*storepb.AccessTokensUserSetting_AccessToken, error)) error {
+	mutex, _ := s.accessTokenMutexes.LoadOrStore(userID, &sync.Mutex{})
+	mutex.(*sync.Mutex).Lock()
+	defer mutex.(*sync.Mutex).Unlock()
 
+	accessTokens, err := s.GetUserAccessTokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+	newAccessTokens, err := update(accessTokens)
+	if err != nil || newAccessTokens == nil {
+		return err
+	}
 	_, err = s.UpsertUserSetting(ctx, &storepb.UserSetting{
 		UserId: userID,
 		Key:    storepb.UserSettingKey_ACCESS_TOKENS,
@@ -128,7 +243,6 @@ func (s *Store) RemoveUserAccessToken(ctx context.Context, userID int32, token s
 			},
 		},
 	})
-
 	return err
 }
 
@@ -151,6 +265,12 @@ func convertUserSettingFromRaw(raw *UserSetting) (*storepb.UserSetting, error) {
 			return nil, err
 		}
 		userSetting.Value = &storepb.UserSetting_Shortcuts{Shortcuts: shortcutsUserSetting}
+	case storepb.UserSettingKey_TWO_FACTOR_AUTH:
+		twoFactorAuthUserSetting := &storepb.TwoFactorAuthUserSetting{}
+		if err := protojsonUnmarshaler.Unmarshal([]byte(raw.Value), twoFactorAuthUserSetting); err != nil {
+			return nil, err
+		}
+		userSetting.Value = &storepb.UserSetting_TwoFactorAuth{TwoFactorAuth: twoFactorAuthUserSetting}
 	case storepb.UserSettingKey_LOCALE:
 		userSetting.Value = &storepb.UserSetting_Locale{Locale: raw.Value}
 	case storepb.UserSettingKey_APPEARANCE:
@@ -184,6 +304,13 @@ func convertUserSettingToRaw(userSetting *storepb.UserSetting) (*UserSetting, er
 			return nil, err
 		}
 		raw.Value = string(value)
+	case storepb.UserSettingKey_TWO_FACTOR_AUTH:
+		twoFactorAuthUserSetting := userSetting.GetTwoFactorAuth()
+		value, err := protojson.Marshal(twoFactorAuthUserSetting)
+		if err != nil {
+			return nil, err
+		}
+		raw.Value = string(value)
 	case storepb.UserSettingKey_LOCALE:
 		raw.Value = userSetting.GetLocale()
 	case storepb.UserSettingKey_APPEARANCE:
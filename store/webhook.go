@@ -2,6 +2,8 @@ package store
 
 import (
 	"context"
+
+	storepb "github.com/usememos/memos/proto/gen/store"
 )
 
 type Webhook struct {
@@ -11,6 +13,9 @@ type Webhook struct {
 	CreatorID int32
 	Name      string
 	URL       string
+	// Secret is the key used to sign the requests, empty if requests are not signed.
+	Secret  string
+	Payload *storepb.WebhookPayload
 }
 
 type FindWebhook struct {
@@ -19,9 +24,11 @@ type FindWebhook struct {
 }
 
 type UpdateWebhook struct {
-	ID   int32
-	Name *string
-	URL  *string
+	ID      int32
+	Name    *string
+	URL     *string
+	Secret  *string
+	Payload *storepb.WebhookPayload
 }
 
 type DeleteWebhook struct {
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+)
+
+// WebhookDeliveryStatus is the status of a webhook delivery.
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending is waiting for its next attempt.
+	WebhookDeliveryPending WebhookDeliveryStatus = "PENDING"
+	// WebhookDeliverySucceeded has been accepted by the endpoint.
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	// WebhookDeliveryFailed has run out of attempts.
+	WebhookDeliveryFailed WebhookDeliveryStatus = "FAILED"
+)
+
+func (s WebhookDeliveryStatus) String() string {
+	return string(s)
+}
+
+// WebhookDelivery is an event queued for a webhook, together with the outcome of its last attempt.
+type WebhookDelivery struct {
+	ID        int32
+	CreatedTs int64
+	UpdatedTs int64
+	WebhookID int32
+	// ActivityType is the event type, e.g. "memos.memo.created".
+	ActivityType string
+	// Payload is the JSON request body.
+	Payload       string
+	Status        WebhookDeliveryStatus
+	Attempts      int32
+	NextAttemptTs int64
+	// ResponseCode and ResponseBody are recorded from the last attempt that reached the endpoint.
+	ResponseCode int32
+	ResponseBody string
+	// Error is the error of the last attempt, empty if it succeeded.
+	Error string
+}
+
+type FindWebhookDelivery struct {
+	ID                  *int32
+	WebhookID           *int32
+	Status              *WebhookDeliveryStatus
+	NextAttemptTsBefore *int64
+	// OrderByIDAsc lists the deliveries in the order they were queued, instead of newest first.
+	OrderByIDAsc bool
+
+	// Pagination
+	Limit  *int
+	Offset *int
+}
+
+type UpdateWebhookDelivery struct {
+	ID            int32
+	Status        *WebhookDeliveryStatus
+	Attempts      *int32
+	NextAttemptTs *int64
+	ResponseCode  *int32
+	ResponseBody  *string
+	Error         *string
+}
+
+type DeleteWebhookDelivery struct {
+	WebhookID       *int32
+	CreatedTsBefore *int64
+}
+
+func (s *Store) CreateWebhookDelivery(ctx context.Context, create *WebhookDelivery) (*WebhookDelivery, error) {
+	return s.driver.CreateWebhookDelivery(ctx, create)
+}
+
+func (s *Store) ListWebhookDeliveries(ctx context.Context, find *FindWebhookDelivery) ([]*WebhookDelivery, error) {
+	return s.driver.ListWebhookDeliveries(ctx, find)
+}
+
+func (s *Store) GetWebhookDelivery(ctx context.Context, find *FindWebhookDelivery) (*WebhookDelivery, error) {
+	list, err := s.ListWebhookDeliveries(ctx, find)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+func (s *Store) UpdateWebhookDelivery(ctx context.Context, update *UpdateWebhookDelivery) (*WebhookDelivery, error) {
+	return s.driver.UpdateWebhookDelivery(ctx, update)
+}
+
+func (s *Store) DeleteWebhookDeliveries(ctx context.Context, delete *DeleteWebhookDelivery) error {
+	return s.driver.DeleteWebhookDeliveries(ctx, delete)
+}
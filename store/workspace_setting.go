@@ -37,6 +37,10 @@ func (s *Store) UpsertWorkspaceSetting(ctx context.Context, upsert *storepb.Work
 		valueBytes, err = protojson.Marshal(upsert.GetStorageSetting())
 	} else if upsert.Key == storepb.WorkspaceSettingKey_MEMO_RELATED {
 		valueBytes, err = protojson.Marshal(upsert.GetMemoRelatedSetting())
+	} else if upsert.Key == storepb.WorkspaceSettingKey_SCHEDULER {
+		valueBytes, err = protojson.Marshal(upsert.GetSchedulerSetting())
+	} else if upsert.Key == storepb.WorkspaceSettingKey_RATE_LIMIT {
+		valueBytes, err = protojson.Marshal(upsert.GetRateLimitSetting())
 	} else {
 		return nil, errors.Errorf("unsupported workspace setting key: %v", upsert.Key)
 	}
@@ -202,6 +206,60 @@ func (s *Store) GetWorkspaceStorageSetting(ctx context.Context) (*storepb.Worksp
 	return workspaceStorageSetting, nil
 }
 
+func (s *Store) GetWorkspaceSchedulerSetting(ctx context.Context) (*storepb.WorkspaceSchedulerSetting, error) {
+	workspaceSetting, err := s.GetWorkspaceSetting(ctx, &FindWorkspaceSetting{
+		Name: storepb.WorkspaceSettingKey_SCHEDULER.String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get workspace scheduler setting")
+	}
+
+	workspaceSchedulerSetting := &storepb.WorkspaceSchedulerSetting{}
+	if workspaceSetting != nil {
+		workspaceSchedulerSetting = workspaceSetting.GetSchedulerSetting()
+	}
+	if workspaceSchedulerSetting.JobSpecs == nil {
+		workspaceSchedulerSetting.JobSpecs = map[string]string{}
+	}
+	s.workspaceSettingCache.Store(storepb.WorkspaceSettingKey_SCHEDULER.String(), &storepb.WorkspaceSetting{
+		Key:   storepb.WorkspaceSettingKey_SCHEDULER,
+		Value: &storepb.WorkspaceSetting_SchedulerSetting{SchedulerSetting: workspaceSchedulerSetting},
+	})
+	return workspaceSchedulerSetting, nil
+}
+
+// GetWorkspaceRateLimitSetting returns the rate limit setting, with the defaults for the unset limits.
+func (s *Store) GetWorkspaceRateLimitSetting(ctx context.Context) (*storepb.WorkspaceRateLimitSetting, error) {
+	workspaceSetting, err := s.GetWorkspaceSetting(ctx, &FindWorkspaceSetting{
+		Name: storepb.WorkspaceSettingKey_RATE_LIMIT.String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get workspace rate limit setting")
+	}
+
+	workspaceRateLimitSetting := &storepb.WorkspaceRateLimitSetting{}
+	if workspaceSetting != nil {
+		workspaceRateLimitSetting = workspaceSetting.GetRateLimitSetting()
+	}
+	if workspaceRateLimitSetting.AuthLimit.GetRequestsPerMinute() <= 0 || workspaceRateLimitSetting.AuthLimit.GetBurst() <= 0 {
+		workspaceRateLimitSetting.AuthLimit = &storepb.WorkspaceRateLimit{RequestsPerMinute: 10, Burst: 10}
+	}
+	if workspaceRateLimitSetting.WriteLimit.GetRequestsPerMinute() <= 0 || workspaceRateLimitSetting.WriteLimit.GetBurst() <= 0 {
+		workspaceRateLimitSetting.WriteLimit = &storepb.WorkspaceRateLimit{RequestsPerMinute: 120, Burst: 60}
+	}
+	if workspaceRateLimitSetting.LockoutThreshold <= 0 {
+		workspaceRateLimitSetting.LockoutThreshold = 5
+	}
+	if workspaceRateLimitSetting.LockoutSeconds <= 0 {
+		workspaceRateLimitSetting.LockoutSeconds = 30
+	}
+	s.workspaceSettingCache.Store(storepb.WorkspaceSettingKey_RATE_LIMIT.String(), &storepb.WorkspaceSetting{
+		Key:   storepb.WorkspaceSettingKey_RATE_LIMIT,
+		Value: &storepb.WorkspaceSetting_RateLimitSetting{RateLimitSetting: workspaceRateLimitSetting},
+	})
+	return workspaceRateLimitSetting, nil
+}
+
 func convertWorkspaceSettingFromRaw(workspaceSettingRaw *WorkspaceSetting) (*storepb.WorkspaceSetting, error) {
 	workspaceSetting := &storepb.WorkspaceSetting{
 		Key: storepb.WorkspaceSettingKey(storepb.WorkspaceSettingKey_value[workspaceSettingRaw.Name]),
@@ -231,6 +289,18 @@ func convertWorkspaceSettingFromRaw(workspaceSettingRaw *WorkspaceSetting) (*sto
 			return nil, err
 		}
 		workspaceSetting.Value = &storepb.WorkspaceSetting_MemoRelatedSetting{MemoRelatedSetting: memoRelatedSetting}
+	case storepb.WorkspaceSettingKey_SCHEDULER.String():
+		schedulerSetting := &storepb.WorkspaceSchedulerSetting{}
+		if err := protojsonUnmarshaler.Unmarshal([]byte(workspaceSettingRaw.Value), schedulerSetting); err != nil {
+			return nil, err
+		}
+		workspaceSetting.Value = &storepb.WorkspaceSetting_SchedulerSetting{SchedulerSetting: schedulerSetting}
+	case storepb.WorkspaceSettingKey_RATE_LIMIT.String():
+		rateLimitSetting := &storepb.WorkspaceRateLimitSetting{}
+		if err := protojsonUnmarshaler.Unmarshal([]byte(workspaceSettingRaw.Value), rateLimitSetting); err != nil {
+			return nil, err
+		}
+		workspaceSetting.Value = &storepb.WorkspaceSetting_RateLimitSetting{RateLimitSetting: rateLimitSetting}
 	default:
 		// Skip unsupported workspace setting key.
 		return nil, nil
@@ -0,0 +1,140 @@
+package teststore
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/usememos/memos/plugin/archive"
+	apiv1 "github.com/usememos/memos/server/router/api/v1"
+	"github.com/usememos/memos/store"
+)
+
+func TestUserArchive(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	memo, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:        "archive-memo",
+		CreatorID:  user.ID,
+		Content:    "hello #archive",
+		Visibility: store.Public,
+	})
+	require.NoError(t, err)
+	comment, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:        "archive-comment",
+		CreatorID:  user.ID,
+		Content:    "comment",
+		Visibility: store.Public,
+	})
+	require.NoError(t, err)
+	_, err = ts.UpsertMemoRelation(ctx, &store.MemoRelation{
+		MemoID:        comment.ID,
+		RelatedMemoID: memo.ID,
+		Type:          store.MemoRelationComment,
+	})
+	require.NoError(t, err)
+	_, err = ts.CreateResource(ctx, &store.Resource{
+		UID:       "archive-resource",
+		CreatorID: user.ID,
+		Filename:  "test.txt",
+		Blob:      []byte("test"),
+		Type:      "text/plain",
+		Size:      4,
+		MemoID:    &memo.ID,
+	})
+	require.NoError(t, err)
+
+	userArchive, err := apiv1.ExportUserArchive(ctx, ts, user)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(userArchive.Memos))
+	require.Equal(t, 1, len(userArchive.Resources))
+	// The blobs are read while the archive is written.
+	require.Nil(t, userArchive.Resources[0].Blob)
+	require.Equal(t, "archive-memo", userArchive.Resources[0].Memo)
+	var buf bytes.Buffer
+	require.NoError(t, archive.Write(&buf, userArchive))
+	userArchive, err = archive.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()), archive.DefaultLimits)
+	require.NoError(t, err)
+	require.Equal(t, []byte("test"), userArchive.Resources[0].Blob)
+
+	// The user already has everything in the archive.
+	result, err := apiv1.ImportUserArchive(ctx, ts, user, userArchive)
+	require.NoError(t, err)
+	require.Equal(t, &apiv1.ArchiveImportResult{SkippedMemos: 2, SkippedResources: 1}, result)
+
+	// Another user gets copies with remapped UIDs.
+	other, err := ts.CreateUser(ctx, &store.User{
+		Username: "other",
+		Role:     store.RoleUser,
+		Email:    "other@test.com",
+	})
+	require.NoError(t, err)
+	result, err = apiv1.ImportUserArchive(ctx, ts, other, userArchive)
+	require.NoError(t, err)
+	require.Equal(t, &apiv1.ArchiveImportResult{ImportedMemos: 2, ImportedResources: 1}, result)
+	memos, err := ts.ListMemos(ctx, &store.FindMemo{CreatorID: &other.ID})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(memos))
+	importedMemos := map[string]*store.Memo{}
+	for _, m := range memos {
+		require.NotEqual(t, "archive-memo", m.UID)
+		require.NotEqual(t, "archive-comment", m.UID)
+		importedMemos[m.Content] = m
+	}
+	require.Equal(t, memo.CreatedTs, importedMemos["hello #archive"].CreatedTs)
+	require.Equal(t, []string{"archive"}, importedMemos["hello #archive"].Payload.Tags)
+	relations, err := ts.ListMemoRelations(ctx, &store.FindMemoRelation{MemoID: &importedMemos["comment"].ID})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(relations))
+	require.Equal(t, importedMemos["hello #archive"].ID, relations[0].RelatedMemoID)
+	resources, err := ts.ListResources(ctx, &store.FindResource{CreatorID: &other.ID, GetBlob: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(resources))
+	require.Equal(t, []byte("test"), resources[0].Blob)
+	require.Equal(t, importedMemos["hello #archive"].ID, *resources[0].MemoID)
+
+	// Importing again is a no-op.
+	result, err = apiv1.ImportUserArchive(ctx, ts, other, userArchive)
+	require.NoError(t, err)
+	require.Equal(t, &apiv1.ArchiveImportResult{SkippedMemos: 2, SkippedResources: 1}, result)
+	ts.Close()
+}
+
+func TestImportObsidianVault(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+
+	notes, err := archive.ReadObsidianVault(fstest.MapFS{
+		"Daily.md":           {Data: []byte("See [[Project]] ![[photo.png]]")},
+		"Project.md":         {Data: []byte("---\ntags: [work]\n---\nPlans")},
+		"assets/photo.png":   {Data: []byte("png")},
+		".obsidian/app.json": {Data: []byte("{}")},
+	})
+	require.NoError(t, err)
+	result, err := apiv1.ImportUserArchive(ctx, ts, user, notes)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.ImportedMemos)
+	require.Equal(t, 1, result.ImportedResources)
+
+	memos, err := ts.ListMemos(ctx, &store.FindMemo{CreatorID: &user.ID})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(memos))
+	referenceType := store.MemoRelationReference
+	relations, err := ts.ListMemoRelations(ctx, &store.FindMemoRelation{Type: &referenceType})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(relations))
+
+	// Importing the vault again skips the notes imported before.
+	result, err = apiv1.ImportUserArchive(ctx, ts, user, notes)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.ImportedMemos)
+	require.Equal(t, 2, result.SkippedMemos)
+	ts.Close()
+}
@@ -0,0 +1,89 @@
+package teststore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/usememos/memos/store"
+)
+
+func TestCustomRoleStore(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	_, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	member, err := ts.CreateUser(ctx, &store.User{
+		Username: "member",
+		Role:     store.RoleUser,
+		Email:    "member@test.com",
+	})
+	require.NoError(t, err)
+
+	role, err := ts.CreateCustomRole(ctx, &store.CustomRole{
+		Name:        "moderator",
+		Description: "Archives spam",
+		Permissions: []store.Permission{store.PermissionMemosManage},
+	})
+	require.NoError(t, err)
+	require.NotZero(t, role.ID)
+	require.NotZero(t, role.CreatedTs)
+	_, err = ts.CreateCustomRole(ctx, &store.CustomRole{Name: "moderator"})
+	require.Error(t, err)
+
+	permissions := []store.Permission{store.PermissionMemosManage, store.PermissionGroupsManage}
+	role, err = ts.UpdateCustomRole(ctx, &store.UpdateCustomRole{ID: role.ID, Permissions: permissions})
+	require.NoError(t, err)
+	require.Equal(t, "moderator", role.Name)
+	require.Equal(t, "Archives spam", role.Description)
+	require.Equal(t, permissions, role.Permissions)
+
+	hasPermission, err := ts.HasPermission(ctx, member, store.PermissionMemosManage)
+	require.NoError(t, err)
+	require.False(t, hasPermission)
+	_, err = ts.UpsertCustomRoleMember(ctx, &store.CustomRoleMember{RoleID: role.ID, UserID: member.ID})
+	require.NoError(t, err)
+	roles, err := ts.ListCustomRoles(ctx, &store.FindCustomRole{MemberID: &member.ID})
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	hasPermission, err = ts.HasPermission(ctx, member, store.PermissionMemosManage)
+	require.NoError(t, err)
+	require.True(t, hasPermission)
+	hasPermission, err = ts.HasPermission(ctx, member, store.PermissionWorkspaceManage)
+	require.NoError(t, err)
+	require.False(t, hasPermission)
+
+	// Deleting the role deletes its members.
+	require.NoError(t, ts.DeleteCustomRole(ctx, &store.DeleteCustomRole{ID: role.ID}))
+	members, err := ts.ListCustomRoleMembers(ctx, &store.FindCustomRoleMember{UserID: &member.ID})
+	require.NoError(t, err)
+	require.Len(t, members, 0)
+	hasPermission, err = ts.HasPermission(ctx, member, store.PermissionMemosManage)
+	require.NoError(t, err)
+	require.False(t, hasPermission)
+	ts.Close()
+}
+
+func TestBuiltinRolePermissions(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	host, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	admin, err := ts.CreateUser(ctx, &store.User{
+		Username: "admin",
+		Role:     store.RoleAdmin,
+		Email:    "admin@test.com",
+	})
+	require.NoError(t, err)
+
+	permissions, err := ts.ListUserPermissions(ctx, host)
+	require.NoError(t, err)
+	require.ElementsMatch(t, store.Permissions, permissions)
+	permissions, err = ts.ListUserPermissions(ctx, admin)
+	require.NoError(t, err)
+	require.NotContains(t, permissions, store.PermissionWorkspaceManage)
+	require.NotContains(t, permissions, store.PermissionRolesManage)
+	require.Contains(t, permissions, store.PermissionUsersManage)
+	ts.Close()
+}
@@ -58,3 +58,130 @@ func TestIdentityProviderStore(t *testing.T) {
 	require.Equal(t, 0, len(idpList))
 	ts.Close()
 }
+
+func TestOIDCIdentityProviderStore(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	oidcConfig := &storepb.OIDCConfig{
+		IssuerUrl:    "https://accounts.example.com",
+		ClientId:     "client_id",
+		ClientSecret: "client_secret",
+		Scopes:       []string{"profile", "email", "groups"},
+		FieldMapping: &storepb.FieldMapping{
+			Identifier: "email",
+		},
+		GroupsClaim: "roles",
+	}
+	createdIDP, err := ts.CreateIdentityProvider(ctx, &storepb.IdentityProvider{
+		Name: "Example OIDC",
+		Type: storepb.IdentityProvider_OIDC,
+		Config: &storepb.IdentityProviderConfig{
+			Config: &storepb.IdentityProviderConfig_OidcConfig{OidcConfig: oidcConfig},
+		},
+	})
+	require.NoError(t, err)
+	// List from the database rather than the cache.
+	idpList, err := ts.ListIdentityProviders(ctx, &store.FindIdentityProvider{ID: &createdIDP.Id})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(idpList))
+	require.Equal(t, oidcConfig.IssuerUrl, idpList[0].Config.GetOidcConfig().IssuerUrl)
+	require.Equal(t, oidcConfig.Scopes, idpList[0].Config.GetOidcConfig().Scopes)
+	require.Equal(t, oidcConfig.GroupsClaim, idpList[0].Config.GetOidcConfig().GroupsClaim)
+	require.Equal(t, "email", idpList[0].Config.GetOidcConfig().FieldMapping.Identifier)
+
+	oidcConfig.ClientSecret = "new_client_secret"
+	_, err = ts.UpdateIdentityProvider(ctx, &store.UpdateIdentityProviderV1{
+		ID:   createdIDP.Id,
+		Type: storepb.IdentityProvider_OIDC,
+		Config: &storepb.IdentityProviderConfig{
+			Config: &storepb.IdentityProviderConfig_OidcConfig{OidcConfig: oidcConfig},
+		},
+	})
+	require.NoError(t, err)
+	idpList, err = ts.ListIdentityProviders(ctx, &store.FindIdentityProvider{ID: &createdIDP.Id})
+	require.NoError(t, err)
+	require.Equal(t, "new_client_secret", idpList[0].Config.GetOidcConfig().ClientSecret)
+	ts.Close()
+}
+
+func TestLDAPIdentityProviderStore(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	ldapConfig := &storepb.LDAPConfig{
+		Url:          "ldap://ldap.example.com",
+		StartTls:     true,
+		BindDn:       "cn=memos,dc=example,dc=com",
+		BindPassword: "bind_password",
+		BaseDn:       "ou=people,dc=example,dc=com",
+		UserFilter:   "(uid={username})",
+	}
+	createdIDP, err := ts.CreateIdentityProvider(ctx, &storepb.IdentityProvider{
+		Name: "Example LDAP",
+		Type: storepb.IdentityProvider_LDAP,
+		Config: &storepb.IdentityProviderConfig{
+			Config: &storepb.IdentityProviderConfig_LdapConfig{LdapConfig: ldapConfig},
+		},
+	})
+	require.NoError(t, err)
+	// List from the database rather than the cache.
+	idpList, err := ts.ListIdentityProviders(ctx, &store.FindIdentityProvider{ID: &createdIDP.Id})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(idpList))
+	storedConfig := idpList[0].Config.GetLdapConfig()
+	require.Equal(t, ldapConfig.Url, storedConfig.Url)
+	require.True(t, storedConfig.StartTls)
+	require.Equal(t, ldapConfig.BindPassword, storedConfig.BindPassword)
+	require.Equal(t, ldapConfig.UserFilter, storedConfig.UserFilter)
+	ts.Close()
+}
+
+func TestIdentityProviderProvisioningStore(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	createdIDP, err := ts.CreateIdentityProvider(ctx, &storepb.IdentityProvider{
+		Name: "Example OIDC",
+		Type: storepb.IdentityProvider_OIDC,
+		Config: &storepb.IdentityProviderConfig{
+			Config: &storepb.IdentityProviderConfig_OidcConfig{
+				OidcConfig: &storepb.OIDCConfig{IssuerUrl: "https://accounts.example.com", ClientId: "client_id"},
+			},
+		},
+		Provisioning: &storepb.ProvisioningRules{
+			GroupRoleMappings: []*storepb.GroupRoleMapping{
+				{Group: "admins", Role: "ADMIN"},
+			},
+			RequiredGroups: []string{"staff"},
+			SyncOnSignIn:   true,
+		},
+	})
+	require.NoError(t, err)
+	// List from the database rather than the cache.
+	idpList, err := ts.ListIdentityProviders(ctx, &store.FindIdentityProvider{ID: &createdIDP.Id})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(idpList))
+	provisioning := idpList[0].Provisioning
+	require.Equal(t, 1, len(provisioning.GroupRoleMappings))
+	require.Equal(t, "ADMIN", provisioning.GroupRoleMappings[0].Role)
+	require.Equal(t, []string{"staff"}, provisioning.RequiredGroups)
+	require.True(t, provisioning.SyncOnSignIn)
+	require.False(t, provisioning.DisableAutoCreation)
+
+	_, err = ts.UpdateIdentityProvider(ctx, &store.UpdateIdentityProviderV1{
+		ID:   createdIDP.Id,
+		Type: storepb.IdentityProvider_OIDC,
+		Provisioning: &storepb.ProvisioningRules{
+			DisableAutoCreation: true,
+			LinkByEmail:         true,
+		},
+	})
+	require.NoError(t, err)
+	idpList, err = ts.ListIdentityProviders(ctx, &store.FindIdentityProvider{ID: &createdIDP.Id})
+	require.NoError(t, err)
+	provisioning = idpList[0].Provisioning
+	require.Equal(t, 0, len(provisioning.GroupRoleMappings))
+	require.True(t, provisioning.DisableAutoCreation)
+	require.True(t, provisioning.LinkByEmail)
+	// The config is left unchanged.
+	require.Equal(t, "https://accounts.example.com", idpList[0].Config.GetOidcConfig().IssuerUrl)
+	ts.Close()
+}
@@ -52,3 +52,42 @@ func TestInboxStore(t *testing.T) {
 	require.Equal(t, 0, len(inboxes))
 	ts.Close()
 }
+
+func TestInboxListCursor(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := ts.CreateInbox(ctx, &store.Inbox{
+			ReceiverID: user.ID,
+			Status:     store.UNREAD,
+			Message: &storepb.InboxMessage{
+				Type: storepb.InboxMessage_MEMO_COMMENT,
+			},
+		})
+		require.NoError(t, err)
+	}
+	inboxes, err := ts.ListInboxes(ctx, &store.FindInbox{ReceiverID: &user.ID})
+	require.NoError(t, err)
+	require.Equal(t, 5, len(inboxes))
+
+	limit := 2
+	find := &store.FindInbox{
+		ReceiverID: &user.ID,
+		Limit:      &limit,
+	}
+	paged := []*store.Inbox{}
+	for {
+		page, err := ts.ListInboxes(ctx, find)
+		require.NoError(t, err)
+		paged = append(paged, page...)
+		if len(page) < limit {
+			break
+		}
+		last := page[len(page)-1]
+		find.Cursor = &store.InboxCursor{CreatedTs: last.CreatedTs, ID: last.ID}
+	}
+	require.Equal(t, inboxes, paged)
+	ts.Close()
+}
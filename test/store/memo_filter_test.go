@@ -0,0 +1,188 @@
+package teststore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	storepb "github.com/usememos/memos/proto/gen/store"
+	"github.com/usememos/memos/store"
+)
+
+// TestMemoFilter runs the same filters against the configured driver, so running it with every
+// DRIVER checks that all drivers return identical memos for a filter.
+func TestMemoFilter(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	other, err := ts.CreateUser(ctx, &store.User{
+		Username: "other",
+		Role:     store.RoleUser,
+		Email:    "other@test.com",
+	})
+	require.NoError(t, err)
+
+	archived := store.Archived
+	pinned := true
+	memoIDs := map[string]int32{}
+	for _, memo := range []struct {
+		memo      *store.Memo
+		createdTs int64
+		update    *store.UpdateMemo
+	}{
+		{
+			memo: &store.Memo{
+				UID:        "release",
+				CreatorID:  user.ID,
+				Content:    "Plan the Release\n- [ ] write notes #work/project",
+				Visibility: store.Public,
+				Payload: &storepb.MemoPayload{
+					Tags:     []string{"work/project"},
+					Property: &storepb.MemoPayload_Property{HasTaskList: true, HasIncompleteTasks: true},
+					Location: &storepb.MemoPayload_Location{Placeholder: "Paris", Latitude: 48.85, Longitude: 2.35},
+				},
+			},
+			createdTs: 1700000000,
+			update:    &store.UpdateMemo{Pinned: &pinned},
+		},
+		{
+			memo: &store.Memo{
+				UID:        "reading",
+				CreatorID:  user.ID,
+				Content:    "Read https://usememos.com #reading",
+				Visibility: store.Private,
+				Payload: &storepb.MemoPayload{
+					Tags:     []string{"reading"},
+					Property: &storepb.MemoPayload_Property{HasLink: true},
+				},
+			},
+			createdTs: 1700100000,
+		},
+		{
+			memo: &store.Memo{
+				UID:        "snippet",
+				CreatorID:  other.ID,
+				Content:    "```go\nfmt.Println(\"hello\")\n``` #work",
+				Visibility: store.Protected,
+				Payload: &storepb.MemoPayload{
+					Tags:     []string{"work", "workshop"},
+					Property: &storepb.MemoPayload_Property{HasCode: true},
+					Location: &storepb.MemoPayload_Location{Placeholder: "Berlin", Latitude: 52.52, Longitude: 13.4},
+				},
+			},
+			createdTs: 1700200000,
+		},
+		{
+			memo: &store.Memo{
+				UID:        "old",
+				CreatorID:  other.ID,
+				Content:    "An old note",
+				Visibility: store.Public,
+				Payload:    &storepb.MemoPayload{},
+			},
+			createdTs: 1690000000,
+			update:    &store.UpdateMemo{RowStatus: &archived},
+		},
+	} {
+		created, err := ts.CreateMemo(ctx, memo.memo)
+		require.NoError(t, err)
+		update := memo.update
+		if update == nil {
+			update = &store.UpdateMemo{}
+		}
+		update.ID = created.ID
+		update.CreatedTs = &memo.createdTs
+		require.NoError(t, ts.UpdateMemo(ctx, update))
+		memoIDs[created.UID] = created.ID
+	}
+
+	// The release has two comments, the snippet has two reactions.
+	for _, uid := range []string{"comment-1", "comment-2"} {
+		comment, err := ts.CreateMemo(ctx, &store.Memo{
+			UID:        uid,
+			CreatorID:  other.ID,
+			Content:    "Looks good",
+			Visibility: store.Public,
+		})
+		require.NoError(t, err)
+		_, err = ts.UpsertMemoRelation(ctx, &store.MemoRelation{
+			MemoID:        comment.ID,
+			RelatedMemoID: memoIDs["release"],
+			Type:          store.MemoRelationComment,
+		})
+		require.NoError(t, err)
+	}
+	for _, reactionType := range []string{"👍", "🎉"} {
+		_, err := ts.UpsertReaction(ctx, &store.Reaction{
+			CreatorID:    user.ID,
+			ContentID:    "memos/snippet",
+			ReactionType: reactionType,
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		// Content.
+		{filter: `content.contains("release")`, want: []string{"release"}},
+		{filter: `content.contains("RELEASE") || content.contains("fmt.")`, want: []string{"release", "snippet"}},
+		{filter: `content.startsWith("an old")`, want: []string{"old"}},
+		{filter: `content == "An old note"`, want: []string{"old"}},
+		// Creator, visibility and status.
+		{filter: `creator == "users/2"`, want: []string{"old", "snippet"}},
+		{filter: `creator != "users/2"`, want: []string{"release", "reading"}},
+		{filter: `visibility in ["PUBLIC", "PROTECTED"]`, want: []string{"old", "release", "snippet"}},
+		{filter: `visibility != "PUBLIC"`, want: []string{"reading", "snippet"}},
+		{filter: `row_status == "ARCHIVED"`, want: []string{"old"}},
+		{filter: `pinned`, want: []string{"release"}},
+		{filter: `!pinned && row_status == "NORMAL"`, want: []string{"reading", "snippet"}},
+		// Time.
+		{filter: `create_time >= 1700100000`, want: []string{"reading", "snippet"}},
+		{filter: `create_time < "2023-11-14T22:13:21Z"`, want: []string{"old", "release"}},
+		{filter: `create_time > 1695000000 && create_time < 1700150000`, want: []string{"release", "reading"}},
+		// Tags.
+		{filter: `tag in ["work"]`, want: []string{"snippet"}},
+		{filter: `tag == "reading"`, want: []string{"reading"}},
+		{filter: `tag.startsWith("work")`, want: []string{"release", "snippet"}},
+		{filter: `tag in ["work"] || tag.startsWith("work/")`, want: []string{"release", "snippet"}},
+		{filter: `!(tag in ["reading"])`, want: []string{"old", "release", "snippet"}},
+		{filter: `tag in []`, want: []string{}},
+		{filter: `tag.within("work")`, want: []string{"release", "snippet"}},
+		{filter: `tag.within("work/project")`, want: []string{"release"}},
+		{filter: `tag.within("wor")`, want: []string{}},
+		{filter: `tag.within("workshop") && !tag.within("work/project")`, want: []string{"snippet"}},
+		// Properties.
+		{filter: `has_link`, want: []string{"reading"}},
+		{filter: `has_task_list && has_incomplete_tasks`, want: []string{"release"}},
+		{filter: `has_code == true`, want: []string{"snippet"}},
+		{filter: `!has_link && !has_code`, want: []string{"old", "release"}},
+		// Location.
+		{filter: `location.placeholder == "Paris"`, want: []string{"release"}},
+		{filter: `location.placeholder.contains("ber")`, want: []string{"snippet"}},
+		{filter: `location.latitude > 50`, want: []string{"snippet"}},
+		{filter: `location.longitude >= 2 && location.longitude < 10.5`, want: []string{"release"}},
+		// Counts.
+		{filter: `comment_count >= 2`, want: []string{"release"}},
+		{filter: `reaction_count > 0 || comment_count > 0`, want: []string{"release", "snippet"}},
+		{filter: `reaction_count == 0 && comment_count == 0`, want: []string{"old", "reading"}},
+	}
+	for _, tt := range tests {
+		memoFilter := tt.filter
+		memos, err := ts.ListMemos(ctx, &store.FindMemo{
+			Filter:          &memoFilter,
+			ExcludeComments: true,
+			OrderByTimeAsc:  true,
+		})
+		require.NoError(t, err, tt.filter)
+		uids := []string{}
+		for _, memo := range memos {
+			uids = append(uids, memo.UID)
+		}
+		require.Equal(t, tt.want, uids, tt.filter)
+	}
+	ts.Close()
+}
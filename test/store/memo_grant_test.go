@@ -0,0 +1,135 @@
+package teststore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/usememos/memos/store"
+)
+
+func TestMemoGrantStore(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	reader, err := ts.CreateUser(ctx, &store.User{
+		Username: "reader",
+		Role:     store.RoleUser,
+		Email:    "reader@test.com",
+	})
+	require.NoError(t, err)
+	memo, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:        "granted",
+		CreatorID:  user.ID,
+		Content:    "test_content",
+		Visibility: store.Private,
+	})
+	require.NoError(t, err)
+
+	// Upserting a grant to the same principal updates its permission.
+	for _, permission := range []store.MemoPermission{store.MemoPermissionWrite, store.MemoPermissionRead} {
+		_, err = ts.UpsertMemoGrant(ctx, &store.MemoGrant{
+			MemoID:        memo.ID,
+			PrincipalType: store.MemoGrantPrincipalUser,
+			PrincipalID:   reader.ID,
+			Permission:    permission,
+		})
+		require.NoError(t, err)
+	}
+	grants, err := ts.ListMemoGrants(ctx, &store.FindMemoGrant{MemoID: &memo.ID})
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	require.Equal(t, store.MemoPermissionRead, grants[0].Permission)
+	permission, err := ts.GetMemoPermission(ctx, memo.ID, reader.ID)
+	require.NoError(t, err)
+	require.Equal(t, store.MemoPermissionRead, permission)
+
+	// A write grant to a group of the user wins over the read grant to the user.
+	group, err := ts.CreateUserGroup(ctx, &store.UserGroup{Name: "editors", CreatorID: user.ID})
+	require.NoError(t, err)
+	_, err = ts.UpsertUserGroupMember(ctx, &store.UserGroupMember{GroupID: group.ID, UserID: reader.ID})
+	require.NoError(t, err)
+	_, err = ts.UpsertMemoGrant(ctx, &store.MemoGrant{
+		MemoID:        memo.ID,
+		PrincipalType: store.MemoGrantPrincipalGroup,
+		PrincipalID:   group.ID,
+		Permission:    store.MemoPermissionWrite,
+	})
+	require.NoError(t, err)
+	permission, err = ts.GetMemoPermission(ctx, memo.ID, reader.ID)
+	require.NoError(t, err)
+	require.Equal(t, store.MemoPermissionWrite, permission)
+	permission, err = ts.GetMemoPermission(ctx, memo.ID, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, store.MemoPermission(""), permission)
+
+	// Deleting the memo deletes its grants.
+	require.NoError(t, ts.DeleteMemo(ctx, &store.DeleteMemo{ID: memo.ID}))
+	grants, err = ts.ListMemoGrants(ctx, &store.FindMemoGrant{MemoID: &memo.ID})
+	require.NoError(t, err)
+	require.Len(t, grants, 0)
+	ts.Close()
+}
+
+func TestMemoListWithGrants(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	reader, err := ts.CreateUser(ctx, &store.User{
+		Username: "reader",
+		Role:     store.RoleUser,
+		Email:    "reader@test.com",
+	})
+	require.NoError(t, err)
+	group, err := ts.CreateUserGroup(ctx, &store.UserGroup{Name: "readers", CreatorID: user.ID})
+	require.NoError(t, err)
+	_, err = ts.UpsertUserGroupMember(ctx, &store.UserGroupMember{GroupID: group.ID, UserID: reader.ID})
+	require.NoError(t, err)
+
+	memoIDs := map[string]int32{}
+	for _, uid := range []string{"public", "private", "user-grant", "group-grant"} {
+		visibility := store.Private
+		if uid == "public" {
+			visibility = store.Public
+		}
+		memo, err := ts.CreateMemo(ctx, &store.Memo{UID: uid, CreatorID: user.ID, Content: uid, Visibility: visibility})
+		require.NoError(t, err)
+		memoIDs[uid] = memo.ID
+	}
+	_, err = ts.UpsertMemoGrant(ctx, &store.MemoGrant{
+		MemoID:        memoIDs["user-grant"],
+		PrincipalType: store.MemoGrantPrincipalUser,
+		PrincipalID:   reader.ID,
+		Permission:    store.MemoPermissionRead,
+	})
+	require.NoError(t, err)
+	_, err = ts.UpsertMemoGrant(ctx, &store.MemoGrant{
+		MemoID:        memoIDs["group-grant"],
+		PrincipalType: store.MemoGrantPrincipalGroup,
+		PrincipalID:   group.ID,
+		Permission:    store.MemoPermissionWrite,
+	})
+	require.NoError(t, err)
+
+	listUIDs := func(find *store.FindMemo) []string {
+		find.OrderByTimeAsc = true
+		memos, err := ts.ListMemos(ctx, find)
+		require.NoError(t, err)
+		uids := []string{}
+		for _, memo := range memos {
+			uids = append(uids, memo.UID)
+		}
+		return uids
+	}
+	visibilities := []store.Visibility{store.Public, store.Protected}
+	require.ElementsMatch(t, []string{"public"}, listUIDs(&store.FindMemo{VisibilityList: visibilities}))
+	require.ElementsMatch(t, []string{"public", "user-grant", "group-grant"}, listUIDs(&store.FindMemo{VisibilityList: visibilities, GrantedUserID: &reader.ID}))
+	// Grants are combined with the other conditions.
+	content := []string{"group"}
+	require.ElementsMatch(t, []string{"group-grant"}, listUIDs(&store.FindMemo{VisibilityList: visibilities, GrantedUserID: &reader.ID, ContentSearch: content}))
+	require.ElementsMatch(t, []string{"public"}, listUIDs(&store.FindMemo{VisibilityList: visibilities, GrantedUserID: &user.ID}))
+	ts.Close()
+}
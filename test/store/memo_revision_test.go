@@ -0,0 +1,91 @@
+package teststore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/usememos/memos/store"
+)
+
+func TestMemoRevisionStore(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	memo, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:        "test-memo",
+		CreatorID:  user.ID,
+		Content:    "first",
+		Visibility: store.Private,
+	})
+	require.NoError(t, err)
+	revisions, err := ts.ListMemoRevisions(ctx, &store.FindMemoRevision{MemoID: &memo.ID})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(revisions))
+	require.Equal(t, "first", revisions[0].Content)
+	require.Equal(t, store.Private, revisions[0].Visibility)
+
+	content, visibility, pinned := "second", store.Public, true
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: memo.ID, Content: &content}))
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: memo.ID, Visibility: &visibility}))
+	// Updates that change neither the content nor the visibility are not recorded.
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: memo.ID, Content: &content}))
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: memo.ID, Pinned: &pinned}))
+	revisions, err = ts.ListMemoRevisions(ctx, &store.FindMemoRevision{MemoID: &memo.ID})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(revisions))
+	require.Equal(t, "second", revisions[0].Content)
+	require.Equal(t, store.Public, revisions[0].Visibility)
+	require.Equal(t, "second", revisions[1].Content)
+	require.Equal(t, store.Private, revisions[1].Visibility)
+	require.Equal(t, "first", revisions[2].Content)
+
+	revision, err := ts.GetMemoRevision(ctx, &store.FindMemoRevision{ID: &revisions[2].ID})
+	require.NoError(t, err)
+	require.Equal(t, revisions[2], revision)
+	limit := 1
+	revisions, err = ts.ListMemoRevisions(ctx, &store.FindMemoRevision{MemoID: &memo.ID, Limit: &limit})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(revisions))
+
+	require.NoError(t, ts.DeleteMemo(ctx, &store.DeleteMemo{ID: memo.ID}))
+	revisions, err = ts.ListMemoRevisions(ctx, &store.FindMemoRevision{MemoID: &memo.ID})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(revisions))
+	ts.Close()
+}
+
+func TestMemoRevisionEditor(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	editor, err := ts.CreateUser(ctx, &store.User{
+		Username: "editor",
+		Role:     store.RoleUser,
+		Email:    "editor@test.com",
+	})
+	require.NoError(t, err)
+	memo, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:        "test-memo",
+		CreatorID:  user.ID,
+		Content:    "first",
+		Visibility: store.Private,
+	})
+	require.NoError(t, err)
+
+	content := "second"
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: memo.ID, Content: &content, EditorID: &editor.ID}))
+	visibility := store.Public
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: memo.ID, Visibility: &visibility}))
+	revisions, err := ts.ListMemoRevisions(ctx, &store.FindMemoRevision{MemoID: &memo.ID})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(revisions))
+	// The editor defaults to the creator of the memo.
+	require.Equal(t, user.ID, revisions[0].EditorID)
+	require.Equal(t, editor.ID, revisions[1].EditorID)
+	require.Equal(t, user.ID, revisions[2].EditorID)
+	ts.Close()
+}
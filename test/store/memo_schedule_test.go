@@ -0,0 +1,181 @@
+package teststore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	storepb "github.com/usememos/memos/proto/gen/store"
+	"github.com/usememos/memos/server/runner/memoschedule"
+	"github.com/usememos/memos/server/scheduler"
+	"github.com/usememos/memos/store"
+)
+
+func TestScheduledMemoStore(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	_, err = ts.CreateMemo(ctx, &store.Memo{
+		UID:        "published-memo",
+		CreatorID:  user.ID,
+		Content:    "published",
+		Visibility: store.Public,
+	})
+	require.NoError(t, err)
+	scheduledTs := time.Now().Add(time.Hour).Unix()
+	scheduled, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:         "scheduled-memo",
+		CreatorID:   user.ID,
+		Content:     "scheduled",
+		Visibility:  store.Public,
+		ScheduledTs: scheduledTs,
+	})
+	require.NoError(t, err)
+	require.Equal(t, scheduledTs, scheduled.ScheduledTs)
+
+	memoList, err := ts.ListMemos(ctx, &store.FindMemo{})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(memoList))
+	memoList, err = ts.ListMemos(ctx, &store.FindMemo{
+		ExcludeScheduled: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(memoList))
+	require.Equal(t, "published-memo", memoList[0].UID)
+
+	now := time.Now().Unix()
+	memoList, err = ts.ListMemos(ctx, &store.FindMemo{
+		ScheduledTsBefore: &now,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(memoList))
+	memoList, err = ts.ListMemos(ctx, &store.FindMemo{
+		ScheduledTsBefore: &scheduledTs,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(memoList))
+	require.Equal(t, scheduled.ID, memoList[0].ID)
+	ts.Close()
+}
+
+func TestMemoScheduleRunner(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	dueTs := time.Now().Add(-time.Minute).Unix()
+	due, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:         "due-memo",
+		CreatorID:   user.ID,
+		Content:     "due",
+		Visibility:  store.Public,
+		ScheduledTs: dueTs,
+	})
+	require.NoError(t, err)
+	_, err = ts.CreateMemo(ctx, &store.Memo{
+		UID:         "future-memo",
+		CreatorID:   user.ID,
+		Content:     "future",
+		Visibility:  store.Public,
+		ScheduledTs: time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	published := []*store.Memo{}
+	runner := memoschedule.NewRunner(ts, func(_ context.Context, memo *store.Memo) {
+		published = append(published, memo)
+	})
+	runner.RunOnce(ctx)
+	require.Equal(t, 1, len(published))
+	require.Equal(t, due.ID, published[0].ID)
+
+	memo, err := ts.GetMemo(ctx, &store.FindMemo{ID: &due.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), memo.ScheduledTs)
+	require.Equal(t, dueTs, memo.CreatedTs)
+	require.Equal(t, dueTs, memo.UpdatedTs)
+	memoList, err := ts.ListMemos(ctx, &store.FindMemo{
+		ExcludeScheduled: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(memoList))
+
+	// Published memos are not published again.
+	runner.RunOnce(ctx)
+	require.Equal(t, 1, len(published))
+
+	// Archived memos are not published.
+	archived := store.Archived
+	dueArchived, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:         "due-archived-memo",
+		CreatorID:   user.ID,
+		Content:     "archived",
+		Visibility:  store.Public,
+		ScheduledTs: dueTs,
+	})
+	require.NoError(t, err)
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: dueArchived.ID, RowStatus: &archived}))
+	runner.RunOnce(ctx)
+	require.Equal(t, 1, len(published))
+
+	// Memos rescheduled after they were read are not published at the old time.
+	rescheduled, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:         "rescheduled-memo",
+		CreatorID:   user.ID,
+		Content:     "rescheduled",
+		Visibility:  store.Public,
+		ScheduledTs: dueTs,
+	})
+	require.NoError(t, err)
+	rescheduled, err = ts.GetMemo(ctx, &store.FindMemo{ID: &rescheduled.ID})
+	require.NoError(t, err)
+	laterTs := time.Now().Add(time.Hour).Unix()
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: rescheduled.ID, ScheduledTs: &laterTs}))
+	require.NoError(t, runner.Publish(ctx, rescheduled))
+	require.Equal(t, 1, len(published))
+	memo, err = ts.GetMemo(ctx, &store.FindMemo{ID: &rescheduled.ID})
+	require.NoError(t, err)
+	require.Equal(t, laterTs, memo.ScheduledTs)
+	ts.Close()
+}
+
+func TestSchedulerSync(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	s := scheduler.NewScheduler(ts)
+	s.Register(&scheduler.Job{
+		Name:        "first",
+		DefaultSpec: "@every 10s",
+		Run:         func(context.Context) {},
+	})
+	s.Register(&scheduler.Job{
+		Name:        "second",
+		DefaultSpec: "0 */12 * * *",
+		Run:         func(context.Context) {},
+	})
+	s.Sync(ctx)
+	require.Equal(t, "@every 10s", s.Spec("first"))
+	require.Equal(t, "0 */12 * * *", s.Spec("second"))
+	require.Equal(t, "", s.Spec("unknown"))
+
+	_, err := ts.UpsertWorkspaceSetting(ctx, &storepb.WorkspaceSetting{
+		Key: storepb.WorkspaceSettingKey_SCHEDULER,
+		Value: &storepb.WorkspaceSetting_SchedulerSetting{
+			SchedulerSetting: &storepb.WorkspaceSchedulerSetting{
+				JobSpecs: map[string]string{
+					"first":  "*/5 * * * *",
+					"second": "invalid spec",
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	s.Sync(ctx)
+	require.Equal(t, "*/5 * * * *", s.Spec("first"))
+	// Jobs with an invalid spec keep their previous schedule.
+	require.Equal(t, "0 */12 * * *", s.Spec("second"))
+	ts.Close()
+}
@@ -0,0 +1,58 @@
+package teststore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/usememos/memos/store"
+)
+
+func TestMemoShareStore(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	memo, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:        "shared",
+		CreatorID:  user.ID,
+		Content:    "test_content",
+		Visibility: store.Private,
+	})
+	require.NoError(t, err)
+
+	share, err := ts.CreateMemoShare(ctx, &store.MemoShare{
+		Token:     "test_token",
+		MemoID:    memo.ID,
+		CreatorID: user.ID,
+		ExpiresTs: 1700000000,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, share.ID)
+	require.NotZero(t, share.CreatedTs)
+	require.Equal(t, int64(1700000000), share.ExpiresTs)
+	require.Equal(t, int32(0), share.ViewCount)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, ts.UpdateMemoShare(ctx, &store.UpdateMemoShare{ID: share.ID, IncreaseViewCount: true}))
+	}
+	token := "test_token"
+	share, err = ts.GetMemoShare(ctx, &store.FindMemoShare{Token: &token})
+	require.NoError(t, err)
+	require.Equal(t, memo.ID, share.MemoID)
+	require.Equal(t, int32(2), share.ViewCount)
+
+	// Deleting the memo deletes its shares.
+	_, err = ts.CreateMemoShare(ctx, &store.MemoShare{Token: "other_token", MemoID: memo.ID, CreatorID: user.ID})
+	require.NoError(t, err)
+	shares, err := ts.ListMemoShares(ctx, &store.FindMemoShare{MemoID: &memo.ID})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(shares))
+	require.Equal(t, "other_token", shares[0].Token)
+	require.NoError(t, ts.DeleteMemo(ctx, &store.DeleteMemo{ID: memo.ID}))
+	shares, err = ts.ListMemoShares(ctx, &store.FindMemoShare{MemoID: &memo.ID})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(shares))
+	ts.Close()
+}
@@ -0,0 +1,65 @@
+package teststore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	storepb "github.com/usememos/memos/proto/gen/store"
+	"github.com/usememos/memos/store"
+)
+
+func TestMemoTagStore(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	memo, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:        "tagged",
+		CreatorID:  user.ID,
+		Content:    "#work/project #work/project #say\"hi\"",
+		Visibility: store.Public,
+		Payload: &storepb.MemoPayload{
+			Tags: []string{"work/project", "work/project", `say"hi"`},
+		},
+	})
+	require.NoError(t, err)
+	listTags := func() []string {
+		memoTags, err := ts.ListMemoTags(ctx, &store.FindMemoTag{MemoID: &memo.ID})
+		require.NoError(t, err)
+		tags := []string{}
+		for _, memoTag := range memoTags {
+			tags = append(tags, memoTag.Tag)
+		}
+		return tags
+	}
+	require.Equal(t, []string{`say"hi"`, "work/project"}, listTags())
+
+	// Tags with quotes are matched exactly.
+	memoFilter := `tag in ["say\"hi\""]`
+	memos, err := ts.ListMemos(ctx, &store.FindMemo{Filter: &memoFilter})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(memos))
+
+	// Updating the payload replaces the tags, other updates keep them.
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{
+		ID:      memo.ID,
+		Payload: &storepb.MemoPayload{Tags: []string{"reading"}},
+	}))
+	require.Equal(t, []string{"reading"}, listTags())
+	pinned := true
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: memo.ID, Pinned: &pinned}))
+	require.Equal(t, []string{"reading"}, listTags())
+
+	// Tags differing only in case are different tags.
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{
+		ID:      memo.ID,
+		Payload: &storepb.MemoPayload{Tags: []string{"Go", "go"}},
+	}))
+	require.ElementsMatch(t, []string{"Go", "go"}, listTags())
+
+	require.NoError(t, ts.DeleteMemo(ctx, &store.DeleteMemo{ID: memo.ID}))
+	require.Equal(t, []string{}, listTags())
+	ts.Close()
+}
@@ -2,6 +2,7 @@ package teststore
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -62,6 +63,44 @@ func TestMemoStore(t *testing.T) {
 	ts.Close()
 }
 
+func TestMemoUpdateCurrent(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	memo, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:        "current",
+		CreatorID:  user.ID,
+		Content:    "first",
+		Visibility: store.Private,
+	})
+	require.NoError(t, err)
+	memo, err = ts.GetMemo(ctx, &store.FindMemo{ID: &memo.ID})
+	require.NoError(t, err)
+
+	// Both updates read the same memo, only the first one applies.
+	for i, content := range []string{"second", "third"} {
+		err := ts.UpdateMemo(ctx, &store.UpdateMemo{ID: memo.ID, Content: &content, Current: memo})
+		if i == 0 {
+			require.NoError(t, err)
+		} else {
+			require.ErrorIs(t, err, store.ErrMemoModified)
+		}
+	}
+	updated, err := ts.GetMemo(ctx, &store.FindMemo{ID: &memo.ID})
+	require.NoError(t, err)
+	require.Equal(t, "second", updated.Content)
+
+	// Updates that set nothing still check the memo.
+	require.ErrorIs(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: memo.ID, Current: memo}), store.ErrMemoModified)
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: memo.ID, Current: updated}))
+
+	// Updates that set the same values still apply.
+	pinned := false
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: memo.ID, Pinned: &pinned, Current: updated}))
+	ts.Close()
+}
+
 func TestMemoListByTags(t *testing.T) {
 	ctx := context.Background()
 	ts := NewTestingStore(ctx, t)
@@ -93,6 +132,33 @@ func TestMemoListByTags(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1, len(memoList))
 	require.Equal(t, memo, memoList[0])
+
+	// The tag search matches the descendants of the tag, but not other tags with the same prefix.
+	_, err = ts.CreateMemo(ctx, &store.Memo{
+		UID:        "test-nested-tag",
+		CreatorID:  user.ID,
+		Content:    "test_content",
+		Visibility: store.Public,
+		Payload: &storepb.MemoPayload{
+			Tags: []string{"test_tag/nested", "test_tags"},
+		},
+	})
+	require.NoError(t, err)
+	memoList, err = ts.ListMemos(ctx, &store.FindMemo{
+		PayloadFind: &store.FindMemoPayload{
+			TagSearch: []string{"test_tag"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(memoList))
+	memoList, err = ts.ListMemos(ctx, &store.FindMemo{
+		PayloadFind: &store.FindMemoPayload{
+			TagSearch: []string{"test_tag/nested"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(memoList))
+	require.Equal(t, "test-nested-tag", memoList[0].UID)
 	ts.Close()
 }
 
@@ -116,3 +182,246 @@ func TestDeleteMemoStore(t *testing.T) {
 	require.NoError(t, err)
 	ts.Close()
 }
+
+func TestMemoFullTextSearch(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	for uid, content := range map[string]string{
+		"memo-apple":  "An apple a day keeps the doctor away",
+		"memo-apples": "Apples and apples and more apples",
+		"memo-banana": "Banana bread recipe",
+	} {
+		_, err := ts.CreateMemo(ctx, &store.Memo{
+			UID:        uid,
+			CreatorID:  user.ID,
+			Content:    content,
+			Visibility: store.Public,
+		})
+		require.NoError(t, err)
+	}
+
+	query := "apple"
+	memoList, err := ts.ListMemos(ctx, &store.FindMemo{
+		FullTextSearch: &query,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(memoList))
+	require.Equal(t, "memo-apples", memoList[0].UID)
+	require.Greater(t, memoList[0].SearchScore, memoList[1].SearchScore)
+
+	// The index follows content updates.
+	bananaUID := "memo-banana"
+	banana, err := ts.GetMemo(ctx, &store.FindMemo{UID: &bananaUID})
+	require.NoError(t, err)
+	content := "Banana and apple smoothie"
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: banana.ID, Content: &content}))
+	query = "banana smoo"
+	memoList, err = ts.ListMemos(ctx, &store.FindMemo{
+		FullTextSearch: &query,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(memoList))
+	require.Equal(t, banana.ID, memoList[0].ID)
+
+	// The index follows deletions.
+	require.NoError(t, ts.DeleteMemo(ctx, &store.DeleteMemo{ID: banana.ID}))
+	memoList, err = ts.ListMemos(ctx, &store.FindMemo{
+		FullTextSearch: &query,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(memoList))
+
+	// Queries without any terms match nothing.
+	query = "  ?! "
+	memoList, err = ts.ListMemos(ctx, &store.FindMemo{
+		FullTextSearch: &query,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(memoList))
+	ts.Close()
+}
+
+func TestMemoListCursor(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	createdTsList := []int64{100, 200, 200, 300, 300, 300, 400}
+	for i, createdTs := range createdTsList {
+		memo, err := ts.CreateMemo(ctx, &store.Memo{
+			UID:        fmt.Sprintf("cursor-%d", i),
+			CreatorID:  user.ID,
+			Content:    "test",
+			Visibility: store.Public,
+		})
+		require.NoError(t, err)
+		updatedTs := createdTs + int64(len(createdTsList)-i)%3
+		pinned := i%3 == 0
+		err = ts.UpdateMemo(ctx, &store.UpdateMemo{
+			ID:        memo.ID,
+			CreatedTs: &createdTs,
+			UpdatedTs: &updatedTs,
+			Pinned:    &pinned,
+		})
+		require.NoError(t, err)
+	}
+
+	for _, orderByPinned := range []bool{false, true} {
+		for _, orderByUpdatedTs := range []bool{false, true} {
+			for _, orderByTimeAsc := range []bool{false, true} {
+				find := &store.FindMemo{
+					CreatorID:        &user.ID,
+					OrderByPinned:    orderByPinned,
+					OrderByUpdatedTs: orderByUpdatedTs,
+					OrderByTimeAsc:   orderByTimeAsc,
+				}
+				memos, err := ts.ListMemos(ctx, find)
+				require.NoError(t, err)
+				expected := []int32{}
+				for _, memo := range memos {
+					expected = append(expected, memo.ID)
+				}
+
+				// Page through the memos two at a time.
+				limit := 2
+				find.Limit = &limit
+				paged := []int32{}
+				for {
+					page, err := ts.ListMemos(ctx, find)
+					require.NoError(t, err)
+					for _, memo := range page {
+						paged = append(paged, memo.ID)
+					}
+					if len(page) < limit {
+						break
+					}
+					find.Cursor = find.CursorOf(page[len(page)-1])
+				}
+				require.Equal(t, expected, paged, "pinned=%v updated=%v asc=%v", orderByPinned, orderByUpdatedTs, orderByTimeAsc)
+			}
+		}
+	}
+	ts.Close()
+}
+
+func TestMemoListComments(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	memo, err := ts.CreateMemo(ctx, &store.Memo{
+		UID:        "parent",
+		CreatorID:  user.ID,
+		Content:    "parent",
+		Visibility: store.Public,
+	})
+	require.NoError(t, err)
+	commentIDs := []int32{}
+	for i := 0; i < 3; i++ {
+		comment, err := ts.CreateMemo(ctx, &store.Memo{
+			UID:        fmt.Sprintf("comment-%d", i),
+			CreatorID:  user.ID,
+			Content:    "comment",
+			Visibility: store.Public,
+		})
+		require.NoError(t, err)
+		_, err = ts.UpsertMemoRelation(ctx, &store.MemoRelation{
+			MemoID:        comment.ID,
+			RelatedMemoID: memo.ID,
+			Type:          store.MemoRelationComment,
+		})
+		require.NoError(t, err)
+		commentIDs = append(commentIDs, comment.ID)
+	}
+
+	limit := 2
+	find := &store.FindMemo{
+		ParentID:       &memo.ID,
+		OrderByTimeAsc: true,
+		Limit:          &limit,
+	}
+	comments, err := ts.ListMemos(ctx, find)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(comments))
+	require.Equal(t, commentIDs[:2], []int32{comments[0].ID, comments[1].ID})
+	find.Cursor = find.CursorOf(comments[1])
+	comments, err = ts.ListMemos(ctx, find)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(comments))
+	require.Equal(t, commentIDs[2], comments[0].ID)
+	require.Equal(t, memo.ID, *comments[0].ParentID)
+	ts.Close()
+}
+
+func TestMemoListOrderBy(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	memos := []*store.Memo{}
+	for i := 0; i < 3; i++ {
+		memo, err := ts.CreateMemo(ctx, &store.Memo{
+			UID:        fmt.Sprintf("order-%d", i),
+			CreatorID:  user.ID,
+			Content:    "test",
+			Visibility: store.Public,
+		})
+		require.NoError(t, err)
+		memos = append(memos, memo)
+	}
+	// The first memo has one reaction and two comments, the last memo has two reactions.
+	for _, reaction := range []struct {
+		memo         *store.Memo
+		reactionType string
+	}{{memos[0], "👍"}, {memos[2], "👍"}, {memos[2], "🎉"}} {
+		_, err := ts.UpsertReaction(ctx, &store.Reaction{
+			CreatorID:    user.ID,
+			ContentID:    "memos/" + reaction.memo.UID,
+			ReactionType: reaction.reactionType,
+		})
+		require.NoError(t, err)
+	}
+	for i := 0; i < 2; i++ {
+		comment, err := ts.CreateMemo(ctx, &store.Memo{
+			UID:        fmt.Sprintf("order-comment-%d", i),
+			CreatorID:  user.ID,
+			Content:    "comment",
+			Visibility: store.Public,
+		})
+		require.NoError(t, err)
+		_, err = ts.UpsertMemoRelation(ctx, &store.MemoRelation{
+			MemoID:        comment.ID,
+			RelatedMemoID: memos[0].ID,
+			Type:          store.MemoRelationComment,
+		})
+		require.NoError(t, err)
+	}
+	pinned := true
+	require.NoError(t, ts.UpdateMemo(ctx, &store.UpdateMemo{ID: memos[1].ID, Pinned: &pinned}))
+
+	listIDs := func(orderBy ...store.MemoOrder) []int32 {
+		list, err := ts.ListMemos(ctx, &store.FindMemo{
+			CreatorID:       &user.ID,
+			ExcludeComments: true,
+			OrderBy:         orderBy,
+		})
+		require.NoError(t, err)
+		ids := []int32{}
+		for _, memo := range list {
+			ids = append(ids, memo.ID)
+		}
+		return ids
+	}
+	require.Equal(t, []int32{memos[2].ID, memos[0].ID, memos[1].ID}, listIDs(store.MemoOrder{Field: store.MemoOrderFieldReactionCount, Desc: true}))
+	require.Equal(t, []int32{memos[0].ID, memos[2].ID, memos[1].ID}, listIDs(store.MemoOrder{Field: store.MemoOrderFieldCommentCount, Desc: true}))
+	require.Equal(t, []int32{memos[1].ID, memos[0].ID, memos[2].ID}, listIDs(store.MemoOrder{Field: store.MemoOrderFieldPinned, Desc: true}, store.MemoOrder{Field: store.MemoOrderFieldCreatedTs}))
+
+	_, err = ts.ListMemos(ctx, &store.FindMemo{
+		OrderBy: []store.MemoOrder{{Field: store.MemoOrderFieldCreatedTs}},
+		Cursor:  &store.MemoCursor{},
+	})
+	require.Error(t, err)
+	ts.Close()
+}
@@ -0,0 +1,126 @@
+package teststore
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/usememos/memos/store"
+)
+
+func TestRateLimitStore(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	require.NoError(t, ts.UpsertRateLimit(ctx, &store.RateLimit{
+		Key:       "auth:10.0.0.1",
+		Tokens:    2.5,
+		UpdatedMs: 1700000000500,
+	}))
+	require.NoError(t, ts.UpsertRateLimit(ctx, &store.RateLimit{
+		Key:           "signin:alice",
+		Failures:      5,
+		LockedUntilTs: 1700000030,
+		UpdatedMs:     1700000000000,
+	}))
+
+	key := "auth:10.0.0.1"
+	rateLimit, err := ts.GetRateLimit(ctx, &store.FindRateLimit{Key: &key})
+	require.NoError(t, err)
+	require.Equal(t, 2.5, rateLimit.Tokens)
+	require.Equal(t, int64(1700000000500), rateLimit.UpdatedMs)
+
+	// Upserting the same key replaces its state.
+	require.NoError(t, ts.UpsertRateLimit(ctx, &store.RateLimit{
+		Key:       key,
+		Tokens:    1.25,
+		UpdatedMs: 1700000001000,
+	}))
+	rateLimit, err = ts.GetRateLimit(ctx, &store.FindRateLimit{Key: &key})
+	require.NoError(t, err)
+	require.Equal(t, 1.25, rateLimit.Tokens)
+	require.Equal(t, int64(1700000001000), rateLimit.UpdatedMs)
+	list, err := ts.ListRateLimits(ctx, &store.FindRateLimit{})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	updatedMsBefore := int64(1700000000800)
+	require.NoError(t, ts.DeleteRateLimits(ctx, &store.DeleteRateLimit{UpdatedMsBefore: &updatedMsBefore}))
+	list, err = ts.ListRateLimits(ctx, &store.FindRateLimit{})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, key, list[0].Key)
+
+	require.NoError(t, ts.DeleteRateLimits(ctx, &store.DeleteRateLimit{Key: &key}))
+	rateLimit, err = ts.GetRateLimit(ctx, &store.FindRateLimit{Key: &key})
+	require.NoError(t, err)
+	require.Nil(t, rateLimit)
+	ts.Close()
+}
+
+func TestRateLimitStoreAtomic(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+
+	// Concurrent requests take every token of the bucket once.
+	take := &store.TakeRateLimitToken{
+		Key:         "auth:10.0.0.1",
+		Burst:       5,
+		TokensPerMs: 1.0 / 60000,
+		NowMs:       1700000000000,
+	}
+	taken := make(chan bool, 20)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := ts.TakeRateLimitToken(ctx, take)
+			assert.NoError(t, err)
+			taken <- ok
+		}()
+	}
+	wg.Wait()
+	close(taken)
+	count := 0
+	for ok := range taken {
+		if ok {
+			count++
+		}
+	}
+	require.Equal(t, 5, count)
+
+	// The bucket refills by the time passed.
+	take.NowMs += 60000
+	ok, err := ts.TakeRateLimitToken(ctx, take)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = ts.TakeRateLimitToken(ctx, take)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Failures count up, and start over after the window.
+	increase := &store.IncreaseRateLimitFailures{
+		Key:      "signin:alice",
+		NowMs:    1700000000000,
+		WindowMs: 1000,
+	}
+	for _, expected := range []int32{1, 2, 3} {
+		failures, err := ts.IncreaseRateLimitFailures(ctx, increase)
+		require.NoError(t, err)
+		require.Equal(t, expected, failures)
+	}
+	require.NoError(t, ts.LockRateLimit(ctx, &store.LockRateLimit{Key: increase.Key, LockedUntilTs: 1700000060}))
+	// A shorter lockout does not shorten the current one.
+	require.NoError(t, ts.LockRateLimit(ctx, &store.LockRateLimit{Key: increase.Key, LockedUntilTs: 1700000030}))
+	rateLimit, err := ts.GetRateLimit(ctx, &store.FindRateLimit{Key: &increase.Key})
+	require.NoError(t, err)
+	require.Equal(t, int64(1700000060), rateLimit.LockedUntilTs)
+	increase.NowMs += 2000
+	failures, err := ts.IncreaseRateLimitFailures(ctx, increase)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), failures)
+	ts.Close()
+}
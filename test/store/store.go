@@ -39,6 +39,14 @@ func resetTestingDB(ctx context.Context, profile *profile.Profile, dbDriver stor
 		DROP TABLE IF EXISTS memo;
 		DROP TABLE IF EXISTS memo_organizer;
 		DROP TABLE IF EXISTS memo_relation;
+		DROP TABLE IF EXISTS memo_revision;
+		DROP TABLE IF EXISTS memo_tag;
+		DROP TABLE IF EXISTS memo_share;
+		DROP TABLE IF EXISTS memo_grant;
+		DROP TABLE IF EXISTS user_group;
+		DROP TABLE IF EXISTS user_group_member;
+		DROP TABLE IF EXISTS custom_role;
+		DROP TABLE IF EXISTS custom_role_member;
 		DROP TABLE IF EXISTS resource;
 		DROP TABLE IF EXISTS tag;
 		DROP TABLE IF EXISTS activity;
@@ -46,7 +54,9 @@ func resetTestingDB(ctx context.Context, profile *profile.Profile, dbDriver stor
 		DROP TABLE IF EXISTS idp;
 		DROP TABLE IF EXISTS inbox;
 		DROP TABLE IF EXISTS webhook;
-		DROP TABLE IF EXISTS reaction;`)
+		DROP TABLE IF EXISTS webhook_delivery;
+		DROP TABLE IF EXISTS reaction;
+		DROP TABLE IF EXISTS rate_limit;`)
 		if err != nil {
 			slog.Error("failed to reset testing db", slog.String("error", err.Error()))
 			panic(err)
@@ -60,6 +70,14 @@ func resetTestingDB(ctx context.Context, profile *profile.Profile, dbDriver stor
 		DROP TABLE IF EXISTS memo CASCADE;
 		DROP TABLE IF EXISTS memo_organizer CASCADE;
 		DROP TABLE IF EXISTS memo_relation CASCADE;
+		DROP TABLE IF EXISTS memo_revision CASCADE;
+		DROP TABLE IF EXISTS memo_tag CASCADE;
+		DROP TABLE IF EXISTS memo_share CASCADE;
+		DROP TABLE IF EXISTS memo_grant CASCADE;
+		DROP TABLE IF EXISTS user_group CASCADE;
+		DROP TABLE IF EXISTS user_group_member CASCADE;
+		DROP TABLE IF EXISTS custom_role CASCADE;
+		DROP TABLE IF EXISTS custom_role_member CASCADE;
 		DROP TABLE IF EXISTS resource CASCADE;
 		DROP TABLE IF EXISTS tag CASCADE;
 		DROP TABLE IF EXISTS activity CASCADE;
@@ -67,7 +85,9 @@ func resetTestingDB(ctx context.Context, profile *profile.Profile, dbDriver stor
 		DROP TABLE IF EXISTS idp CASCADE;
 		DROP TABLE IF EXISTS inbox CASCADE;
 		DROP TABLE IF EXISTS webhook CASCADE;
-		DROP TABLE IF EXISTS reaction CASCADE;`)
+		DROP TABLE IF EXISTS webhook_delivery CASCADE;
+		DROP TABLE IF EXISTS reaction CASCADE;
+		DROP TABLE IF EXISTS rate_limit CASCADE;`)
 		if err != nil {
 			slog.Error("failed to reset testing db", slog.String("error", err.Error()))
 			panic(err)
@@ -0,0 +1,71 @@
+package teststore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/usememos/memos/store"
+)
+
+func TestUserGroupStore(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	member, err := ts.CreateUser(ctx, &store.User{
+		Username: "member",
+		Role:     store.RoleUser,
+		Email:    "member@test.com",
+	})
+	require.NoError(t, err)
+
+	group, err := ts.CreateUserGroup(ctx, &store.UserGroup{
+		Name:      "team",
+		CreatorID: user.ID,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, group.ID)
+	require.NotZero(t, group.CreatedTs)
+	_, err = ts.CreateUserGroup(ctx, &store.UserGroup{Name: "team", CreatorID: user.ID})
+	require.Error(t, err)
+
+	name := "staff"
+	group, err = ts.UpdateUserGroup(ctx, &store.UpdateUserGroup{ID: group.ID, Name: &name})
+	require.NoError(t, err)
+	require.Equal(t, "staff", group.Name)
+
+	for i := 0; i < 2; i++ {
+		_, err = ts.UpsertUserGroupMember(ctx, &store.UserGroupMember{GroupID: group.ID, UserID: member.ID})
+		require.NoError(t, err)
+	}
+	members, err := ts.ListUserGroupMembers(ctx, &store.FindUserGroupMember{GroupID: &group.ID})
+	require.NoError(t, err)
+	require.Equal(t, []*store.UserGroupMember{{GroupID: group.ID, UserID: member.ID}}, members)
+	groups, err := ts.ListUserGroups(ctx, &store.FindUserGroup{MemberID: &member.ID})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	groups, err = ts.ListUserGroups(ctx, &store.FindUserGroup{MemberID: &user.ID})
+	require.NoError(t, err)
+	require.Len(t, groups, 0)
+
+	// Deleting the group deletes its members and the memo grants to it.
+	memo, err := ts.CreateMemo(ctx, &store.Memo{UID: "granted", CreatorID: user.ID, Content: "test_content", Visibility: store.Private})
+	require.NoError(t, err)
+	_, err = ts.UpsertMemoGrant(ctx, &store.MemoGrant{
+		MemoID:        memo.ID,
+		PrincipalType: store.MemoGrantPrincipalGroup,
+		PrincipalID:   group.ID,
+		Permission:    store.MemoPermissionRead,
+	})
+	require.NoError(t, err)
+	require.NoError(t, ts.DeleteUserGroup(ctx, &store.DeleteUserGroup{ID: group.ID}))
+	members, err = ts.ListUserGroupMembers(ctx, &store.FindUserGroupMember{GroupID: &group.ID})
+	require.NoError(t, err)
+	require.Len(t, members, 0)
+	grants, err := ts.ListMemoGrants(ctx, &store.FindMemoGrant{MemoID: &memo.ID})
+	require.NoError(t, err)
+	require.Len(t, grants, 0)
+	ts.Close()
+}
@@ -2,9 +2,14 @@ package teststore
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	storepb "github.com/usememos/memos/proto/gen/store"
 	"github.com/usememos/memos/store"
@@ -26,3 +31,202 @@ func TestUserSettingStore(t *testing.T) {
 	require.Equal(t, 1, len(list))
 	ts.Close()
 }
+
+func TestUserAccessTokenUsage(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	_, err = ts.UpsertUserSetting(ctx, &storepb.UserSetting{
+		UserId: user.ID,
+		Key:    storepb.UserSettingKey_ACCESS_TOKENS,
+		Value: &storepb.UserSetting_AccessTokens{
+			AccessTokens: &storepb.AccessTokensUserSetting{
+				AccessTokens: []*storepb.AccessTokensUserSetting_AccessToken{
+					{AccessToken: "token-1", Description: "script", Scopes: []string{"memos:write"}},
+					{AccessToken: "token-2", Description: "user login"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	usedTime := time.Unix(1700000000, 0)
+	require.NoError(t, ts.UpdateUserAccessTokenUsage(ctx, user.ID, "token-1", usedTime, "10.0.0.1"))
+	accessTokens, err := ts.GetUserAccessTokens(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(accessTokens))
+	require.Equal(t, []string{"memos:write"}, accessTokens[0].Scopes)
+	require.Equal(t, usedTime.Unix(), accessTokens[0].LastUsedTime.AsTime().Unix())
+	require.Equal(t, "10.0.0.1", accessTokens[0].LastUsedIp)
+	require.Nil(t, accessTokens[1].LastUsedTime)
+
+	require.Error(t, ts.UpdateUserAccessTokenUsage(ctx, user.ID, "unknown", usedTime, "10.0.0.1"))
+	ts.Close()
+}
+
+func TestUserTwoFactorAuth(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	twoFactorAuth, err := ts.GetUserTwoFactorAuth(ctx, user.ID)
+	require.NoError(t, err)
+	require.Nil(t, twoFactorAuth)
+
+	require.NoError(t, ts.UpsertUserTwoFactorAuth(ctx, user.ID, &storepb.TwoFactorAuthUserSetting{
+		TotpSecret:         "JBSWY3DPEHPK3PXP",
+		Enabled:            true,
+		RecoveryCodeHashes: []string{"hash-1", "hash-2"},
+		LastUsedStep:       56666666,
+	}))
+	twoFactorAuth, err = ts.GetUserTwoFactorAuth(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, twoFactorAuth.Enabled)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", twoFactorAuth.TotpSecret)
+	require.Equal(t, []string{"hash-1", "hash-2"}, twoFactorAuth.RecoveryCodeHashes)
+	require.Equal(t, int64(56666666), twoFactorAuth.LastUsedStep)
+
+	// Reading the setting from the database returns the same value as the cache.
+	list, err := ts.ListUserSettings(ctx, &store.FindUserSetting{UserID: &user.ID, Key: storepb.UserSettingKey_TWO_FACTOR_AUTH})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, []string{"hash-1", "hash-2"}, list[0].GetTwoFactorAuth().RecoveryCodeHashes)
+	ts.Close()
+}
+
+func TestRemoveUserAccessTokens(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	_, err = ts.UpsertUserSetting(ctx, &storepb.UserSetting{
+		UserId: user.ID,
+		Key:    storepb.UserSettingKey_ACCESS_TOKENS,
+		Value: &storepb.UserSetting_AccessTokens{
+			AccessTokens: &storepb.AccessTokensUserSetting{
+				AccessTokens: []*storepb.AccessTokensUserSetting_AccessToken{
+					{AccessToken: "token-1", Description: "script"},
+					{AccessToken: "token-2", Description: "user login", Session: &storepb.AccessTokensUserSetting_Session{
+						Id:         "session-2",
+						UserAgent:  "Mozilla/5.0",
+						Ip:         "10.0.0.2",
+						CreateTime: timestamppb.New(time.Unix(1700000000, 0)),
+					}},
+					{AccessToken: "token-3", Description: "user login", Session: &storepb.AccessTokensUserSetting_Session{
+						Id: "session-3",
+					}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// The session is read back from the database as it was written.
+	list, err := ts.ListUserSettings(ctx, &store.FindUserSetting{UserID: &user.ID, Key: storepb.UserSettingKey_ACCESS_TOKENS})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	session := list[0].GetAccessTokens().AccessTokens[1].Session
+	require.Equal(t, "session-2", session.Id)
+	require.Equal(t, "Mozilla/5.0", session.UserAgent)
+	require.Equal(t, "10.0.0.2", session.Ip)
+	require.Equal(t, int64(1700000000), session.CreateTime.AsTime().Unix())
+
+	removed, err := ts.RemoveUserAccessTokens(ctx, user.ID, func(accessToken *storepb.AccessTokensUserSetting_AccessToken) bool {
+		return accessToken.Session.GetId() == "session-3"
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	removed, err = ts.RemoveUserAccessTokens(ctx, user.ID, func(accessToken *storepb.AccessTokensUserSetting_AccessToken) bool {
+		return accessToken.Session.GetId() == "unknown"
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, removed)
+
+	removed, err = ts.RemoveUserAccessTokens(ctx, user.ID, func(accessToken *storepb.AccessTokensUserSetting_AccessToken) bool {
+		return accessToken.Session != nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	accessTokens, err := ts.GetUserAccessTokens(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, accessTokens, 1)
+	require.Equal(t, "token-1", accessTokens[0].AccessToken)
+	ts.Close()
+}
+
+func TestUpdateUserAccessTokensConcurrently(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	require.NoError(t, ts.AddUserAccessToken(ctx, user.ID, &storepb.AccessTokensUserSetting_AccessToken{AccessToken: "revoked"}))
+
+	// Concurrent sign-ins, usage records and revocations must not overwrite each other.
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, ts.AddUserAccessToken(ctx, user.ID, &storepb.AccessTokensUserSetting_AccessToken{AccessToken: fmt.Sprintf("token-%d", i)}))
+		}()
+		go func() {
+			defer wg.Done()
+			_ = ts.UpdateUserAccessTokenUsage(ctx, user.ID, "revoked", time.Now(), "10.0.0.1")
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, ts.RemoveUserAccessToken(ctx, user.ID, "revoked"))
+		}()
+	}
+	wg.Wait()
+
+	accessTokens, err := ts.GetUserAccessTokens(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, accessTokens, 50)
+	for _, accessToken := range accessTokens {
+		require.NotEqual(t, "revoked", accessToken.AccessToken)
+	}
+	ts.Close()
+}
+
+func TestUpdateUserTwoFactorAuthConcurrently(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	require.NoError(t, ts.UpsertUserTwoFactorAuth(ctx, user.ID, &storepb.TwoFactorAuthUserSetting{
+		TotpSecret:         "secret",
+		Enabled:            true,
+		RecoveryCodeHashes: []string{"code"},
+	}))
+
+	// Concurrent sign-ins use the same recovery code once.
+	used := make(chan bool, 20)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok := false
+			assert.NoError(t, ts.UpdateUserTwoFactorAuth(ctx, user.ID, func(twoFactorAuth *storepb.TwoFactorAuthUserSetting) (*storepb.TwoFactorAuthUserSetting, error) {
+				if len(twoFactorAuth.RecoveryCodeHashes) == 0 {
+					return nil, nil
+				}
+				ok = true
+				return &storepb.TwoFactorAuthUserSetting{TotpSecret: twoFactorAuth.TotpSecret, Enabled: true}, nil
+			}))
+			used <- ok
+		}()
+	}
+	wg.Wait()
+	close(used)
+	count := 0
+	for ok := range used {
+		if ok {
+			count++
+		}
+	}
+	require.Equal(t, 1, count)
+	ts.Close()
+}
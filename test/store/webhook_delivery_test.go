@@ -0,0 +1,189 @@
+package teststore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/usememos/memos/server/runner/webhookdelivery"
+	"github.com/usememos/memos/store"
+)
+
+func TestWebhookDeliveryStore(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	webhook, err := ts.CreateWebhook(ctx, &store.Webhook{
+		CreatorID: user.ID,
+		Name:      "test_webhook",
+		URL:       "https://example.com",
+	})
+	require.NoError(t, err)
+
+	now := time.Now().Unix()
+	delivery, err := ts.CreateWebhookDelivery(ctx, &store.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		ActivityType:  "memos.memo.created",
+		Payload:       `{"activityType":"memos.memo.created"}`,
+		Status:        store.WebhookDeliveryPending,
+		NextAttemptTs: now,
+	})
+	require.NoError(t, err)
+	require.Equal(t, store.WebhookDeliveryPending, delivery.Status)
+
+	pending := store.WebhookDeliveryPending
+	deliveries, err := ts.ListWebhookDeliveries(ctx, &store.FindWebhookDelivery{
+		Status:              &pending,
+		NextAttemptTsBefore: &now,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(deliveries))
+	require.Equal(t, `{"activityType":"memos.memo.created"}`, deliveries[0].Payload)
+
+	succeeded, attempts, code, body := store.WebhookDeliverySucceeded, int32(1), int32(200), "ok"
+	updatedDelivery, err := ts.UpdateWebhookDelivery(ctx, &store.UpdateWebhookDelivery{
+		ID:           delivery.ID,
+		Status:       &succeeded,
+		Attempts:     &attempts,
+		ResponseCode: &code,
+		ResponseBody: &body,
+	})
+	require.NoError(t, err)
+	require.Equal(t, store.WebhookDeliverySucceeded, updatedDelivery.Status)
+	require.Equal(t, attempts, updatedDelivery.Attempts)
+	require.Equal(t, code, updatedDelivery.ResponseCode)
+	require.Equal(t, body, updatedDelivery.ResponseBody)
+	deliveries, err = ts.ListWebhookDeliveries(ctx, &store.FindWebhookDelivery{
+		Status: &pending,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(deliveries))
+
+	err = ts.DeleteWebhookDeliveries(ctx, &store.DeleteWebhookDelivery{
+		WebhookID: &webhook.ID,
+	})
+	require.NoError(t, err)
+	deliveries, err = ts.ListWebhookDeliveries(ctx, &store.FindWebhookDelivery{
+		WebhookID: &webhook.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(deliveries))
+	ts.Close()
+}
+
+func TestWebhookDeliveryRunner(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+
+	statusCode := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte("response"))
+	}))
+	defer server.Close()
+	webhook, err := ts.CreateWebhook(ctx, &store.Webhook{
+		CreatorID: user.ID,
+		Name:      "test_webhook",
+		URL:       server.URL,
+	})
+	require.NoError(t, err)
+	delivery, err := ts.CreateWebhookDelivery(ctx, &store.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		ActivityType:  "memos.memo.created",
+		Payload:       "{}",
+		Status:        store.WebhookDeliveryPending,
+		NextAttemptTs: time.Now().Unix(),
+	})
+	require.NoError(t, err)
+
+	// A failed attempt is retried later.
+	runner := webhookdelivery.NewRunner(ts)
+	runner.RunOnce(ctx)
+	delivery, err = ts.GetWebhookDelivery(ctx, &store.FindWebhookDelivery{ID: &delivery.ID})
+	require.NoError(t, err)
+	require.Equal(t, store.WebhookDeliveryPending, delivery.Status)
+	require.Equal(t, int32(1), delivery.Attempts)
+	require.Equal(t, int32(http.StatusInternalServerError), delivery.ResponseCode)
+	require.Equal(t, "response", delivery.ResponseBody)
+	require.NotEmpty(t, delivery.Error)
+	require.Greater(t, delivery.NextAttemptTs, time.Now().Unix())
+
+	// The delivery is not due yet.
+	runner.RunOnce(ctx)
+	delivery, err = ts.GetWebhookDelivery(ctx, &store.FindWebhookDelivery{ID: &delivery.ID})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), delivery.Attempts)
+
+	statusCode = http.StatusOK
+	require.NoError(t, runner.Deliver(ctx, delivery))
+	delivery, err = ts.GetWebhookDelivery(ctx, &store.FindWebhookDelivery{ID: &delivery.ID})
+	require.NoError(t, err)
+	require.Equal(t, store.WebhookDeliverySucceeded, delivery.Status)
+	require.Equal(t, int32(2), delivery.Attempts)
+	require.Equal(t, int32(http.StatusOK), delivery.ResponseCode)
+	require.Empty(t, delivery.Error)
+
+	// A delivery of a deleted webhook fails without being retried.
+	err = ts.DeleteWebhook(ctx, &store.DeleteWebhook{ID: webhook.ID})
+	require.NoError(t, err)
+	require.NoError(t, runner.Deliver(ctx, delivery))
+	delivery, err = ts.GetWebhookDelivery(ctx, &store.FindWebhookDelivery{ID: &delivery.ID})
+	require.NoError(t, err)
+	require.Equal(t, store.WebhookDeliveryFailed, delivery.Status)
+	ts.Close()
+}
+
+func TestWebhookDeliveryRunnerQueues(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer working.Close()
+
+	deliveries := map[string][]*store.WebhookDelivery{}
+	for _, url := range []string{failing.URL, working.URL} {
+		webhook, err := ts.CreateWebhook(ctx, &store.Webhook{
+			CreatorID: user.ID,
+			Name:      "test_webhook",
+			URL:       url,
+		})
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			delivery, err := ts.CreateWebhookDelivery(ctx, &store.WebhookDelivery{
+				WebhookID:     webhook.ID,
+				ActivityType:  "memos.memo.created",
+				Payload:       "{}",
+				Status:        store.WebhookDeliveryPending,
+				NextAttemptTs: time.Now().Unix(),
+			})
+			require.NoError(t, err)
+			deliveries[url] = append(deliveries[url], delivery)
+		}
+	}
+
+	// A failing endpoint is only attempted once per run, and does not hold up the other endpoints.
+	webhookdelivery.NewRunner(ts).RunOnce(ctx)
+	for url, expected := range map[string][]int32{failing.URL: {1, 0, 0}, working.URL: {1, 1, 1}} {
+		for i, delivery := range deliveries[url] {
+			delivery, err := ts.GetWebhookDelivery(ctx, &store.FindWebhookDelivery{ID: &delivery.ID})
+			require.NoError(t, err)
+			require.Equal(t, expected[i], delivery.Attempts)
+		}
+	}
+	ts.Close()
+}
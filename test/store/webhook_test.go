@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	storepb "github.com/usememos/memos/proto/gen/store"
 	"github.com/usememos/memos/store"
 )
 
@@ -47,3 +48,42 @@ func TestWebhookStore(t *testing.T) {
 	require.Equal(t, 0, len(webhooks))
 	ts.Close()
 }
+
+func TestWebhookStorePayload(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestingStore(ctx, t)
+	user, err := createTestingHostUser(ctx, ts)
+	require.NoError(t, err)
+	webhook, err := ts.CreateWebhook(ctx, &store.Webhook{
+		CreatorID: user.ID,
+		Name:      "test_webhook",
+		URL:       "https://example.com",
+		Secret:    "secret",
+		Payload: &storepb.WebhookPayload{
+			Headers:    map[string]string{"X-Api-Key": "key"},
+			EventTypes: []string{"memos.reaction.created"},
+		},
+	})
+	require.NoError(t, err)
+	webhook, err = ts.GetWebhook(ctx, &store.FindWebhook{
+		ID: &webhook.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "secret", webhook.Secret)
+	require.Equal(t, map[string]string{"X-Api-Key": "key"}, webhook.Payload.Headers)
+	require.Equal(t, []string{"memos.reaction.created"}, webhook.Payload.EventTypes)
+
+	newSecret := ""
+	updatedWebhook, err := ts.UpdateWebhook(ctx, &store.UpdateWebhook{
+		ID:     webhook.ID,
+		Secret: &newSecret,
+		Payload: &storepb.WebhookPayload{
+			Headers: map[string]string{"Authorization": "Bearer token"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "", updatedWebhook.Secret)
+	require.Equal(t, map[string]string{"Authorization": "Bearer token"}, updatedWebhook.Payload.Headers)
+	require.Equal(t, webhook.Name, updatedWebhook.Name)
+	ts.Close()
+}